@@ -0,0 +1,275 @@
+package cmd
+
+import (
+	"asvec/cmd/flags"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+
+	avs "github.com/aerospike/avs-client-go"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+//nolint:govet // Padding not a concern for a CLI
+var applyFlags = &struct {
+	clientFlags *flags.ClientFlags
+	yes         bool
+	dryRun      bool
+	inputFile   string
+}{
+	clientFlags: rootFlags.clientFlags,
+}
+
+func newApplyFlagSet() *pflag.FlagSet {
+	flagSet := &pflag.FlagSet{}
+	flagSet.BoolVarP(&applyFlags.yes, flags.Yes, flags.YesShort, false, "When true do not prompt for confirmation.")                                                                               //nolint:lll // For readability
+	flagSet.BoolVar(&applyFlags.dryRun, flags.DryRun, false, "Display the plan without applying it.")                                                                                              //nolint:lll // For readability
+	flagSet.StringVarP(&applyFlags.inputFile, flags.InputFile, flags.InputFileShort, StdIn, "A yaml or json manifest containing \"indices\" and \"users\". Use \"-\" or omit to read from stdin.") //nolint:lll // For readability
+
+	return flagSet
+}
+
+func newApplyCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "apply",
+		Short: "A command for applying a manifest of indexes and users",
+		Long: fmt.Sprintf(`A command for declaratively managing indexes and users. The manifest
+is compared to the cluster and the resulting plan is displayed before it is
+applied. Missing indexes and users are created, mutable index parameters and
+labels are updated, and user roles are granted or revoked to match the manifest.
+
+Changes which require an index to be dropped and recreated, such as a new
+dimension or distance metric, are displayed but never applied. Indexes and
+users which are not in the manifest are left unchanged.
+
+The "indices" key uses the format output by "asvec index ls --yaml". Users
+which do not exist are created with their "password", or you are prompted for
+one.
+
+Example manifest:
+
+indices:
+  - id:
+      namespace: test
+      name: myindex
+    dimensions: 256
+    vectorDistanceMetric: COSINE
+    field: vector
+    labels:
+      model: all-MiniLM-L6-v2
+    hnswParams:
+      batchingParams:
+        maxIndexRecords: 100000
+users:
+  - username: foo
+    roles:
+      - read-write
+
+For example:
+
+%s
+asvec apply -f cluster.yaml --%s
+asvec apply -f cluster.yaml -y
+			`, HelpTxtSetupEnv, flags.DryRun),
+		PreRunE: func(_ *cobra.Command, _ []string) error {
+			return checkSeedsAndHost()
+		},
+		RunE: func(_ *cobra.Command, _ []string) error {
+			logger.Debug("parsed flags",
+				append(applyFlags.clientFlags.NewSLogAttr(),
+					slog.Bool(flags.Yes, applyFlags.yes),
+					slog.Bool(flags.DryRun, applyFlags.dryRun),
+					slog.String(flags.InputFile, applyFlags.inputFile),
+				)...,
+			)
+
+			data, err := readApplyManifest(applyFlags.inputFile)
+			if err != nil {
+				return err
+			}
+
+			manifest, err := parseApplyManifest(data)
+			if err != nil {
+				logger.Error("failed to parse manifest", slog.Any("error", err))
+				return err
+			}
+
+			client, err := createClientFromFlags(applyFlags.clientFlags)
+			if err != nil {
+				return err
+			}
+			defer client.Close()
+
+			plan, err := newApplyPlan(client, manifest)
+			if err != nil {
+				return err
+			}
+
+			view.PrintApplyPlan(plan)
+
+			if destructive := plan.destructiveCount(); destructive > 0 {
+				view.Warningf(
+					"%d change(s) require dropping and recreating an index and will not be applied",
+					destructive,
+				)
+			}
+
+			pending := plan.pending()
+
+			if len(pending) == 0 {
+				view.Print("No changes to apply. The cluster matches the manifest.")
+				return nil
+			}
+
+			if applyFlags.dryRun {
+				return nil
+			}
+
+			if !applyFlags.yes && !confirm("Do you want to apply these changes?") {
+				return nil
+			}
+
+			return runApplyPlan(client, pending)
+		},
+	}
+}
+
+// readApplyManifest reads the manifest from file or, when file is StdIn or
+// "-", from stdin.
+func readApplyManifest(file string) ([]byte, error) {
+	if file != StdIn && file != "-" {
+		logger.Info("reading manifest file", slog.String("file", file))
+
+		data, err := os.ReadFile(file)
+		if err != nil {
+			logger.Error("failed to read manifest file", slog.Any("error", err))
+			return nil, err
+		}
+
+		return data, nil
+	}
+
+	stat, _ := os.Stdin.Stat()
+	if (stat.Mode() & os.ModeCharDevice) != 0 {
+		err := fmt.Errorf("no manifest provided, use --%s or pipe a manifest to stdin", flags.InputFile)
+		logger.Error(err.Error())
+
+		return nil, err
+	}
+
+	data, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		logger.Error("failed to read manifest from stdin", slog.Any("error", err))
+		return nil, err
+	}
+
+	return data, nil
+}
+
+func newApplyPlan(client *avs.Client, manifest *applyManifest) (*applyPlan, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), applyFlags.clientFlags.Timeout)
+	defer cancel()
+
+	indexes, err := client.IndexList(ctx, true)
+	if err != nil {
+		logger.Error("failed to list indexes", slog.Any("error", err))
+		return nil, err
+	}
+
+	logger.Debug("server index list", slog.String("response", indexes.String()))
+
+	if len(manifest.users) == 0 {
+		return planApply(manifest, indexes, nil), nil
+	}
+
+	users, err := client.ListUsers(ctx)
+	if err != nil {
+		logger.Error("failed to list users", slog.Any("error", err))
+		return nil, err
+	}
+
+	logger.Debug("server user list", slog.String("response", users.String()))
+
+	return planApply(manifest, indexes, users), nil
+}
+
+func runApplyPlan(client *avs.Client, changes []*applyChange) error {
+	failed := 0
+
+	for _, change := range changes {
+		err := runApplyChange(client, change)
+		if err != nil {
+			logger.Error("failed to apply change",
+				slog.String("resource", change.resource),
+				slog.String("name", change.name),
+				slog.Any("error", err),
+			)
+			view.Errorf("Failed to %s %s %s: %s", change.action, change.resource, change.name, err)
+
+			failed++
+
+			continue
+		}
+
+		view.Printf("Successfully applied %s %s %s", change.action, change.resource, change.name)
+	}
+
+	if failed > 0 {
+		err := fmt.Errorf("%d of %d changes failed to apply", failed, len(changes))
+		logger.Error(err.Error())
+
+		return err
+	}
+
+	view.Print("Successfully applied all changes")
+
+	return nil
+}
+
+func runApplyChange(client *avs.Client, change *applyChange) error {
+	if change.resource == applyResourceUser && change.action == applyActionCreate && change.user.Password == "" {
+		password, err := passwordPrompt(fmt.Sprintf("New password for user %s: ", change.name))
+		if err != nil {
+			return err
+		}
+
+		change.user.Password = password
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), applyFlags.clientFlags.Timeout)
+	defer cancel()
+
+	switch {
+	case change.resource == applyResourceIndex && change.action == applyActionCreate:
+		return client.IndexCreateFromIndexDef(ctx, change.index)
+	case change.resource == applyResourceIndex:
+		id := change.index.GetId()
+		return client.IndexUpdate(ctx, id.GetNamespace(), id.GetName(), change.labels, change.indexUpdate, change.mode)
+	case change.action == applyActionCreate:
+		return client.CreateUser(ctx, change.user.Username, change.user.Password, change.user.Roles)
+	}
+
+	if len(change.grant) > 0 {
+		err := client.GrantRoles(ctx, change.name, change.grant)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(change.revoke) > 0 {
+		return client.RevokeRoles(ctx, change.name, change.revoke)
+	}
+
+	return nil
+}
+
+func init() {
+	applyCmd := newApplyCmd()
+	rootCmd.AddCommand(applyCmd)
+
+	flagSet := newApplyFlagSet()
+	applyCmd.Flags().AddFlagSet(flagSet)
+}
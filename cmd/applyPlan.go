@@ -0,0 +1,394 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/aerospike/avs-client-go/protos"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	applyResourceIndex = "index"
+	applyResourceUser  = "user"
+)
+
+type applyAction string
+
+const (
+	applyActionCreate applyAction = "create"
+	applyActionUpdate applyAction = "update"
+	applyActionNone   applyAction = "none"
+)
+
+// mutableIndexFields are the index definition fields which can be changed
+// using an index update. Changes to any other field require the index to be
+// dropped and recreated.
+var mutableIndexFields = []string{
+	"labels",
+	"mode",
+	"hnswParams.maxMemQueueSize",
+	"hnswParams.batchingParams",
+	"hnswParams.indexCachingParams",
+	"hnswParams.recordCachingParams",
+	"hnswParams.healerParams",
+	"hnswParams.mergeParams",
+	"hnswParams.enableVectorIntegrityCheck",
+}
+
+// applyManifest is the desired state of a cluster. The indices key uses the
+// format output by "asvec index list --yaml". Protobuf can't tell an empty map
+// from an unset one, so labelsSet records the indexes with a labels key, which
+// is how "labels: {}" removes all labels. In the same way rolesSet records the
+// users with a roles key, so a user without one keeps their roles.
+type applyManifest struct {
+	indexes   *protos.IndexDefinitionList
+	labelsSet map[string]bool
+	users     []*applyUser
+	rolesSet  map[string]bool
+}
+
+type applyUser struct {
+	Username string   `json:"username"`
+	Password string   `json:"password"`
+	Roles    []string `json:"roles"`
+}
+
+// applyChange is a single step of an apply plan. Destructive changes are
+// displayed but never applied.
+type applyChange struct {
+	resource    string
+	name        string
+	action      applyAction
+	diffs       []fieldDiff
+	destructive []fieldDiff
+
+	index       *protos.IndexDefinition
+	indexUpdate *protos.HnswIndexUpdate
+	labels      map[string]string
+	mode        *protos.IndexMode
+	user        *applyUser
+	grant       []string
+	revoke      []string
+}
+
+type applyPlan struct {
+	changes []*applyChange
+}
+
+// pending returns the changes which modify the cluster.
+func (p *applyPlan) pending() []*applyChange {
+	pending := []*applyChange{}
+
+	for _, c := range p.changes {
+		if c.action != applyActionNone {
+			pending = append(pending, c)
+		}
+	}
+
+	return pending
+}
+
+func (p *applyPlan) destructiveCount() int {
+	count := 0
+
+	for _, c := range p.changes {
+		count += len(c.destructive)
+	}
+
+	return count
+}
+
+// parseApplyManifest parses a YAML or JSON manifest containing an
+// IndexDefinitionList under "indices" and a list of users under "users".
+func parseApplyManifest(data []byte) (*applyManifest, error) {
+	intermediate := map[string]any{}
+
+	err := yaml.Unmarshal(data, &intermediate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse manifest: %w", err)
+	}
+
+	manifest := &applyManifest{
+		indexes:   &protos.IndexDefinitionList{},
+		labelsSet: map[string]bool{},
+		users:     []*applyUser{},
+		rolesSet:  map[string]bool{},
+	}
+
+	rawUsers, _ := intermediate["users"].([]any)
+
+	if users, ok := intermediate["users"]; ok {
+		delete(intermediate, "users")
+
+		usersJSON, err := json.Marshal(users)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse manifest users: %w", err)
+		}
+
+		err = json.Unmarshal(usersJSON, &manifest.users)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse manifest users: %w", err)
+		}
+	}
+
+	indexesJSON, err := json.Marshal(intermediate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse manifest indices: %w", err)
+	}
+
+	err = protojson.Unmarshal(indexesJSON, manifest.indexes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse manifest indices: %w", err)
+	}
+
+	seen := map[string]bool{}
+	rawIndexes, _ := intermediate["indices"].([]any)
+
+	for i, index := range manifest.indexes.GetIndices() {
+		name := indexFullName(index.GetId())
+		if index.GetId().GetNamespace() == "" || index.GetId().GetName() == "" {
+			return nil, fmt.Errorf("manifest index %q is missing a namespace or name", name)
+		}
+
+		if seen[name] {
+			return nil, fmt.Errorf("manifest index %s is defined more than once", name)
+		}
+
+		seen[name] = true
+
+		if raw, ok := rawIndexes[i].(map[string]any); ok {
+			_, manifest.labelsSet[name] = raw["labels"]
+		}
+	}
+
+	seen = map[string]bool{}
+
+	for i, user := range manifest.users {
+		if user.Username == "" {
+			return nil, fmt.Errorf("manifest user is missing a username")
+		}
+
+		if seen[user.Username] {
+			return nil, fmt.Errorf("manifest user %s is defined more than once", user.Username)
+		}
+
+		seen[user.Username] = true
+
+		if raw, ok := rawUsers[i].(map[string]any); ok {
+			_, manifest.rolesSet[user.Username] = raw["roles"]
+		}
+	}
+
+	return manifest, nil
+}
+
+// planApply compares a manifest to the indexes and users of a cluster.
+// Indexes and users which exist on the cluster but are not in the manifest are
+// left unchanged.
+func planApply(
+	manifest *applyManifest,
+	indexes *protos.IndexDefinitionList,
+	users *protos.ListUsersResponse,
+) *applyPlan {
+	plan := &applyPlan{changes: []*applyChange{}}
+
+	liveIndexes := map[string]*protos.IndexDefinition{}
+	for _, index := range indexes.GetIndices() {
+		liveIndexes[indexFullName(index.GetId())] = index
+	}
+
+	for _, desired := range manifest.indexes.GetIndices() {
+		name := indexFullName(desired.GetId())
+
+		live, ok := liveIndexes[name]
+		if !ok {
+			plan.changes = append(plan.changes, &applyChange{
+				resource: applyResourceIndex,
+				name:     name,
+				action:   applyActionCreate,
+				index:    desired,
+			})
+
+			continue
+		}
+
+		plan.changes = append(plan.changes, planIndexUpdate(name, desired, live, manifest.labelsSet[name]))
+	}
+
+	liveUsers := map[string]*protos.User{}
+	for _, user := range users.GetUsers() {
+		liveUsers[user.GetUsername()] = user
+	}
+
+	for _, desired := range manifest.users {
+		live, ok := liveUsers[desired.Username]
+		if !ok {
+			plan.changes = append(plan.changes, &applyChange{
+				resource: applyResourceUser,
+				name:     desired.Username,
+				action:   applyActionCreate,
+				user:     desired,
+			})
+
+			continue
+		}
+
+		plan.changes = append(plan.changes, planUserUpdate(desired, live, manifest.rolesSet[desired.Username]))
+	}
+
+	return plan
+}
+
+// planIndexUpdate compares an index in the manifest to the live index. Fields
+// not set in the manifest are left unchanged, except labels when labelsSet.
+func planIndexUpdate(name string, desired, live *protos.IndexDefinition, labelsSet bool) *applyChange {
+	change := &applyChange{
+		resource: applyResourceIndex,
+		name:     name,
+		action:   applyActionNone,
+		index:    desired,
+	}
+
+	// The id is used to match the indexes and can not differ.
+	desired, _ = proto.Clone(desired).(*protos.IndexDefinition)
+	desired.Id = nil
+
+	changed := map[string]bool{}
+
+	for _, diff := range diffMessages("", live.ProtoReflect(), desired.ProtoReflect(), true) {
+		mutable := ""
+
+		for _, field := range mutableIndexFields {
+			if diff.field == field || strings.HasPrefix(diff.field, field+".") {
+				mutable = field
+				break
+			}
+		}
+
+		if mutable == "" {
+			change.destructive = append(change.destructive, diff)
+			continue
+		}
+
+		change.diffs = append(change.diffs, diff)
+		changed[mutable] = true
+	}
+
+	if labelsSet && len(desired.GetLabels()) == 0 && len(live.GetLabels()) > 0 {
+		fd := live.ProtoReflect().Descriptor().Fields().ByName("labels")
+
+		change.diffs = append(change.diffs, fieldDiff{
+			field: fd.JSONName(),
+			old:   formatProtoField(live.ProtoReflect(), fd),
+			new:   "{}",
+		})
+		changed["labels"] = true
+	}
+
+	if len(change.diffs) == 0 {
+		return change
+	}
+
+	change.action = applyActionUpdate
+
+	if changed["labels"] {
+		change.labels = desired.GetLabels()
+		if change.labels == nil {
+			change.labels = map[string]string{}
+		}
+	}
+
+	if changed["mode"] {
+		change.mode = desired.Mode
+	}
+
+	hnsw := desired.GetHnswParams()
+	update := &protos.HnswIndexUpdate{}
+
+	if changed["hnswParams.maxMemQueueSize"] {
+		update.MaxMemQueueSize = hnsw.MaxMemQueueSize
+	}
+
+	if changed["hnswParams.batchingParams"] {
+		update.BatchingParams = hnsw.GetBatchingParams()
+	}
+
+	if changed["hnswParams.indexCachingParams"] {
+		update.IndexCachingParams = hnsw.GetIndexCachingParams()
+	}
+
+	if changed["hnswParams.recordCachingParams"] {
+		update.RecordCachingParams = hnsw.GetRecordCachingParams()
+	}
+
+	if changed["hnswParams.healerParams"] {
+		update.HealerParams = hnsw.GetHealerParams()
+	}
+
+	if changed["hnswParams.mergeParams"] {
+		update.MergeParams = hnsw.GetMergeParams()
+	}
+
+	if changed["hnswParams.enableVectorIntegrityCheck"] {
+		update.EnableVectorIntegrityCheck = hnsw.EnableVectorIntegrityCheck
+	}
+
+	change.indexUpdate = update
+
+	return change
+}
+
+// planUserUpdate compares a user in the manifest to the live user. Roles are
+// only changed when rolesSet.
+func planUserUpdate(desired *applyUser, live *protos.User, rolesSet bool) *applyChange {
+	change := &applyChange{
+		resource: applyResourceUser,
+		name:     desired.Username,
+		action:   applyActionNone,
+	}
+
+	if !rolesSet {
+		return change
+	}
+
+	for _, role := range desired.Roles {
+		if !slices.Contains(live.GetRoles(), role) && !slices.Contains(change.grant, role) {
+			change.grant = append(change.grant, role)
+		}
+	}
+
+	for _, role := range live.GetRoles() {
+		if !slices.Contains(desired.Roles, role) {
+			change.revoke = append(change.revoke, role)
+		}
+	}
+
+	if len(change.grant) == 0 && len(change.revoke) == 0 {
+		return change
+	}
+
+	change.action = applyActionUpdate
+	change.diffs = []fieldDiff{{
+		field: "roles",
+		old:   formatRoles(live.GetRoles()),
+		new:   formatRoles(desired.Roles),
+	}}
+
+	return change
+}
+
+func formatRoles(roles []string) string {
+	sorted := slices.Clone(roles)
+	slices.Sort(sorted)
+
+	return "[" + strings.Join(sorted, ", ") + "]"
+}
+
+func indexFullName(id *protos.IndexId) string {
+	return fmt.Sprintf("%s.%s", id.GetNamespace(), id.GetName())
+}
@@ -0,0 +1,260 @@
+//go:build unit
+
+package cmd
+
+import (
+	"asvec/utils"
+	"bytes"
+	"log/slog"
+	"testing"
+
+	"github.com/aerospike/avs-client-go/protos"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testManifest = `
+indices:
+  - id:
+      namespace: test
+      name: existing
+    dimensions: 128
+    vectorDistanceMetric: COSINE
+    field: vector
+    labels:
+      model: new
+    hnswParams:
+      m: 32
+      batchingParams:
+        maxIndexRecords: 50000
+  - id:
+      namespace: test
+      name: missing
+    dimensions: 3
+    field: vector
+users:
+  - username: bar
+    roles: [admin, read-write]
+  - username: foo
+    password: secret
+    roles: [read-write]
+`
+
+func TestParseApplyManifest(t *testing.T) {
+	manifest, err := parseApplyManifest([]byte(testManifest))
+	require.NoError(t, err)
+
+	require.Len(t, manifest.indexes.GetIndices(), 2)
+	assert.Equal(t, "existing", manifest.indexes.GetIndices()[0].GetId().GetName())
+	assert.Equal(t, protos.VectorDistanceMetric_COSINE, manifest.indexes.GetIndices()[0].GetVectorDistanceMetric())
+	assert.Equal(t, uint32(50000), manifest.indexes.GetIndices()[0].GetHnswParams().GetBatchingParams().GetMaxIndexRecords())
+	assert.Equal(t, []*applyUser{
+		{Username: "bar", Roles: []string{"admin", "read-write"}},
+		{Username: "foo", Password: "secret", Roles: []string{"read-write"}},
+	}, manifest.users)
+}
+
+func TestParseApplyManifestErrors(t *testing.T) {
+	testCases := []struct {
+		name     string
+		manifest string
+	}{
+		{name: "invalid yaml", manifest: "indices: ["},
+		{name: "unknown field", manifest: "indices:\n  - foo: bar\n"},
+		{name: "missing index name", manifest: "indices:\n  - id:\n      namespace: test\n"},
+		{name: "duplicate index", manifest: "indices:\n  - id: {namespace: test, name: a}\n  - id: {namespace: test, name: a}\n"},
+		{name: "missing username", manifest: "users:\n  - roles: [admin]\n"},
+		{name: "duplicate user", manifest: "users:\n  - username: a\n  - username: a\n"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := parseApplyManifest([]byte(tc.manifest))
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestPlanApply(t *testing.T) {
+	manifest, err := parseApplyManifest([]byte(testManifest))
+	require.NoError(t, err)
+
+	indexes := &protos.IndexDefinitionList{
+		Indices: []*protos.IndexDefinition{
+			{
+				Id:                   &protos.IndexId{Namespace: "test", Name: "existing"},
+				Dimensions:           256,
+				VectorDistanceMetric: utils.Ptr(protos.VectorDistanceMetric_COSINE),
+				Field:                "vector",
+				Labels:               map[string]string{"model": "old"},
+				Params: &protos.IndexDefinition_HnswParams{
+					HnswParams: &protos.HnswParams{
+						M:  utils.Ptr(uint32(32)),
+						Ef: utils.Ptr(uint32(100)),
+						BatchingParams: &protos.HnswBatchingParams{
+							MaxIndexRecords: utils.Ptr(uint32(100000)),
+							IndexInterval:   utils.Ptr(uint32(30000)),
+						},
+					},
+				},
+			},
+			{
+				Id:         &protos.IndexId{Namespace: "test", Name: "unmanaged"},
+				Dimensions: 3,
+			},
+		},
+	}
+	users := &protos.ListUsersResponse{
+		Users: []*protos.User{
+			{Username: "admin", Roles: []string{"admin"}},
+			{Username: "bar", Roles: []string{"read-write", "read"}},
+		},
+	}
+
+	plan := planApply(manifest, indexes, users)
+	require.Len(t, plan.changes, 4)
+
+	existing := plan.changes[0]
+	assert.Equal(t, applyActionUpdate, existing.action)
+	assert.Equal(t, []fieldDiff{
+		{field: "hnswParams.batchingParams.maxIndexRecords", old: "100000", new: "50000"},
+		{field: "labels", old: "{model=old}", new: "{model=new}"},
+	}, existing.diffs)
+	assert.Equal(t, []fieldDiff{{field: "dimensions", old: "256", new: "128"}}, existing.destructive)
+	assert.Equal(t, map[string]string{"model": "new"}, existing.labels)
+	assert.Nil(t, existing.mode)
+	assert.Equal(t, uint32(50000), existing.indexUpdate.GetBatchingParams().GetMaxIndexRecords())
+	assert.Nil(t, existing.indexUpdate.GetHealerParams())
+	assert.Equal(t, "existing", existing.index.GetId().GetName())
+
+	missing := plan.changes[1]
+	assert.Equal(t, applyActionCreate, missing.action)
+	assert.Equal(t, "test.missing", missing.name)
+	assert.Equal(t, manifest.indexes.GetIndices()[1], missing.index)
+
+	bar := plan.changes[2]
+	assert.Equal(t, applyActionUpdate, bar.action)
+	assert.Equal(t, []string{"admin"}, bar.grant)
+	assert.Equal(t, []string{"read"}, bar.revoke)
+	assert.Equal(t, []fieldDiff{{field: "roles", old: "[read, read-write]", new: "[admin, read-write]"}}, bar.diffs)
+
+	foo := plan.changes[3]
+	assert.Equal(t, applyActionCreate, foo.action)
+	assert.Equal(t, "secret", foo.user.Password)
+
+	assert.Len(t, plan.pending(), 4)
+	assert.Equal(t, 1, plan.destructiveCount())
+}
+
+func TestPlanApplyUnchanged(t *testing.T) {
+	manifest, err := parseApplyManifest([]byte(`
+indices:
+  - id: {namespace: test, name: a}
+    dimensions: 3
+    mode: STANDALONE
+users:
+  - username: foo
+    roles: [read-write]
+`))
+	require.NoError(t, err)
+
+	indexes := &protos.IndexDefinitionList{
+		Indices: []*protos.IndexDefinition{
+			{
+				Id:         &protos.IndexId{Namespace: "test", Name: "a"},
+				Dimensions: 3,
+				Field:      "vector",
+				Mode:       utils.Ptr(protos.IndexMode_STANDALONE),
+			},
+		},
+	}
+	users := &protos.ListUsersResponse{
+		Users: []*protos.User{{Username: "foo", Roles: []string{"read-write"}}},
+	}
+
+	plan := planApply(manifest, indexes, users)
+
+	require.Len(t, plan.changes, 2)
+	assert.Empty(t, plan.pending())
+	assert.Equal(t, 0, plan.destructiveCount())
+}
+
+func TestPlanApplyOmittedRoles(t *testing.T) {
+	manifest, err := parseApplyManifest([]byte(`
+users:
+  - username: foo
+    password: secret
+`))
+	require.NoError(t, err)
+
+	users := &protos.ListUsersResponse{
+		Users: []*protos.User{{Username: "foo", Roles: []string{"admin", "read-write"}}},
+	}
+
+	plan := planApply(manifest, &protos.IndexDefinitionList{}, users)
+
+	require.Len(t, plan.changes, 1)
+	assert.Equal(t, applyActionNone, plan.changes[0].action)
+	assert.Empty(t, plan.changes[0].revoke)
+	assert.Empty(t, plan.pending())
+}
+
+func TestViewPrintApplyPlan(t *testing.T) {
+	plan := &applyPlan{
+		changes: []*applyChange{
+			{resource: applyResourceIndex, name: "test.a", action: applyActionCreate},
+			{
+				resource:    applyResourceIndex,
+				name:        "test.b",
+				action:      applyActionUpdate,
+				diffs:       []fieldDiff{{field: "mode", old: "DISTRIBUTED", new: "STANDALONE"}},
+				destructive: []fieldDiff{{field: "field", old: "vector", new: "embedding"}},
+			},
+			{resource: applyResourceUser, name: "foo", action: applyActionCreate, user: &applyUser{Roles: []string{"read-write"}}},
+			{resource: applyResourceUser, name: "bar", action: applyActionNone},
+		},
+	}
+
+	out := &bytes.Buffer{}
+	v := NewView(out, out, slog.Default())
+	v.DisableColor()
+
+	v.PrintApplyPlan(plan)
+
+	assert.Equal(t, `+ create index test.a
+~ update index test.b
+    mode: DISTRIBUTED -> STANDALONE
+  ! field: vector -> embedding (requires dropping and recreating the index, not applied)
++ create user foo with roles [read-write]
+Plan: 2 to create, 1 to update, 1 unchanged.
+`, out.String())
+}
+
+func TestPlanApplyClearLabels(t *testing.T) {
+	manifest, err := parseApplyManifest([]byte(`
+indices:
+  - id: {namespace: test, name: a}
+    labels: {}
+  - id: {namespace: test, name: b}
+`))
+	require.NoError(t, err)
+
+	labels := map[string]string{"model": "all-MiniLM-L6-v2"}
+	indexes := &protos.IndexDefinitionList{
+		Indices: []*protos.IndexDefinition{
+			{Id: &protos.IndexId{Namespace: "test", Name: "a"}, Labels: labels},
+			{Id: &protos.IndexId{Namespace: "test", Name: "b"}, Labels: labels},
+		},
+	}
+
+	plan := planApply(manifest, indexes, &protos.ListUsersResponse{})
+
+	require.Len(t, plan.changes, 2)
+
+	a := plan.changes[0]
+	assert.Equal(t, applyActionUpdate, a.action)
+	assert.Equal(t, map[string]string{}, a.labels)
+	assert.Equal(t, []fieldDiff{{field: "labels", old: "{model=all-MiniLM-L6-v2}", new: "{}"}}, a.diffs)
+
+	assert.Equal(t, applyActionNone, plan.changes[1].action)
+}
@@ -0,0 +1,473 @@
+package cmd
+
+import (
+	"asvec/cmd/flags"
+	"asvec/cmd/records"
+	"asvec/cmd/writers"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aerospike/avs-client-go/protos"
+	"github.com/jedib0t/go-pretty/v6/progress"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+const (
+	defaultBenchDuration    = 30 * time.Second
+	defaultBenchConcurrency = 8
+	benchRateTick           = time.Millisecond
+	benchErrorCodeUnknown   = "Unknown"
+)
+
+// benchCmd represents the bench command
+var benchCmd = &cobra.Command{
+	Use:   "bench",
+	Short: "A parent command for benchmarking search and write throughput.",
+	Long: `A parent command for benchmarking the search and write throughput and
+latency of an index.
+
+For example:
+
+	asvec bench --help
+		`,
+}
+
+// benchFlags are the flags shared by all bench commands.
+//
+//nolint:govet // Padding not a concern for a CLI
+type benchFlags struct {
+	clientFlags    *flags.ClientFlags
+	namespace      string
+	indexName      string
+	duration       time.Duration
+	concurrency    int
+	qps            int
+	vectorFile     string
+	timeSeriesFile string
+	output         flags.OutputFlag
+}
+
+func newBenchFlags() benchFlags {
+	return benchFlags{
+		clientFlags: rootFlags.clientFlags,
+		output:      flags.OutputTable,
+	}
+}
+
+func newBenchFlagSet(benchFlags *benchFlags) *pflag.FlagSet {
+	flagSet := &pflag.FlagSet{}
+	flagSet.StringVarP(&benchFlags.namespace, flags.Namespace, flags.NamespaceShort, "", "The namespace for the index.")                                                                                       //nolint:lll // For readability
+	flagSet.StringVarP(&benchFlags.indexName, flags.IndexName, flags.IndexNameShort, "", "The name of the index.")                                                                                             //nolint:lll // For readability
+	flagSet.DurationVar(&benchFlags.duration, flags.Duration, defaultBenchDuration, "How long to run the benchmark for.")                                                                                      //nolint:lll // For readability
+	flagSet.IntVar(&benchFlags.concurrency, flags.Concurrency, defaultBenchConcurrency, "The number of requests to have in flight at once.")                                                                   //nolint:lll // For readability
+	flagSet.IntVar(&benchFlags.qps, flags.QPS, 0, "The target number of requests per second. If 0, requests are sent as fast as --concurrency allows.")                                                        //nolint:lll // For readability
+	flagSet.StringVar(&benchFlags.vectorFile, flags.VectorFile, "", "A JSONL, CSV, npy, fvecs, or bvecs file of vectors to use, in the same format as a query file. Random vectors are used if not provided.") //nolint:lll // For readability
+	flagSet.StringVar(&benchFlags.timeSeriesFile, flags.TimeSeriesFile, "", "A file to write per-second requests, errors, and latencies to as CSV.")                                                           //nolint:lll // For readability
+	flagSet.VarP(&benchFlags.output, flags.Output, flags.OutputShort, fmt.Sprintf("The output format. Valid values: %s", strings.Join(flags.OutputEnum(), ", ")))                                              //nolint:lll // For readability
+
+	return flagSet
+}
+
+var benchRequiredFlags = []string{
+	flags.Namespace,
+	flags.IndexName,
+}
+
+func (f *benchFlags) validate() error {
+	if f.duration <= 0 {
+		return fmt.Errorf("--%s must be greater than 0", flags.Duration)
+	}
+
+	if f.concurrency < 1 {
+		return fmt.Errorf("--%s must be at least 1", flags.Concurrency)
+	}
+
+	if f.qps < 0 {
+		return fmt.Errorf("--%s must not be negative", flags.QPS)
+	}
+
+	return checkSeedsAndHost()
+}
+
+func (f *benchFlags) newSLogAttr() []any {
+	return append(
+		f.clientFlags.NewSLogAttr(),
+		slog.String(flags.Namespace, f.namespace),
+		slog.String(flags.IndexName, f.indexName),
+		slog.Duration(flags.Duration, f.duration),
+		slog.Int(flags.Concurrency, f.concurrency),
+		slog.Int(flags.QPS, f.qps),
+		slog.String(flags.VectorFile, f.vectorFile),
+		slog.String(flags.TimeSeriesFile, f.timeSeriesFile),
+		slog.String(flags.Output, f.output.String()),
+	)
+}
+
+// benchTopology describes how the client connects to the cluster.
+func benchTopology(clientFlags *flags.ClientFlags) string {
+	if isLoadBalancer(clientFlags.Seeds) {
+		return fmt.Sprintf("load balancer %s", clientFlags.Host.String())
+	}
+
+	return fmt.Sprintf("seeds %s", clientFlags.Seeds.String())
+}
+
+// benchVectors returns a function returning the vector to use for the nth
+// request. Vectors cycle through --vector-file or are random vectors matching
+// the index's dimensions, with bool vectors used for hamming indexes.
+func benchVectors(vectorFile string, indexDef *protos.IndexDefinition) (func(n int) any, error) {
+	if vectorFile == "" {
+		dimensions := int(indexDef.Dimensions)
+
+		if indexDef.VectorDistanceMetric != nil &&
+			*indexDef.VectorDistanceMetric == protos.VectorDistanceMetric_HAMMING {
+			return func(_ int) any {
+				v := make([]bool, dimensions)
+				for i := range v {
+					v[i] = rand.IntN(2) == 1 //nolint:gosec // Cryptographic randomness is not needed
+				}
+
+				return v
+			}, nil
+		}
+
+		return func(_ int) any {
+			v := make([]float32, dimensions)
+			for i := range v {
+				v[i] = rand.Float32() //nolint:gosec // Cryptographic randomness is not needed
+			}
+
+			return v
+		}, nil
+	}
+
+	queries, err := readRecallQueries(vectorFile)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(queries) == 0 {
+		return nil, fmt.Errorf("no vectors found in %s", vectorFile)
+	}
+
+	vectors := make([]any, len(queries))
+
+	for i, q := range queries {
+		if n, _ := records.VectorLen(q.vector); n != int(indexDef.Dimensions) {
+			return nil, fmt.Errorf(
+				"vector %v has %d dimensions but the index has %d", q.id, n, indexDef.Dimensions,
+			)
+		}
+
+		vectors[i] = q.vector
+	}
+
+	return func(n int) any {
+		return vectors[n%len(vectors)]
+	}, nil
+}
+
+// benchSample is the outcome of a single benchmark request.
+type benchSample struct {
+	// end is when the request completed, relative to the start of the run.
+	end     time.Duration
+	latency time.Duration
+	// code is the gRPC status code of a failed request or empty on success.
+	code string
+}
+
+// benchmark sends requests using op from concurrency workers for duration,
+// optionally limited to qps requests per second.
+type benchmark struct {
+	op          func(ctx context.Context, n int) error
+	duration    time.Duration
+	timeout     time.Duration
+	concurrency int
+	qps         int
+}
+
+func (b *benchmark) run(message string) ([]benchSample, time.Duration) {
+	// The clock starts before the deadline is set so that the elapsed time is
+	// never shorter than the requested duration.
+	start := time.Now()
+
+	ctx, cancel := context.WithTimeout(context.Background(), b.duration)
+	defer cancel()
+
+	tracker, stopProgress := newProgressTracker(message, 0, progress.UnitsDefault)
+
+	var tokens chan struct{}
+
+	if b.qps > 0 {
+		tokens = make(chan struct{}, b.concurrency)
+
+		go b.rateLimit(ctx, start, tokens)
+	}
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		n       int
+		samples = make([][]benchSample, b.concurrency)
+	)
+
+	for w := 0; w < b.concurrency; w++ {
+		wg.Add(1)
+
+		go func(w int) {
+			defer wg.Done()
+
+			for {
+				if tokens != nil {
+					if _, ok := <-tokens; !ok {
+						return
+					}
+				}
+
+				if ctx.Err() != nil {
+					return
+				}
+
+				mu.Lock()
+				seq := n
+				n++
+				mu.Unlock()
+
+				opCtx, opCancel := context.WithTimeout(context.Background(), b.timeout)
+				opStart := time.Now()
+				err := b.op(opCtx, seq)
+				end := time.Now()
+
+				opCancel()
+
+				sample := benchSample{end: end.Sub(start), latency: end.Sub(opStart)}
+
+				if err != nil {
+					logger.Debug("request failed", slog.Int("request", seq), slog.Any("error", err))
+					sample.code = grpcCode(err)
+				}
+
+				samples[w] = append(samples[w], sample)
+
+				tracker.Increment(1)
+			}
+		}(w)
+	}
+
+	wg.Wait()
+
+	elapsed := time.Since(start)
+
+	stopProgress()
+
+	all := []benchSample{}
+	for _, s := range samples {
+		all = append(all, s...)
+	}
+
+	return all, elapsed
+}
+
+// rateLimit sends qps tokens per second until ctx is done. Tokens that can't
+// be sent because every worker is busy are dropped rather than sent later in
+// a burst.
+func (b *benchmark) rateLimit(ctx context.Context, start time.Time, tokens chan<- struct{}) {
+	defer close(tokens)
+
+	ticker := time.NewTicker(benchRateTick)
+	defer ticker.Stop()
+
+	sent := 0
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			due := int(float64(b.qps) * time.Since(start).Seconds())
+
+			for ; sent < due; sent++ {
+				select {
+				case tokens <- struct{}{}:
+				default:
+					sent = due
+				}
+			}
+		}
+	}
+}
+
+var grpcCodeRegexp = regexp.MustCompile(`server error: (\w+)`)
+
+// grpcCode returns the name of the gRPC status code of err. The client
+// reports server errors as "server error: <code>" in the error message.
+func grpcCode(err error) string {
+	if m := grpcCodeRegexp.FindStringSubmatch(err.Error()); m != nil {
+		return m[1]
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		return "DeadlineExceeded"
+	}
+
+	if errors.Is(err, context.Canceled) {
+		return "Canceled"
+	}
+
+	return benchErrorCodeUnknown
+}
+
+// newBenchResult summarizes samples from a benchmark run.
+func newBenchResult(
+	operation string,
+	benchFlags *benchFlags,
+	samples []benchSample,
+	elapsed time.Duration,
+) *writers.BenchResult {
+	result := &writers.BenchResult{
+		Operation:    operation,
+		Topology:     benchTopology(benchFlags.clientFlags),
+		Concurrency:  benchFlags.concurrency,
+		TargetQPS:    benchFlags.qps,
+		Duration:     elapsed,
+		Requests:     len(samples),
+		ErrorsByCode: map[string]int{},
+	}
+
+	latencies := make([]time.Duration, 0, len(samples))
+
+	for _, s := range samples {
+		if s.code != "" {
+			result.Errors++
+			result.ErrorsByCode[s.code]++
+
+			continue
+		}
+
+		latencies = append(latencies, s.latency)
+	}
+
+	if elapsed > 0 {
+		result.QPS = float64(len(latencies)) / elapsed.Seconds()
+	}
+
+	result.P50 = percentile(latencies, 50)
+	result.P90 = percentile(latencies, 90)
+	result.P99 = percentile(latencies, 99)
+	result.P999 = percentile(latencies, 99.9)
+
+	return result
+}
+
+// writeBenchTimeSeries writes the number of requests and errors completed in
+// each second of the run along with their latency percentiles as CSV.
+func writeBenchTimeSeries(file string, samples []benchSample, elapsed time.Duration) error {
+	seconds := int(elapsed/time.Second) + 1
+	latencies := make([][]time.Duration, seconds)
+	errs := make([]int, seconds)
+
+	for _, s := range samples {
+		second := min(int(s.end/time.Second), seconds-1)
+
+		if s.code != "" {
+			errs[second]++
+			continue
+		}
+
+		latencies[second] = append(latencies[second], s.latency)
+	}
+
+	f, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w := csv.NewWriter(f)
+
+	err = w.Write([]string{"second", "requests", "errors", "latencyP50Ms", "latencyP90Ms", "latencyP99Ms"})
+	if err != nil {
+		return err
+	}
+
+	for second := range seconds {
+		l := latencies[second]
+		row := []string{
+			strconv.Itoa(second),
+			strconv.Itoa(len(l) + errs[second]),
+			strconv.Itoa(errs[second]),
+			formatMs(percentile(l, 50)),
+			formatMs(percentile(l, 90)),
+			formatMs(percentile(l, 99)),
+		}
+
+		if err := w.Write(row); err != nil {
+			return err
+		}
+	}
+
+	w.Flush()
+
+	if err := w.Error(); err != nil {
+		return err
+	}
+
+	return f.Close()
+}
+
+func formatMs(d time.Duration) string {
+	return strconv.FormatFloat(float64(d)/float64(time.Millisecond), 'f', 3, 64)
+}
+
+// runBenchmark runs op and reports the results.
+func runBenchmark(
+	operation string,
+	benchFlags *benchFlags,
+	op func(ctx context.Context, n int) error,
+) error {
+	view.PrintfErr(
+		"Running %s benchmark against %s for %s using %s",
+		operation, benchFlags.indexName, benchFlags.duration, benchTopology(benchFlags.clientFlags),
+	)
+
+	b := &benchmark{
+		op:          op,
+		duration:    benchFlags.duration,
+		timeout:     benchFlags.clientFlags.Timeout,
+		concurrency: benchFlags.concurrency,
+		qps:         benchFlags.qps,
+	}
+
+	samples, elapsed := b.run(fmt.Sprintf("Running %s benchmark", operation))
+	result := newBenchResult(operation, benchFlags, samples, elapsed)
+
+	view.PrintBenchResult(result, benchFlags.output)
+
+	if benchFlags.timeSeriesFile != "" {
+		if err := writeBenchTimeSeries(benchFlags.timeSeriesFile, samples, elapsed); err != nil {
+			logger.Error("unable to write time series", slog.Any("error", err))
+			return err
+		}
+	}
+
+	if result.Errors > 0 {
+		view.Warningf("%d of %d requests failed", result.Errors, result.Requests)
+	}
+
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(benchCmd)
+}
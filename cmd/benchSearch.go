@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"asvec/cmd/flags"
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/aerospike/avs-client-go/protos"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+//nolint:govet // Padding not a concern for a CLI
+var benchSearchFlags = &struct {
+	benchFlags
+	maxResults uint32
+	hnswEf     flags.Uint32OptionalFlag
+}{
+	benchFlags: newBenchFlags(),
+	maxResults: defaultMaxResults,
+}
+
+func newBenchSearchFlagSet() *pflag.FlagSet {
+	flagSet := newBenchFlagSet(&benchSearchFlags.benchFlags)
+	flagSet.Uint32VarP(&benchSearchFlags.maxResults, flags.MaxResults, "r", defaultMaxResults, "The maximum number of neighbors to return for each search.")              //nolint:lll // For readability
+	flagSet.Var(&benchSearchFlags.hnswEf, flags.HnswEf, "The number of candidate nearest neighbors shortlisted during search. Uses the index's default if not provided.") //nolint:lll // For readability
+
+	return flagSet
+}
+
+func newBenchSearchCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "search",
+		Short: "A command for benchmarking search throughput and latency",
+		Long: fmt.Sprintf(`A command for benchmarking the search throughput and latency of an index.
+Searches are run for --%s from --%s concurrent workers, optionally limited to
+--%s searches per second. Search vectors are read from --%s or randomly
+generated to match the index's dimensions.
+
+Throughput, latency percentiles, and errors broken down by gRPC status code
+are reported. Connecting with --%s uses a load balancer while --%s tends the
+cluster, allowing the two topologies to be compared.
+
+For example:
+
+%s
+asvec bench search -n test -i my-index --%s 1m --%s 32
+
+asvec bench search -n test -i my-index --%s 500 --%s queries.fvecs \
+	--%s per-second.csv
+			`, flags.Duration, flags.Concurrency, flags.QPS, flags.VectorFile, flags.Host, flags.Seeds,
+			HelpTxtSetupEnv, flags.Duration, flags.Concurrency, flags.QPS, flags.VectorFile, flags.TimeSeriesFile),
+		PreRunE: func(_ *cobra.Command, _ []string) error {
+			return benchSearchFlags.validate()
+		},
+		RunE: func(_ *cobra.Command, _ []string) error {
+			logger.Debug("parsed flags",
+				append(
+					benchSearchFlags.newSLogAttr(),
+					slog.Any(flags.MaxResults, benchSearchFlags.maxResults),
+					slog.String(flags.HnswEf, benchSearchFlags.hnswEf.String()),
+				)...,
+			)
+
+			client, err := createClientFromFlags(benchSearchFlags.clientFlags)
+			if err != nil {
+				return err
+			}
+			defer client.Close()
+
+			ctx, cancel := context.WithTimeout(context.Background(), benchSearchFlags.clientFlags.Timeout)
+			defer cancel()
+
+			indexDef, err := client.IndexGet(ctx, benchSearchFlags.namespace, benchSearchFlags.indexName, false)
+			if err != nil {
+				logger.Error("unable to get index definition", slog.Any("error", err))
+				return err
+			}
+
+			vectors, err := benchVectors(benchSearchFlags.vectorFile, indexDef)
+			if err != nil {
+				logger.Error("unable to read vector file", slog.Any("error", err))
+				return err
+			}
+
+			params := &protos.HnswSearchParams{Ef: benchSearchFlags.hnswEf.Val}
+
+			return runBenchmark("search", &benchSearchFlags.benchFlags, func(ctx context.Context, n int) error {
+				_, err := vectorSearch(
+					ctx,
+					client,
+					benchSearchFlags.namespace,
+					benchSearchFlags.indexName,
+					vectors(n),
+					benchSearchFlags.maxResults,
+					params,
+					[]string{},
+				)
+
+				return err
+			})
+		},
+	}
+}
+
+func init() {
+	benchSearchCmd := newBenchSearchCmd()
+	benchCmd.AddCommand(benchSearchCmd)
+	benchSearchCmd.Flags().AddFlagSet(newBenchSearchFlagSet())
+
+	for _, flag := range benchRequiredFlags {
+		err := benchSearchCmd.MarkFlagRequired(flag)
+		if err != nil {
+			panic(err)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"asvec/cmd/flags"
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/aerospike/avs-client-go/protos"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+const defaultBenchKeyPrefix = "asvec-bench-"
+
+//nolint:govet // Padding not a concern for a CLI
+var benchWriteFlags = &struct {
+	benchFlags
+	set                flags.StringOptionalFlag
+	keyPrefix          string
+	writeType          flags.WriteTypeFlag
+	ignoreMemQueueFull bool
+}{
+	benchFlags: newBenchFlags(),
+	writeType:  flags.WriteTypeFlag(protos.WriteType_UPSERT.String()),
+}
+
+func newBenchWriteFlagSet() *pflag.FlagSet {
+	flagSet := newBenchFlagSet(&benchWriteFlags.benchFlags)
+	flagSet.VarP(&benchWriteFlags.set, flags.Set, flags.SetShort, "The set to write records to. Defaults to the index's set filter.")                                                                        //nolint:lll // For readability
+	flagSet.StringVar(&benchWriteFlags.keyPrefix, flags.KeyPrefix, defaultBenchKeyPrefix, "The prefix of the keys records are written with. Each record's key is the prefix followed by a sequence number.") //nolint:lll // For readability
+	flagSet.Var(&benchWriteFlags.writeType, flags.WriteType, fmt.Sprintf("How records are written. Valid values: %s", strings.Join(flags.WriteTypeEnum(), ", ")))                                            //nolint:lll // For readability
+	flagSet.BoolVar(&benchWriteFlags.ignoreMemQueueFull, flags.IgnoreMemQueueFull, false, "Write records even if the index's in-memory queue is full.")                                                      //nolint:lll // For readability
+
+	return flagSet
+}
+
+func newBenchWriteCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "write",
+		Short: "A command for benchmarking write throughput and latency",
+		Long: fmt.Sprintf(`A command for benchmarking the write throughput and latency of an index.
+Records are written for --%s from --%s concurrent workers, optionally limited
+to --%s writes per second. Each record contains only the index's vector field
+with a vector read from --%s or randomly generated to match the index's
+dimensions.
+
+Records are written with the keys <--%s>0, <--%s>1, and so on and are not
+removed once the benchmark completes.
+
+Throughput, latency percentiles, and errors broken down by gRPC status code
+are reported. Connecting with --%s uses a load balancer while --%s tends the
+cluster, allowing the two topologies to be compared.
+
+For example:
+
+%s
+asvec bench write -n test -i my-index --%s 1m --%s 32
+
+asvec bench write -n test -s bench -i my-index --%s 1000 --%s per-second.csv
+			`, flags.Duration, flags.Concurrency, flags.QPS, flags.VectorFile, flags.KeyPrefix, flags.KeyPrefix,
+			flags.Host, flags.Seeds, HelpTxtSetupEnv, flags.Duration, flags.Concurrency, flags.QPS,
+			flags.TimeSeriesFile),
+		PreRunE: func(_ *cobra.Command, _ []string) error {
+			return benchWriteFlags.validate()
+		},
+		RunE: func(_ *cobra.Command, _ []string) error {
+			logger.Debug("parsed flags",
+				append(
+					benchWriteFlags.newSLogAttr(),
+					slog.Any(flags.Set, benchWriteFlags.set.Val),
+					slog.String(flags.KeyPrefix, benchWriteFlags.keyPrefix),
+					slog.String(flags.WriteType, benchWriteFlags.writeType.String()),
+					slog.Bool(flags.IgnoreMemQueueFull, benchWriteFlags.ignoreMemQueueFull),
+				)...,
+			)
+
+			client, err := createClientFromFlags(benchWriteFlags.clientFlags)
+			if err != nil {
+				return err
+			}
+			defer client.Close()
+
+			ctx, cancel := context.WithTimeout(context.Background(), benchWriteFlags.clientFlags.Timeout)
+			defer cancel()
+
+			indexDef, err := client.IndexGet(ctx, benchWriteFlags.namespace, benchWriteFlags.indexName, false)
+			if err != nil {
+				logger.Error("unable to get index definition", slog.Any("error", err))
+				return err
+			}
+
+			vectors, err := benchVectors(benchWriteFlags.vectorFile, indexDef)
+			if err != nil {
+				logger.Error("unable to read vector file", slog.Any("error", err))
+				return err
+			}
+
+			set := benchWriteFlags.set.Val
+			if set == nil {
+				set = indexDef.SetFilter
+			}
+
+			writeType := benchWriteFlags.writeType.WriteType()
+
+			return runBenchmark("write", &benchWriteFlags.benchFlags, func(ctx context.Context, n int) error {
+				return writeRecord(
+					ctx,
+					client,
+					writeType,
+					benchWriteFlags.namespace,
+					set,
+					fmt.Sprintf("%s%d", benchWriteFlags.keyPrefix, n),
+					map[string]any{indexDef.Field: vectors(n)},
+					benchWriteFlags.ignoreMemQueueFull,
+				)
+			})
+		},
+	}
+}
+
+func init() {
+	benchWriteCmd := newBenchWriteCmd()
+	benchCmd.AddCommand(benchWriteCmd)
+	benchWriteCmd.Flags().AddFlagSet(newBenchWriteFlagSet())
+
+	for _, flag := range benchRequiredFlags {
+		err := benchWriteCmd.MarkFlagRequired(flag)
+		if err != nil {
+			panic(err)
+		}
+	}
+}
@@ -0,0 +1,165 @@
+//go:build unit
+
+package cmd
+
+import (
+	"asvec/cmd/flags"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aerospike/avs-client-go/protos"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGrpcCode(t *testing.T) {
+	testCases := []struct {
+		err      error
+		expected string
+	}{
+		{
+			err:      errors.New("failed to get index: server error: NotFound, msg=index test:DNE not found"),
+			expected: "NotFound",
+		},
+		{
+			err:      errors.New("failed to insert record: server error: ResourceExhausted"),
+			expected: "ResourceExhausted",
+		},
+		{
+			err:      fmt.Errorf("wrapped: %w", context.DeadlineExceeded),
+			expected: "DeadlineExceeded",
+		},
+		{
+			err:      context.Canceled,
+			expected: "Canceled",
+		},
+		{
+			err:      errors.New("something else"),
+			expected: "Unknown",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.expected, func(t *testing.T) {
+			assert.Equal(t, tc.expected, grpcCode(tc.err))
+		})
+	}
+}
+
+func TestNewBenchResult(t *testing.T) {
+	benchFlags := &benchFlags{
+		clientFlags: flags.NewClientFlags(),
+		concurrency: 2,
+		qps:         10,
+	}
+
+	samples := []benchSample{
+		{end: time.Millisecond, latency: time.Millisecond},
+		{end: 2 * time.Millisecond, latency: 2 * time.Millisecond},
+		{end: 3 * time.Millisecond, latency: 3 * time.Millisecond},
+		{end: 4 * time.Millisecond, latency: time.Second, code: "Unavailable"},
+		{end: 5 * time.Millisecond, latency: time.Second, code: "Unavailable"},
+		{end: 6 * time.Millisecond, latency: time.Second, code: "NotFound"},
+	}
+
+	result := newBenchResult("search", benchFlags, samples, 2*time.Second)
+
+	assert.Equal(t, "search", result.Operation)
+	assert.Equal(t, 2, result.Concurrency)
+	assert.Equal(t, 10, result.TargetQPS)
+	assert.Equal(t, 6, result.Requests)
+	assert.Equal(t, 3, result.Errors)
+	assert.InDelta(t, 1.5, result.QPS, 1e-9)
+	assert.Equal(t, 2*time.Millisecond, result.P50)
+	assert.Equal(t, 3*time.Millisecond, result.P999)
+	assert.Equal(t, map[string]int{"Unavailable": 2, "NotFound": 1}, result.ErrorsByCode)
+	assert.Contains(t, result.Topology, "load balancer")
+}
+
+func TestWriteBenchTimeSeries(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "series.csv")
+
+	samples := []benchSample{
+		{end: 100 * time.Millisecond, latency: time.Millisecond},
+		{end: 900 * time.Millisecond, latency: 3 * time.Millisecond},
+		{end: 1500 * time.Millisecond, latency: time.Millisecond, code: "Unavailable"},
+	}
+
+	require.NoError(t, writeBenchTimeSeries(file, samples, 1600*time.Millisecond))
+
+	content, err := os.ReadFile(file)
+	require.NoError(t, err)
+
+	expected := "second,requests,errors,latencyP50Ms,latencyP90Ms,latencyP99Ms\n" +
+		"0,2,0,1.000,3.000,3.000\n" +
+		"1,1,1,0.000,0.000,0.000\n"
+	assert.Equal(t, expected, string(content))
+}
+
+func TestBenchmarkRun(t *testing.T) {
+	b := &benchmark{
+		op: func(_ context.Context, n int) error {
+			if n%2 == 1 {
+				return errors.New("server error: Unavailable")
+			}
+
+			return nil
+		},
+		duration:    500 * time.Millisecond,
+		timeout:     time.Second,
+		concurrency: 4,
+		qps:         100,
+	}
+
+	samples, elapsed := b.run("test")
+
+	assert.GreaterOrEqual(t, elapsed, 500*time.Millisecond)
+	// A 500ms run at 100 QPS sends roughly 50 requests.
+	assert.InDelta(t, 50, len(samples), 10)
+
+	failed := 0
+
+	for _, s := range samples {
+		if s.code != "" {
+			assert.Equal(t, "Unavailable", s.code)
+			failed++
+		}
+	}
+
+	assert.InDelta(t, len(samples)/2, failed, 1)
+}
+
+func TestBenchVectors(t *testing.T) {
+	metric := protos.VectorDistanceMetric_HAMMING
+	indexDef := &protos.IndexDefinition{Dimensions: 4, VectorDistanceMetric: &metric}
+
+	vectors, err := benchVectors("", indexDef)
+	require.NoError(t, err)
+	assert.Len(t, vectors(0), 4)
+	assert.IsType(t, []bool{}, vectors(0))
+
+	metric = protos.VectorDistanceMetric_COSINE
+
+	vectors, err = benchVectors("", indexDef)
+	require.NoError(t, err)
+	assert.IsType(t, []float32{}, vectors(0))
+
+	file := filepath.Join(t.TempDir(), "vectors.jsonl")
+	content := "{\"id\": 1, \"vector\": [1, 2, 3, 4]}\n{\"id\": 2, \"vector\": [5, 6, 7, 8]}\n"
+	require.NoError(t, os.WriteFile(file, []byte(content), 0o600))
+
+	vectors, err = benchVectors(file, indexDef)
+	require.NoError(t, err)
+	assert.Equal(t, []float32{1, 2, 3, 4}, vectors(0))
+	assert.Equal(t, []float32{5, 6, 7, 8}, vectors(1))
+	assert.Equal(t, []float32{1, 2, 3, 4}, vectors(2))
+
+	indexDef.Dimensions = 3
+	_, err = benchVectors(file, indexDef)
+	assert.Error(t, err)
+}
@@ -0,0 +1,252 @@
+package cmd
+
+import (
+	"asvec/cmd/flags"
+	"context"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	avs "github.com/aerospike/avs-client-go"
+)
+
+const (
+	clientReconnectInitialBackoff = time.Second
+	clientReconnectMaxBackoff     = 30 * time.Second
+)
+
+// clients is the client cache used by acquireClient. It is only set while a
+// command runs in watch mode.
+var clients *clientCache
+
+// clientCache shares a single client between the runs of a command so that
+// each run does not repeat the TLS handshake, authentication, and cluster
+// tending. A client which has lost its connection is closed and reconnected
+// with exponential backoff. Commands which run against several clusters keep
+// a cache for each cluster in clusters.
+//
+//nolint:govet // Padding not a concern for a CLI
+type clientCache struct {
+	mu          sync.Mutex
+	client      *avs.Client
+	connectedAt time.Time
+	failures    int
+	retryAt     time.Time
+	lastErr     error
+	clusters    map[string]*clientCache
+}
+
+func newClientCache() *clientCache {
+	return &clientCache{}
+}
+
+// acquireClient returns a client and a function to release it when the
+// command is done with it. The cached client is returned when one is set.
+func acquireClient(clientFlags *flags.ClientFlags) (*avs.Client, func(), error) {
+	if clients != nil {
+		client, err := clients.get(clientFlags)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return client, func() {}, nil
+	}
+
+	client, err := createClientFromFlags(clientFlags)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return client, func() { client.Close() }, nil
+}
+
+// acquireClusterClient is acquireClient for the named cluster of a command
+// which runs against several clusters.
+func acquireClusterClient(name string, clientFlags *flags.ClientFlags) (*avs.Client, func(), error) {
+	if clients == nil {
+		return acquireClient(clientFlags)
+	}
+
+	client, err := clients.cluster(name).get(clientFlags)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return client, func() {}, nil
+}
+
+// cluster returns the cache of the named cluster, creating it if needed.
+func (c *clientCache) cluster(name string) *clientCache {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.clusters == nil {
+		c.clusters = map[string]*clientCache{}
+	}
+
+	cache, ok := c.clusters[name]
+	if !ok {
+		cache = newClientCache()
+		c.clusters[name] = cache
+	}
+
+	return cache
+}
+
+// get returns the cached client, connecting if there is none. While waiting
+// to reconnect, the last connection error is returned without connecting.
+func (c *clientCache) get(clientFlags *flags.ClientFlags) (*avs.Client, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.client != nil {
+		return c.client, nil
+	}
+
+	if time.Now().Before(c.retryAt) {
+		return nil, fmt.Errorf("waiting to reconnect: %w", c.lastErr)
+	}
+
+	client, err := createClientFromFlags(clientFlags)
+	if err != nil {
+		c.disconnected(err)
+		return nil, err
+	}
+
+	if c.failures > 0 {
+		logger.Info("reconnected", slog.Int("attempts", c.failures))
+	}
+
+	c.client = client
+	c.connectedAt = time.Now()
+	c.failures = 0
+	c.lastErr = nil
+
+	return client, nil
+}
+
+// lostConnection reports whether a command failed with err because the
+// cached client lost its connection to the cluster. If it did, the client is
+// closed and the next get reconnects. Failing to connect for the first time
+// is not a lost connection.
+func (c *clientCache) lostConnection(timeout time.Duration, err error) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.client == nil {
+		return !c.connectedAt.IsZero()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	if _, aboutErr := c.client.About(ctx, nil); aboutErr != nil {
+		logger.Warn("lost connection to the cluster", slog.Any("error", err), slog.Any("aboutError", aboutErr))
+		c.client.Close()
+		c.client = nil
+		c.disconnected(aboutErr)
+
+		return true
+	}
+
+	return false
+}
+
+// disconnected records a failed connection and schedules the next attempt.
+// The caller must hold c.mu.
+func (c *clientCache) disconnected(err error) {
+	c.lastErr = err
+	c.failures++
+	c.retryAt = time.Now().Add(reconnectBackoff(c.failures))
+}
+
+// state describes the connection for display, e.g. in the watch header. The
+// connection to each cluster is described when there are several.
+func (c *clientCache) state() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.clusters) > 0 {
+		states := make([]string, 0, len(c.clusters))
+		for _, name := range slices.Sorted(maps.Keys(c.clusters)) {
+			states = append(states, fmt.Sprintf("%s %s", name, c.clusters[name].state()))
+		}
+
+		return strings.Join(states, "; ")
+	}
+
+	switch {
+	case c.client != nil:
+		return fmt.Sprintf("connected since %s", c.connectedAt.Format("15:04:05"))
+	case c.lastErr != nil:
+		return fmt.Sprintf(
+			"disconnected, reconnect attempt %d in %s: %s",
+			c.failures+1, max(0, time.Until(c.retryAt)).Round(time.Second), c.lastErr,
+		)
+	default:
+		return "connecting"
+	}
+}
+
+// lost reports whether the cached client, or the client of any cluster, lost
+// its connection and has not reconnected yet.
+func (c *clientCache) lost() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, cache := range c.clusters {
+		if cache.lost() {
+			return true
+		}
+	}
+
+	return c.client == nil && !c.connectedAt.IsZero()
+}
+
+// lastError returns the most recent connection error, if any, prefixed with
+// the cluster name for a cluster's client.
+func (c *clientCache) lastError() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, name := range slices.Sorted(maps.Keys(c.clusters)) {
+		if err := c.clusters[name].lastError(); err != "" {
+			return fmt.Sprintf("cluster %s: %s", name, err)
+		}
+	}
+
+	if c.lastErr == nil {
+		return ""
+	}
+
+	return c.lastErr.Error()
+}
+
+func (c *clientCache) Close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.client != nil {
+		c.client.Close()
+		c.client = nil
+	}
+
+	for _, cache := range c.clusters {
+		cache.Close()
+	}
+}
+
+// reconnectBackoff returns the delay before the next connection attempt
+// after failures consecutive failures.
+func reconnectBackoff(failures int) time.Duration {
+	backoff := clientReconnectInitialBackoff
+	for i := 1; i < failures && backoff < clientReconnectMaxBackoff; i++ {
+		backoff *= 2
+	}
+
+	return min(backoff, clientReconnectMaxBackoff)
+}
@@ -0,0 +1,50 @@
+//go:build unit
+
+package cmd
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReconnectBackoff(t *testing.T) {
+	assert.Equal(t, time.Second, reconnectBackoff(1))
+	assert.Equal(t, 2*time.Second, reconnectBackoff(2))
+	assert.Equal(t, 16*time.Second, reconnectBackoff(5))
+	assert.Equal(t, clientReconnectMaxBackoff, reconnectBackoff(6))
+	assert.Equal(t, clientReconnectMaxBackoff, reconnectBackoff(100))
+}
+
+func TestClientCacheState(t *testing.T) {
+	c := newClientCache()
+
+	assert.Equal(t, "connecting", c.state())
+	assert.False(t, c.lostConnection(time.Second, errors.New("failed")), "never connected")
+
+	c.disconnected(errors.New("connection refused"))
+
+	assert.Equal(t, 1, c.failures)
+	assert.Contains(t, c.state(), "disconnected, reconnect attempt 2 in 1s: connection refused")
+	assert.False(t, c.lostConnection(time.Second, errors.New("failed")), "never connected")
+
+	c.connectedAt = time.Now()
+
+	assert.True(t, c.lostConnection(time.Second, errors.New("failed")), "was connected")
+
+	_, err := c.get(nil)
+	assert.ErrorContains(t, err, "waiting to reconnect: connection refused")
+}
+
+func TestClientCacheClusterState(t *testing.T) {
+	c := newClientCache()
+
+	c.cluster("us").connectedAt = time.Now()
+	c.cluster("eu").disconnected(errors.New("connection refused"))
+
+	assert.Contains(t, c.state(), "eu disconnected, reconnect attempt 2 in 1s: connection refused; us connecting")
+	assert.True(t, c.lost(), "us was connected")
+	assert.Equal(t, "cluster eu: connection refused", c.lastError())
+}
@@ -0,0 +1,21 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// clusterCmd represents the cluster command
+var clusterCmd = &cobra.Command{
+	Use:   "cluster",
+	Short: "A parent command for checking the health of your cluster.",
+	Long: `A parent command for checking the health of your cluster.
+
+For example:
+
+	asvec cluster --help
+		`,
+}
+
+func init() {
+	rootCmd.AddCommand(clusterCmd)
+}
@@ -0,0 +1,714 @@
+package cmd
+
+import (
+	"asvec/cmd/flags"
+	"asvec/cmd/writers"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"log/slog"
+	"maps"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	avs "github.com/aerospike/avs-client-go"
+	"github.com/aerospike/avs-client-go/protos"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+const defaultDoctorMaxUnmerged = 100000
+
+//nolint:govet // Padding not a concern for a CLI
+var clusterDoctorFlags = &struct {
+	clientFlags *flags.ClientFlags
+	output      flags.OutputFlag
+	maxUnmerged int64
+}{
+	clientFlags: rootFlags.clientFlags,
+	output:      flags.OutputTable,
+}
+
+func newClusterDoctorFlagSet() *pflag.FlagSet {
+	flagSet := &pflag.FlagSet{}
+	flagSet.AddFlagSet(clusterDoctorFlags.clientFlags.NewClientFlagSet())
+	flagSet.VarP(&clusterDoctorFlags.output, flags.Output, flags.OutputShort, fmt.Sprintf("The output format. Valid values: %s", strings.Join(flags.OutputEnum(), ", "))) //nolint:lll // For readability
+	flagSet.Int64Var(&clusterDoctorFlags.maxUnmerged, flags.MaxUnmerged, defaultDoctorMaxUnmerged, "Warn when an index has more unmerged records than this.")             //nolint:lll // For readability
+
+	return flagSet
+}
+
+func newClusterDoctorCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "doctor",
+		Short: "A command for diagnosing common cluster problems",
+		Long: fmt.Sprintf(`A command for diagnosing common cluster problems. The doctor runs
+the following checks and reports pass, warn, fail, or skip for each, with a
+hint for fixing any problem found:
+
+  connectivity       asvec can connect and every node responds.
+  client-visibility  asvec can reach every node in the cluster.
+  peer-visibility    every node can see every other node.
+  cluster-id         every node is in the same cluster.
+  version-skew       every node runs the same version.
+  role-coverage      the nodes together have every role the cluster needs.
+  listener-name      the nodes advertise endpoints asvec can use.
+  tls-names          the TLS certificates are valid for the addresses asvec
+                     connects to.
+  index-status       every index is ready.
+  unmerged-backlog   no index has more than --%s unmerged records.
+
+asvec exits with code 1 if any check fails. Use --%s json to produce a report
+to share with support.
+
+For example:
+
+%s
+asvec cluster doctor
+asvec cluster doctor --%s json > doctor.json
+			`, flags.MaxUnmerged, flags.Output, HelpTxtSetupEnv, flags.Output),
+		PreRunE: func(_ *cobra.Command, _ []string) error {
+			return checkSeedsAndHost()
+		},
+		RunE: func(_ *cobra.Command, _ []string) error {
+			logger := logger.With("cmd", "clusterDoctorCmd")
+			logger.Debug("parsed flags",
+				append(
+					clusterDoctorFlags.clientFlags.NewSLogAttr(),
+					slog.String(flags.Output, clusterDoctorFlags.output.String()),
+					slog.Int64(flags.MaxUnmerged, clusterDoctorFlags.maxUnmerged),
+				)...,
+			)
+
+			checks := runDoctorChecks(clusterDoctorFlags.clientFlags, clusterDoctorFlags.maxUnmerged)
+			report := writers.NewDoctorReport(checks)
+
+			view.PrintDoctorReport(report, clusterDoctorFlags.output)
+
+			if report.Summary.Fail != 0 {
+				setErrCode(1)
+			}
+
+			return nil
+		},
+	}
+}
+
+// runDoctorChecks runs every doctor check against the cluster. Checks which
+// need a connection are skipped if asvec can't connect.
+func runDoctorChecks(clientFlags *flags.ClientFlags, maxUnmerged int64) []*writers.DoctorCheck {
+	isLB := isLoadBalancer(clientFlags.Seeds)
+	seeds := parseBothHostSeedsFlag(clientFlags.Seeds, clientFlags.Host)
+
+	client, releaseClient, err := acquireClient(clientFlags)
+	if err != nil {
+		logger.Error("failed to connect to the cluster", slog.Any("error", err))
+
+		checks := []*writers.DoctorCheck{checkConnectivity(err, nil, isLB)}
+		skipped := "Skipped because asvec could not connect to the cluster."
+
+		for _, name := range []string{
+			"client-visibility", "peer-visibility", "cluster-id", "version-skew", "role-coverage", "listener-name",
+		} {
+			checks = append(checks, doctorCheck(name, writers.DoctorSkip, skipped, ""))
+		}
+
+		// A certificate which is not valid for the seeds is a common reason
+		// for not being able to connect.
+		checks = append(checks, checkTLSNames(clientFlags, seeds, nil))
+
+		for _, name := range []string{"index-status", "unmerged-backlog"} {
+			checks = append(checks, doctorCheck(name, writers.DoctorSkip, skipped, ""))
+		}
+
+		return checks
+	}
+	defer releaseClient()
+
+	ctx, cancel := context.WithTimeout(context.Background(), clientFlags.Timeout)
+	defer cancel()
+
+	nodeInfos := getAllNodesInfo(ctx, client, clientFlags.ListenerName.Val)
+
+	logger.Debug("received node states", slog.Any("nodeStates", nodeInfos))
+
+	indexes, statuses, indexErr := listIndexesWithStatus(client, clientFlags.Timeout)
+	if indexErr != nil {
+		logger.Error("failed to list indexes", slog.Any("error", indexErr))
+	}
+
+	var tlsAddresses avs.HostPortSlice
+
+	if !isLB {
+		// In seed mode asvec also connects to the addresses the nodes
+		// advertise so their certificates must be valid for them too.
+		tlsAddresses = advertisedHostPorts(nodeInfos)
+	}
+
+	return []*writers.DoctorCheck{
+		checkConnectivity(nil, nodeInfos, isLB),
+		checkClientVisibility(nodeInfos, isLB),
+		checkPeerVisibility(nodeInfos),
+		checkClusterID(nodeInfos),
+		checkVersionSkew(nodeInfos, isLB),
+		checkRoleCoverage(nodeInfos, indexes, isLB),
+		checkListenerName(nodeInfos, clientFlags.ListenerName.Val, seeds, isLB),
+		checkTLSNames(clientFlags, seeds, tlsAddresses),
+		checkIndexStatus(indexes, statuses, indexErr),
+		checkUnmergedBacklog(indexes, statuses, indexErr, maxUnmerged),
+	}
+}
+
+func doctorCheck(name, status, message, remediation string) *writers.DoctorCheck {
+	return &writers.DoctorCheck{Name: name, Status: status, Message: message, Remediation: remediation}
+}
+
+func checkConnectivity(err error, nodeInfos []*writers.NodeInfo, isLB bool) *writers.DoctorCheck {
+	const name = "connectivity"
+
+	if err != nil {
+		return doctorCheck(name, writers.DoctorFail,
+			fmt.Sprintf("Failed to connect: %s", err),
+			fmt.Sprintf("Check --%s or --%s, that AVS is running and reachable from this host, and the TLS and "+
+				"credential flags.", flags.Host, flags.Seeds),
+		)
+	}
+
+	unresponsive := []string{}
+
+	for _, node := range nodeInfos {
+		if node.About == nil || node.State == nil || node.Endpoints == nil {
+			unresponsive = append(unresponsive, strconv.FormatUint(node.NodeID.GetId(), 10))
+		}
+	}
+
+	if len(unresponsive) != 0 {
+		if isLB {
+			return doctorCheck(name, writers.DoctorFail,
+				"The node behind the load balancer did not respond to every request.",
+				"Check the AVS logs and that the load balancer forwards gRPC traffic.",
+			)
+		}
+
+		return doctorCheck(name, writers.DoctorFail,
+			fmt.Sprintf("Node(s) did not respond to every request: %s", strings.Join(unresponsive, ", ")),
+			"Check the AVS logs on the nodes listed and the network between them and this host.",
+		)
+	}
+
+	if isLB {
+		return doctorCheck(name, writers.DoctorPass, "Connected through a load balancer.", "")
+	}
+
+	return doctorCheck(name, writers.DoctorPass, fmt.Sprintf("Connected to %d node(s).", len(nodeInfos)), "")
+}
+
+func checkClientVisibility(nodeInfos []*writers.NodeInfo, isLB bool) *writers.DoctorCheck {
+	const name = "client-visibility"
+
+	if isLB {
+		return doctorCheck(name, writers.DoctorSkip,
+			"asvec only connects to the load balancer so it does not need to reach every node.", "",
+		)
+	}
+
+	idsVisibleToClient := map[uint64]struct{}{}
+
+	for _, node := range nodeInfos {
+		idsVisibleToClient[node.NodeID.GetId()] = struct{}{}
+	}
+
+	idsNotVisibleToClient := getNodesNotVisibleToClient(getIDsVisibleToAllNodes(nodeInfos), idsVisibleToClient)
+
+	if len(idsNotVisibleToClient) != 0 {
+		return doctorCheck(name, writers.DoctorFail,
+			fmt.Sprintf("asvec can't reach node(s): %s", strings.Join(idsNotVisibleToClient, ", ")),
+			fmt.Sprintf("If you are connecting through a load balancer use --%s instead of --%s. Otherwise the "+
+				"nodes are advertising endpoints asvec can't reach, did you forget --%s?",
+				flags.Host, flags.Seeds, flags.ListenerName),
+		)
+	}
+
+	return doctorCheck(name, writers.DoctorPass, fmt.Sprintf("asvec can reach all %d node(s).", len(nodeInfos)), "")
+}
+
+func checkPeerVisibility(nodeInfos []*writers.NodeInfo) *writers.DoctorCheck {
+	const name = "peer-visibility"
+
+	nodesNotVisibleToEachNode := getNodesNotVisibleToEachNode(
+		getIDsVisibleToEachNode(nodeInfos),
+		getIDsVisibleToAllNodes(nodeInfos),
+	)
+
+	if len(nodesNotVisibleToEachNode) != 0 {
+		msgs := []string{}
+
+		for _, id := range slices.Sorted(maps.Keys(nodesNotVisibleToEachNode)) {
+			notVisible := nodesNotVisibleToEachNode[id]
+			slices.Sort(notVisible)
+
+			msgs = append(msgs, fmt.Sprintf("Node %d can't see: %s", id, strings.Join(notVisible, ", ")))
+		}
+
+		return doctorCheck(name, writers.DoctorFail,
+			strings.Join(msgs, "\n"),
+			"Check the network between the nodes and the heartbeat seeds and advertised listeners in the AVS "+
+				"configuration.",
+		)
+	}
+
+	return doctorCheck(name, writers.DoctorPass, "Every node can see every other node.", "")
+}
+
+func checkClusterID(nodeInfos []*writers.NodeInfo) *writers.DoctorCheck {
+	const name = "cluster-id"
+
+	nodesByClusterID := map[uint64][]string{}
+	notInCluster := []string{}
+
+	for _, node := range nodeInfos {
+		if node.State == nil {
+			continue
+		}
+
+		id := strconv.FormatUint(node.NodeID.GetId(), 10)
+
+		if !node.State.GetIsInCluster() {
+			notInCluster = append(notInCluster, id)
+			continue
+		}
+
+		clusterID := node.State.GetClusterId().GetId()
+		nodesByClusterID[clusterID] = append(nodesByClusterID[clusterID], id)
+	}
+
+	if len(nodesByClusterID) == 0 && len(notInCluster) == 0 {
+		return doctorCheck(name, writers.DoctorSkip, "No node returned its clustering state.", "")
+	}
+
+	if len(nodesByClusterID) > 1 {
+		msgs := []string{}
+
+		for _, clusterID := range slices.Sorted(maps.Keys(nodesByClusterID)) {
+			msgs = append(msgs, fmt.Sprintf("Cluster %d: node(s) %s", clusterID,
+				strings.Join(nodesByClusterID[clusterID], ", ")))
+		}
+
+		return doctorCheck(name, writers.DoctorFail,
+			"The nodes have split into multiple clusters.\n"+strings.Join(msgs, "\n"),
+			"Check the network between the nodes and that every node has the same heartbeat seeds.",
+		)
+	}
+
+	if len(notInCluster) != 0 {
+		return doctorCheck(name, writers.DoctorFail,
+			fmt.Sprintf("Node(s) not in a cluster: %s", strings.Join(notInCluster, ", ")),
+			"Check the AVS logs on the nodes listed and their heartbeat seeds.",
+		)
+	}
+
+	clusterID := slices.Collect(maps.Keys(nodesByClusterID))[0]
+
+	return doctorCheck(name, writers.DoctorPass, fmt.Sprintf("Every node is in cluster %d.", clusterID), "")
+}
+
+func checkVersionSkew(nodeInfos []*writers.NodeInfo, isLB bool) *writers.DoctorCheck {
+	const name = "version-skew"
+
+	if isLB {
+		return doctorCheck(name, writers.DoctorSkip,
+			"Only the node behind the load balancer can be checked. Use --seeds to check every node.", "",
+		)
+	}
+
+	nodesByVersion := map[string][]string{}
+
+	for _, node := range nodeInfos {
+		if node.About == nil {
+			continue
+		}
+
+		version := node.About.GetVersion()
+		nodesByVersion[version] = append(nodesByVersion[version], strconv.FormatUint(node.NodeID.GetId(), 10))
+	}
+
+	versions := slices.Sorted(maps.Keys(nodesByVersion))
+
+	switch len(versions) {
+	case 0:
+		return doctorCheck(name, writers.DoctorSkip, "No node returned its version.", "")
+	case 1:
+		return doctorCheck(name, writers.DoctorPass, fmt.Sprintf("Every node runs version %s.", versions[0]), "")
+	}
+
+	msgs := []string{}
+
+	for _, version := range versions {
+		msgs = append(msgs, fmt.Sprintf("%s: node(s) %s", version, strings.Join(nodesByVersion[version], ", ")))
+	}
+
+	return doctorCheck(name, writers.DoctorWarn,
+		"The nodes run different versions.\n"+strings.Join(msgs, "\n"),
+		"Finish upgrading so every node runs the same version.",
+	)
+}
+
+// requiredNodeRoles are the roles at least one node must have for the
+// cluster to serve reads, writes, and queries and keep indexes up to date.
+var requiredNodeRoles = []protos.NodeRole{
+	protos.NodeRole_INDEX_QUERY,
+	protos.NodeRole_INDEX_UPDATE,
+	protos.NodeRole_KV_READ,
+	protos.NodeRole_KV_WRITE,
+	protos.NodeRole_INDEXER,
+}
+
+func checkRoleCoverage(
+	nodeInfos []*writers.NodeInfo,
+	indexes *protos.IndexDefinitionList,
+	isLB bool,
+) *writers.DoctorCheck {
+	const name = "role-coverage"
+
+	if isLB {
+		return doctorCheck(name, writers.DoctorSkip,
+			"Only the node behind the load balancer can be checked. Use --seeds to check every node.", "",
+		)
+	}
+
+	roles := map[protos.NodeRole]struct{}{}
+	responded := false
+
+	for _, node := range nodeInfos {
+		if node.About == nil {
+			continue
+		}
+
+		responded = true
+
+		for _, role := range node.About.GetRoles() {
+			roles[role] = struct{}{}
+		}
+	}
+
+	if !responded {
+		return doctorCheck(name, writers.DoctorSkip, "No node returned its roles.", "")
+	}
+
+	missing := []string{}
+
+	for _, role := range requiredNodeRoles {
+		if _, ok := roles[role]; !ok {
+			missing = append(missing, role.String())
+		}
+	}
+
+	if len(missing) != 0 {
+		return doctorCheck(name, writers.DoctorFail,
+			fmt.Sprintf("No node has the role(s): %s", strings.Join(missing, ", ")),
+			"Add the roles listed to the node-roles of at least one node in the AVS configuration.",
+		)
+	}
+
+	if _, ok := roles[protos.NodeRole_STANDALONE_INDEXER]; !ok {
+		for _, index := range indexes.GetIndices() {
+			if index.GetMode() == protos.IndexMode_STANDALONE {
+				return doctorCheck(name, writers.DoctorWarn,
+					fmt.Sprintf("Index %s.%s is standalone but no node has the %s role.",
+						index.GetId().GetNamespace(), index.GetId().GetName(), protos.NodeRole_STANDALONE_INDEXER),
+					fmt.Sprintf("Add the %s role to a node or the standalone index will not be built.",
+						protos.NodeRole_STANDALONE_INDEXER),
+				)
+			}
+		}
+	}
+
+	return doctorCheck(name, writers.DoctorPass, "The nodes have every required role.", "")
+}
+
+func checkListenerName(
+	nodeInfos []*writers.NodeInfo,
+	listenerName *string,
+	seeds avs.HostPortSlice,
+	isLB bool,
+) *writers.DoctorCheck {
+	const name = "listener-name"
+
+	if isLB {
+		return doctorCheck(name, writers.DoctorSkip,
+			"asvec only connects to the load balancer so it does not use the advertised endpoints.", "",
+		)
+	}
+
+	if listenerName != nil {
+		missing := []string{}
+
+		for _, node := range nodeInfos {
+			for id, endpoints := range node.Endpoints.GetEndpoints() {
+				if len(endpoints.GetEndpoints()) == 0 {
+					missing = append(missing, strconv.FormatUint(id, 10))
+				}
+			}
+		}
+
+		if len(missing) != 0 {
+			slices.Sort(missing)
+
+			return doctorCheck(name, writers.DoctorFail,
+				fmt.Sprintf("Node(s) advertise no endpoints for listener %q: %s",
+					*listenerName, strings.Join(slices.Compact(missing), ", ")),
+				fmt.Sprintf("Check --%s matches a listener in the advertised-listeners of every node.", flags.ListenerName),
+			)
+		}
+
+		return doctorCheck(name, writers.DoctorPass,
+			fmt.Sprintf("Every node advertises endpoints for listener %q.", *listenerName), "",
+		)
+	}
+
+	seedsAreLoopback := true
+
+	for _, seed := range seeds {
+		if !isLoopbackHost(seed.Host) {
+			seedsAreLoopback = false
+		}
+	}
+
+	if !seedsAreLoopback {
+		for _, hostPort := range advertisedHostPorts(nodeInfos) {
+			if isLoopbackHost(hostPort.Host) {
+				return doctorCheck(name, writers.DoctorWarn,
+					fmt.Sprintf("The nodes advertise the loopback address %s which is not reachable from this host.",
+						hostPort.Host),
+					fmt.Sprintf("Set advertised-listeners in the AVS configuration, and --%s if the listener is not "+
+						"the default.", flags.ListenerName),
+				)
+			}
+		}
+	}
+
+	return doctorCheck(name, writers.DoctorPass, "The nodes advertise endpoints for the default listener.", "")
+}
+
+// checkTLSNames checks the certificates presented at the seeds and addresses
+// are valid for the name asvec verifies, which is --tls-hostname-override if
+// set.
+func checkTLSNames(clientFlags *flags.ClientFlags, seeds, addresses avs.HostPortSlice) *writers.DoctorCheck {
+	const name = "tls-names"
+
+	tlsConfig, err := clientFlags.NewTLSConfig()
+	if err != nil {
+		return doctorCheck(name, writers.DoctorFail,
+			fmt.Sprintf("Failed to load the TLS configuration: %s", err),
+			"Check the --tls-* flags point to readable certificate and key files.",
+		)
+	}
+
+	if tlsConfig == nil {
+		return doctorCheck(name, writers.DoctorSkip, "TLS is not configured.", "")
+	}
+
+	seen := map[string]struct{}{}
+	mismatches := []string{}
+	unreachable := []string{}
+	certNames := []string{}
+
+	for _, hostPort := range append(slices.Clone(seeds), addresses...) {
+		addr := hostPort.String()
+		if _, ok := seen[addr]; ok {
+			continue
+		}
+
+		seen[addr] = struct{}{}
+
+		serverName := hostPort.Host
+		if clientFlags.HostnameOverride != "" {
+			serverName = clientFlags.HostnameOverride
+		}
+
+		names, err := verifyCertificateName(tlsConfig, addr, serverName, clientFlags.Timeout)
+		if err != nil {
+			mismatches = append(mismatches, fmt.Sprintf("%s: %s", addr, err))
+			certNames = append(certNames, names...)
+
+			continue
+		}
+
+		if names == nil {
+			unreachable = append(unreachable, addr)
+		}
+	}
+
+	if len(mismatches) != 0 {
+		slices.Sort(certNames)
+
+		return doctorCheck(name, writers.DoctorFail,
+			"Certificate(s) are not valid for the names asvec verifies:\n"+strings.Join(mismatches, "\n"),
+			fmt.Sprintf("Add the addresses to the certificate's subject alternative names, or use --%s with one of "+
+				"the names in the certificate: %s", flags.TLSHostnameOverride, strings.Join(slices.Compact(certNames), ", ")),
+		)
+	}
+
+	if len(unreachable) != 0 {
+		return doctorCheck(name, writers.DoctorWarn,
+			fmt.Sprintf("Failed to get the certificate from: %s", strings.Join(unreachable, ", ")),
+			"Check the addresses listed are reachable from this host and serve TLS.",
+		)
+	}
+
+	return doctorCheck(name, writers.DoctorPass,
+		fmt.Sprintf("The certificates at %d address(es) are valid for the names asvec verifies.", len(seen)), "",
+	)
+}
+
+// verifyCertificateName connects to addr and checks its certificate is valid
+// for serverName. It returns the names in the certificate, or nil if the
+// certificate could not be retrieved.
+func verifyCertificateName(
+	tlsConfig *tls.Config,
+	addr, serverName string,
+	timeout time.Duration,
+) ([]string, error) {
+	config := tlsConfig.Clone()
+	config.ServerName = serverName
+	// The chain is verified when the client connects, this only reads the
+	// certificate to check the name.
+	config.InsecureSkipVerify = true //nolint:gosec // The certificate is not trusted, only inspected
+
+	conn, err := tls.DialWithDialer(&net.Dialer{Timeout: timeout}, "tcp", addr, config)
+	if err != nil {
+		logger.Debug("failed to get certificate", slog.String("address", addr), slog.Any("error", err))
+		return nil, nil
+	}
+	defer conn.Close()
+
+	certs := conn.ConnectionState().PeerCertificates
+	if len(certs) == 0 {
+		return nil, nil
+	}
+
+	names := slices.Clone(certs[0].DNSNames)
+	for _, ip := range certs[0].IPAddresses {
+		names = append(names, ip.String())
+	}
+
+	return names, certs[0].VerifyHostname(serverName)
+}
+
+func checkIndexStatus(
+	indexes *protos.IndexDefinitionList,
+	statuses []*protos.IndexStatusResponse,
+	indexErr error,
+) *writers.DoctorCheck {
+	const name = "index-status"
+
+	if indexErr != nil {
+		return doctorCheck(name, writers.DoctorFail,
+			fmt.Sprintf("Failed to list indexes: %s", indexErr),
+			"Check the user has permission to list indexes and the AVS logs.",
+		)
+	}
+
+	if len(indexes.GetIndices()) == 0 {
+		return doctorCheck(name, writers.DoctorPass, "There are no indexes.", "")
+	}
+
+	unknown := []string{}
+	notReady := []string{}
+
+	for i, index := range indexes.GetIndices() {
+		indexName := index.GetId().GetNamespace() + "." + index.GetId().GetName()
+
+		switch {
+		case statuses[i] == nil:
+			unknown = append(unknown, indexName)
+		case statuses[i].GetStatus() != protos.Status_READY:
+			notReady = append(notReady, fmt.Sprintf("%s (%s)", indexName, statuses[i].GetStatus()))
+		}
+	}
+
+	if len(unknown) != 0 {
+		return doctorCheck(name, writers.DoctorFail,
+			fmt.Sprintf("Failed to get the status of index(es): %s", strings.Join(unknown, ", ")),
+			"Check the AVS logs for errors about the indexes listed.",
+		)
+	}
+
+	if len(notReady) != 0 {
+		return doctorCheck(name, writers.DoctorWarn,
+			fmt.Sprintf("Index(es) not ready: %s", strings.Join(notReady, ", ")),
+			"Indexes are not ready while they are being created or are catching up. Use \"asvec index wait\" to "+
+				"wait for them, and check the AVS logs if they stay not ready.",
+		)
+	}
+
+	return doctorCheck(name, writers.DoctorPass, fmt.Sprintf("All %d index(es) are ready.", len(statuses)), "")
+}
+
+func checkUnmergedBacklog(
+	indexes *protos.IndexDefinitionList,
+	statuses []*protos.IndexStatusResponse,
+	indexErr error,
+	maxUnmerged int64,
+) *writers.DoctorCheck {
+	const name = "unmerged-backlog"
+
+	if indexErr != nil {
+		return doctorCheck(name, writers.DoctorSkip, "Skipped because the indexes could not be listed.", "")
+	}
+
+	backlogged := []string{}
+
+	for i, index := range indexes.GetIndices() {
+		if statuses[i] != nil && statuses[i].GetUnmergedRecordCount() > maxUnmerged {
+			backlogged = append(backlogged, fmt.Sprintf("%s.%s (%d)",
+				index.GetId().GetNamespace(), index.GetId().GetName(), statuses[i].GetUnmergedRecordCount()))
+		}
+	}
+
+	if len(backlogged) != 0 {
+		return doctorCheck(name, writers.DoctorWarn,
+			fmt.Sprintf("Index(es) with more than %d unmerged records: %s", maxUnmerged, strings.Join(backlogged, ", ")),
+			fmt.Sprintf("Check the nodes with the %s role are healthy. If writes are consistently faster than "+
+				"merging, increase --%s with \"asvec index update\".", protos.NodeRole_INDEXER, flags.HnswMergeParallelism),
+		)
+	}
+
+	return doctorCheck(name, writers.DoctorPass,
+		fmt.Sprintf("No index has more than %d unmerged records.", maxUnmerged), "",
+	)
+}
+
+// advertisedHostPorts returns every endpoint advertised by the nodes.
+func advertisedHostPorts(nodeInfos []*writers.NodeInfo) avs.HostPortSlice {
+	hostPorts := avs.HostPortSlice{}
+
+	for _, node := range nodeInfos {
+		for _, id := range slices.Sorted(maps.Keys(node.Endpoints.GetEndpoints())) {
+			for _, endpoint := range node.Endpoints.GetEndpoints()[id].GetEndpoints() {
+				hostPorts = append(hostPorts, avs.NewHostPort(endpoint.GetAddress(), int(endpoint.GetPort())))
+			}
+		}
+	}
+
+	return hostPorts
+}
+
+func isLoopbackHost(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+
+	ip := net.ParseIP(host)
+
+	return ip != nil && ip.IsLoopback()
+}
+
+func init() {
+	clusterDoctorCmd := newClusterDoctorCmd()
+	clusterCmd.AddCommand(clusterDoctorCmd)
+	clusterDoctorCmd.Flags().AddFlagSet(newClusterDoctorFlagSet())
+}
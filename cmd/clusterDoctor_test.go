@@ -0,0 +1,263 @@
+//go:build unit
+
+package cmd
+
+import (
+	"asvec/cmd/flags"
+	"asvec/cmd/writers"
+	"encoding/pem"
+	"errors"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	avs "github.com/aerospike/avs-client-go"
+	"github.com/aerospike/avs-client-go/protos"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newDoctorNode(id, clusterID uint64, version string, peers []uint64, roles ...protos.NodeRole) *writers.NodeInfo {
+	endpoints := map[uint64]*protos.ServerEndpointList{}
+
+	for _, peer := range peers {
+		endpoints[peer] = &protos.ServerEndpointList{Endpoints: []*protos.ServerEndpoint{
+			{Address: "10.0.0." + strconv.FormatUint(peer, 10), Port: 5000},
+		}}
+	}
+
+	return &writers.NodeInfo{
+		NodeID:    &protos.NodeId{Id: id},
+		Endpoints: &protos.ClusterNodeEndpoints{Endpoints: endpoints},
+		State:     &protos.ClusteringState{IsInCluster: true, ClusterId: &protos.ClusterId{Id: clusterID}},
+		About:     &protos.AboutResponse{Version: version, Roles: roles},
+	}
+}
+
+func TestDoctorNodeChecks(t *testing.T) {
+	allRoles := []protos.NodeRole{
+		protos.NodeRole_INDEX_QUERY,
+		protos.NodeRole_INDEX_UPDATE,
+		protos.NodeRole_KV_READ,
+		protos.NodeRole_KV_WRITE,
+		protos.NodeRole_INDEXER,
+	}
+
+	healthy := []*writers.NodeInfo{
+		newDoctorNode(1, 42, "1.0.0", []uint64{1, 2}, allRoles...),
+		newDoctorNode(2, 42, "1.0.0", []uint64{1, 2}, allRoles...),
+	}
+
+	for _, check := range []*writers.DoctorCheck{
+		checkConnectivity(nil, healthy, false),
+		checkClientVisibility(healthy, false),
+		checkPeerVisibility(healthy),
+		checkClusterID(healthy),
+		checkVersionSkew(healthy, false),
+		checkRoleCoverage(healthy, nil, false),
+		checkListenerName(healthy, nil, avs.HostPortSlice{avs.NewHostPort("10.0.0.1", 5000)}, false),
+	} {
+		assert.Equal(t, writers.DoctorPass, check.Status, "%s: %s", check.Name, check.Message)
+	}
+
+	unhealthy := []*writers.NodeInfo{
+		newDoctorNode(1, 42, "1.0.0", []uint64{1, 2, 3}, protos.NodeRole_INDEX_QUERY),
+		newDoctorNode(2, 43, "1.1.0", []uint64{2}, protos.NodeRole_KV_READ),
+		{NodeID: &protos.NodeId{Id: 4}},
+	}
+
+	testCases := []struct {
+		check           *writers.DoctorCheck
+		expectedStatus  string
+		expectedMessage string
+	}{
+		{
+			checkConnectivity(errors.New("connection refused"), nil, false),
+			writers.DoctorFail,
+			"Failed to connect: connection refused",
+		},
+		{
+			checkConnectivity(nil, unhealthy, false),
+			writers.DoctorFail,
+			"Node(s) did not respond to every request: 4",
+		},
+		{
+			checkClientVisibility(unhealthy, false),
+			writers.DoctorFail,
+			"asvec can't reach node(s): 3",
+		},
+		{
+			checkClientVisibility(unhealthy, true),
+			writers.DoctorSkip,
+			"asvec only connects to the load balancer so it does not need to reach every node.",
+		},
+		{
+			checkPeerVisibility(unhealthy),
+			writers.DoctorFail,
+			"Node 2 can't see: 1, 3",
+		},
+		{
+			checkClusterID(unhealthy),
+			writers.DoctorFail,
+			"The nodes have split into multiple clusters.\nCluster 42: node(s) 1\nCluster 43: node(s) 2",
+		},
+		{
+			checkVersionSkew(unhealthy, false),
+			writers.DoctorWarn,
+			"The nodes run different versions.\n1.0.0: node(s) 1\n1.1.0: node(s) 2",
+		},
+		{
+			checkRoleCoverage(unhealthy, nil, false),
+			writers.DoctorFail,
+			"No node has the role(s): INDEX_UPDATE, KV_WRITE, INDEXER",
+		},
+		{
+			checkListenerName(unhealthy, nil, avs.HostPortSlice{avs.NewHostPort("10.0.0.1", 5000)}, true),
+			writers.DoctorSkip,
+			"asvec only connects to the load balancer so it does not use the advertised endpoints.",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.check.Name, func(t *testing.T) {
+			assert.Equal(t, tc.expectedStatus, tc.check.Status)
+			assert.Equal(t, tc.expectedMessage, tc.check.Message)
+
+			if tc.expectedStatus == writers.DoctorWarn || tc.expectedStatus == writers.DoctorFail {
+				assert.NotEmpty(t, tc.check.Remediation)
+			}
+		})
+	}
+}
+
+func TestCheckClusterIDNotInCluster(t *testing.T) {
+	node := newDoctorNode(1, 42, "1.0.0", []uint64{1})
+	node.State.IsInCluster = false
+
+	check := checkClusterID([]*writers.NodeInfo{node})
+
+	assert.Equal(t, writers.DoctorFail, check.Status)
+	assert.Equal(t, "Node(s) not in a cluster: 1", check.Message)
+}
+
+func TestCheckRoleCoverageStandalone(t *testing.T) {
+	node := newDoctorNode(1, 42, "1.0.0", []uint64{1},
+		protos.NodeRole_INDEX_QUERY,
+		protos.NodeRole_INDEX_UPDATE,
+		protos.NodeRole_KV_READ,
+		protos.NodeRole_KV_WRITE,
+		protos.NodeRole_INDEXER,
+	)
+	mode := protos.IndexMode_STANDALONE
+	indexes := &protos.IndexDefinitionList{Indices: []*protos.IndexDefinition{
+		{Id: &protos.IndexId{Namespace: "test", Name: "myindex"}, Mode: &mode},
+	}}
+
+	check := checkRoleCoverage([]*writers.NodeInfo{node}, indexes, false)
+
+	assert.Equal(t, writers.DoctorWarn, check.Status)
+	assert.Equal(t, "Index test.myindex is standalone but no node has the STANDALONE_INDEXER role.", check.Message)
+}
+
+func TestCheckListenerName(t *testing.T) {
+	node := newDoctorNode(1, 42, "1.0.0", []uint64{1, 2})
+	node.Endpoints.Endpoints[2].Endpoints = nil
+	listenerName := "external"
+
+	check := checkListenerName([]*writers.NodeInfo{node}, &listenerName, nil, false)
+
+	assert.Equal(t, writers.DoctorFail, check.Status)
+	assert.Equal(t, `Node(s) advertise no endpoints for listener "external": 2`, check.Message)
+
+	loopback := newDoctorNode(1, 42, "1.0.0", nil)
+	loopback.Endpoints.Endpoints[1] = &protos.ServerEndpointList{Endpoints: []*protos.ServerEndpoint{
+		{Address: "127.0.0.1", Port: 5000},
+	}}
+
+	check = checkListenerName(
+		[]*writers.NodeInfo{loopback}, nil, avs.HostPortSlice{avs.NewHostPort("avs.example.com", 5000)}, false,
+	)
+
+	assert.Equal(t, writers.DoctorWarn, check.Status)
+
+	check = checkListenerName(
+		[]*writers.NodeInfo{loopback}, nil, avs.HostPortSlice{avs.NewHostPort("localhost", 5000)}, false,
+	)
+
+	assert.Equal(t, writers.DoctorPass, check.Status, "loopback endpoints are fine when asvec is on the same host")
+}
+
+func TestDoctorIndexChecks(t *testing.T) {
+	indexes := &protos.IndexDefinitionList{Indices: []*protos.IndexDefinition{
+		{Id: &protos.IndexId{Namespace: "test", Name: "a"}},
+		{Id: &protos.IndexId{Namespace: "test", Name: "b"}},
+	}}
+
+	ready := []*protos.IndexStatusResponse{
+		{Status: protos.Status_READY, UnmergedRecordCount: 10},
+		{Status: protos.Status_READY, UnmergedRecordCount: 20},
+	}
+
+	assert.Equal(t, writers.DoctorPass, checkIndexStatus(indexes, ready, nil).Status)
+	assert.Equal(t, writers.DoctorPass, checkUnmergedBacklog(indexes, ready, nil, 20).Status)
+	assert.Equal(t, writers.DoctorPass, checkIndexStatus(&protos.IndexDefinitionList{}, nil, nil).Status)
+
+	check := checkUnmergedBacklog(indexes, ready, nil, 15)
+	assert.Equal(t, writers.DoctorWarn, check.Status)
+	assert.Equal(t, "Index(es) with more than 15 unmerged records: test.b (20)", check.Message)
+
+	check = checkIndexStatus(indexes, []*protos.IndexStatusResponse{{Status: protos.Status_NOT_READY}, ready[1]}, nil)
+	assert.Equal(t, writers.DoctorWarn, check.Status)
+	assert.Equal(t, "Index(es) not ready: test.a (NOT_READY)", check.Message)
+
+	check = checkIndexStatus(indexes, []*protos.IndexStatusResponse{nil, ready[1]}, nil)
+	assert.Equal(t, writers.DoctorFail, check.Status)
+	assert.Equal(t, "Failed to get the status of index(es): test.a", check.Message)
+
+	check = checkIndexStatus(nil, nil, errors.New("permission denied"))
+	assert.Equal(t, writers.DoctorFail, check.Status)
+	assert.Equal(t, writers.DoctorSkip, checkUnmergedBacklog(nil, nil, errors.New("permission denied"), 15).Status)
+}
+
+func TestCheckTLSNames(t *testing.T) {
+	assert.Equal(t, writers.DoctorSkip, checkTLSNames(flags.NewClientFlags(), nil, nil).Status)
+
+	server := httptest.NewTLSServer(nil)
+	defer server.Close()
+
+	serverURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	port, err := strconv.Atoi(serverURL.Port())
+	require.NoError(t, err)
+
+	clientFlags := flags.NewClientFlags()
+	clientFlags.Timeout = 5 * time.Second
+	clientFlags.RootCAFile = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+
+	seeds := avs.HostPortSlice{avs.NewHostPort("127.0.0.1", port)}
+
+	check := checkTLSNames(clientFlags, seeds, seeds)
+	assert.Equal(t, writers.DoctorPass, check.Status, check.Message)
+	assert.Equal(t, "The certificates at 1 address(es) are valid for the names asvec verifies.", check.Message)
+
+	clientFlags.HostnameOverride = "avs.example.org"
+
+	check = checkTLSNames(clientFlags, seeds, nil)
+	assert.Equal(t, writers.DoctorFail, check.Status)
+	assert.Contains(t, check.Message, "127.0.0.1:"+serverURL.Port())
+	assert.Contains(t, check.Remediation, "--tls-hostname-override with one of the names in the certificate: "+
+		"*.example.com, 127.0.0.1, ::1, example.com")
+
+	clientFlags.HostnameOverride = "example.com"
+
+	check = checkTLSNames(clientFlags, seeds, nil)
+	assert.Equal(t, writers.DoctorPass, check.Status, check.Message)
+
+	server.Close()
+
+	check = checkTLSNames(clientFlags, seeds, nil)
+	assert.Equal(t, writers.DoctorWarn, check.Status)
+}
@@ -0,0 +1,386 @@
+package cmd
+
+import (
+	"archive/tar"
+	"asvec/cmd/flags"
+	"asvec/cmd/writers"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"runtime"
+	"runtime/debug"
+	"time"
+
+	avs "github.com/aerospike/avs-client-go"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+const collectInfoSummaryFile = "collectinfo.json"
+
+//nolint:govet // Padding not a concern for a CLI
+var collectInfoFlags = &struct {
+	clientFlags *flags.ClientFlags
+	file        string
+}{
+	clientFlags: rootFlags.clientFlags,
+}
+
+func newCollectInfoFlagSet() *pflag.FlagSet {
+	flagSet := &pflag.FlagSet{}
+	flagSet.AddFlagSet(collectInfoFlags.clientFlags.NewClientFlagSet())
+	flagSet.StringVar(&collectInfoFlags.file, flags.OutputFile, "", "The file to write the support bundle to. Defaults to asvec-collectinfo-<timestamp>.tar.gz in the current directory.") //nolint:lll // For readability
+
+	return flagSet
+}
+
+func newCollectInfoCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "collectinfo",
+		Short: "A command for collecting a support bundle",
+		Long: fmt.Sprintf(`A command for collecting the information needed by Aerospike support into
+a single tar.gz file. The bundle contains:
+
+  %s   asvec's version, the platform, and how long each
+                     section took to collect, or why it failed.
+  config.json        the client configuration, with secrets redacted.
+  nodes.json         each node's about info, clustering state, and endpoints.
+  indexes.json       every index definition and status.
+  users.json         users and their roles. Passwords are never included.
+  roles.json         the roles available.
+
+A section which can't be collected, e.g. users when the user is not an
+admin, is recorded in %s and the rest are still collected.
+
+For example:
+
+%s
+asvec collectinfo
+asvec collectinfo --%s /tmp/support.tar.gz
+			`, collectInfoSummaryFile, collectInfoSummaryFile, HelpTxtSetupEnv, flags.OutputFile),
+		PreRunE: func(_ *cobra.Command, _ []string) error {
+			return checkSeedsAndHost()
+		},
+		RunE: func(_ *cobra.Command, _ []string) error {
+			logger := logger.With("cmd", "collectInfoCmd")
+			logger.Debug("parsed flags",
+				append(
+					collectInfoFlags.clientFlags.NewSLogAttr(),
+					slog.String(flags.OutputFile, collectInfoFlags.file),
+				)...,
+			)
+
+			start := time.Now()
+			collector := newInfoCollector(start, isLoadBalancer(collectInfoFlags.clientFlags.Seeds))
+
+			collector.collect("config", "config.json", func() (any, error) {
+				return collectInfoConfig(collectInfoFlags.clientFlags), nil
+			})
+
+			var (
+				client        *avs.Client
+				releaseClient func()
+			)
+
+			collector.collect("connect", "", func() (any, error) {
+				var err error
+
+				client, releaseClient, err = acquireClient(collectInfoFlags.clientFlags)
+
+				return nil, err
+			})
+
+			if client != nil {
+				defer releaseClient()
+
+				collector.collectCluster(client, collectInfoFlags.clientFlags)
+			}
+
+			file := collectInfoFlags.file
+			if file == "" {
+				file = collectInfoName(start) + ".tar.gz"
+			}
+
+			if err := writeCollectInfoFile(file, collector); err != nil {
+				logger.Error("failed to write support bundle", slog.Any("error", err))
+				return err
+			}
+
+			for _, section := range collector.summary.Sections {
+				if section.Error != "" {
+					view.Warningf("Failed to collect %s: %s", section.Name, section.Error)
+				}
+			}
+
+			view.Printf("Wrote support bundle to %s", file)
+
+			return nil
+		},
+	}
+}
+
+// collectInfoSection records how long a section of the bundle took to
+// collect and the error if it failed.
+type collectInfoSection struct {
+	Name     string `json:"name"`
+	File     string `json:"file,omitempty"`
+	Duration string `json:"duration"`
+	Error    string `json:"error,omitempty"`
+}
+
+//nolint:govet // Padding not a concern for a CLI
+type collectInfoSummary struct {
+	AsvecVersion  string                `json:"asvecVersion"`
+	ClientVersion string                `json:"avsClientVersion"`
+	GoVersion     string                `json:"goVersion"`
+	OS            string                `json:"os"`
+	Arch          string                `json:"arch"`
+	LoadBalancer  bool                  `json:"loadBalancer"`
+	StartTime     time.Time             `json:"startTime"`
+	Duration      string                `json:"duration"`
+	Sections      []*collectInfoSection `json:"sections"`
+}
+
+type collectInfoFile struct {
+	name string
+	data []byte
+}
+
+// infoCollector collects the sections of a support bundle. A section which
+// fails is recorded in the summary and does not stop the others.
+type infoCollector struct {
+	summary *collectInfoSummary
+	files   []*collectInfoFile
+}
+
+func newInfoCollector(start time.Time, isLB bool) *infoCollector {
+	return &infoCollector{
+		summary: &collectInfoSummary{
+			AsvecVersion:  Version,
+			ClientVersion: avsClientVersion(),
+			GoVersion:     runtime.Version(),
+			OS:            runtime.GOOS,
+			Arch:          runtime.GOARCH,
+			LoadBalancer:  isLB,
+			StartTime:     start,
+		},
+	}
+}
+
+// collect runs fn and adds its result to the bundle as file. Nothing is
+// added if file is empty or fn returns nil data.
+func (c *infoCollector) collect(name, file string, fn func() (any, error)) {
+	start := time.Now()
+	data, err := fn()
+
+	section := &collectInfoSection{Name: name}
+
+	if data != nil && file != "" {
+		out, marshalErr := marshalCollectInfo(data)
+		if marshalErr != nil {
+			err = errors.Join(err, marshalErr)
+		} else {
+			section.File = file
+			c.files = append(c.files, &collectInfoFile{name: file, data: out})
+		}
+	}
+
+	if err != nil {
+		logger.Warn("failed to collect section", slog.String("section", name), slog.Any("error", err))
+
+		section.Error = err.Error()
+	}
+
+	section.Duration = time.Since(start).String()
+	c.summary.Sections = append(c.summary.Sections, section)
+}
+
+// collectCluster collects the nodes, indexes, users, and roles.
+func (c *infoCollector) collectCluster(client *avs.Client, clientFlags *flags.ClientFlags) {
+	timeout := clientFlags.Timeout
+
+	c.collect("nodes", "nodes.json", func() (any, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+
+		return getAllNodesInfo(ctx, client, clientFlags.ListenerName.Val), nil
+	})
+
+	c.collect("indexes", "indexes.json", func() (any, error) {
+		indexList, indexStatusList, err := listIndexesWithStatus(client, timeout)
+		if err != nil {
+			return nil, err
+		}
+
+		indexes := make([]*writers.IndexJSON, len(indexList.GetIndices()))
+
+		for i, index := range indexList.GetIndices() {
+			indexes[i] = writers.NewIndexJSON(index, indexStatusList[i])
+		}
+
+		return indexes, nil
+	})
+
+	c.collect("users", "users.json", func() (any, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+
+		users, err := client.ListUsers(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		items := make([]writers.ProtoJSON, len(users.GetUsers()))
+
+		for i, user := range users.GetUsers() {
+			items[i] = writers.ProtoJSON{Message: user}
+		}
+
+		return items, nil
+	})
+
+	c.collect("roles", "roles.json", func() (any, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+
+		roles, err := client.ListRoles(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		items := make([]writers.ProtoJSON, len(roles.GetRoles()))
+
+		for i, role := range roles.GetRoles() {
+			items[i] = writers.ProtoJSON{Message: role}
+		}
+
+		return items, nil
+	})
+}
+
+// collectInfoConfig returns the client configuration with the same
+// redaction as the debug log: the password and TLS files are only reported
+// as set or not.
+func collectInfoConfig(clientFlags *flags.ClientFlags) map[string]any {
+	config := map[string]any{
+		flags.ConfigFile:  viper.ConfigFileUsed(),
+		flags.ClusterName: rootFlags.clusterName,
+	}
+
+	for _, attr := range clientFlags.NewSLogAttr() {
+		a, ok := attr.(slog.Attr)
+		if !ok {
+			continue
+		}
+
+		switch a.Value.Kind() {
+		case slog.KindBool:
+			config[a.Key] = a.Value.Bool()
+		default:
+			config[a.Key] = a.Value.String()
+		}
+	}
+
+	return config
+}
+
+// writeTo writes the bundle as a tar.gz. Every file is in a directory named
+// after the bundle so the archive extracts cleanly.
+func (c *infoCollector) writeTo(w io.Writer, dir string) error {
+	c.summary.Duration = time.Since(c.summary.StartTime).String()
+
+	summary, err := marshalCollectInfo(c.summary)
+	if err != nil {
+		return err
+	}
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+
+	files := append([]*collectInfoFile{{name: collectInfoSummaryFile, data: summary}}, c.files...)
+
+	for _, file := range files {
+		header := &tar.Header{
+			Name:    dir + "/" + file.name,
+			Mode:    0o644,
+			Size:    int64(len(file.data)),
+			ModTime: c.summary.StartTime,
+		}
+
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+
+		if _, err := tw.Write(file.data); err != nil {
+			return err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+
+	return gz.Close()
+}
+
+// marshalCollectInfo marshals indented JSON without escaping HTML characters
+// so values like "<nil>" are readable.
+func marshalCollectInfo(data any) ([]byte, error) {
+	var buf bytes.Buffer
+
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+
+	if err := encoder.Encode(data); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func writeCollectInfoFile(file string, collector *infoCollector) error {
+	f, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+
+	if err := collector.writeTo(f, collectInfoName(collector.summary.StartTime)); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+// collectInfoName returns the name of a bundle collected at start.
+func collectInfoName(start time.Time) string {
+	return "asvec-collectinfo-" + start.UTC().Format("20060102T150405Z")
+}
+
+// avsClientVersion returns the version of the AVS client library asvec was
+// built with.
+func avsClientVersion() string {
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, dep := range info.Deps {
+			if dep.Path == "github.com/aerospike/avs-client-go" {
+				return dep.Version
+			}
+		}
+	}
+
+	return "unknown"
+}
+
+func init() {
+	collectInfoCmd := newCollectInfoCmd()
+	rootCmd.AddCommand(collectInfoCmd)
+	collectInfoCmd.Flags().AddFlagSet(newCollectInfoFlagSet())
+}
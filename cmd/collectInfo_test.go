@@ -0,0 +1,104 @@
+//go:build unit
+
+package cmd
+
+import (
+	"archive/tar"
+	"asvec/cmd/flags"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"io"
+	"maps"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func readCollectInfoBundle(t *testing.T, r io.Reader) map[string][]byte {
+	t.Helper()
+
+	gz, err := gzip.NewReader(r)
+	require.NoError(t, err)
+
+	files := map[string][]byte{}
+	tr := tar.NewReader(gz)
+
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		require.NoError(t, err)
+
+		data, err := io.ReadAll(tr)
+		require.NoError(t, err)
+
+		files[header.Name] = data
+	}
+
+	return files
+}
+
+func TestInfoCollector(t *testing.T) {
+	client := newFakeAVSClient(t)
+	start := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	collector := newInfoCollector(start, true)
+	collector.collect("config", "config.json", func() (any, error) {
+		clientFlags := flags.NewClientFlags()
+		_ = clientFlags.AuthCredentials.Password.Set("secret")
+
+		return collectInfoConfig(clientFlags), nil
+	})
+	clientFlags := flags.NewClientFlags()
+	clientFlags.Timeout = 5 * time.Second
+
+	collector.collectCluster(client, clientFlags)
+
+	var buf bytes.Buffer
+
+	require.NoError(t, collector.writeTo(&buf, collectInfoName(start)))
+
+	files := readCollectInfoBundle(t, &buf)
+
+	dir := "asvec-collectinfo-20250102T030405Z/"
+	assert.ElementsMatch(t, []string{
+		dir + "collectinfo.json",
+		dir + "config.json",
+		dir + "nodes.json",
+		dir + "indexes.json",
+	}, slices.Collect(maps.Keys(files)))
+
+	summary := collectInfoSummary{}
+	require.NoError(t, json.Unmarshal(files[dir+"collectinfo.json"], &summary))
+
+	assert.Equal(t, Version, summary.AsvecVersion)
+	assert.True(t, summary.LoadBalancer)
+	assert.True(t, summary.StartTime.Equal(start))
+	require.Len(t, summary.Sections, 5)
+
+	for _, section := range summary.Sections {
+		assert.NotEmpty(t, section.Duration, section.Name)
+
+		switch section.Name {
+		case "users", "roles":
+			// The fake server does not implement user administration.
+			assert.Contains(t, section.Error, "Unimplemented", section.Name)
+			assert.Empty(t, section.File, section.Name)
+		default:
+			assert.Empty(t, section.Error, section.Name)
+			assert.Equal(t, section.Name+".json", section.File)
+		}
+	}
+
+	assert.NotContains(t, string(files[dir+"config.json"]), "secret")
+	assert.Contains(t, string(files[dir+"config.json"]), `"password": "*"`)
+	assert.Contains(t, string(files[dir+"nodes.json"]), `"version": "1.0.0"`)
+	assert.Contains(t, string(files[dir+"indexes.json"]), `"name": "myindex"`)
+}
@@ -3,4 +3,5 @@ package cmd
 const (
 	HelpTxtSetupEnv = "export ASVEC_HOST=<avs-ip>:5000 ASVEC_CREDENTIALS=<user>[:<password>]"
 	StdIn           = "stdin"
+	StdOut          = "stdout"
 )
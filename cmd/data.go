@@ -0,0 +1,21 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// dataCmd represents the data command
+var dataCmd = &cobra.Command{
+	Use:   "data",
+	Short: "A parent command for bulk importing and exporting records.",
+	Long: `A parent command for bulk importing and exporting vector records.
+
+For example:
+
+	asvec data --help
+		`,
+}
+
+func init() {
+	rootCmd.AddCommand(dataCmd)
+}
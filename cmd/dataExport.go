@@ -0,0 +1,574 @@
+package cmd
+
+import (
+	"asvec/cmd/flags"
+	"asvec/cmd/records"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	as "github.com/aerospike/aerospike-client-go/v7"
+	"github.com/aerospike/avs-client-go"
+	"github.com/jedib0t/go-pretty/v6/progress"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+const (
+	defaultExportParallelism = 16
+	exportPageSize           = 1000
+)
+
+//nolint:govet // Padding not a concern for a CLI
+var dataExportFlags = &struct {
+	clientFlags    *flags.ClientFlags
+	aerospikeFlags *flags.AerospikeFlags
+	namespace      string
+	indexName      string
+	outputFile     string
+	fileFormat     flags.FileFormatFlag
+	keyField       string
+	fields         []string
+	includeVector  bool
+	parallelism    int
+	checkpointFile string
+}{
+	clientFlags:    rootFlags.clientFlags,
+	aerospikeFlags: flags.NewAerospikeFlags(),
+}
+
+func newDataExportFlagSet() *pflag.FlagSet {
+	flagSet := &pflag.FlagSet{}
+	flagSet.StringVarP(&dataExportFlags.namespace, flags.Namespace, flags.NamespaceShort, "", "The namespace of the index.")                                                                                                                      //nolint:lll // For readability
+	flagSet.StringVarP(&dataExportFlags.indexName, flags.IndexName, flags.IndexNameShort, "", "The index whose records are exported.")                                                                                                            //nolint:lll // For readability
+	flagSet.StringVar(&dataExportFlags.outputFile, flags.OutputFile, StdOut, "The file to export records to.")                                                                                                                                    //nolint:lll // For readability
+	flagSet.Var(&dataExportFlags.fileFormat, flags.FileFormat, fmt.Sprintf("The format of the output file. Inferred from the file extension if not provided, otherwise jsonl. Valid values: %s", strings.Join(records.WriteFormatNames(), ", "))) //nolint:lll // For readability
+	flagSet.StringVar(&dataExportFlags.keyField, flags.KeyField, "key", "The field, or CSV column, each record's key is written to.")                                                                                                             //nolint:lll // For readability
+	flagSet.StringSliceVarP(&dataExportFlags.fields, flags.Fields, "f", nil, "The fields to export. All fields are exported if not provided. Required for csv.")                                                                                  //nolint:lll // For readability
+	flagSet.BoolVar(&dataExportFlags.includeVector, flags.IncludeVector, false, "Export each record's vector. Always true for fvecs.")                                                                                                            //nolint:lll // For readability
+	flagSet.IntVar(&dataExportFlags.parallelism, flags.Parallelism, defaultExportParallelism, "The number of records to read concurrently.")                                                                                                      //nolint:lll // For readability
+	flagSet.StringVar(&dataExportFlags.checkpointFile, flags.CheckpointFile, "", "A file used to track export progress. If the file exists the export is resumed from it. Removed once the export completes.")                                    //nolint:lll // For readability
+	flagSet.AddFlagSet(dataExportFlags.aerospikeFlags.NewFlagSet())
+
+	return flagSet
+}
+
+var dataExportRequiredFlags = []string{
+	flags.Namespace,
+	flags.IndexName,
+	flags.AerospikeFlagPrefix + "host",
+}
+
+// exportCheckpoint records how far an export has progressed so that it can
+// be resumed. Offset is the size of the output file when the checkpoint was
+// written and Cursor is the encoded Aerospike partition filter. Fields and
+// IncludeVector are stored so that a resumed export writes the same fields as
+// the records already in the output file.
+type exportCheckpoint struct {
+	Namespace     string         `json:"namespace"`
+	IndexName     string         `json:"indexName"`
+	Format        records.Format `json:"format"`
+	Fields        []string       `json:"fields"`
+	IncludeVector bool           `json:"includeVector"`
+	Cursor        []byte         `json:"cursor"`
+	Offset        int64          `json:"offset"`
+	Exported      int            `json:"exported"`
+	Skipped       int            `json:"skipped"`
+}
+
+func newDataExportCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "export",
+		Short: "A command for bulk exporting the records behind an index",
+		Long: fmt.Sprintf(`A command for bulk exporting the records behind an index to a file or
+stdout. Supported formats are JSONL, CSV, and fvecs. Files written by this
+command can be imported using "asvec data import".
+
+AVS does not support listing records so the namespace, or the index's set
+filter, is scanned directly from Aerospike Database using the --%shost
+flags. Each record found is then read using AVS. Records without the
+index's vector field are skipped.
+
+JSONL files contain all fields, or only --%s, and the vector when
+--%s is set. CSV files contain a column for the key, each of --%s,
+and the vector when --%s is set. fvecs files only contain vectors.
+
+Use --%s to make an export resumable. If the export is interrupted
+running the same command again continues where it left off.
+
+For example:
+
+%s
+asvec data export -n test -i my-index --%shost 127.0.0.1:3000 \
+	--%s --file my-index.jsonl --%s my-index.checkpoint
+
+asvec data export -n test -i my-index --%shost 127.0.0.1:3000 \
+	--%s --%s name,tags --file my-index.csv
+			`, flags.AerospikeFlagPrefix, flags.Fields, flags.IncludeVector, flags.Fields,
+			flags.IncludeVector, flags.CheckpointFile, HelpTxtSetupEnv, flags.AerospikeFlagPrefix,
+			flags.IncludeVector, flags.CheckpointFile, flags.AerospikeFlagPrefix, flags.IncludeVector,
+			flags.Fields),
+		PreRunE: func(_ *cobra.Command, _ []string) error {
+			if dataExportFlags.parallelism < 1 {
+				return fmt.Errorf("--%s must be at least 1", flags.Parallelism)
+			}
+
+			if dataExportFlags.checkpointFile != "" && dataExportFlags.outputFile == StdOut {
+				return fmt.Errorf("--%s requires --%s", flags.CheckpointFile, flags.OutputFile)
+			}
+
+			return checkSeedsAndHost()
+		},
+		RunE: func(_ *cobra.Command, _ []string) error {
+			logger.Debug("parsed flags",
+				append(
+					append(dataExportFlags.clientFlags.NewSLogAttr(), dataExportFlags.aerospikeFlags.NewSLogAttr()...),
+					slog.String(flags.Namespace, dataExportFlags.namespace),
+					slog.String(flags.IndexName, dataExportFlags.indexName),
+					slog.String(flags.OutputFile, dataExportFlags.outputFile),
+					slog.String(flags.FileFormat, dataExportFlags.fileFormat.String()),
+					slog.String(flags.KeyField, dataExportFlags.keyField),
+					slog.Any(flags.Fields, dataExportFlags.fields),
+					slog.Bool(flags.IncludeVector, dataExportFlags.includeVector),
+					slog.Int(flags.Parallelism, dataExportFlags.parallelism),
+					slog.String(flags.CheckpointFile, dataExportFlags.checkpointFile),
+				)...,
+			)
+
+			format, err := exportFileFormat(dataExportFlags.outputFile, &dataExportFlags.fileFormat)
+			if err != nil {
+				logger.Error("unable to determine file format", slog.Any("error", err))
+				return err
+			}
+
+			includeVector := dataExportFlags.includeVector || format == records.FormatFvecs
+
+			if format == records.FormatCSV && len(dataExportFlags.fields) == 0 && !includeVector {
+				return fmt.Errorf("csv export requires --%s or --%s", flags.Fields, flags.IncludeVector)
+			}
+
+			checkpoint, err := loadExportCheckpoint(dataExportFlags.checkpointFile)
+			if err != nil {
+				logger.Error("unable to load checkpoint", slog.Any("error", err))
+				return err
+			}
+
+			if checkpoint != nil {
+				if checkpoint.Namespace != dataExportFlags.namespace ||
+					checkpoint.IndexName != dataExportFlags.indexName ||
+					checkpoint.Format != format ||
+					!slices.Equal(checkpoint.Fields, dataExportFlags.fields) ||
+					checkpoint.IncludeVector != includeVector {
+					return fmt.Errorf(
+						"checkpoint %s is for a different export, remove it to start a new export",
+						dataExportFlags.checkpointFile,
+					)
+				}
+
+				view.PrintfErr(
+					"Resuming export from %s, %d records already exported",
+					dataExportFlags.checkpointFile, checkpoint.Exported,
+				)
+			}
+
+			client, err := createClientFromFlags(dataExportFlags.clientFlags)
+			if err != nil {
+				return err
+			}
+			defer client.Close()
+
+			ctx, cancel := context.WithTimeout(context.Background(), dataExportFlags.clientFlags.Timeout)
+			defer cancel()
+
+			indexDef, err := client.IndexGet(ctx, dataExportFlags.namespace, dataExportFlags.indexName, false)
+			if err != nil {
+				logger.Error("unable to get index definition", slog.Any("error", err))
+				return err
+			}
+
+			asClient, err := createAerospikeClientFromFlags(dataExportFlags.aerospikeFlags)
+			if err != nil {
+				return err
+			}
+			defer asClient.Close()
+
+			output, err := openExportFile(dataExportFlags.outputFile, checkpoint)
+			if err != nil {
+				logger.Error("unable to open output file", slog.Any("error", err))
+				return err
+			}
+			defer output.Close()
+
+			columns := slices.Clone(dataExportFlags.fields)
+			if includeVector {
+				columns = append(columns, indexDef.Field)
+			}
+
+			writer, err := records.NewWriter(output, format, records.WriterOptions{
+				KeyField:    dataExportFlags.keyField,
+				VectorField: indexDef.Field,
+				Columns:     columns,
+				Append:      checkpoint != nil && checkpoint.Offset > 0,
+			})
+			if err != nil {
+				logger.Error("unable to write output file", slog.Any("error", err))
+				return err
+			}
+
+			if checkpoint == nil {
+				checkpoint = &exportCheckpoint{
+					Namespace:     dataExportFlags.namespace,
+					IndexName:     dataExportFlags.indexName,
+					Format:        format,
+					Fields:        dataExportFlags.fields,
+					IncludeVector: includeVector,
+				}
+			}
+
+			tracker, stopProgress := newProgressTracker("Exporting records", 0, progress.UnitsDefault)
+			tracker.SetValue(int64(checkpoint.Exported))
+
+			exporter := &recordExporter{
+				client:         client,
+				asClient:       asClient,
+				writer:         writer,
+				output:         output,
+				namespace:      dataExportFlags.namespace,
+				setFilter:      indexDef.SetFilter,
+				vectorField:    indexDef.Field,
+				fields:         dataExportFlags.fields,
+				includeVector:  includeVector,
+				parallelism:    dataExportFlags.parallelism,
+				timeout:        dataExportFlags.clientFlags.Timeout,
+				checkpointFile: dataExportFlags.checkpointFile,
+				checkpoint:     checkpoint,
+				tracker:        tracker,
+			}
+
+			err = exporter.run()
+
+			stopProgress()
+
+			if err != nil {
+				logger.Error("unable to export records", slog.Any("error", err))
+
+				if dataExportFlags.checkpointFile != "" {
+					view.PrintfErr("Export interrupted, run the same command again to resume")
+				}
+
+				return err
+			}
+
+			if dataExportFlags.checkpointFile != "" {
+				if err := os.Remove(dataExportFlags.checkpointFile); err != nil {
+					logger.Warn("unable to remove checkpoint file", slog.Any("error", err))
+				}
+			}
+
+			msg := fmt.Sprintf(
+				"Successfully exported %d records from index %s.%s",
+				checkpoint.Exported, dataExportFlags.namespace, dataExportFlags.indexName,
+			)
+
+			if checkpoint.Skipped > 0 {
+				msg += fmt.Sprintf(
+					", skipped %d records without a stored key or field %s", checkpoint.Skipped, indexDef.Field,
+				)
+			}
+
+			// Keep stdout clean when records are being written to it.
+			if dataExportFlags.outputFile == StdOut {
+				view.PrintErr(msg)
+			} else {
+				view.Print(msg)
+			}
+
+			return nil
+		},
+	}
+}
+
+// exportFileFormat returns the format set by the flag, otherwise the format
+// inferred from the file extension. Stdout defaults to JSONL.
+func exportFileFormat(file string, formatFlag *flags.FileFormatFlag) (records.Format, error) {
+	if file == StdOut && !formatFlag.IsSet() {
+		return records.FormatJSONL, nil
+	}
+
+	return importFileFormat(file, formatFlag)
+}
+
+func loadExportCheckpoint(file string) (*exportCheckpoint, error) {
+	if file == "" {
+		return nil, nil
+	}
+
+	b, err := os.ReadFile(file)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	checkpoint := &exportCheckpoint{}
+	if err := json.Unmarshal(b, checkpoint); err != nil {
+		return nil, fmt.Errorf("invalid checkpoint file %s: %w", file, err)
+	}
+
+	return checkpoint, nil
+}
+
+// save writes the checkpoint to a temporary file which is then renamed so an
+// interrupted save never leaves a partially written checkpoint.
+func (c *exportCheckpoint) save(file string) error {
+	b, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+
+	tmp := file + ".tmp"
+
+	if err := os.WriteFile(tmp, b, 0o600); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, file)
+}
+
+// openExportFile opens the output file. When resuming, anything written after
+// the checkpoint is discarded as those records will be exported again.
+func openExportFile(file string, checkpoint *exportCheckpoint) (*os.File, error) {
+	if file == StdOut {
+		return os.Stdout, nil
+	}
+
+	if checkpoint == nil {
+		return os.Create(file)
+	}
+
+	f, err := os.OpenFile(file, os.O_RDWR, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := f.Truncate(checkpoint.Offset); err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	if _, err := f.Seek(checkpoint.Offset, io.SeekStart); err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	return f, nil
+}
+
+// recordExporter scans Aerospike Database for the keys of the records behind
+// an index, a page at a time, and writes each record read from AVS.
+//
+//nolint:govet // Padding not a concern for a CLI
+type recordExporter struct {
+	client         *avs.Client
+	asClient       *as.Client
+	writer         records.Writer
+	output         *os.File
+	namespace      string
+	setFilter      *string
+	vectorField    string
+	fields         []string
+	includeVector  bool
+	parallelism    int
+	timeout        time.Duration
+	checkpointFile string
+	checkpoint     *exportCheckpoint
+	tracker        *progress.Tracker
+}
+
+func (e *recordExporter) run() error {
+	partitionFilter := as.NewPartitionFilterAll()
+
+	if e.checkpoint.Cursor != nil {
+		if err := partitionFilter.DecodeCursor(e.checkpoint.Cursor); err != nil {
+			return fmt.Errorf("invalid checkpoint cursor: %w", err)
+		}
+	}
+
+	policy := as.NewScanPolicy()
+	policy.IncludeBinData = false
+	policy.MaxRecords = exportPageSize
+
+	var set string
+	if e.setFilter != nil {
+		set = *e.setFilter
+	}
+
+	for !partitionFilter.IsDone() {
+		recordset, scanErr := e.asClient.ScanPartitions(policy, partitionFilter, e.namespace, set)
+		if scanErr != nil {
+			return scanErr
+		}
+
+		keys := []*as.Key{}
+
+		for result := range recordset.Results() {
+			if result.Err != nil {
+				return result.Err
+			}
+
+			keys = append(keys, result.Record.Key)
+		}
+
+		page, err := e.getRecords(keys)
+		if err != nil {
+			return err
+		}
+
+		for _, record := range page {
+			if record == nil {
+				e.checkpoint.Skipped++
+				continue
+			}
+
+			if err := e.writer.Write(record); err != nil {
+				return err
+			}
+
+			e.checkpoint.Exported++
+		}
+
+		if err := e.writer.Flush(); err != nil {
+			return err
+		}
+
+		e.tracker.SetValue(int64(e.checkpoint.Exported))
+
+		if err := e.saveCheckpoint(partitionFilter); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (e *recordExporter) saveCheckpoint(partitionFilter *as.PartitionFilter) error {
+	if e.checkpointFile == "" {
+		return nil
+	}
+
+	cursor, encodeErr := partitionFilter.EncodeCursor()
+	if encodeErr != nil {
+		return encodeErr
+	}
+
+	offset, err := e.output.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+
+	e.checkpoint.Cursor = cursor
+	e.checkpoint.Offset = offset
+
+	return e.checkpoint.save(e.checkpointFile)
+}
+
+// getRecords reads the records for keys from AVS in parallel. The returned
+// slice is in the same order as keys and contains nil for records that were
+// skipped.
+func (e *recordExporter) getRecords(keys []*as.Key) ([]*records.Record, error) {
+	page := make([]*records.Record, len(keys))
+	errs := make([]error, len(keys))
+	indexCh := make(chan int)
+
+	var wg sync.WaitGroup
+
+	for w := 0; w < e.parallelism; w++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for i := range indexCh {
+				page[i], errs[i] = e.getRecord(keys[i])
+			}
+		}()
+	}
+
+	for i := range keys {
+		indexCh <- i
+	}
+
+	close(indexCh)
+	wg.Wait()
+
+	return page, errors.Join(errs...)
+}
+
+func (e *recordExporter) getRecord(asKey *as.Key) (*records.Record, error) {
+	if asKey.Value() == nil {
+		logger.Warn("skipping record without a stored key", slog.String("digest", fmt.Sprintf("%x", asKey.Digest())))
+		return nil, nil
+	}
+
+	key := asKey.Value().GetObject()
+	if i, ok := key.(int); ok {
+		key = int64(i)
+	}
+
+	var set *string
+	if asKey.SetName() != "" {
+		setName := asKey.SetName()
+		set = &setName
+	}
+
+	var includeFields []string
+	if e.fields != nil {
+		includeFields = append([]string{e.vectorField}, e.fields...)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), e.timeout)
+	defer cancel()
+
+	record, err := e.client.Get(ctx, e.namespace, set, key, includeFields, nil)
+	if err != nil {
+		if grpcCode(err) == "NotFound" {
+			logger.Debug("skipping record removed during export", slog.Any("key", key))
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	if _, ok := record.Data[e.vectorField]; !ok {
+		return nil, nil
+	}
+
+	if !e.includeVector {
+		delete(record.Data, e.vectorField)
+	}
+
+	return &records.Record{Key: key, Data: record.Data}, nil
+}
+
+func init() {
+	dataExportCmd := newDataExportCmd()
+	dataCmd.AddCommand(dataExportCmd)
+	dataExportCmd.Flags().AddFlagSet(newDataExportFlagSet())
+
+	for _, flag := range dataExportRequiredFlags {
+		err := dataExportCmd.MarkFlagRequired(flag)
+		if err != nil {
+			panic(err)
+		}
+	}
+}
@@ -0,0 +1,497 @@
+package cmd
+
+import (
+	"asvec/cmd/flags"
+	"asvec/cmd/records"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/aerospike/avs-client-go"
+	"github.com/aerospike/avs-client-go/protos"
+	"github.com/jedib0t/go-pretty/v6/progress"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+const (
+	defaultImportParallelism = 16
+	defaultImportRetries     = 3
+	defaultImportErrorFile   = "asvec-import-errors.jsonl"
+	importRetryBackoff       = 100 * time.Millisecond
+)
+
+// importRetryCodes are the gRPC codes of write errors which may succeed when
+// retried. Other errors, e.g. a record which already exists, fail at once.
+var importRetryCodes = []string{"Unavailable", "DeadlineExceeded", "ResourceExhausted"}
+
+//nolint:govet // Padding not a concern for a CLI
+var dataImportFlags = &struct {
+	clientFlags        *flags.ClientFlags
+	namespace          string
+	set                flags.StringOptionalFlag
+	indexName          string
+	inputFile          string
+	fileFormat         flags.FileFormatFlag
+	keyField           string
+	writeType          flags.WriteTypeFlag
+	ignoreMemQueueFull bool
+	parallelism        int
+	retries            int
+	errorFile          string
+}{
+	clientFlags: rootFlags.clientFlags,
+	writeType:   flags.WriteTypeFlag(protos.WriteType_UPSERT.String()),
+}
+
+func newDataImportFlagSet() *pflag.FlagSet {
+	flagSet := &pflag.FlagSet{}
+	flagSet.StringVarP(&dataImportFlags.namespace, flags.Namespace, flags.NamespaceShort, "", "The namespace to write records to.")                                                                                                         //nolint:lll // For readability
+	flagSet.VarP(&dataImportFlags.set, flags.Set, flags.SetShort, "The set to write records to. Defaults to the index's set filter.")                                                                                                       //nolint:lll // For readability
+	flagSet.StringVarP(&dataImportFlags.indexName, flags.IndexName, flags.IndexNameShort, "", "The index the records are written for. Used to determine the vector field and number of dimensions.")                                        //nolint:lll // For readability
+	flagSet.StringVar(&dataImportFlags.inputFile, flags.InputFile, StdIn, "The file to import records from.")                                                                                                                               //nolint:lll // For readability
+	flagSet.Var(&dataImportFlags.fileFormat, flags.FileFormat, fmt.Sprintf("The format of the input file. Inferred from the file extension if not provided, otherwise jsonl. Valid values: %s", strings.Join(records.FormatNames(), ", "))) //nolint:lll // For readability
+	flagSet.StringVar(&dataImportFlags.keyField, flags.KeyField, "key", "The field, or CSV column, containing each record's key. Ignored for npy, fvecs, and bvecs files which use the row number as the key.")                             //nolint:lll // For readability
+	flagSet.Var(&dataImportFlags.writeType, flags.WriteType, fmt.Sprintf("How records are written. Valid values: %s", strings.Join(flags.WriteTypeEnum(), ", ")))                                                                           //nolint:lll // For readability
+	flagSet.BoolVar(&dataImportFlags.ignoreMemQueueFull, flags.IgnoreMemQueueFull, false, "Write records even if the index's in-memory queue is full.")                                                                                     //nolint:lll // For readability
+	flagSet.IntVar(&dataImportFlags.parallelism, flags.Parallelism, defaultImportParallelism, "The number of records to write concurrently.")                                                                                               //nolint:lll // For readability
+	flagSet.IntVar(&dataImportFlags.retries, flags.Retries, defaultImportRetries, "The number of times a failed write is retried.")                                                                                                         //nolint:lll // For readability
+	flagSet.StringVar(&dataImportFlags.errorFile, flags.ErrorFile, defaultImportErrorFile, "The JSONL file that records which failed to import are written to. Only created if a record fails.")                                            //nolint:lll // For readability
+
+	return flagSet
+}
+
+var dataImportRequiredFlags = []string{
+	flags.Namespace,
+	flags.IndexName,
+}
+
+// importResult is the outcome of importing a single record.
+type importResult struct {
+	Key   any    `json:"key"`
+	Error string `json:"error"`
+	Line  int    `json:"line,omitempty"`
+}
+
+// countingReader counts the number of bytes read so that progress can be
+// reported for formats where the number of records is not known upfront.
+type countingReader struct {
+	reader io.Reader
+	count  atomic.Int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.count.Add(int64(n))
+
+	return n, err
+}
+
+func newDataImportCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "import",
+		Short: "A command for bulk importing records",
+		Long: fmt.Sprintf(`A command for bulk importing records from a file or stdin. Supported
+formats are JSONL, CSV, NumPy .npy, and fvecs/bvecs. The vector field and
+number of dimensions are read from the index provided with --%s, and
+records whose vectors do not match are not written.
+
+JSONL and CSV records are keyed using --%s. CSV files must have a header
+row and vectors are written as a JSON list e.g. "[0.1,0.2]". Records in
+.npy, fvecs, and bvecs files are keyed by their row number starting at 0.
+
+Failed writes are retried up to --%s times. Records which still fail are
+written to --%s.
+
+For example:
+
+%s
+asvec data import -n test -i my-index --file vectors.jsonl
+
+asvec data import -n test -s testset -i my-index --file sift_base.fvecs \
+	--%s 32
+
+cat vectors.csv | asvec data import -n test -i my-index --%s csv \
+	--%s id
+			`, flags.IndexName, flags.KeyField, flags.Retries, flags.ErrorFile,
+			HelpTxtSetupEnv, flags.Parallelism, flags.FileFormat, flags.KeyField),
+		PreRunE: func(_ *cobra.Command, _ []string) error {
+			if dataImportFlags.parallelism < 1 {
+				return fmt.Errorf("--%s must be at least 1", flags.Parallelism)
+			}
+
+			if dataImportFlags.retries < 0 {
+				return fmt.Errorf("--%s must not be negative", flags.Retries)
+			}
+
+			return checkSeedsAndHost()
+		},
+		RunE: func(_ *cobra.Command, _ []string) error {
+			logger.Debug("parsed flags",
+				append(
+					dataImportFlags.clientFlags.NewSLogAttr(),
+					slog.String(flags.Namespace, dataImportFlags.namespace),
+					slog.Any(flags.Set, dataImportFlags.set.Val),
+					slog.String(flags.IndexName, dataImportFlags.indexName),
+					slog.String(flags.InputFile, dataImportFlags.inputFile),
+					slog.String(flags.FileFormat, dataImportFlags.fileFormat.String()),
+					slog.String(flags.KeyField, dataImportFlags.keyField),
+					slog.String(flags.WriteType, dataImportFlags.writeType.String()),
+					slog.Bool(flags.IgnoreMemQueueFull, dataImportFlags.ignoreMemQueueFull),
+					slog.Int(flags.Parallelism, dataImportFlags.parallelism),
+					slog.Int(flags.Retries, dataImportFlags.retries),
+					slog.String(flags.ErrorFile, dataImportFlags.errorFile),
+				)...,
+			)
+
+			format, err := importFileFormat(dataImportFlags.inputFile, &dataImportFlags.fileFormat)
+			if err != nil {
+				logger.Error("unable to determine file format", slog.Any("error", err))
+				return err
+			}
+
+			input, size, err := openImportFile(dataImportFlags.inputFile)
+			if err != nil {
+				logger.Error("unable to open input file", slog.Any("error", err))
+				return err
+			}
+			defer input.Close()
+
+			client, err := createClientFromFlags(dataImportFlags.clientFlags)
+			if err != nil {
+				return err
+			}
+			defer client.Close()
+
+			ctx, cancel := context.WithTimeout(context.Background(), dataImportFlags.clientFlags.Timeout)
+			defer cancel()
+
+			indexDef, err := client.IndexGet(ctx, dataImportFlags.namespace, dataImportFlags.indexName, false)
+			if err != nil {
+				logger.Error("unable to get index definition", slog.Any("error", err))
+				return err
+			}
+
+			set := dataImportFlags.set.Val
+			if set == nil {
+				set = indexDef.SetFilter
+			}
+
+			counter := &countingReader{reader: input}
+
+			reader, err := records.NewReader(counter, format, records.ReaderOptions{
+				KeyField:    dataImportFlags.keyField,
+				VectorField: indexDef.Field,
+			})
+			if err != nil {
+				logger.Error("unable to read input file", slog.Any("error", err))
+				return err
+			}
+
+			tracker, stopProgress := newProgressTracker("Importing records", size, progress.UnitsBytes)
+
+			importer := &recordImporter{
+				client:             client,
+				namespace:          dataImportFlags.namespace,
+				set:                set,
+				vectorField:        indexDef.Field,
+				dimensions:         int(indexDef.Dimensions),
+				writeType:          dataImportFlags.writeType.WriteType(),
+				ignoreMemQueueFull: dataImportFlags.ignoreMemQueueFull,
+				retries:            dataImportFlags.retries,
+				timeout:            dataImportFlags.clientFlags.Timeout,
+				errorFile:          dataImportFlags.errorFile,
+			}
+
+			succeeded, failed, err := importer.run(reader, dataImportFlags.parallelism, func() {
+				tracker.SetValue(counter.count.Load())
+			})
+
+			stopProgress()
+
+			if err != nil {
+				logger.Error("import stopped early", slog.Any("error", err))
+
+				msg := fmt.Sprintf(
+					"Import stopped early after importing %d records into %s",
+					succeeded, nsAndSetString(dataImportFlags.namespace, set),
+				)
+
+				if failed > 0 {
+					msg += fmt.Sprintf(", %d records failed, see %s for details", failed, dataImportFlags.errorFile)
+				}
+
+				view.Warningf("%s", msg)
+
+				return err
+			}
+
+			view.Printf(
+				"Successfully imported %d records into %s",
+				succeeded, nsAndSetString(dataImportFlags.namespace, set),
+			)
+
+			if failed > 0 {
+				view.Warningf("Failed to import %d records, see %s for details", failed, dataImportFlags.errorFile)
+			}
+
+			return nil
+		},
+	}
+}
+
+// importFileFormat returns the format set by the flag, otherwise the format
+// inferred from the file extension. Stdin defaults to JSONL.
+func importFileFormat(file string, formatFlag *flags.FileFormatFlag) (records.Format, error) {
+	if formatFlag.IsSet() {
+		return formatFlag.Format(), nil
+	}
+
+	if file == StdIn {
+		return records.FormatJSONL, nil
+	}
+
+	format, err := records.FormatFromFilename(file)
+	if err != nil {
+		return "", fmt.Errorf("%w, use --%s to provide the format", err, flags.FileFormat)
+	}
+
+	return format, nil
+}
+
+// openImportFile opens the file to import along with its size, if known.
+func openImportFile(file string) (io.ReadCloser, int64, error) {
+	if file == StdIn {
+		stat, _ := os.Stdin.Stat()
+		if (stat.Mode() & os.ModeCharDevice) != 0 {
+			return nil, 0, fmt.Errorf("no data is being piped from stdin, use --%s to provide a file", flags.InputFile)
+		}
+
+		return io.NopCloser(os.Stdin), 0, nil
+	}
+
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	stat, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, 0, err
+	}
+
+	return f, stat.Size(), nil
+}
+
+// recordImporter writes records read from a file using a pool of workers.
+//
+//nolint:govet // Padding not a concern for a CLI
+type recordImporter struct {
+	client             *avs.Client
+	namespace          string
+	set                *string
+	vectorField        string
+	dimensions         int
+	writeType          protos.WriteType
+	ignoreMemQueueFull bool
+	retries            int
+	timeout            time.Duration
+	errorFile          string
+}
+
+// run reads all records and writes them using parallelism workers. onResult
+// is called after each record is processed. It returns the number of
+// records which were and were not written. An error is returned if the
+// records could not be read or the failures could not be written to the
+// error file, in which case the remaining records are not imported.
+func (i *recordImporter) run(reader records.Reader, parallelism int, onResult func()) (succeeded, failed int, err error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	recordCh := make(chan *records.Record, parallelism)
+	resultCh := make(chan *importResult, parallelism)
+
+	var wg sync.WaitGroup
+
+	for w := 0; w < parallelism; w++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for record := range recordCh {
+				resultCh <- i.importRecord(ctx, record)
+			}
+		}()
+	}
+
+	var readErr error
+
+	go func() {
+		defer func() {
+			close(recordCh)
+			wg.Wait()
+			close(resultCh)
+		}()
+
+		for {
+			record, err := reader.Read()
+			if err != nil {
+				if errors.Is(err, io.EOF) {
+					return
+				}
+
+				var recordErr *records.RecordError
+				if errors.As(err, &recordErr) {
+					select {
+					case resultCh <- &importResult{Line: recordErr.Line, Error: recordErr.Err.Error()}:
+					case <-ctx.Done():
+						return
+					}
+
+					continue
+				}
+
+				readErr = err
+
+				return
+			}
+
+			select {
+			case recordCh <- record:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	// stop cancels the import and waits for the workers to finish so none is
+	// left blocked on resultCh.
+	stop := func() {
+		cancel()
+
+		for range resultCh {
+			// Discard the results of records which were already being written.
+		}
+	}
+
+	var errorWriter *json.Encoder
+
+	for result := range resultCh {
+		onResult()
+
+		if result == nil {
+			succeeded++
+			continue
+		}
+
+		failed++
+
+		logger.Debug("failed to import record", slog.Any("key", result.Key), slog.String("error", result.Error))
+
+		if errorWriter == nil {
+			f, err := os.Create(i.errorFile)
+			if err != nil {
+				logger.Error("unable to create error file", slog.Any("error", err))
+				stop()
+
+				return succeeded, failed, err
+			}
+			defer f.Close()
+
+			errorWriter = json.NewEncoder(f)
+		}
+
+		if err := errorWriter.Encode(result); err != nil {
+			logger.Error("unable to write to error file", slog.Any("error", err))
+			stop()
+
+			return succeeded, failed, err
+		}
+	}
+
+	return succeeded, failed, readErr
+}
+
+// importRecord validates and writes a single record, retrying writes which
+// failed with one of importRetryCodes. A nil result means the record was
+// written successfully.
+func (i *recordImporter) importRecord(ctx context.Context, record *records.Record) *importResult {
+	vector, ok := record.Data[i.vectorField]
+	if !ok {
+		return &importResult{Key: record.Key, Error: fmt.Sprintf("missing vector field %s", i.vectorField)}
+	}
+
+	dims, ok := records.VectorLen(vector)
+	if !ok {
+		return &importResult{Key: record.Key, Error: fmt.Sprintf("field %s is not a vector", i.vectorField)}
+	}
+
+	if dims != i.dimensions {
+		return &importResult{
+			Key:   record.Key,
+			Error: fmt.Sprintf("vector has %d dimensions but the index expects %d", dims, i.dimensions),
+		}
+	}
+
+	var err error
+
+	for attempt := 0; attempt <= i.retries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-time.After(importRetryBackoff * time.Duration(1<<(attempt-1))):
+			case <-ctx.Done():
+				return &importResult{Key: record.Key, Error: err.Error()}
+			}
+		}
+
+		writeCtx, cancel := context.WithTimeout(ctx, i.timeout)
+		err = writeRecord(
+			writeCtx,
+			i.client,
+			i.writeType,
+			i.namespace,
+			i.set,
+			record.Key,
+			record.Data,
+			i.ignoreMemQueueFull,
+		)
+
+		cancel()
+
+		if err == nil {
+			return nil
+		}
+
+		logger.Debug("failed to write record", slog.Any("key", record.Key), slog.Int("attempt", attempt), slog.Any("error", err))
+
+		if !slices.Contains(importRetryCodes, grpcCode(err)) {
+			break
+		}
+	}
+
+	return &importResult{Key: record.Key, Error: err.Error()}
+}
+
+func init() {
+	dataImportCmd := newDataImportCmd()
+	dataCmd.AddCommand(dataImportCmd)
+	dataImportCmd.Flags().AddFlagSet(newDataImportFlagSet())
+
+	for _, flag := range dataImportRequiredFlags {
+		err := dataImportCmd.MarkFlagRequired(flag)
+		if err != nil {
+			panic(err)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+//go:build unit
+
+package cmd
+
+import (
+	"asvec/cmd/records"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordImporterRunErrorFileFailure(t *testing.T) {
+	// Every record is missing its vector so fails before reaching the client.
+	var input strings.Builder
+	for i := 0; i < 100; i++ {
+		input.WriteString(`{"id": 1, "name": "no vector"}` + "\n")
+	}
+
+	reader, err := records.NewReader(strings.NewReader(input.String()), records.FormatJSONL, records.ReaderOptions{
+		KeyField:    "id",
+		VectorField: "vector",
+	})
+	require.NoError(t, err)
+
+	importer := &recordImporter{
+		vectorField: "vector",
+		dimensions:  3,
+		errorFile:   filepath.Join(t.TempDir(), "missing-dir", "errors.jsonl"),
+	}
+
+	done := make(chan error)
+
+	go func() {
+		_, failed, err := importer.run(reader, 2, func() {})
+		assert.Equal(t, 1, failed)
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		assert.ErrorContains(t, err, "no such file or directory")
+	case <-time.After(5 * time.Second):
+		t.Fatal("run did not return after failing to create the error file")
+	}
+}
@@ -0,0 +1,84 @@
+package embed
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// ModelEnv is the environment variable holding the model name when running
+// an embedding command.
+const ModelEnv = "ASVEC_EMBEDDING_MODEL"
+
+// CommandProvider embeds text by running a command with the text on stdin.
+// The command prints either a JSON array of numbers or an OpenAI embeddings
+// response.
+type CommandProvider struct {
+	args  []string
+	model string
+}
+
+// NewCommandProvider returns a provider running command, which is split into
+// arguments on whitespace. Commands which need quoting should be wrapped in a
+// script.
+func NewCommandProvider(command, model string) (*CommandProvider, error) {
+	args := strings.Fields(command)
+	if len(args) == 0 {
+		return nil, fmt.Errorf("embedding command is empty")
+	}
+
+	return &CommandProvider{args: args, model: model}, nil
+}
+
+func (p *CommandProvider) Name() string {
+	return strings.Join(p.args, " ")
+}
+
+func (p *CommandProvider) Embed(ctx context.Context, text string) ([]float32, error) {
+	//nolint:gosec // The command is provided by the user to run
+	cmd := exec.CommandContext(ctx, p.args[0], p.args[1:]...)
+	cmd.Env = append(os.Environ(), ModelEnv+"="+p.model)
+	cmd.Stdin = strings.NewReader(text)
+
+	stderr := &bytes.Buffer{}
+	cmd.Stderr = stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("embedding command failed: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	return parseEmbedding(out)
+}
+
+// parseEmbedding parses a JSON array of numbers or an OpenAI embeddings
+// response.
+func parseEmbedding(out []byte) ([]float32, error) {
+	out = bytes.TrimSpace(out)
+
+	if bytes.HasPrefix(out, []byte("[")) {
+		var embedding []float32
+
+		if err := json.Unmarshal(out, &embedding); err != nil {
+			return nil, fmt.Errorf("unable to parse embedding: %w", err)
+		}
+
+		if len(embedding) == 0 {
+			return nil, fmt.Errorf("embedding is empty")
+		}
+
+		return embedding, nil
+	}
+
+	var r embeddingResponse
+
+	if err := json.Unmarshal(out, &r); err != nil {
+		return nil, fmt.Errorf("unable to parse embedding, expected a JSON array of numbers: %w", err)
+	}
+
+	return r.embedding()
+}
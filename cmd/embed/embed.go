@@ -0,0 +1,76 @@
+// Package embed turns text into query vectors with the embedding model an
+// index was built with. The model is read from the index's "model" label,
+// e.g. "asvec index create --index-labels model=all-MiniLM-L6-v2", and the
+// text is embedded by a Provider: either an OpenAI compatible HTTP endpoint,
+// such as a local Ollama, vLLM, or text-embeddings-inference server, or an
+// external command.
+package embed
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ModelLabel is the index label naming the model used to embed its vectors.
+const ModelLabel = "model"
+
+var (
+	// ErrNoModel is returned by Resolve when no model is configured and the
+	// index has no model label.
+	ErrNoModel = errors.New("the index has no \"" + ModelLabel + "\" label")
+	// ErrNoProvider is returned by Resolve when neither a URL nor a command is
+	// configured.
+	ErrNoProvider = errors.New("no embedding provider is configured")
+)
+
+// Provider embeds text with a model.
+type Provider interface {
+	// Embed returns the embedding of text.
+	Embed(ctx context.Context, text string) ([]float32, error)
+	// Name describes the provider in messages, e.g. the URL of an endpoint.
+	Name() string
+}
+
+// Config selects and configures a Provider. Exactly one of URL or Command
+// must be set.
+type Config struct {
+	// Model overrides the model label of the index.
+	Model string
+	// URL is the base URL of an OpenAI compatible endpoint, e.g.
+	// http://localhost:11434/v1. /embeddings is appended if missing.
+	URL string
+	// APIKey is sent to URL as a bearer token if set.
+	APIKey string
+	// Command is run with the text on stdin and prints the embedding.
+	Command string
+}
+
+// Resolve returns the model of an index with labels and a provider for it.
+// The model is cfg.Model if set, otherwise the index's model label.
+func Resolve(labels map[string]string, cfg *Config) (Provider, string, error) {
+	model := cfg.Model
+	if model == "" {
+		model = labels[ModelLabel]
+	}
+
+	if model == "" {
+		return nil, "", ErrNoModel
+	}
+
+	switch {
+	case cfg.URL != "" && cfg.Command != "":
+		return nil, "", fmt.Errorf("only an embedding URL or an embedding command can be set")
+	case cfg.URL != "":
+		return NewHTTPProvider(cfg.URL, model, cfg.APIKey), model, nil
+	case cfg.Command != "":
+		p, err := NewCommandProvider(cfg.Command, model)
+		if err != nil {
+			return nil, "", err
+		}
+
+		return p, model, nil
+	default:
+		return nil, "", fmt.Errorf("%w for model %s", ErrNoProvider, model)
+	}
+}
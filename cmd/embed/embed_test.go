@@ -0,0 +1,131 @@
+//go:build unit
+
+package embed
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newStubServer returns an OpenAI compatible embeddings endpoint which embeds
+// every text as embedding and records the last request.
+func newStubServer(t *testing.T, embedding []float32) (*httptest.Server, *embeddingRequest, *http.Header) {
+	t.Helper()
+
+	var (
+		lastReq    embeddingRequest
+		lastHeader http.Header
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/embeddings" {
+			http.Error(w, `{"error":{"message":"not found"}}`, http.StatusNotFound)
+			return
+		}
+
+		lastHeader = r.Header.Clone()
+
+		if err := json.NewDecoder(r.Body).Decode(&lastReq); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		resp := map[string]any{
+			"object": "list",
+			"model":  lastReq.Model,
+			"data":   []map[string]any{{"object": "embedding", "index": 0, "embedding": embedding}},
+		}
+
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+
+	t.Cleanup(server.Close)
+
+	return server, &lastReq, &lastHeader
+}
+
+func TestHTTPProvider(t *testing.T) {
+	server, lastReq, lastHeader := newStubServer(t, []float32{0.5, 0.25, 1})
+
+	for _, url := range []string{server.URL + "/v1", server.URL + "/v1/", server.URL + "/v1/embeddings"} {
+		p := NewHTTPProvider(url, "all-MiniLM-L6-v2", "secret")
+		assert.Equal(t, server.URL+"/v1/embeddings", p.Name())
+
+		v, err := p.Embed(context.Background(), "red shoes")
+		require.NoError(t, err)
+		assert.Equal(t, []float32{0.5, 0.25, 1}, v)
+		assert.Equal(t, embeddingRequest{Model: "all-MiniLM-L6-v2", Input: "red shoes"}, *lastReq)
+		assert.Equal(t, "Bearer secret", lastHeader.Get("Authorization"))
+	}
+
+	_, err := NewHTTPProvider(server.URL, "m", "").Embed(context.Background(), "red shoes")
+	assert.ErrorContains(t, err, "404 Not Found: {\"error\":{\"message\":\"not found\"}}")
+}
+
+func TestParseEmbedding(t *testing.T) {
+	v, err := parseEmbedding([]byte(" [1, 2.5, -3]\n"))
+	require.NoError(t, err)
+	assert.Equal(t, []float32{1, 2.5, -3}, v)
+
+	v, err = parseEmbedding([]byte(`{"data": [{"embedding": [0.5]}]}`))
+	require.NoError(t, err)
+	assert.Equal(t, []float32{0.5}, v)
+
+	_, err = parseEmbedding([]byte(`{"error": {"message": "model not loaded"}}`))
+	assert.EqualError(t, err, "model not loaded")
+
+	_, err = parseEmbedding([]byte(`[]`))
+	assert.EqualError(t, err, "embedding is empty")
+
+	_, err = parseEmbedding([]byte(`0.5 0.25`))
+	assert.ErrorContains(t, err, "expected a JSON array of numbers")
+}
+
+func TestCommandProvider(t *testing.T) {
+	p, err := NewCommandProvider("echo [0.5,1]", "m")
+	require.NoError(t, err)
+	assert.Equal(t, "echo [0.5,1]", p.Name())
+
+	v, err := p.Embed(context.Background(), "red shoes")
+	require.NoError(t, err)
+	assert.Equal(t, []float32{0.5, 1}, v)
+
+	p, err = NewCommandProvider("false", "m")
+	require.NoError(t, err)
+
+	_, err = p.Embed(context.Background(), "red shoes")
+	assert.ErrorContains(t, err, "embedding command failed")
+
+	_, err = NewCommandProvider(" ", "m")
+	assert.EqualError(t, err, "embedding command is empty")
+}
+
+func TestResolve(t *testing.T) {
+	labels := map[string]string{ModelLabel: "all-MiniLM-L6-v2"}
+
+	p, model, err := Resolve(labels, &Config{URL: "http://localhost:11434/v1"})
+	require.NoError(t, err)
+	assert.Equal(t, "all-MiniLM-L6-v2", model)
+	assert.IsType(t, &HTTPProvider{}, p)
+
+	p, model, err = Resolve(labels, &Config{Command: "embed.sh", Model: "other"})
+	require.NoError(t, err)
+	assert.Equal(t, "other", model)
+	assert.IsType(t, &CommandProvider{}, p)
+
+	_, _, err = Resolve(nil, &Config{URL: "http://localhost:11434/v1"})
+	assert.ErrorIs(t, err, ErrNoModel)
+
+	_, _, err = Resolve(labels, &Config{})
+	assert.ErrorIs(t, err, ErrNoProvider)
+	assert.ErrorContains(t, err, "for model all-MiniLM-L6-v2")
+
+	_, _, err = Resolve(labels, &Config{URL: "http://localhost:11434/v1", Command: "embed.sh"})
+	assert.Error(t, err)
+}
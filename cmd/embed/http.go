@@ -0,0 +1,107 @@
+package embed
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// maxErrorBody is how much of an error response is included in errors.
+const maxErrorBody = 512
+
+// HTTPProvider embeds text with an OpenAI compatible /embeddings endpoint.
+type HTTPProvider struct {
+	client *http.Client
+	url    string
+	model  string
+	apiKey string
+}
+
+// NewHTTPProvider returns a provider posting to the /embeddings endpoint of
+// baseURL.
+func NewHTTPProvider(baseURL, model, apiKey string) *HTTPProvider {
+	url := strings.TrimSuffix(baseURL, "/")
+	if !strings.HasSuffix(url, "/embeddings") {
+		url += "/embeddings"
+	}
+
+	return &HTTPProvider{
+		client: http.DefaultClient,
+		url:    url,
+		model:  model,
+		apiKey: apiKey,
+	}
+}
+
+func (p *HTTPProvider) Name() string {
+	return p.url
+}
+
+type embeddingRequest struct {
+	Model string `json:"model"`
+	Input string `json:"input"`
+}
+
+// embeddingResponse is the subset of an OpenAI embeddings response asvec
+// reads. Commands may also print one.
+type embeddingResponse struct {
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+	Data []struct {
+		Embedding []float32 `json:"embedding"`
+	} `json:"data"`
+}
+
+func (r *embeddingResponse) embedding() ([]float32, error) {
+	if r.Error != nil {
+		return nil, fmt.Errorf("%s", r.Error.Message)
+	}
+
+	if len(r.Data) == 0 || len(r.Data[0].Embedding) == 0 {
+		return nil, fmt.Errorf("response does not contain an embedding")
+	}
+
+	return r.Data[0].Embedding, nil
+}
+
+func (p *HTTPProvider) Embed(ctx context.Context, text string) ([]float32, error) {
+	body, err := json.Marshal(&embeddingRequest{Model: p.model, Input: text})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	if p.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+p.apiKey)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("unable to request embedding: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		b, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		return nil, fmt.Errorf("%s returned %s: %s", p.url, resp.Status, strings.TrimSpace(string(b)))
+	}
+
+	var r embeddingResponse
+
+	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		return nil, fmt.Errorf("unable to decode embedding response: %w", err)
+	}
+
+	return r.embedding()
+}
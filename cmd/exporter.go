@@ -0,0 +1,330 @@
+package cmd
+
+import (
+	"asvec/cmd/flags"
+	"asvec/cmd/writers"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"slices"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	avs "github.com/aerospike/avs-client-go"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+const (
+	defaultExporterListen   = ":9145"
+	defaultExporterInterval = 15 * time.Second
+
+	exporterMetricsPath = "/metrics"
+)
+
+//nolint:govet // Padding not a concern for a CLI
+var exporterFlags = &struct {
+	clientFlags *flags.ClientFlags
+	listen      string
+	interval    time.Duration
+}{
+	clientFlags: rootFlags.clientFlags,
+}
+
+func newExporterFlagSet() *pflag.FlagSet {
+	flagSet := &pflag.FlagSet{}
+	flagSet.StringVar(&exporterFlags.listen, flags.Listen, defaultExporterListen, "The address to serve metrics on.")                       //nolint:lll // For readability
+	flagSet.DurationVar(&exporterFlags.interval, flags.Interval, defaultExporterInterval, "How often to collect metrics from the cluster.") //nolint:lll // For readability
+
+	return flagSet
+}
+
+func newExporterCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "exporter",
+		Short: "A command for serving cluster and index metrics to Prometheus",
+		Long: fmt.Sprintf(`A command for serving AVS cluster and index metrics in the Prometheus text
+format. Every --%s, the exporter collects the information displayed by
+"asvec node ls" and "asvec index ls" using a single connection and serves it
+at %s:
+
+  Nodes: roles, versions, cluster IDs, cluster membership, and which nodes
+    each node can see.
+  Indexes: status, unmerged records, vector records, vertices, estimated
+    size, and standalone build metrics.
+
+asvec_up is 0 and no other metrics are served if the last collection failed.
+
+For example:
+
+%s
+asvec exporter --%s :9145 --%s 30s
+			`, flags.Interval, exporterMetricsPath, HelpTxtSetupEnv, flags.Listen, flags.Interval),
+		PreRunE: func(_ *cobra.Command, _ []string) error {
+			if exporterFlags.interval <= 0 {
+				return fmt.Errorf("--%s must be greater than 0", flags.Interval)
+			}
+
+			return checkSeedsAndHost()
+		},
+		RunE: func(_ *cobra.Command, _ []string) error {
+			logger.Debug("parsed flags",
+				append(exporterFlags.clientFlags.NewSLogAttr(),
+					slog.String(flags.Listen, exporterFlags.listen),
+					slog.Duration(flags.Interval, exporterFlags.interval),
+				)...,
+			)
+
+			listener, err := net.Listen("tcp", exporterFlags.listen)
+			if err != nil {
+				logger.Error("failed to listen", slog.Any("error", err))
+				return err
+			}
+
+			// Share one client between collections and reconnect if the
+			// connection is lost
+			clients = newClientCache()
+
+			defer func() {
+				clients.Close()
+				clients = nil
+			}()
+
+			ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer cancel()
+
+			exp := &exporter{}
+			exp.collect()
+
+			mux := http.NewServeMux()
+			mux.Handle(exporterMetricsPath, exp)
+
+			server := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+
+			serveErr := make(chan error, 1)
+
+			go func() {
+				serveErr <- server.Serve(listener)
+			}()
+
+			view.Printf("Serving metrics at http://%s%s", listener.Addr(), exporterMetricsPath)
+
+			ticker := time.NewTicker(exporterFlags.interval)
+			defer ticker.Stop()
+
+			for {
+				select {
+				case <-ctx.Done():
+					logger.Debug("exporter terminated by user")
+
+					shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), exporterFlags.clientFlags.Timeout)
+					defer shutdownCancel()
+
+					return server.Shutdown(shutdownCtx)
+				case err := <-serveErr:
+					if !errors.Is(err, http.ErrServerClosed) {
+						logger.Error("failed to serve metrics", slog.Any("error", err))
+					}
+
+					return err
+				case <-ticker.C:
+					exp.collect()
+				}
+			}
+		},
+	}
+}
+
+// exporter serves the metrics from the most recent collection.
+type exporter struct {
+	mu      sync.RWMutex
+	metrics []byte
+}
+
+// collect gathers metrics from the cluster and replaces the served metrics.
+func (e *exporter) collect() {
+	start := time.Now()
+
+	w, err := collectExporterMetrics()
+	if err != nil {
+		// The failure is reported through asvec_up rather than the exit code
+		// as the exporter keeps serving. Stale metrics are not served.
+		logger.Error("failed to collect metrics", slog.Any("error", err))
+		view.PrintfErr("Failed to collect metrics: %s", err)
+
+		w = writers.NewPrometheusWriter()
+	}
+
+	w.Gauge("asvec_up", "Whether the last collection from the cluster succeeded.", writers.Bool(err == nil))
+	w.Gauge("asvec_collect_duration_seconds", "How long the last collection took.", time.Since(start).Seconds())
+	w.Gauge("asvec_last_collect_timestamp_seconds", "When the last collection finished.", float64(time.Now().Unix()))
+
+	var buf bytes.Buffer
+
+	_, _ = w.WriteTo(&buf)
+
+	e.mu.Lock()
+	e.metrics = buf.Bytes()
+	e.mu.Unlock()
+}
+
+func (e *exporter) ServeHTTP(rw http.ResponseWriter, _ *http.Request) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	rw.Header().Set("Content-Type", writers.PrometheusContentType)
+
+	if _, err := rw.Write(e.metrics); err != nil {
+		logger.Debug("failed to write metrics response", slog.Any("error", err))
+	}
+}
+
+// collectExporterMetrics collects metrics using the cached client. If the
+// connection was lost, the client reconnects on the next collection.
+func collectExporterMetrics() (*writers.PrometheusWriter, error) {
+	client, releaseClient, err := acquireClient(exporterFlags.clientFlags)
+	if err != nil {
+		return nil, err
+	}
+	defer releaseClient()
+
+	w, err := collectMetrics(client, exporterFlags.clientFlags)
+	if err != nil && clients != nil {
+		clients.lostConnection(exporterFlags.clientFlags.Timeout, err)
+	}
+
+	return w, err
+}
+
+// collectMetrics returns the node and index information reported by
+// "node ls" and "index ls" as Prometheus metrics.
+func collectMetrics(client *avs.Client, clientFlags *flags.ClientFlags) (*writers.PrometheusWriter, error) {
+	w := writers.NewPrometheusWriter()
+	timeout := clientFlags.Timeout
+	isLB := isLoadBalancer(clientFlags.Seeds)
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	writeNodeMetrics(w, getAllNodesInfo(ctx, client, clientFlags.ListenerName.Val), isLB)
+
+	indexList, indexStatusList, err := listIndexesWithStatus(client, timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, index := range indexList.GetIndices() {
+		labels := []string{"namespace", index.GetId().GetNamespace(), "index", index.GetId().GetName()}
+		withLabels := func(extra ...string) []string {
+			return append(slices.Clone(labels), extra...)
+		}
+
+		w.Gauge("asvec_index_info", "Information about an index. Always 1.", 1,
+			withLabels(
+				"set", index.GetSetFilter(),
+				"field", index.GetField(),
+				"mode", index.GetMode().String(),
+				"distance_metric", index.GetVectorDistanceMetric().String(),
+				"dimensions", strconv.FormatUint(uint64(index.GetDimensions()), 10),
+			)...,
+		)
+
+		status := indexStatusList[i]
+		if status == nil {
+			continue
+		}
+
+		w.Gauge("asvec_index_status", "The status of an index. Always 1.", 1,
+			withLabels("status", status.GetStatus().String())...)
+		w.Gauge("asvec_index_unmerged_records", "Records which have not been merged into the index.",
+			float64(status.GetUnmergedRecordCount()), labels...)
+		w.Gauge("asvec_index_vector_records", "Vector records indexed by the healer.",
+			float64(status.GetIndexHealerVectorRecordsIndexed()), labels...)
+		w.Gauge("asvec_index_vertices", "Valid vertices in the index.",
+			float64(status.GetIndexHealerVerticesValid()), labels...)
+		w.Gauge("asvec_index_size_bytes", "The approximate size of the index.",
+			float64(writers.CalculateIndexSize(index, status)), labels...)
+
+		if metrics := status.GetStandaloneIndexMetrics(); metrics != nil {
+			w.Gauge("asvec_standalone_index_state", "The state of a standalone index build. Always 1.", 1,
+				withLabels("state", metrics.GetState().String())...)
+			w.Gauge("asvec_standalone_index_scanned_records", "Vector records scanned by a standalone index build.",
+				float64(metrics.GetScannedVectorRecordCount()), labels...)
+			w.Gauge("asvec_standalone_index_indexed_records", "Vector records indexed by a standalone index build.",
+				float64(metrics.GetIndexedVectorRecordCount()), labels...)
+		}
+	}
+
+	return w, nil
+}
+
+// writeNodeMetrics writes the metrics of each node. A node without an ID, a
+// seed or a load balancer, is labelled with the endpoint it was reached at so
+// that several seeds don't report the same series.
+func writeNodeMetrics(w *writers.PrometheusWriter, nodes []*writers.NodeInfo, isLB bool) {
+	for _, node := range nodes {
+		endpoint := ""
+		if e := node.ConnectedEndpoint; e != nil {
+			endpoint = net.JoinHostPort(e.GetAddress(), strconv.FormatUint(uint64(e.GetPort()), 10))
+		}
+
+		id := node.NodeID.GetId()
+		nodeID := strconv.FormatUint(id, 10)
+
+		if id == 0 {
+			switch {
+			case endpoint != "":
+				nodeID = endpoint
+			case isLB:
+				nodeID = "LB"
+			default:
+				nodeID = "Seed"
+			}
+		}
+
+		w.Gauge("asvec_node_info", "Information about a node. Always 1.", 1,
+			"node_id", nodeID,
+			"endpoint", endpoint,
+			"cluster_id", strconv.FormatUint(node.State.GetClusterId().GetId(), 10),
+			"version", node.About.GetVersion(),
+		)
+
+		for _, role := range node.About.GetRoles() {
+			w.Gauge("asvec_node_role", "A role of a node. Always 1.", 1, "node_id", nodeID, "role", role.String())
+		}
+
+		if node.State != nil {
+			w.Gauge("asvec_node_in_cluster", "Whether a node is part of the cluster.",
+				writers.Bool(node.State.GetIsInCluster()), "node_id", nodeID)
+			w.Gauge("asvec_node_cluster_members", "The number of cluster members reported by a node.",
+				float64(len(node.State.GetMembers())), "node_id", nodeID)
+		}
+
+		if node.Endpoints != nil {
+			peers := node.Endpoints.GetEndpoints()
+
+			w.Gauge("asvec_node_visible_nodes", "The number of nodes a node can see.",
+				float64(len(peers)), "node_id", nodeID)
+
+			for _, peerID := range slices.Sorted(maps.Keys(peers)) {
+				w.Gauge("asvec_node_visible", "Whether a node can see a peer. Always 1.", 1,
+					"node_id", nodeID, "peer_id", strconv.FormatUint(peerID, 10))
+			}
+		}
+	}
+}
+
+func init() {
+	exporterCmd := newExporterCmd()
+	rootCmd.AddCommand(exporterCmd)
+	exporterCmd.Flags().AddFlagSet(newExporterFlagSet())
+}
@@ -0,0 +1,221 @@
+//go:build unit
+
+package cmd
+
+import (
+	"asvec/cmd/flags"
+	"asvec/cmd/writers"
+	"context"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	avs "github.com/aerospike/avs-client-go"
+	"github.com/aerospike/avs-client-go/protos"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+type fakeAboutServer struct {
+	protos.UnimplementedAboutServiceServer
+}
+
+func (fakeAboutServer) Get(context.Context, *protos.AboutRequest) (*protos.AboutResponse, error) {
+	return &protos.AboutResponse{Version: "1.0.0", Roles: []protos.NodeRole{protos.NodeRole_INDEX_QUERY}}, nil
+}
+
+type fakeClusterInfoServer struct {
+	protos.UnimplementedClusterInfoServiceServer
+}
+
+func (fakeClusterInfoServer) GetClusteringState(context.Context, *emptypb.Empty) (*protos.ClusteringState, error) {
+	return &protos.ClusteringState{
+		IsInCluster: true,
+		ClusterId:   &protos.ClusterId{Id: 42},
+		Members:     []*protos.NodeId{{Id: 1}, {Id: 2}},
+	}, nil
+}
+
+func (fakeClusterInfoServer) GetClusterEndpoints(
+	context.Context, *protos.ClusterNodeEndpointsRequest,
+) (*protos.ClusterNodeEndpoints, error) {
+	return &protos.ClusterNodeEndpoints{
+		Endpoints: map[uint64]*protos.ServerEndpointList{2: {}, 1: {}},
+	}, nil
+}
+
+type fakeIndexServer struct {
+	protos.UnimplementedIndexServiceServer
+}
+
+func (fakeIndexServer) List(context.Context, *protos.IndexListRequest) (*protos.IndexDefinitionList, error) {
+	mode := protos.IndexMode_DISTRIBUTED
+	metric := protos.VectorDistanceMetric_COSINE
+	m := uint32(16)
+
+	return &protos.IndexDefinitionList{Indices: []*protos.IndexDefinition{{
+		Id:                   &protos.IndexId{Namespace: "test", Name: "myindex"},
+		Field:                "vec",
+		Dimensions:           3,
+		VectorDistanceMetric: &metric,
+		Mode:                 &mode,
+		Params:               &protos.IndexDefinition_HnswParams{HnswParams: &protos.HnswParams{M: &m}},
+	}}}, nil
+}
+
+func (fakeIndexServer) GetStatus(context.Context, *protos.IndexStatusRequest) (*protos.IndexStatusResponse, error) {
+	return &protos.IndexStatusResponse{
+		Status:                          protos.Status_READY,
+		UnmergedRecordCount:             7,
+		IndexHealerVectorRecordsIndexed: 100,
+		IndexHealerVerticesValid:        100,
+	}, nil
+}
+
+// newFakeAVSServer starts a fake AVS server with the services used by the
+// exporter and returns its address.
+func newFakeAVSServer(t *testing.T) *net.TCPAddr {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	server := grpc.NewServer()
+	protos.RegisterAboutServiceServer(server, fakeAboutServer{})
+	protos.RegisterClusterInfoServiceServer(server, fakeClusterInfoServer{})
+	protos.RegisterIndexServiceServer(server, fakeIndexServer{})
+
+	go func() {
+		_ = server.Serve(listener)
+	}()
+
+	t.Cleanup(server.Stop)
+
+	return listener.Addr().(*net.TCPAddr)
+}
+
+// newFakeAVSClient starts a fake AVS server and returns a client connected to
+// it as a load balancer.
+func newFakeAVSClient(t *testing.T) *avs.Client {
+	t.Helper()
+
+	client, _ := newFakeAVSClientAddr(t)
+
+	return client
+}
+
+// newFakeAVSClientAddr is newFakeAVSClient which also returns the address of
+// the fake server.
+func newFakeAVSClientAddr(t *testing.T) (*avs.Client, *net.TCPAddr) {
+	t.Helper()
+
+	addr := newFakeAVSServer(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	client, err := avs.NewClient(
+		ctx, avs.HostPortSlice{avs.NewHostPort(addr.IP.String(), addr.Port)}, nil, true, nil, nil, slog.Default(),
+	)
+	require.NoError(t, err)
+
+	t.Cleanup(func() { client.Close() })
+
+	return client, addr
+}
+
+func TestCollectMetrics(t *testing.T) {
+	client, addr := newFakeAVSClientAddr(t)
+
+	clientFlags := flags.NewClientFlags()
+	clientFlags.Timeout = 5 * time.Second
+
+	w, err := collectMetrics(client, clientFlags)
+	require.NoError(t, err)
+
+	exp := &exporter{}
+	w.Gauge("asvec_up", "", 1)
+
+	var buf strings.Builder
+
+	_, err = w.WriteTo(&buf)
+	require.NoError(t, err)
+
+	exp.metrics = []byte(buf.String())
+
+	recorder := httptest.NewRecorder()
+	exp.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, exporterMetricsPath, nil))
+
+	assert.Equal(t, "text/plain; version=0.0.4; charset=utf-8", recorder.Header().Get("Content-Type"))
+
+	metrics := recorder.Body.String()
+
+	for _, expected := range []string{
+		`asvec_node_info{node_id="` + addr.String() + `",endpoint="` + addr.String() + `",`,
+		`cluster_id="42",version="1.0.0"} 1`,
+		`asvec_node_role{node_id="` + addr.String() + `",role="INDEX_QUERY"} 1`,
+		`asvec_node_in_cluster{node_id="` + addr.String() + `"} 1`,
+		`asvec_node_cluster_members{node_id="` + addr.String() + `"} 2`,
+		`asvec_node_visible_nodes{node_id="` + addr.String() + `"} 2`,
+		`asvec_node_visible{node_id="` + addr.String() + `",peer_id="1"} 1` + "\n" +
+			`asvec_node_visible{node_id="` + addr.String() + `",peer_id="2"} 1` + "\n",
+		`asvec_index_info{namespace="test",index="myindex",set="",field="vec",mode="DISTRIBUTED",` +
+			`distance_metric="COSINE",dimensions="3"} 1`,
+		`asvec_index_status{namespace="test",index="myindex",status="READY"} 1`,
+		`asvec_index_unmerged_records{namespace="test",index="myindex"} 7`,
+		`asvec_index_vector_records{namespace="test",index="myindex"} 100`,
+		`asvec_index_vertices{namespace="test",index="myindex"} 100`,
+		`# TYPE asvec_index_size_bytes gauge`,
+		"asvec_up 1\n",
+	} {
+		assert.Contains(t, metrics, expected)
+	}
+
+	assert.NotContains(t, metrics, "asvec_standalone_index", "distributed indexes have no standalone metrics")
+}
+
+func TestWriteNodeMetricsSeeds(t *testing.T) {
+	seed := func(port uint32) *writers.NodeInfo {
+		return &writers.NodeInfo{
+			NodeID:            &protos.NodeId{},
+			ConnectedEndpoint: &protos.ServerEndpoint{Address: "10.0.0.1", Port: port},
+			State:             &protos.ClusteringState{IsInCluster: true, Members: []*protos.NodeId{{Id: 1}}},
+			Endpoints:         &protos.ClusterNodeEndpoints{Endpoints: map[uint64]*protos.ServerEndpointList{1: {}}},
+		}
+	}
+
+	w := writers.NewPrometheusWriter()
+	writeNodeMetrics(w, []*writers.NodeInfo{seed(5000), seed(5001)}, false)
+
+	var buf strings.Builder
+
+	_, err := w.WriteTo(&buf)
+	require.NoError(t, err)
+
+	seen := map[string]bool{}
+
+	for _, line := range strings.Split(buf.String(), "\n") {
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		series := line[:strings.LastIndex(line, " ")]
+		assert.False(t, seen[series], "duplicate series %s", series)
+		seen[series] = true
+	}
+
+	for _, expected := range []string{
+		`asvec_node_in_cluster{node_id="10.0.0.1:5000"} 1`,
+		`asvec_node_in_cluster{node_id="10.0.0.1:5001"} 1`,
+		`asvec_node_cluster_members{node_id="10.0.0.1:5001"} 1`,
+		`asvec_node_visible_nodes{node_id="10.0.0.1:5000"} 1`,
+	} {
+		assert.Contains(t, buf.String(), expected)
+	}
+}
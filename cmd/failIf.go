@@ -0,0 +1,221 @@
+package cmd
+
+import (
+	"asvec/cmd/flags"
+	"asvec/cmd/writers"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// The fields identifying the items of index and node results. They are used
+// by both --fail-if alerts and the watch change log.
+var (
+	indexItemKeys = []string{"definition.id.namespace", "definition.id.name"}
+	nodeItemKeys  = []string{"nodeId"}
+)
+
+// indexFailIfFields maps the fields usable in --fail-if conditions on
+// indexes to their path in the JSON output.
+var indexFailIfFields = map[string]string{
+	"namespace":        "definition.id.namespace",
+	"name":             "definition.id.name",
+	"mode":             "definition.mode",
+	"status":           "status.status",
+	"unmerged":         "status.unmergedRecordCount",
+	"vector-records":   "status.indexHealerVectorRecordsIndexed",
+	"vertices":         "status.indexHealerVerticesValid",
+	"standalone-state": "status.standaloneIndexMetrics.state",
+}
+
+// nodeFailIfFields maps the fields usable in --fail-if conditions on nodes to
+// their path in the JSON output.
+var nodeFailIfFields = map[string]string{
+	"node-id":         "nodeId",
+	"version":         "about.version",
+	"cluster-id":      "state.clusterId.id",
+	"in-cluster":      "state.isInCluster",
+	"cluster-members": "state.members",
+	"visible-nodes":   "endpoints.endpoints",
+}
+
+// alertSink receives the alerts of each run instead of them being printed. It
+// is only set while a command runs in watch mode.
+var alertSink func(alerts []*failIfAlert)
+
+// failIfCheck evaluates --fail-if conditions against the structured results
+// of a command.
+type failIfCheck struct {
+	conditions *flags.FailIfFlag
+	// fields maps the field names usable in conditions to dot separated paths
+	// in an item's JSON. Lists and objects are compared by their length.
+	fields map[string]string
+	// keys are the paths of the fields which identify an item.
+	keys []string
+}
+
+// failIfAlert is a condition which is true for an item.
+type failIfAlert struct {
+	// Name is the item's key for display, e.g. "test.myindex".
+	Name      string
+	Key       map[string]any
+	Condition string
+	Field     string
+	Value     any
+}
+
+// id identifies the alert across runs.
+func (a *failIfAlert) id() string {
+	key, _ := json.Marshal(a.Key)
+	return string(key) + a.Condition
+}
+
+func (a *failIfAlert) String() string {
+	value := "missing"
+	if a.Value != nil {
+		value = fmt.Sprint(a.Value)
+	}
+
+	return fmt.Sprintf("%s: %s (%s is %s)", a.Name, a.Condition, a.Field, value)
+}
+
+// validate returns an error if a condition uses an unknown field.
+func (c *failIfCheck) validate() error {
+	for _, condition := range *c.conditions {
+		if _, ok := c.fields[condition.Field]; !ok {
+			names := slices.Sorted(maps.Keys(c.fields))
+
+			return fmt.Errorf(
+				"unknown --%s field %q, valid fields: %s",
+				flags.FailIf, condition.Field, strings.Join(names, ", "),
+			)
+		}
+	}
+
+	return nil
+}
+
+// eval returns an alert for each condition which is true for an item. Items
+// are evaluated as they appear in the JSON output.
+func (c *failIfCheck) eval(items []any) ([]*failIfAlert, error) {
+	alerts := []*failIfAlert{}
+
+	if len(*c.conditions) == 0 {
+		return alerts, nil
+	}
+
+	for _, item := range items {
+		value, err := toJSONValue(item)
+		if err != nil {
+			return nil, err
+		}
+
+		key := make(map[string]any, len(c.keys))
+		names := make([]string, len(c.keys))
+
+		for i, k := range c.keys {
+			key[k] = jsonField(value, k)
+
+			if key[k] == nil {
+				names[i] = "-"
+			} else {
+				names[i] = fmt.Sprint(key[k])
+			}
+		}
+
+		name := strings.Join(names, ".")
+
+		// Items merged from multiple clusters are also keyed by their cluster.
+		if cluster := jsonField(value, writers.ClusterKey); cluster != nil {
+			key[writers.ClusterKey] = cluster
+			name = fmt.Sprintf("%v/%s", cluster, name)
+		}
+
+		for _, condition := range *c.conditions {
+			fieldValue := jsonField(value, c.fields[condition.Field])
+
+			switch v := fieldValue.(type) {
+			case []any:
+				fieldValue = len(v)
+			case map[string]any:
+				fieldValue = len(v)
+			}
+
+			tripped, err := condition.Eval(fieldValue)
+			if err != nil {
+				return nil, err
+			}
+
+			if tripped {
+				alerts = append(alerts, &failIfAlert{
+					Name:      name,
+					Key:       key,
+					Condition: condition.String(),
+					Field:     condition.Field,
+					Value:     fieldValue,
+				})
+			}
+		}
+	}
+
+	return alerts, nil
+}
+
+// failIfUsage returns the usage of a --fail-if flag. subject is what the
+// conditions are evaluated against, e.g. "any index".
+func failIfUsage(subject string, fields map[string]string) string {
+	return fmt.Sprintf(
+		"Exit with code %d if a condition, e.g. status!=READY, is true for %s. "+
+			"Operators: > >= < <= == !=. Can be repeated. Valid fields: %s",
+		flags.ExitCodeConditionFailed, subject, strings.Join(slices.Sorted(maps.Keys(fields)), ", "),
+	)
+}
+
+// toJSONValue returns v as it is decoded from its JSON output.
+func toJSONValue(v any) (any, error) {
+	out, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(out))
+	decoder.UseNumber()
+
+	var value any
+
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+
+	return value, nil
+}
+
+// checkFailIf evaluates the --fail-if conditions against items. If any are
+// true, they are reported and the command exits with
+// flags.ExitCodeConditionFailed.
+func checkFailIf(c *failIfCheck, items []any) error {
+	alerts, err := c.eval(items)
+	if err != nil {
+		logger.Error("failed to evaluate conditions", slog.Any("error", err))
+		return err
+	}
+
+	if len(alerts) == 0 {
+		return nil
+	}
+
+	setErrCode(flags.ExitCodeConditionFailed)
+
+	if alertSink != nil {
+		alertSink(alerts)
+		return nil
+	}
+
+	view.PrintAlerts(alerts)
+
+	return nil
+}
@@ -0,0 +1,114 @@
+//go:build unit
+
+package cmd
+
+import (
+	"asvec/cmd/flags"
+	"asvec/cmd/writers"
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/aerospike/avs-client-go/protos"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestFailIfCheck(t *testing.T, fields map[string]string, keys []string, conditions ...string) *failIfCheck {
+	t.Helper()
+
+	var failIf flags.FailIfFlag
+
+	for _, c := range conditions {
+		require.NoError(t, failIf.Set(c))
+	}
+
+	return &failIfCheck{conditions: &failIf, fields: fields, keys: keys}
+}
+
+func TestFailIfCheckIndexes(t *testing.T) {
+	items := []any{
+		writers.NewIndexJSON(
+			&protos.IndexDefinition{Id: &protos.IndexId{Namespace: "test", Name: "a"}},
+			&protos.IndexStatusResponse{Status: protos.Status_READY, UnmergedRecordCount: 10},
+		),
+		writers.NewIndexJSON(
+			&protos.IndexDefinition{Id: &protos.IndexId{Namespace: "test", Name: "b"}},
+			&protos.IndexStatusResponse{Status: protos.Status_NOT_READY, UnmergedRecordCount: 200000},
+		),
+		// The status of an index could not be retrieved
+		writers.NewIndexJSON(&protos.IndexDefinition{Id: &protos.IndexId{Namespace: "test", Name: "c"}}, nil),
+	}
+
+	check := newTestFailIfCheck(t, indexFailIfFields, indexItemKeys, "unmerged>100000", "status!=READY")
+	require.NoError(t, check.validate())
+
+	alerts, err := check.eval(items)
+	require.NoError(t, err)
+	require.Len(t, alerts, 3)
+
+	assert.Equal(t, "test.b: unmerged>100000 (unmerged is 200000)", alerts[0].String())
+	assert.Equal(t, "test.b: status!=READY (status is NOT_READY)", alerts[1].String())
+	assert.Equal(t, "test.c: status!=READY (status is missing)", alerts[2].String())
+
+	check = newTestFailIfCheck(t, indexFailIfFields, indexItemKeys, "unmerged<5")
+
+	alerts, err = check.eval(items)
+	require.NoError(t, err)
+	assert.Empty(t, alerts)
+
+	check = newTestFailIfCheck(t, indexFailIfFields, indexItemKeys, "status>READY")
+
+	_, err = check.eval(items)
+	assert.Error(t, err, "only numbers can be ordered")
+
+	check = newTestFailIfCheck(t, indexFailIfFields, indexItemKeys, "visible-nodes<3")
+	assert.ErrorContains(t, check.validate(), "valid fields: mode, name, namespace")
+}
+
+func TestFailIfCheckNodes(t *testing.T) {
+	endpoints := &protos.ClusterNodeEndpoints{
+		Endpoints: map[uint64]*protos.ServerEndpointList{1: {}, 2: {}},
+	}
+	items := []any{
+		&writers.NodeInfo{NodeID: &protos.NodeId{Id: 1}, Endpoints: endpoints},
+		&writers.NodeInfo{NodeID: &protos.NodeId{Id: 2}, Endpoints: endpoints},
+	}
+
+	check := newTestFailIfCheck(t, nodeFailIfFields, nodeItemKeys, "visible-nodes<3")
+
+	alerts, err := check.eval(items)
+	require.NoError(t, err)
+	require.Len(t, alerts, 2)
+	assert.Equal(t, map[string]any{"nodeId": json.Number("1")}, alerts[0].Key)
+	assert.Equal(t, "1: visible-nodes<3 (visible-nodes is 2)", alerts[0].String())
+}
+
+func TestCheckFailIf(t *testing.T) {
+	defer errCode.Store(0)
+
+	errBuf := &bytes.Buffer{}
+	originalView := view
+	view = NewView(&bytes.Buffer{}, errBuf, slog.Default())
+
+	defer func() {
+		view = originalView
+	}()
+
+	items := []any{writers.NewIndexJSON(
+		&protos.IndexDefinition{Id: &protos.IndexId{Namespace: "test", Name: "a"}},
+		&protos.IndexStatusResponse{Status: protos.Status_READY},
+	)}
+
+	require.NoError(t, checkFailIf(newTestFailIfCheck(t, indexFailIfFields, indexItemKeys, "status!=READY"), items))
+	assert.Equal(t, uint32(0), errCode.Load())
+	assert.Empty(t, errBuf.String())
+
+	require.NoError(t, checkFailIf(newTestFailIfCheck(t, indexFailIfFields, indexItemKeys, "status==READY"), items))
+	assert.Equal(t, uint32(flags.ExitCodeConditionFailed), errCode.Load())
+	assert.Contains(t, errBuf.String(), "Alert: test.a: status==READY")
+
+	view.Warning("a later warning")
+	assert.Equal(t, uint32(flags.ExitCodeConditionFailed), errCode.Load(), "warnings do not lower the exit code")
+}
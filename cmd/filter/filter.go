@@ -0,0 +1,371 @@
+// Package filter implements the expressions accepted by "asvec query
+// --filter" to select records by their bins, for example:
+//
+//	tenant == "acme" and lang in ("en", "de") and price < 100
+//
+// Comparisons are ==, !=, <, <=, >, >=, in, and not in. They are combined
+// with and, or, not, and parentheses. Fields are bin names, with dots to
+// select keys of map bins, e.g. meta.lang. Values are numbers, true, false,
+// or strings. Strings only need quotes when they are not a single word.
+//
+// A comparison against a list bin is true if it is true for any element, so
+// tags == "sale" matches a record with tags ["new", "sale"]. A missing bin
+// only matches != and not in. Values of different types, e.g. a string and
+// a number, are never equal or ordered.
+package filter
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// Filter is a parsed filter expression.
+type Filter struct {
+	expr expr
+}
+
+// Parse parses a filter expression.
+func Parse(text string) (*Filter, error) {
+	tokens, err := lex(text)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+
+	e, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	if tok := p.peek(); tok.kind != tokenEOF {
+		return nil, fmt.Errorf("unexpected %s at position %d", tok, tok.pos)
+	}
+
+	return &Filter{expr: e}, nil
+}
+
+// Match reports whether the bins of a record match the filter.
+func (f *Filter) Match(data map[string]any) bool {
+	return f.expr.match(data)
+}
+
+// Bins returns the names of the bins the filter reads, in sorted order. They
+// must be included in search results for the filter to match.
+func (f *Filter) Bins() []string {
+	bins := map[string]struct{}{}
+	f.expr.bins(bins)
+
+	names := make([]string, 0, len(bins))
+	for name := range bins {
+		names = append(names, name)
+	}
+
+	slices.Sort(names)
+
+	return names
+}
+
+func (f *Filter) String() string {
+	return f.expr.String()
+}
+
+type expr interface {
+	match(data map[string]any) bool
+	bins(names map[string]struct{})
+	String() string
+}
+
+type andExpr struct {
+	left, right expr
+}
+
+func (e *andExpr) match(data map[string]any) bool {
+	return e.left.match(data) && e.right.match(data)
+}
+
+func (e *andExpr) bins(names map[string]struct{}) {
+	e.left.bins(names)
+	e.right.bins(names)
+}
+
+func (e *andExpr) String() string {
+	return fmt.Sprintf("(%s and %s)", e.left, e.right)
+}
+
+type orExpr struct {
+	left, right expr
+}
+
+func (e *orExpr) match(data map[string]any) bool {
+	return e.left.match(data) || e.right.match(data)
+}
+
+func (e *orExpr) bins(names map[string]struct{}) {
+	e.left.bins(names)
+	e.right.bins(names)
+}
+
+func (e *orExpr) String() string {
+	return fmt.Sprintf("(%s or %s)", e.left, e.right)
+}
+
+type notExpr struct {
+	expr expr
+}
+
+func (e *notExpr) match(data map[string]any) bool {
+	return !e.expr.match(data)
+}
+
+func (e *notExpr) bins(names map[string]struct{}) {
+	e.expr.bins(names)
+}
+
+func (e *notExpr) String() string {
+	return fmt.Sprintf("not %s", e.expr)
+}
+
+// compareExpr compares a field to a value. != is the negation of == so that
+// it matches missing fields.
+type compareExpr struct {
+	value any
+	field string
+	op    string
+}
+
+func (e *compareExpr) match(data map[string]any) bool {
+	actual, ok := lookup(data, e.field)
+
+	if e.op == "!=" {
+		return !ok || !anyElement(actual, func(v any) bool { return equal(v, e.value) })
+	}
+
+	if !ok {
+		return false
+	}
+
+	return anyElement(actual, func(v any) bool {
+		if e.op == "==" {
+			return equal(v, e.value)
+		}
+
+		c, ok := compare(v, e.value)
+		if !ok {
+			return false
+		}
+
+		switch e.op {
+		case "<":
+			return c < 0
+		case "<=":
+			return c <= 0
+		case ">":
+			return c > 0
+		default:
+			return c >= 0
+		}
+	})
+}
+
+func (e *compareExpr) bins(names map[string]struct{}) {
+	names[binName(e.field)] = struct{}{}
+}
+
+func (e *compareExpr) String() string {
+	return fmt.Sprintf("%s %s %s", e.field, e.op, formatValue(e.value))
+}
+
+// inExpr matches a field equal to any of a list of values.
+type inExpr struct {
+	field  string
+	values []any
+}
+
+func (e *inExpr) match(data map[string]any) bool {
+	actual, ok := lookup(data, e.field)
+	if !ok {
+		return false
+	}
+
+	return anyElement(actual, func(v any) bool {
+		return slices.ContainsFunc(e.values, func(value any) bool { return equal(v, value) })
+	})
+}
+
+func (e *inExpr) bins(names map[string]struct{}) {
+	names[binName(e.field)] = struct{}{}
+}
+
+func (e *inExpr) String() string {
+	values := make([]string, len(e.values))
+	for i, v := range e.values {
+		values[i] = formatValue(v)
+	}
+
+	return fmt.Sprintf("%s in (%s)", e.field, strings.Join(values, ", "))
+}
+
+func binName(field string) string {
+	name, _, _ := strings.Cut(field, ".")
+	return name
+}
+
+// lookup returns the value at a dot separated path in a record's bins.
+func lookup(data map[string]any, field string) (any, bool) {
+	var current any = data
+
+	for _, name := range strings.Split(field, ".") {
+		switch m := current.(type) {
+		case map[string]any:
+			v, ok := m[name]
+			if !ok {
+				return nil, false
+			}
+
+			current = v
+		case map[any]any:
+			v, ok := m[name]
+			if !ok {
+				return nil, false
+			}
+
+			current = v
+		default:
+			return nil, false
+		}
+	}
+
+	return current, current != nil
+}
+
+// anyElement reports whether fn is true for value or, if value is a list,
+// for any of its elements.
+func anyElement(value any, fn func(v any) bool) bool {
+	if _, ok := value.([]byte); ok {
+		return fn(value)
+	}
+
+	rv := reflect.ValueOf(value)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return fn(value)
+	}
+
+	for i := 0; i < rv.Len(); i++ {
+		if fn(rv.Index(i).Interface()) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func equal(a, b any) bool {
+	if ab, ok := a.(bool); ok {
+		bb, ok := b.(bool)
+		return ok && ab == bb
+	}
+
+	c, ok := compare(a, b)
+
+	return ok && c == 0
+}
+
+// compare orders a record value against a filter value. It returns false if
+// they are not both numbers or both strings.
+func compare(a, b any) (int, bool) {
+	if as, ok := toString(a); ok {
+		bs, ok := b.(string)
+		if !ok {
+			return 0, false
+		}
+
+		return strings.Compare(as, bs), true
+	}
+
+	if ai, ok := toInt(a); ok {
+		if bi, ok := b.(int64); ok {
+			switch {
+			case ai < bi:
+				return -1, true
+			case ai > bi:
+				return 1, true
+			default:
+				return 0, true
+			}
+		}
+	}
+
+	af, ok := toFloat(a)
+	if !ok {
+		return 0, false
+	}
+
+	bf, ok := toFloat(b)
+	if !ok {
+		return 0, false
+	}
+
+	switch {
+	case af < bf:
+		return -1, true
+	case af > bf:
+		return 1, true
+	default:
+		return 0, true
+	}
+}
+
+func toString(v any) (string, bool) {
+	switch s := v.(type) {
+	case string:
+		return s, true
+	case []byte:
+		return string(s), true
+	default:
+		return "", false
+	}
+}
+
+func toInt(v any) (int64, bool) {
+	rv := reflect.ValueOf(v)
+
+	switch rv.Kind() { //nolint:exhaustive // Other kinds are not integers
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int(), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u := rv.Uint()
+		if u > 1<<63-1 {
+			return 0, false
+		}
+
+		return int64(u), true
+	default:
+		return 0, false
+	}
+}
+
+func toFloat(v any) (float64, bool) {
+	rv := reflect.ValueOf(v)
+
+	switch rv.Kind() { //nolint:exhaustive // Other kinds are not numbers
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), true
+	default:
+		return 0, false
+	}
+}
+
+func formatValue(v any) string {
+	if s, ok := v.(string); ok {
+		return fmt.Sprintf("%q", s)
+	}
+
+	return fmt.Sprint(v)
+}
@@ -0,0 +1,102 @@
+//go:build unit
+
+package filter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatch(t *testing.T) {
+	record := map[string]any{
+		"tenant": "acme",
+		"lang":   "en",
+		"price":  int64(42),
+		"rating": 4.5,
+		"active": true,
+		"tags":   []any{"new", "sale"},
+		"meta":   map[any]any{"region": "eu", "stock": int64(3)},
+	}
+
+	testCases := []struct {
+		filter   string
+		expected bool
+	}{
+		{`tenant == "acme"`, true},
+		{`tenant = acme`, true},
+		{`tenant == 'ACME'`, false},
+		{`tenant != acme`, false},
+		{`price > 10 and price <= 42`, true},
+		{`price < 42`, false},
+		{`price >= 42.0`, true},
+		{`rating > 4`, true},
+		{`rating == 4.5`, true},
+		{`price == "42"`, false},
+		{`active == true`, true},
+		{`lang in ("de", "en")`, true},
+		{`lang in [de, fr]`, false},
+		{`lang not in (de, fr)`, true},
+		{`tags == sale`, true},
+		{`tags in (old)`, false},
+		{`tags != sale`, false},
+		{`meta.region == eu && meta.stock > 0`, true},
+		{`meta.missing == eu`, false},
+		{`missing == 1`, false},
+		{`missing != 1`, true},
+		{`missing not in (1, 2)`, true},
+		{`tenant == other or lang == en`, true},
+		{`tenant == other || lang == de`, false},
+		{`not (tenant == other) and !(lang == de)`, true},
+		{`tenant == acme and (lang == de or price < 50)`, true},
+		{`tenant == acme and lang == de or price < 50`, true},
+		{`tenant == other and lang == de or price > 50`, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.filter, func(t *testing.T) {
+			f, err := Parse(tc.filter)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, f.Match(record), "parsed as %s", f)
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	f, err := Parse(`tenant == "acme" AND lang IN ('en', "de") or NOT price >= -1.5e2`)
+	require.NoError(t, err)
+
+	assert.Equal(t, `((tenant == "acme" and lang in ("en", "de")) or not price >= -150)`, f.String())
+	assert.Equal(t, []string{"lang", "price", "tenant"}, f.Bins())
+
+	f, err = Parse(`meta.region == eu`)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"meta"}, f.Bins())
+}
+
+func TestParseErrors(t *testing.T) {
+	testCases := []struct {
+		filter   string
+		expected string
+	}{
+		{``, "expected a bin name at position 0 but found end of filter"},
+		{`tenant`, "expected a comparison after \"tenant\""},
+		{`tenant ==`, "expected a value at position 9"},
+		{`tenant == "acme`, "unterminated string starting at position 10"},
+		{`(tenant == acme`, "expected \")\""},
+		{`tenant == acme)`, "unexpected \")\" at position 14"},
+		{`lang in en`, "expected a list after in"},
+		{`lang in (en de)`, "expected \",\" or the end of the list"},
+		{`and == 1`, "expected a bin name"},
+		{`tenant == acme and`, "expected a bin name"},
+		{`tenant ~ acme`, "unexpected '~' at position 7"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.filter, func(t *testing.T) {
+			_, err := Parse(tc.filter)
+			assert.ErrorContains(t, err, tc.expected)
+		})
+	}
+}
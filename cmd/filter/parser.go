@@ -0,0 +1,344 @@
+package filter
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenWord
+	tokenNumber
+	tokenString
+	tokenOp
+	tokenLParen
+	tokenRParen
+	tokenComma
+)
+
+type token struct {
+	text string
+	kind tokenKind
+	pos  int
+}
+
+func (t token) String() string {
+	if t.kind == tokenEOF {
+		return "end of filter"
+	}
+
+	return strconv.Quote(t.text)
+}
+
+// keyword reports whether the token is the case-insensitive keyword kw.
+func (t token) keyword(kw string) bool {
+	return t.kind == tokenWord && strings.EqualFold(t.text, kw)
+}
+
+// Operators, with longer operators listed first so that "<=" is not lexed
+// as "<".
+var operators = []string{"==", "!=", "<=", ">=", "&&", "||", "<", ">", "=", "!"}
+
+func lex(text string) ([]token, error) {
+	tokens := []token{}
+
+	for pos := 0; pos < len(text); {
+		c := rune(text[pos])
+
+		switch {
+		case unicode.IsSpace(c):
+			pos++
+		case c == '(' || c == '[':
+			tokens = append(tokens, token{kind: tokenLParen, text: string(c), pos: pos})
+			pos++
+		case c == ')' || c == ']':
+			tokens = append(tokens, token{kind: tokenRParen, text: string(c), pos: pos})
+			pos++
+		case c == ',':
+			tokens = append(tokens, token{kind: tokenComma, text: ",", pos: pos})
+			pos++
+		case c == '"' || c == '\'':
+			end := pos + 1
+			for end < len(text) && text[end] != byte(c) {
+				if text[end] == '\\' {
+					end++
+				}
+
+				end++
+			}
+
+			if end >= len(text) {
+				return nil, fmt.Errorf("unterminated string starting at position %d", pos)
+			}
+
+			s, err := unquote(text[pos : end+1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid string at position %d: %w", pos, err)
+			}
+
+			tokens = append(tokens, token{kind: tokenString, text: s, pos: pos})
+			pos = end + 1
+		case isDigit(c) || (c == '-' || c == '+' || c == '.') && pos+1 < len(text) && isDigit(rune(text[pos+1])):
+			end := pos + 1
+			for end < len(text) && isWordChar(rune(text[end])) {
+				end++
+			}
+
+			tokens = append(tokens, token{kind: tokenNumber, text: text[pos:end], pos: pos})
+			pos = end
+		case isWordStart(c):
+			end := pos + 1
+			for end < len(text) && isWordChar(rune(text[end])) {
+				end++
+			}
+
+			tokens = append(tokens, token{kind: tokenWord, text: text[pos:end], pos: pos})
+			pos = end
+		default:
+			op := ""
+
+			for _, o := range operators {
+				if strings.HasPrefix(text[pos:], o) {
+					op = o
+					break
+				}
+			}
+
+			if op == "" {
+				return nil, fmt.Errorf("unexpected %q at position %d", c, pos)
+			}
+
+			tokens = append(tokens, token{kind: tokenOp, text: op, pos: pos})
+			pos += len(op)
+		}
+	}
+
+	return append(tokens, token{kind: tokenEOF, pos: len(text)}), nil
+}
+
+func isDigit(c rune) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isWordStart(c rune) bool {
+	return unicode.IsLetter(c) || c == '_'
+}
+
+// isWordChar reports whether c can be part of a word. Dots separate the keys
+// of map bins and dashes are common in bin names.
+func isWordChar(c rune) bool {
+	return isWordStart(c) || isDigit(c) || c == '.' || c == '-' || c == '+'
+}
+
+func unquote(s string) (string, error) {
+	if s[0] == '\'' {
+		s = `"` + strings.ReplaceAll(strings.ReplaceAll(s[1:len(s)-1], `\'`, `'`), `"`, `\"`) + `"`
+	}
+
+	return strconv.Unquote(s)
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+
+	if tok.kind != tokenEOF {
+		p.pos++
+	}
+
+	return tok
+}
+
+// parseOr parses: and ("or" and)*
+func (p *parser) parseOr() (expr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for tok := p.peek(); tok.keyword("or") || tok.kind == tokenOp && tok.text == "||"; tok = p.peek() {
+		p.next()
+
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+
+		left = &orExpr{left: left, right: right}
+	}
+
+	return left, nil
+}
+
+// parseAnd parses: not ("and" not)*
+func (p *parser) parseAnd() (expr, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+
+	for tok := p.peek(); tok.keyword("and") || tok.kind == tokenOp && tok.text == "&&"; tok = p.peek() {
+		p.next()
+
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+
+		left = &andExpr{left: left, right: right}
+	}
+
+	return left, nil
+}
+
+// parseNot parses: "not" not | "(" or ")" | comparison
+func (p *parser) parseNot() (expr, error) {
+	tok := p.peek()
+
+	switch {
+	case tok.keyword("not") || tok.kind == tokenOp && tok.text == "!":
+		p.next()
+
+		e, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+
+		return &notExpr{expr: e}, nil
+	case tok.kind == tokenLParen && tok.text == "(":
+		p.next()
+
+		e, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+
+		if tok := p.next(); tok.kind != tokenRParen {
+			return nil, fmt.Errorf("expected \")\" at position %d but found %s", tok.pos, tok)
+		}
+
+		return e, nil
+	default:
+		return p.parseComparison()
+	}
+}
+
+// parseComparison parses: field op value | field ["not"] "in" list
+func (p *parser) parseComparison() (expr, error) {
+	field := p.next()
+	if field.kind != tokenWord || isKeyword(field.text) {
+		return nil, fmt.Errorf("expected a bin name at position %d but found %s", field.pos, field)
+	}
+
+	tok := p.next()
+
+	switch {
+	case tok.keyword("in"):
+		return p.parseIn(field.text)
+	case tok.keyword("not") && p.peek().keyword("in"):
+		p.next()
+
+		e, err := p.parseIn(field.text)
+		if err != nil {
+			return nil, err
+		}
+
+		return &notExpr{expr: e}, nil
+	case tok.kind == tokenOp && tok.text != "!" && tok.text != "&&" && tok.text != "||":
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+
+		op := tok.text
+		if op == "=" {
+			op = "=="
+		}
+
+		return &compareExpr{field: field.text, op: op, value: value}, nil
+	default:
+		return nil, fmt.Errorf(
+			"expected a comparison after %s at position %d but found %s", field, tok.pos, tok,
+		)
+	}
+}
+
+// parseIn parses: "(" value ("," value)* ")"
+func (p *parser) parseIn(field string) (expr, error) {
+	if tok := p.next(); tok.kind != tokenLParen {
+		return nil, fmt.Errorf("expected a list after in at position %d but found %s", tok.pos, tok)
+	}
+
+	values := []any{}
+
+	for {
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+
+		values = append(values, value)
+
+		tok := p.next()
+		if tok.kind == tokenRParen {
+			break
+		}
+
+		if tok.kind != tokenComma {
+			return nil, fmt.Errorf("expected \",\" or the end of the list at position %d but found %s", tok.pos, tok)
+		}
+	}
+
+	return &inExpr{field: field, values: values}, nil
+}
+
+// parseValue parses a number, string, true, or false. Words other than
+// keywords are strings.
+func (p *parser) parseValue() (any, error) {
+	tok := p.next()
+
+	switch {
+	case tok.kind == tokenString:
+		return tok.text, nil
+	case tok.kind == tokenNumber:
+		if i, err := strconv.ParseInt(tok.text, 10, 64); err == nil {
+			return i, nil
+		}
+
+		f, err := strconv.ParseFloat(tok.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %s at position %d", tok, tok.pos)
+		}
+
+		return f, nil
+	case tok.keyword("true"):
+		return true, nil
+	case tok.keyword("false"):
+		return false, nil
+	case tok.kind == tokenWord && !isKeyword(tok.text):
+		return tok.text, nil
+	default:
+		return nil, fmt.Errorf("expected a value at position %d but found %s", tok.pos, tok)
+	}
+}
+
+func isKeyword(word string) bool {
+	switch strings.ToLower(word) {
+	case "and", "or", "not", "in", "true", "false":
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,52 @@
+package flags
+
+import (
+	"log/slog"
+	"strings"
+
+	commonFlags "github.com/aerospike/tools-common-go/flags"
+	"github.com/spf13/pflag"
+)
+
+// AerospikeFlagPrefix is prepended to the Aerospike Database connection flags
+// so they do not collide with the AVS connection flags.
+const AerospikeFlagPrefix = "asdb-"
+
+// AerospikeFlags are used to connect directly to the Aerospike Database
+// cluster backing AVS. They are only needed by commands AVS does not support
+// e.g. scanning the records behind an index.
+type AerospikeFlags struct {
+	*commonFlags.AerospikeFlags
+}
+
+func NewAerospikeFlags() *AerospikeFlags {
+	return &AerospikeFlags{
+		AerospikeFlags: commonFlags.NewDefaultAerospikeFlags(),
+	}
+}
+
+// NewFlagSet returns the tools-common-go Aerospike flags with each flag
+// prefixed by AerospikeFlagPrefix and without shorthands.
+func (af *AerospikeFlags) NewFlagSet() *pflag.FlagSet {
+	flagSet := &pflag.FlagSet{}
+
+	af.AerospikeFlags.NewFlagSet(func(s string) string { return s }).VisitAll(func(f *pflag.Flag) {
+		flagSet.AddFlag(&pflag.Flag{
+			Name:     AerospikeFlagPrefix + f.Name,
+			Usage:    strings.Replace(f.Usage, "Aerospike", "Aerospike Database", 1),
+			Value:    f.Value,
+			DefValue: f.DefValue,
+		})
+	})
+
+	return flagSet
+}
+
+func (af *AerospikeFlags) NewSLogAttr() []any {
+	return []any{
+		slog.String(AerospikeFlagPrefix+"host", af.Seeds.String()),
+		slog.Int(AerospikeFlagPrefix+"port", af.DefaultPort),
+		slog.String(AerospikeFlagPrefix+"user", af.User),
+		slog.Bool(AerospikeFlagPrefix+"tls-enable", af.TLSEnable),
+	}
+}
@@ -0,0 +1,25 @@
+//go:build unit
+
+package flags
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAerospikeFlagsNewFlagSet(t *testing.T) {
+	af := NewAerospikeFlags()
+	flagSet := af.NewFlagSet()
+
+	assert.Nil(t, flagSet.Lookup("host"))
+	assert.NotNil(t, flagSet.Lookup("asdb-tls-enable"))
+
+	err := flagSet.Parse([]string{"--asdb-host", "1.1.1.1:3001", "--asdb-user", "admin"})
+	assert.NoError(t, err)
+
+	conf := af.NewAerospikeConfig()
+	assert.Equal(t, "1.1.1.1", conf.Seeds[0].Host)
+	assert.Equal(t, 3001, conf.Seeds[0].Port)
+	assert.Equal(t, "admin", conf.User)
+}
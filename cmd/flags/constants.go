@@ -1,7 +1,5 @@
 package flags
 
-import "github.com/spf13/pflag"
-
 const (
 	LogLevel                     = "log-level"
 	NoColor                      = "no-color"
@@ -33,9 +31,9 @@ const (
 	IndexLabels                  = "index-labels"
 	Timeout                      = "timeout"
 	Verbose                      = "verbose"
-	Format                       = "format"
 	Yaml                         = "yaml"
 	InputFile                    = "file"
+	OutputFile                   = "file"
 	StorageNamespace             = "storage-namespace"
 	StorageSet                   = "storage-set"
 	CutoffTime                   = "cutoff-time"
@@ -69,6 +67,47 @@ const (
 	TLSHostnameOverride          = "tls-hostname-override"
 	Watch                        = "watch"
 	WatchInterval                = "watch-interval"
+	Data                         = "data"
+	WriteType                    = "write-type"
+	IgnoreMemQueueFull           = "ignore-mem-queue-full"
+	FileFormat                   = "file-format"
+	KeyField                     = "key-field"
+	Parallelism                  = "parallelism"
+	Retries                      = "retries"
+	ErrorFile                    = "error-file"
+	IncludeVector                = "include-vector"
+	CheckpointFile               = "checkpoint-file"
+	QueryFile                    = "query-file"
+	Output                       = "output"
+	GroundTruthFile              = "ground-truth"
+	BaseFile                     = "base-file"
+	Duration                     = "duration"
+	Concurrency                  = "concurrency"
+	QPS                          = "qps"
+	VectorFile                   = "vector-file"
+	TimeSeriesFile               = "time-series-file"
+	KeyPrefix                    = "key-prefix"
+	DryRun                       = "dry-run"
+	CompareClusterName           = "compare-cluster-name"
+	Wait                         = "wait"
+	WaitUntil                    = "until"
+	WaitTimeout                  = "wait-timeout"
+	Interval                     = "interval"
+	Iterations                   = "iterations"
+	Listen                       = "listen"
+	FailIf                       = "fail-if"
+	MaxUnmerged                  = "max-unmerged"
+	Filter                       = "filter"
+	MaxDistance                  = "max-distance"
+	DedupBy                      = "dedup-by"
+	Rerank                       = "rerank"
+	VectorA                      = "a"
+	VectorB                      = "b"
+	Text                         = "text"
+	EmbeddingURL                 = "embedding-url"
+	EmbeddingCommand             = "embedding-command"
+	EmbeddingAPIKey              = "embedding-api-key"
+	EmbeddingModel               = "embedding-model"
 
 	// TODO  Replace short flag constants with variables
 	DimensionShort       = "d"
@@ -82,6 +121,8 @@ const (
 	KeyIntShort          = "t"
 	MaxDataColWidthShort = "w"
 	YesShort             = "y"
+	OutputShort          = "o"
+	InputFileShort       = "f"
 
 	// Flag types
 	FlagTypeEnum = "enum"
@@ -92,8 +133,3 @@ const (
 
 	Infinity = -1
 )
-
-func AddFormatTestFlag(flagSet *pflag.FlagSet, val *int) error {
-	flagSet.IntVar(val, Format, 0, "For testing only")
-	return flagSet.MarkHidden(Format)
-}
@@ -0,0 +1,55 @@
+package flags
+
+import (
+	"asvec/cmd/embed"
+	"fmt"
+	"log/slog"
+
+	"github.com/spf13/pflag"
+)
+
+// EmbeddingFlags configure the provider used to embed query text.
+type EmbeddingFlags struct {
+	Model   string
+	URL     string
+	APIKey  string
+	Command string
+}
+
+func NewEmbeddingFlags() *EmbeddingFlags {
+	return &EmbeddingFlags{}
+}
+
+func (ef *EmbeddingFlags) NewFlagSet() *pflag.FlagSet {
+	flagSet := &pflag.FlagSet{}
+	flagSet.StringVar(&ef.URL, EmbeddingURL, "", "The base URL of an OpenAI compatible embeddings endpoint, e.g. http://localhost:11434/v1.")                                                                    //nolint:lll // For readability
+	flagSet.StringVar(&ef.APIKey, EmbeddingAPIKey, "", fmt.Sprintf("The API key sent to --%s as a bearer token. Additionally can be set using the environment variable ASVEC_EMBEDDING_API_KEY.", EmbeddingURL)) //nolint:lll // For readability
+	flagSet.StringVar(&ef.Command, EmbeddingCommand, "", fmt.Sprintf("A command which reads text on stdin and prints its embedding as a JSON array. The model is in $%s.", embed.ModelEnv))                      //nolint:lll // For readability
+	flagSet.StringVar(&ef.Model, EmbeddingModel, "", fmt.Sprintf("The embedding model. Defaults to the index's %q label.", embed.ModelLabel))                                                                    //nolint:lll // For readability
+
+	return flagSet
+}
+
+func (ef *EmbeddingFlags) NewSLogAttr() []any {
+	logAPIKey := ""
+	if ef.APIKey != "" {
+		logAPIKey = "*"
+	}
+
+	return []any{
+		slog.String(EmbeddingModel, ef.Model),
+		slog.String(EmbeddingURL, ef.URL),
+		slog.String(EmbeddingAPIKey, logAPIKey),
+		slog.String(EmbeddingCommand, ef.Command),
+	}
+}
+
+// Config returns the configuration of the embedding provider.
+func (ef *EmbeddingFlags) Config() *embed.Config {
+	return &embed.Config{
+		Model:   ef.Model,
+		URL:     ef.URL,
+		APIKey:  ef.APIKey,
+		Command: ef.Command,
+	}
+}
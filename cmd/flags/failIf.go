@@ -0,0 +1,137 @@
+package flags
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ExitCodeConditionFailed is the exit code used when a --fail-if condition is
+// true. Warnings and errors exit with 1.
+const ExitCodeConditionFailed = 2
+
+// Operators accepted in a --fail-if condition. Longer operators are listed
+// first so that ">=" is not parsed as ">".
+var failIfOperators = []string{">=", "<=", "!=", "==", ">", "<", "="}
+
+var failIfFieldRegex = regexp.MustCompile(`^[a-z][a-z0-9-]*$`)
+
+// FailIfCondition compares a field of a command's result to a value, e.g.
+// "unmerged>100000" or "status!=READY".
+type FailIfCondition struct {
+	Field    string
+	Operator string
+	Value    string
+}
+
+// ParseFailIfCondition parses a condition of the form <field><op><value>.
+func ParseFailIfCondition(val string) (FailIfCondition, error) {
+	for _, op := range failIfOperators {
+		field, value, found := strings.Cut(val, op)
+		if !found {
+			continue
+		}
+
+		field = strings.ToLower(strings.TrimSpace(field))
+		value = strings.TrimSpace(value)
+
+		if !failIfFieldRegex.MatchString(field) || value == "" {
+			break
+		}
+
+		if op == "=" {
+			op = "=="
+		}
+
+		return FailIfCondition{Field: field, Operator: op, Value: value}, nil
+	}
+
+	return FailIfCondition{}, fmt.Errorf(
+		"invalid condition %q, expected <field><op><value> where op is one of %s",
+		val, strings.Join(failIfOperators[:6], " "),
+	)
+}
+
+// Eval reports whether the condition is true for value, which is a value
+// decoded from JSON. Numbers are compared numerically and everything else is
+// compared as a case-insensitive string. A missing value is only not equal to
+// anything.
+func (c FailIfCondition) Eval(value any) (bool, error) {
+	if value == nil {
+		return c.Operator == "!=", nil
+	}
+
+	actual := fmt.Sprint(value)
+
+	if a, err := strconv.ParseFloat(actual, 64); err == nil {
+		if b, err := strconv.ParseFloat(c.Value, 64); err == nil {
+			return compareFailIf(c.Operator, a, b), nil
+		}
+	}
+
+	switch c.Operator {
+	case "==":
+		return strings.EqualFold(actual, c.Value), nil
+	case "!=":
+		return !strings.EqualFold(actual, c.Value), nil
+	default:
+		return false, fmt.Errorf("%s requires numbers but %s is %q", c.Operator, c.Field, actual)
+	}
+}
+
+func compareFailIf(op string, a, b float64) bool {
+	switch op {
+	case ">":
+		return a > b
+	case ">=":
+		return a >= b
+	case "<":
+		return a < b
+	case "<=":
+		return a <= b
+	case "!=":
+		return a != b
+	default:
+		return a == b
+	}
+}
+
+func (c FailIfCondition) String() string {
+	return c.Field + c.Operator + c.Value
+}
+
+// FailIfFlag is a list of conditions which make a command exit with
+// ExitCodeConditionFailed when any of them is true. The flag can be repeated
+// or given a comma separated list.
+type FailIfFlag []FailIfCondition
+
+func (f *FailIfFlag) Set(val string) error {
+	conditions := []FailIfCondition{}
+
+	for _, s := range strings.Split(val, ",") {
+		condition, err := ParseFailIfCondition(s)
+		if err != nil {
+			return err
+		}
+
+		conditions = append(conditions, condition)
+	}
+
+	*f = append(*f, conditions...)
+
+	return nil
+}
+
+func (f *FailIfFlag) Type() string {
+	return "stringArray"
+}
+
+func (f *FailIfFlag) String() string {
+	conditions := make([]string, len(*f))
+	for i, c := range *f {
+		conditions[i] = c.String()
+	}
+
+	return strings.Join(conditions, ",")
+}
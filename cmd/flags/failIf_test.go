@@ -0,0 +1,93 @@
+//go:build unit
+
+package flags
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFailIfCondition(t *testing.T) {
+	testCases := []struct {
+		val      string
+		expected FailIfCondition
+		err      bool
+	}{
+		{val: "unmerged>100000", expected: FailIfCondition{Field: "unmerged", Operator: ">", Value: "100000"}},
+		{val: "status!=READY", expected: FailIfCondition{Field: "status", Operator: "!=", Value: "READY"}},
+		{val: "visible-nodes<3", expected: FailIfCondition{Field: "visible-nodes", Operator: "<", Value: "3"}},
+		{val: "Vertices >= 10", expected: FailIfCondition{Field: "vertices", Operator: ">=", Value: "10"}},
+		{val: "mode=STANDALONE", expected: FailIfCondition{Field: "mode", Operator: "==", Value: "STANDALONE"}},
+		{val: "name==a<b", expected: FailIfCondition{Field: "name", Operator: "==", Value: "a<b"}},
+		{val: "unmerged", err: true},
+		{val: "unmerged>", err: true},
+		{val: ">10", err: true},
+		{val: "un merged>10", err: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.val, func(t *testing.T) {
+			condition, err := ParseFailIfCondition(tc.val)
+			if tc.err {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, condition)
+		})
+	}
+}
+
+func TestFailIfConditionEval(t *testing.T) {
+	testCases := []struct {
+		condition string
+		value     any
+		expected  bool
+		err       bool
+	}{
+		{condition: "unmerged>100", value: json.Number("101"), expected: true},
+		{condition: "unmerged>100", value: json.Number("100")},
+		{condition: "unmerged>=100", value: json.Number("100"), expected: true},
+		{condition: "unmerged<=1.5", value: 2.0},
+		{condition: "unmerged==0", value: "0", expected: true},
+		{condition: "status!=READY", value: "READY"},
+		{condition: "status!=ready", value: "NOT_READY", expected: true},
+		{condition: "status==ready", value: "READY", expected: true},
+		{condition: "status!=READY", value: nil, expected: true},
+		{condition: "unmerged>100", value: nil},
+		{condition: "in-cluster==false", value: false, expected: true},
+		{condition: "status>READY", value: "READY", err: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.condition, func(t *testing.T) {
+			condition, err := ParseFailIfCondition(tc.condition)
+			require.NoError(t, err)
+
+			actual, err := condition.Eval(tc.value)
+			if tc.err {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestFailIfFlag(t *testing.T) {
+	var f FailIfFlag
+
+	require.NoError(t, f.Set("unmerged>100,status!=READY"))
+	require.NoError(t, f.Set("vertices<1"))
+	assert.Len(t, f, 3)
+	assert.Equal(t, "unmerged>100,status!=READY,vertices<1", f.String())
+
+	assert.Error(t, f.Set("unmerged"))
+	assert.Len(t, f, 3)
+}
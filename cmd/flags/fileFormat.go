@@ -0,0 +1,36 @@
+package flags
+
+import (
+	"asvec/cmd/records"
+)
+
+// FileFormatFlag is the format of a data file. An empty value means the
+// format should be inferred from the file's extension.
+type FileFormatFlag string
+
+func (f *FileFormatFlag) Set(val string) error {
+	format, err := records.ParseFormat(val)
+	if err != nil {
+		return err
+	}
+
+	*f = FileFormatFlag(format)
+
+	return nil
+}
+
+func (f *FileFormatFlag) Type() string {
+	return FlagTypeEnum
+}
+
+func (f *FileFormatFlag) String() string {
+	return string(*f)
+}
+
+func (f *FileFormatFlag) IsSet() bool {
+	return *f != ""
+}
+
+func (f *FileFormatFlag) Format() records.Format {
+	return records.Format(*f)
+}
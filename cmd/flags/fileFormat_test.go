@@ -0,0 +1,23 @@
+//go:build unit
+
+package flags
+
+import (
+	"asvec/cmd/records"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileFormatFlag(t *testing.T) {
+	f := FileFormatFlag("")
+	assert.False(t, f.IsSet())
+
+	err := f.Set("FVECS")
+	assert.NoError(t, err)
+	assert.True(t, f.IsSet())
+	assert.Equal(t, records.FormatFvecs, f.Format())
+
+	err = f.Set("parquet")
+	assert.Error(t, err)
+}
@@ -0,0 +1,32 @@
+package flags
+
+import (
+	"asvec/cmd/filter"
+)
+
+// FilterFlag is a filter expression selecting records by their bins, e.g.
+// `tenant == "acme" and lang in (en, de)`.
+type FilterFlag struct {
+	Filter *filter.Filter
+	text   string
+}
+
+func (f *FilterFlag) Set(val string) error {
+	parsed, err := filter.Parse(val)
+	if err != nil {
+		return err
+	}
+
+	f.Filter = parsed
+	f.text = val
+
+	return nil
+}
+
+func (f *FilterFlag) Type() string {
+	return "string"
+}
+
+func (f *FilterFlag) String() string {
+	return f.text
+}
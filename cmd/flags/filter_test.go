@@ -0,0 +1,24 @@
+//go:build unit
+
+package flags
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilterFlag(t *testing.T) {
+	var f FilterFlag
+
+	assert.Nil(t, f.Filter)
+	assert.Equal(t, "", f.String())
+
+	require.NoError(t, f.Set(`tenant == acme and lang in (en, de)`))
+	assert.Equal(t, `tenant == acme and lang in (en, de)`, f.String())
+	assert.True(t, f.Filter.Match(map[string]any{"tenant": "acme", "lang": "de"}))
+
+	assert.ErrorContains(t, f.Set(`tenant ==`), "expected a value")
+	assert.Equal(t, `tenant == acme and lang in (en, de)`, f.String(), "a bad value does not replace the filter")
+}
@@ -0,0 +1,149 @@
+package flags
+
+import (
+	"asvec/cmd/jsonpath"
+	"asvec/cmd/writers"
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+)
+
+type OutputFlag string
+
+const (
+	OutputTable OutputFlag = "table"
+	OutputCSV   OutputFlag = "csv"
+	OutputJSON  OutputFlag = "json"
+	OutputYAML  OutputFlag = "yaml"
+	OutputJSONL OutputFlag = "jsonl"
+	OutputWide  OutputFlag = "wide"
+
+	// Template outputs are followed by "=" and the template, e.g.
+	// "jsonpath={.name}". The -file variants are followed by a path to a file
+	// containing the template and are stored as their non-file equivalent.
+	OutputGoTemplate     OutputFlag = "go-template"
+	OutputGoTemplateFile OutputFlag = "go-template-file"
+	OutputJSONPath       OutputFlag = "jsonpath"
+	OutputJSONPathFile   OutputFlag = "jsonpath-file"
+)
+
+var outputSet = map[OutputFlag]struct{}{
+	OutputTable: {},
+	OutputCSV:   {},
+	OutputJSON:  {},
+	OutputYAML:  {},
+	OutputJSONL: {},
+	OutputWide:  {},
+}
+
+func (f *OutputFlag) Set(val string) error {
+	if kind, text, ok := strings.Cut(val, "="); ok {
+		return f.setTemplate(OutputFlag(strings.ToLower(kind)), text)
+	}
+
+	output := OutputFlag(strings.ToLower(val))
+	if _, ok := outputSet[output]; ok {
+		*f = output
+		return nil
+	}
+
+	return fmt.Errorf("unrecognized output format, valid values: %s", strings.Join(OutputEnum(), ", "))
+}
+
+// setTemplate validates a template so that errors are reported while flags
+// are parsed rather than after a command has run.
+func (f *OutputFlag) setTemplate(kind OutputFlag, text string) error {
+	switch kind {
+	case OutputGoTemplateFile, OutputJSONPathFile:
+		content, err := os.ReadFile(text)
+		if err != nil {
+			return fmt.Errorf("unable to read %s: %w", kind, err)
+		}
+
+		kind = OutputFlag(strings.TrimSuffix(string(kind), "-file"))
+		text = string(content)
+	case OutputGoTemplate, OutputJSONPath:
+	default:
+		return fmt.Errorf("unrecognized output format, valid values: %s", strings.Join(OutputEnum(), ", "))
+	}
+
+	if text == "" {
+		return fmt.Errorf("%s template must not be empty", kind)
+	}
+
+	var err error
+
+	if kind == OutputGoTemplate {
+		_, err = template.New(string(kind)).Parse(text)
+	} else {
+		_, err = jsonpath.Parse(text)
+	}
+
+	if err != nil {
+		return fmt.Errorf("invalid %s template: %w", kind, err)
+	}
+
+	*f = kind + "=" + OutputFlag(text)
+
+	return nil
+}
+
+func (f *OutputFlag) Type() string {
+	return FlagTypeEnum
+}
+
+func (f *OutputFlag) String() string {
+	return string(*f)
+}
+
+// RenderFormat returns the writers render format used to render tables for
+// this output format.
+func (f *OutputFlag) RenderFormat() int {
+	if *f == OutputCSV {
+		return writers.RenderFormatCSV
+	}
+
+	return writers.RenderFormatTable
+}
+
+// IsStructured returns true if the output is machine readable JSON or YAML,
+// or a template applied to the JSON, rather than a table.
+func (f *OutputFlag) IsStructured() bool {
+	if _, _, ok := f.Template(); ok {
+		return true
+	}
+
+	return *f == OutputJSON || *f == OutputYAML || *f == OutputJSONL
+}
+
+// Template returns the kind, OutputGoTemplate or OutputJSONPath, and text of
+// a template output.
+func (f *OutputFlag) Template() (kind OutputFlag, text string, ok bool) {
+	k, text, ok := strings.Cut(string(*f), "=")
+	if !ok {
+		return "", "", false
+	}
+
+	return OutputFlag(k), text, true
+}
+
+// IsWide returns true if tables should include all available detail.
+func (f *OutputFlag) IsWide() bool {
+	return *f == OutputWide
+}
+
+func OutputEnum() []string {
+	return []string{
+		string(OutputTable),
+		string(OutputCSV),
+		string(OutputJSON),
+		string(OutputYAML),
+		string(OutputJSONL),
+		string(OutputWide),
+		string(OutputGoTemplate) + "=...",
+		string(OutputGoTemplateFile) + "=...",
+		string(OutputJSONPath) + "=...",
+		string(OutputJSONPathFile) + "=...",
+	}
+}
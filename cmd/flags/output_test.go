@@ -0,0 +1,87 @@
+//go:build unit
+
+package flags
+
+import (
+	"asvec/cmd/writers"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOutputFlag(t *testing.T) {
+	f := OutputTable
+	assert.Equal(t, writers.RenderFormatTable, f.RenderFormat())
+	assert.False(t, f.IsStructured())
+
+	err := f.Set("CSV")
+	assert.NoError(t, err)
+	assert.Equal(t, OutputCSV, f)
+	assert.Equal(t, writers.RenderFormatCSV, f.RenderFormat())
+	assert.False(t, f.IsStructured())
+
+	for _, structured := range []OutputFlag{OutputJSON, OutputYAML, OutputJSONL} {
+		err = f.Set(string(structured))
+		assert.NoError(t, err)
+		assert.Equal(t, structured, f)
+		assert.True(t, f.IsStructured())
+	}
+
+	err = f.Set("wide")
+	assert.NoError(t, err)
+	assert.Equal(t, writers.RenderFormatTable, f.RenderFormat())
+	assert.True(t, f.IsWide())
+	assert.False(t, f.IsStructured())
+
+	err = f.Set("xml")
+	assert.Error(t, err)
+}
+
+func TestOutputFlagTemplates(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "template.txt")
+	require.NoError(t, os.WriteFile(file, []byte("{{.name}}"), 0o600))
+
+	testCases := []struct {
+		val          string
+		expectedKind OutputFlag
+		expectedText string
+		err          bool
+	}{
+		{val: "go-template={{range .}}{{.username}}{{end}}", expectedKind: OutputGoTemplate, expectedText: "{{range .}}{{.username}}{{end}}"},
+		{val: "jsonpath={[*].username}", expectedKind: OutputJSONPath, expectedText: "{[*].username}"},
+		{val: "JSONPath={.a=b}", expectedKind: OutputJSONPath, expectedText: "{.a=b}"},
+		{val: "go-template-file=" + file, expectedKind: OutputGoTemplate, expectedText: "{{.name}}"},
+		{val: "go-template={{.name", err: true},
+		{val: "jsonpath={.name", err: true},
+		{val: "jsonpath=", err: true},
+		{val: "jsonpath-file=" + filepath.Join(dir, "missing"), err: true},
+		{val: "xml={.name}", err: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.val, func(t *testing.T) {
+			f := OutputTable
+
+			err := f.Set(tc.val)
+			if tc.err {
+				assert.Error(t, err)
+				assert.Equal(t, OutputTable, f)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.True(t, f.IsStructured())
+			assert.False(t, f.IsWide())
+
+			kind, text, ok := f.Template()
+			assert.True(t, ok)
+			assert.Equal(t, tc.expectedKind, kind)
+			assert.Equal(t, tc.expectedText, text)
+		})
+	}
+}
@@ -0,0 +1,156 @@
+package flags
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"github.com/aerospike/avs-client-go/protos"
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v3"
+)
+
+// RecordKeyFlags identifies a single record using a namespace, an optional
+// set, and either a string or an integer key.
+type RecordKeyFlags struct {
+	Namespace string
+	Set       StringOptionalFlag
+	KeyString StringOptionalFlag
+	KeyInt    IntOptionalFlag
+}
+
+func NewRecordKeyFlags() *RecordKeyFlags {
+	return &RecordKeyFlags{
+		Set:       StringOptionalFlag{},
+		KeyString: StringOptionalFlag{},
+		KeyInt:    IntOptionalFlag{},
+	}
+}
+
+func (cf *RecordKeyFlags) NewFlagSet() *pflag.FlagSet {
+	flagSet := &pflag.FlagSet{}
+	flagSet.StringVarP(&cf.Namespace, Namespace, NamespaceShort, "", "The namespace of the record.") //nolint:lll // For readability
+	flagSet.VarP(&cf.Set, Set, SetShort, "The set of the record.")                                   //nolint:lll // For readability
+	flagSet.VarP(&cf.KeyString, KeyString, KeyStrShort, "The string key of the record.")             //nolint:lll // For readability
+	flagSet.VarP(&cf.KeyInt, KeyInt, KeyIntShort, "The integer key of the record.")                  //nolint:lll // For readability
+
+	return flagSet
+}
+
+func (cf *RecordKeyFlags) NewSLogAttr() []any {
+	return []any{
+		slog.String(Namespace, cf.Namespace),
+		slog.Any(Set, cf.Set.Val),
+		slog.Any(KeyString, cf.KeyString.Val),
+		slog.Any(KeyInt, cf.KeyInt.Val),
+	}
+}
+
+// Key returns the user provided key as either a string or an int64.
+func (cf *RecordKeyFlags) Key() (any, error) {
+	switch {
+	case cf.KeyString.Val != nil && cf.KeyInt.Val != nil:
+		return nil, fmt.Errorf("only --%s or --%s allowed", KeyString, KeyInt)
+	case cf.KeyString.Val != nil:
+		return *cf.KeyString.Val, nil
+	case cf.KeyInt.Val != nil:
+		return *cf.KeyInt.Val, nil
+	default:
+		return nil, fmt.Errorf("one of --%s or --%s is required", KeyString, KeyInt)
+	}
+}
+
+// RecordDataFlag parses a JSON or YAML object into record data. Because JSON
+// is a subset of YAML both are parsed with the same decoder. Integers are
+// parsed as int and decimals as float64.
+type RecordDataFlag struct {
+	Val map[string]any
+}
+
+func (f *RecordDataFlag) Set(val string) error {
+	data, err := ParseRecordData([]byte(val))
+	if err != nil {
+		return err
+	}
+
+	f.Val = data
+
+	return nil
+}
+
+func (f *RecordDataFlag) Type() string {
+	return "json|yaml"
+}
+
+func (f *RecordDataFlag) String() string {
+	if f.Val == nil {
+		return optionalEmptyString
+	}
+
+	b, err := json.Marshal(f.Val)
+	if err != nil {
+		return fmt.Sprintf("%v", f.Val)
+	}
+
+	return string(b)
+}
+
+// ParseRecordData parses a JSON or YAML document containing a single object
+// into record data.
+func ParseRecordData(b []byte) (map[string]any, error) {
+	data := map[string]any{}
+
+	err := yaml.Unmarshal(b, &data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse record data: %w", err)
+	}
+
+	return data, nil
+}
+
+// The subset of protos.WriteType that is exposed by the AVS client.
+var writeTypeSet = map[string]protos.WriteType{
+	protos.WriteType_UPSERT.String():      protos.WriteType_UPSERT,
+	protos.WriteType_INSERT_ONLY.String(): protos.WriteType_INSERT_ONLY,
+	protos.WriteType_UPDATE_ONLY.String(): protos.WriteType_UPDATE_ONLY,
+}
+
+// WriteTypeFlag determines how a record is written. Valid values are
+// UPSERT, INSERT_ONLY, and UPDATE_ONLY.
+type WriteTypeFlag string
+
+func (f *WriteTypeFlag) Set(val string) error {
+	val = strings.ToUpper(val)
+	if _, ok := writeTypeSet[val]; ok {
+		*f = WriteTypeFlag(val)
+		return nil
+	}
+
+	return fmt.Errorf("unrecognized write type")
+}
+
+func (f *WriteTypeFlag) Type() string {
+	return FlagTypeEnum
+}
+
+func (f *WriteTypeFlag) String() string {
+	return string(*f)
+}
+
+func (f *WriteTypeFlag) WriteType() protos.WriteType {
+	return writeTypeSet[f.String()]
+}
+
+func WriteTypeEnum() []string {
+	names := []string{}
+
+	for key := range writeTypeSet {
+		names = append(names, key)
+	}
+
+	slices.Sort(names)
+
+	return names
+}
@@ -0,0 +1,84 @@
+//go:build unit
+
+package flags
+
+import (
+	"testing"
+
+	"github.com/aerospike/avs-client-go/protos"
+	"github.com/stretchr/testify/suite"
+)
+
+type RecordFlagSuite struct {
+	suite.Suite
+}
+
+func TestRecordFlagSuite(t *testing.T) {
+	suite.Run(t, new(RecordFlagSuite))
+}
+
+func (suite *RecordFlagSuite) TestRecordKeyFlagsKey() {
+	f := NewRecordKeyFlags()
+
+	_, err := f.Key()
+	suite.Error(err)
+
+	err = f.KeyString.Set("foo")
+	suite.NoError(err)
+
+	key, err := f.Key()
+	suite.NoError(err)
+	suite.Equal("foo", key)
+
+	f = NewRecordKeyFlags()
+	err = f.KeyInt.Set("10")
+	suite.NoError(err)
+
+	key, err = f.Key()
+	suite.NoError(err)
+	suite.Equal(int64(10), key)
+
+	err = f.KeyString.Set("foo")
+	suite.NoError(err)
+
+	_, err = f.Key()
+	suite.Error(err)
+}
+
+func (suite *RecordFlagSuite) TestRecordDataFlag() {
+	f := RecordDataFlag{}
+
+	err := f.Set(`{"str": "foo", "int": 1, "float": 1.5, "list": [1, 2], "map": {"a": true}}`)
+	suite.NoError(err)
+	suite.Equal(map[string]any{
+		"str":   "foo",
+		"int":   1,
+		"float": 1.5,
+		"list":  []any{1, 2},
+		"map":   map[string]any{"a": true},
+	}, f.Val)
+
+	err = f.Set("str: foo\nint: 1\n")
+	suite.NoError(err)
+	suite.Equal(map[string]any{
+		"str": "foo",
+		"int": 1,
+	}, f.Val)
+
+	err = f.Set("[1, 2]")
+	suite.Error(err)
+}
+
+func (suite *RecordFlagSuite) TestWriteTypeFlag() {
+	f := WriteTypeFlag("")
+
+	err := f.Set("insert_only")
+	suite.NoError(err)
+	suite.Equal(protos.WriteType_INSERT_ONLY, f.WriteType())
+	suite.Equal("INSERT_ONLY", f.String())
+
+	err = f.Set("REPLACE")
+	suite.Error(err)
+
+	suite.Equal([]string{"INSERT_ONLY", "UPDATE_ONLY", "UPSERT"}, WriteTypeEnum())
+}
@@ -0,0 +1,41 @@
+package flags
+
+import (
+	"fmt"
+	"strings"
+)
+
+// RerankExact re-ranks search results by the distance recomputed from each
+// neighbor's stored vector.
+const RerankExact = "exact"
+
+// RerankFlag is how search results are re-ranked. An empty value means the
+// results are returned in the order of the index.
+type RerankFlag string
+
+func (f *RerankFlag) Set(val string) error {
+	val = strings.ToLower(val)
+	if val != RerankExact {
+		return fmt.Errorf("unrecognized re-rank method, valid values: %s", strings.Join(RerankEnum(), ", "))
+	}
+
+	*f = RerankFlag(val)
+
+	return nil
+}
+
+func (f *RerankFlag) Type() string {
+	return FlagTypeEnum
+}
+
+func (f *RerankFlag) String() string {
+	return string(*f)
+}
+
+func (f *RerankFlag) IsExact() bool {
+	return *f == RerankExact
+}
+
+func RerankEnum() []string {
+	return []string{RerankExact}
+}
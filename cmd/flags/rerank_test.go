@@ -0,0 +1,19 @@
+//go:build unit
+
+package flags
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRerankFlag(t *testing.T) {
+	var f RerankFlag
+
+	assert.False(t, f.IsExact())
+	assert.NoError(t, f.Set("EXACT"))
+	assert.True(t, f.IsExact())
+	assert.Equal(t, "exact", f.String())
+	assert.EqualError(t, f.Set("approximate"), "unrecognized re-rank method, valid values: exact")
+}
@@ -1,27 +1,168 @@
 package flags
 
 import (
+	"asvec/cmd/records"
 	"asvec/cmd/writers"
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
 	"fmt"
+	"io"
+	"math"
+	"os"
 	"strconv"
 	"strings"
 )
 
+const (
+	// VectorStdin reads a vector from stdin.
+	VectorStdin = "-"
+	// VectorFilePrefix reads a vector from the file named after it.
+	VectorFilePrefix = "@"
+	// VectorBase64Prefix decodes the base64 little-endian float32s after it.
+	VectorBase64Prefix = "b64:"
+)
+
+// vectorStdin is read by --vector -. Tests replace it.
+var vectorStdin io.Reader = os.Stdin
+
 // A cobra PFlag to parse and store a vector of floats or booleans.
 type VectorFlag struct {
 	FloatSlice []float32
 	BoolSlice  []bool
 }
 
-// NewVectorFlag returns a new VectorFlag. It parses either a bool or float
-// array into the appropriate type. Boolean vectors look like [true,false,0,1].
-// Anything else is parsed as a float [1.0,1,2,3,4.123]
+// Set parses a vector given as a list, a file, stdin, or base64. Long
+// vectors, e.g. copied from a notebook, exceed the shell's argument limit so
+// can be read with:
+//
+//   - @file to read a .npy, .fvecs, or .bvecs file holding one vector, or a
+//     text file holding a vector in any of the formats below.
+//   - - to read the same from stdin.
+//   - b64: followed by base64 little-endian float32s, e.g. from
+//     base64.b64encode(v.astype("<f4").tobytes()).
 func (v *VectorFlag) Set(val string) error {
+	switch {
+	case val == VectorStdin:
+		data, err := io.ReadAll(vectorStdin)
+		if err != nil {
+			return fmt.Errorf("failed to read vector from stdin: %w", err)
+		}
+
+		if records.IsNPY(data) {
+			return v.setVector(records.ReadVector(bytes.NewReader(data), records.FormatNPY))
+		}
+
+		return v.parse(string(data))
+	case strings.HasPrefix(val, VectorFilePrefix):
+		name := strings.TrimPrefix(val, VectorFilePrefix)
+
+		err := v.readFile(name)
+		if err != nil {
+			return fmt.Errorf("failed to read vector from %s: %w", name, err)
+		}
+
+		return nil
+	default:
+		return v.parse(val)
+	}
+}
+
+// readFile reads a vector from a binary file of a single vector or a text
+// file.
+func (v *VectorFlag) readFile(name string) error {
+	format, err := records.FormatFromFilename(name)
+	if err == nil && (format == records.FormatNPY || format == records.FormatFvecs || format == records.FormatBvecs) {
+		f, err := os.Open(name)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		return v.setVector(records.ReadVector(f, format))
+	}
+
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return err
+	}
+
+	return v.parse(string(data))
+}
+
+func (v *VectorFlag) setVector(vector any, err error) error {
+	if err != nil {
+		return err
+	}
+
+	switch vec := vector.(type) {
+	case []float32:
+		v.FloatSlice = vec
+	case []bool:
+		v.BoolSlice = vec
+	default:
+		return fmt.Errorf("unsupported vector type %T", vector)
+	}
+
+	return nil
+}
+
+// parse parses base64 after VectorBase64Prefix or a list.
+func (v *VectorFlag) parse(val string) error {
+	val = strings.TrimSpace(val)
+
+	if strings.HasPrefix(val, VectorBase64Prefix) {
+		vector, err := decodeBase64Vector(strings.TrimPrefix(val, VectorBase64Prefix))
+		if err != nil {
+			return err
+		}
+
+		v.FloatSlice = vector
+
+		return nil
+	}
+
+	return v.parseList(val)
+}
+
+// decodeBase64Vector decodes base64 little-endian float32s. Line breaks are
+// ignored.
+func decodeBase64Vector(val string) ([]float32, error) {
+	b, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(val), ""))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode base64 vector: %w", err)
+	}
+
+	if len(b) == 0 {
+		return nil, fmt.Errorf("empty vector not allowed")
+	}
+
+	if len(b)%4 != 0 {
+		return nil, fmt.Errorf("base64 vector has %d bytes which is not a multiple of 4 bytes per float32", len(b))
+	}
+
+	vector := make([]float32, len(b)/4)
+	for i := range vector {
+		vector[i] = math.Float32frombits(binary.LittleEndian.Uint32(b[i*4:]))
+	}
+
+	return vector, nil
+}
+
+// parseList parses either a bool or float list into the appropriate type.
+// Boolean vectors look like [true,false,0,1]. Anything else is parsed as a
+// float [1.0,1,2,3,4.123]. Elements are separated by commas or, as printed
+// by NumPy, by whitespace.
+func (v *VectorFlag) parseList(val string) error {
 	val = strings.Trim(val, "[]")
-	val = strings.ReplaceAll(val, " ", "")
-	val = strings.ReplaceAll(val, "\n", "")
 
-	ss := strings.Split(val, ",")
+	var ss []string
+
+	if fields := strings.Fields(val); !strings.Contains(val, ",") && len(fields) > 1 {
+		ss = fields
+	} else {
+		ss = strings.Split(strings.Join(fields, ""), ",")
+	}
 
 	if len(ss) == 0 {
 		return fmt.Errorf("empty vector not allowed")
@@ -90,3 +231,12 @@ func (v *VectorFlag) String() string {
 func (v *VectorFlag) IsSet() bool {
 	return v.BoolSlice != nil || v.FloatSlice != nil
 }
+
+// Vector returns the parsed vector, a []float32 or []bool.
+func (v *VectorFlag) Vector() any {
+	if v.FloatSlice != nil {
+		return v.FloatSlice
+	}
+
+	return v.BoolSlice
+}
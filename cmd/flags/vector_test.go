@@ -3,8 +3,14 @@
 package flags
 
 import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
 	"fmt"
+	"os"
+	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -89,3 +95,144 @@ func TestVectorFlag_Type(t *testing.T) {
 		t.Errorf("Expected type %s, got %s", expectedType, flag.Type())
 	}
 }
+
+func TestVectorFlag_Vector(t *testing.T) {
+	var flag1 VectorFlag
+	flag1.Set("[0.5,1]")
+
+	if !reflect.DeepEqual(flag1.Vector(), []float32{0.5, 1}) {
+		t.Errorf("Expected vector %v, got %v", []float32{0.5, 1}, flag1.Vector())
+	}
+
+	var flag2 VectorFlag
+	flag2.Set("[1,0]")
+
+	if !reflect.DeepEqual(flag2.Vector(), []bool{true, false}) {
+		t.Errorf("Expected vector %v, got %v", []bool{true, false}, flag2.Vector())
+	}
+}
+
+func TestVectorFlag_SetInputs(t *testing.T) {
+	dir := t.TempDir()
+
+	float32Bytes := func(v []float32) []byte {
+		buf := &bytes.Buffer{}
+		_ = binary.Write(buf, binary.LittleEndian, v)
+
+		return buf.Bytes()
+	}
+
+	writeFile := func(name string, data []byte) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, data, 0o600); err != nil {
+			t.Fatal(err)
+		}
+
+		return path
+	}
+
+	header := "{'descr': '<f4', 'fortran_order': False, 'shape': (3,), }"
+	header += strings.Repeat(" ", 128-10-len(header)-1) + "\n"
+	npy := append([]byte("\x93NUMPY\x01\x00"), byte(len(header)), 0)
+	npy = append(append(npy, header...), float32Bytes([]float32{0.5, 1, 2})...)
+
+	fvecs := append(binary.LittleEndian.AppendUint32(nil, 3), float32Bytes([]float32{0.5, 1, 2})...)
+
+	testCases := []struct {
+		name                 string
+		testVector           string
+		stdin                string
+		expectedBoolSlice    []bool
+		expectedFloat32Slice []float32
+		expectedError        string
+	}{
+		{
+			name:                 "JSON file",
+			testVector:           "@" + writeFile("vector.json", []byte("[0.5,\n 1.0,\n 2.0]\n")),
+			expectedFloat32Slice: []float32{0.5, 1, 2},
+		},
+		{
+			name:                 "NumPy printed file",
+			testVector:           "@" + writeFile("vector.txt", []byte("[0.5 1.  2. ]\n")),
+			expectedFloat32Slice: []float32{0.5, 1, 2},
+		},
+		{
+			name:                 "npy file",
+			testVector:           "@" + writeFile("vector.npy", npy),
+			expectedFloat32Slice: []float32{0.5, 1, 2},
+		},
+		{
+			name:                 "fvecs file",
+			testVector:           "@" + writeFile("vector.fvecs", fvecs),
+			expectedFloat32Slice: []float32{0.5, 1, 2},
+		},
+		{
+			name:          "missing file",
+			testVector:    "@" + filepath.Join(dir, "missing.json"),
+			expectedError: "failed to read vector from " + filepath.Join(dir, "missing.json"),
+		},
+		{
+			name:              "stdin",
+			testVector:        "-",
+			stdin:             "[1,0,1]\n",
+			expectedBoolSlice: []bool{true, false, true},
+		},
+		{
+			name:                 "npy stdin",
+			testVector:           "-",
+			stdin:                string(npy),
+			expectedFloat32Slice: []float32{0.5, 1, 2},
+		},
+		{
+			name:                 "base64",
+			testVector:           "b64:" + base64.StdEncoding.EncodeToString(float32Bytes([]float32{0.5, 1, 2})),
+			expectedFloat32Slice: []float32{0.5, 1, 2},
+		},
+		{
+			name:                 "base64 stdin",
+			testVector:           "-",
+			stdin:                "b64:" + base64.StdEncoding.EncodeToString(float32Bytes([]float32{-1})) + "\n",
+			expectedFloat32Slice: []float32{-1},
+		},
+		{
+			name:          "base64 wrong length",
+			testVector:    "b64:" + base64.StdEncoding.EncodeToString([]byte{1, 2, 3}),
+			expectedError: "base64 vector has 3 bytes which is not a multiple of 4 bytes per float32",
+		},
+		{
+			name:          "invalid base64",
+			testVector:    "b64:!",
+			expectedError: "failed to decode base64 vector",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			vectorStdin = strings.NewReader(tc.stdin)
+			defer func() { vectorStdin = os.Stdin }()
+
+			var flag VectorFlag
+
+			err := flag.Set(tc.testVector)
+			if tc.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.expectedError) {
+					t.Errorf("Expected error containing %q, got %v", tc.expectedError, err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Unexpected error %v", err)
+			}
+
+			if !reflect.DeepEqual(flag.FloatSlice, tc.expectedFloat32Slice) {
+				t.Errorf("Expected slice %v, got %v", tc.expectedFloat32Slice, flag.FloatSlice)
+			}
+
+			if !reflect.DeepEqual(flag.BoolSlice, tc.expectedBoolSlice) {
+				t.Errorf("Expected slice %v, got %v", tc.expectedBoolSlice, flag.BoolSlice)
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package flags
+
+import (
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+const (
+	WaitReady         = "ready"
+	WaitMerged        = "merged"
+	WaitUnmergedBelow = "unmerged-below"
+
+	DefaultWaitTimeout = 30 * time.Minute
+)
+
+// WaitConditionFlag is the index state to wait for. It is one of "ready",
+// "merged", or "unmerged-below=N".
+type WaitConditionFlag struct {
+	Condition string
+	Threshold int64
+}
+
+func (f *WaitConditionFlag) Set(val string) error {
+	name, threshold, hasThreshold := strings.Cut(strings.ToLower(val), "=")
+
+	switch name {
+	case WaitReady, WaitMerged:
+		if hasThreshold {
+			return fmt.Errorf("%s does not accept a value", name)
+		}
+
+		*f = WaitConditionFlag{Condition: name}
+
+		return nil
+	case WaitUnmergedBelow:
+		n, err := strconv.ParseInt(threshold, 10, 64)
+		if err != nil || n <= 0 {
+			return fmt.Errorf("%s requires a positive record count, e.g. %s=1000", name, name)
+		}
+
+		*f = WaitConditionFlag{Condition: name, Threshold: n}
+
+		return nil
+	default:
+		return fmt.Errorf("unrecognized wait condition, valid values: %s", strings.Join(WaitConditionEnum(), ", "))
+	}
+}
+
+func (f *WaitConditionFlag) Type() string {
+	return FlagTypeEnum
+}
+
+func (f *WaitConditionFlag) String() string {
+	if f.Condition == WaitUnmergedBelow {
+		return fmt.Sprintf("%s=%d", f.Condition, f.Threshold)
+	}
+
+	return f.Condition
+}
+
+func WaitConditionEnum() []string {
+	return []string{WaitReady, WaitMerged, WaitUnmergedBelow + "=N"}
+}
+
+// WaitFlags are the flags used by commands which can optionally wait for an
+// index to reach a state after they complete.
+type WaitFlags struct {
+	Wait    bool
+	Until   WaitConditionFlag
+	Timeout time.Duration
+}
+
+func NewWaitFlags() *WaitFlags {
+	return &WaitFlags{
+		Until: WaitConditionFlag{Condition: WaitReady},
+	}
+}
+
+func (wf *WaitFlags) NewFlagSet() *pflag.FlagSet {
+	flagSet := &pflag.FlagSet{}
+	flagSet.BoolVar(&wf.Wait, Wait, false, fmt.Sprintf("Wait for the index to reach the --%s condition before returning.", WaitUntil)) //nolint:lll // For readability
+	flagSet.AddFlagSet(wf.NewUntilFlagSet())
+
+	return flagSet
+}
+
+// NewUntilFlagSet returns the condition and timeout flags without --wait.
+func (wf *WaitFlags) NewUntilFlagSet() *pflag.FlagSet {
+	flagSet := &pflag.FlagSet{}
+	flagSet.Var(&wf.Until, WaitUntil, fmt.Sprintf("The index condition to wait for. Valid values: %s", strings.Join(WaitConditionEnum(), ", "))) //nolint:lll // For readability
+	flagSet.DurationVar(&wf.Timeout, WaitTimeout, DefaultWaitTimeout, "The maximum amount of time to wait for the index.")                       //nolint:lll // For readability
+
+	return flagSet
+}
+
+func (wf *WaitFlags) NewSLogAttr() []any {
+	return []any{
+		slog.Bool(Wait, wf.Wait),
+		slog.String(WaitUntil, wf.Until.String()),
+		slog.Duration(WaitTimeout, wf.Timeout),
+	}
+}
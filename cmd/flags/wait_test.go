@@ -0,0 +1,46 @@
+//go:build unit
+
+package flags
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWaitConditionFlag(t *testing.T) {
+	testCases := []struct {
+		val      string
+		expected WaitConditionFlag
+		err      bool
+	}{
+		{val: "ready", expected: WaitConditionFlag{Condition: WaitReady}},
+		{val: "MERGED", expected: WaitConditionFlag{Condition: WaitMerged}},
+		{val: "unmerged-below=1000", expected: WaitConditionFlag{Condition: WaitUnmergedBelow, Threshold: 1000}},
+		{val: "unmerged-below", err: true},
+		{val: "unmerged-below=0", err: true},
+		{val: "unmerged-below=abc", err: true},
+		{val: "ready=1", err: true},
+		{val: "done", err: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.val, func(t *testing.T) {
+			f := WaitConditionFlag{Condition: WaitReady}
+
+			err := f.Set(tc.val)
+			if tc.err {
+				assert.Error(t, err)
+				assert.Equal(t, WaitConditionFlag{Condition: WaitReady}, f)
+
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, f)
+		})
+	}
+
+	f := WaitConditionFlag{Condition: WaitUnmergedBelow, Threshold: 10}
+	assert.Equal(t, "unmerged-below=10", f.String())
+}
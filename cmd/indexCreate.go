@@ -45,6 +45,7 @@ var indexCreateFlags = &struct {
 	hnswMerge                flags.MergeFlags
 	hnswVectorIntegrityCheck flags.BoolOptionalFlag
 	indexMode                flags.IndexModeOptionalFlag
+	wait                     flags.WaitFlags
 }{
 	clientFlags:              rootFlags.clientFlags,
 	set:                      flags.StringOptionalFlag{},
@@ -61,6 +62,7 @@ var indexCreateFlags = &struct {
 	hnswMerge:                *flags.NewHnswMergeFlags(),
 	hnswVectorIntegrityCheck: flags.BoolOptionalFlag{},
 	indexMode:                flags.IndexModeOptionalFlag{},
+	wait:                     *flags.NewWaitFlags(),
 }
 
 func newIndexCreateFlagSet() *pflag.FlagSet {
@@ -87,6 +89,7 @@ func newIndexCreateFlagSet() *pflag.FlagSet {
 	flagSet.AddFlagSet(indexCreateFlags.hnswHealer.NewFlagSet())
 	flagSet.AddFlagSet(indexCreateFlags.hnswMerge.NewFlagSet())
 	flagSet.Var(&indexCreateFlags.indexMode, flags.IndexMode, fmt.Sprintf("The index mode. Valid values: %s", strings.Join(flags.IndexModeFlagEnum(), ", "))) //nolint:lll // For readability
+	flagSet.AddFlagSet(indexCreateFlags.wait.NewFlagSet())
 
 	// For backwards compatibility
 	flagSet.Var(&indexCreateFlags.set, "sets", "The sets for the index.")
@@ -223,6 +226,7 @@ asvec index create -i myindex -n test -s testset -d 256 -m COSINE --%s vector \
 			debugFlags = append(debugFlags, indexCreateFlags.hnswRecordCache.NewSLogAttr()...)
 			debugFlags = append(debugFlags, indexCreateFlags.hnswHealer.NewSLogAttr()...)
 			debugFlags = append(debugFlags, indexCreateFlags.hnswMerge.NewSLogAttr()...)
+			debugFlags = append(debugFlags, indexCreateFlags.wait.NewSLogAttr()...)
 			logger.Debug("parsed flags",
 				append(debugFlags,
 					slog.Bool(flags.Yes, indexCreateFlags.yes),
@@ -264,7 +268,7 @@ func runCreateIndexFromDef(client *avs.Client) error {
 		return nil
 	}
 
-	successful := 0
+	created := []*protos.IndexDefinition{}
 
 	for _, indexDef := range stdinIndexDefinitions.GetIndices() {
 		ctx, cancel := context.WithTimeout(context.Background(), indexCreateFlags.clientFlags.Timeout)
@@ -287,10 +291,27 @@ func runCreateIndexFromDef(client *avs.Client) error {
 				indexDef.SetFilter,
 			), indexDef.Id.Name)
 
-			successful++
+			created = append(created, indexDef)
 		}
 	}
 
+	if indexCreateFlags.wait.Wait {
+		for _, indexDef := range created {
+			err := waitForIndex(
+				client,
+				indexCreateFlags.clientFlags.Timeout,
+				indexDef.Id.Namespace,
+				indexDef.Id.Name,
+				&indexCreateFlags.wait,
+			)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	successful := len(created)
+
 	if successful == 0 {
 		err := fmt.Errorf("unable to create any new indexes")
 		logger.Error(err.Error())
@@ -396,6 +417,16 @@ func runCreateIndexFromFlags(client *avs.Client) error {
 
 	view.Printf("Successfully created index %s.%s", indexCreateFlags.namespace, indexCreateFlags.indexName)
 
+	if indexCreateFlags.wait.Wait {
+		return waitForIndex(
+			client,
+			indexCreateFlags.clientFlags.Timeout,
+			indexCreateFlags.namespace,
+			indexCreateFlags.indexName,
+			&indexCreateFlags.wait,
+		)
+	}
+
 	return nil
 }
 
@@ -0,0 +1,247 @@
+package cmd
+
+import (
+	"asvec/cmd/flags"
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/aerospike/avs-client-go/protos"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+//nolint:govet // Padding not a concern for a CLI
+var indexDiffFlags = &struct {
+	clientFlags        *flags.ClientFlags
+	compareClusterName string
+	inputFile          string
+	namespace          string
+	indexName          string
+}{
+	clientFlags: rootFlags.clientFlags,
+}
+
+func newIndexDiffFlagSet() *pflag.FlagSet {
+	flagSet := &pflag.FlagSet{}
+	flagSet.StringVar(&indexDiffFlags.compareClusterName, flags.CompareClusterName, "", fmt.Sprintf("A cluster name from your configuration file to compare with the cluster selected by --%s.", flags.ClusterName)) //nolint:lll // For readability
+	flagSet.StringVarP(&indexDiffFlags.inputFile, flags.InputFile, flags.InputFileShort, "", "A yaml file containing IndexDefinitions created using \"asvec index list --yaml\" to compare with the cluster.")       //nolint:lll // For readability
+	flagSet.StringVarP(&indexDiffFlags.namespace, flags.Namespace, flags.NamespaceShort, "", "Only compare indexes in this namespace.")                                                                              //nolint:lll // For readability
+	flagSet.StringVarP(&indexDiffFlags.indexName, flags.IndexName, flags.IndexNameShort, "", "Only compare indexes with this name.")                                                                                 //nolint:lll // For readability
+
+	return flagSet
+}
+
+// indexDiff is the difference between an index in two sources. Indexes which
+// exist in only one source have no field diffs.
+type indexDiff struct {
+	name      string
+	onlyLeft  bool
+	onlyRight bool
+	diffs     []fieldDiff
+}
+
+func (d *indexDiff) hasDrift() bool {
+	return d.onlyLeft || d.onlyRight || len(d.diffs) > 0
+}
+
+func newIndexDiffCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "diff",
+		Short: "A command for comparing index definitions",
+		Long: fmt.Sprintf(`A command for comparing the index definitions of two clusters, or of a
+cluster and a yaml file created using "asvec index list --yaml". Every field of
+the index definitions is compared, including the HNSW batching, caching, healer,
+and merge parameters. The command exits with a non-zero status when the
+definitions differ.
+
+For example:
+
+%s
+asvec index diff --%s staging --%s prod
+asvec index diff -f indexes.yaml
+			`, HelpTxtSetupEnv, flags.ClusterName, flags.CompareClusterName),
+		PreRunE: func(_ *cobra.Command, _ []string) error {
+			if (indexDiffFlags.compareClusterName == "") == (indexDiffFlags.inputFile == "") {
+				return fmt.Errorf("exactly one of --%s or --%s is required", flags.CompareClusterName, flags.InputFile)
+			}
+
+			return checkSeedsAndHost()
+		},
+		RunE: func(_ *cobra.Command, _ []string) error {
+			logger.Debug("parsed flags",
+				append(indexDiffFlags.clientFlags.NewSLogAttr(),
+					slog.String(flags.CompareClusterName, indexDiffFlags.compareClusterName),
+					slog.String(flags.InputFile, indexDiffFlags.inputFile),
+					slog.String(flags.Namespace, indexDiffFlags.namespace),
+					slog.String(flags.IndexName, indexDiffFlags.indexName),
+				)...,
+			)
+
+			left, err := listClusterIndexes(indexDiffFlags.clientFlags)
+			if err != nil {
+				return err
+			}
+
+			var right *protos.IndexDefinitionList
+
+			rightName := indexDiffFlags.inputFile
+
+			if indexDiffFlags.inputFile != "" {
+				right, err = readIndexDefinitionsFile(indexDiffFlags.inputFile)
+			} else {
+				rightName = indexDiffFlags.compareClusterName
+
+				var compareFlags *flags.ClientFlags
+
+				compareFlags, err = newClusterClientFlags(indexDiffFlags.compareClusterName, indexDiffFlags.clientFlags)
+				if err != nil {
+					logger.Error("failed to load cluster configuration", slog.Any("error", err))
+					return err
+				}
+
+				right, err = listClusterIndexes(compareFlags)
+			}
+
+			if err != nil {
+				return err
+			}
+
+			diffs := diffIndexes(
+				filterIndexes(left, indexDiffFlags.namespace, indexDiffFlags.indexName),
+				filterIndexes(right, indexDiffFlags.namespace, indexDiffFlags.indexName),
+			)
+
+			view.PrintIndexDiffs(diffs, rootFlags.clusterName, rightName)
+
+			drift := 0
+
+			for _, d := range diffs {
+				if d.hasDrift() {
+					drift++
+				}
+			}
+
+			if drift == 0 {
+				view.Printf("No differences found in %d indexes", len(diffs))
+				return nil
+			}
+
+			view.Warningf("%d of %d indexes differ", drift, len(diffs))
+
+			return nil
+		},
+	}
+}
+
+func listClusterIndexes(clientFlags *flags.ClientFlags) (*protos.IndexDefinitionList, error) {
+	client, err := createClientFromFlags(clientFlags)
+	if err != nil {
+		return nil, err
+	}
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), clientFlags.Timeout)
+	defer cancel()
+
+	indexes, err := client.IndexList(ctx, true)
+	if err != nil {
+		logger.Error("failed to list indexes", slog.Any("error", err))
+		return nil, err
+	}
+
+	logger.Debug("server index list", slog.String("response", indexes.String()))
+
+	return indexes, nil
+}
+
+func readIndexDefinitionsFile(file string) (*protos.IndexDefinitionList, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		logger.Error("failed to read index definitions file", slog.Any("error", err))
+		return nil, err
+	}
+
+	manifest, err := parseApplyManifest(data)
+	if err != nil {
+		logger.Error("failed to parse index definitions file", slog.Any("error", err))
+		return nil, err
+	}
+
+	return manifest.indexes, nil
+}
+
+func filterIndexes(indexes *protos.IndexDefinitionList, namespace, indexName string) []*protos.IndexDefinition {
+	filtered := []*protos.IndexDefinition{}
+
+	for _, index := range indexes.GetIndices() {
+		if namespace != "" && index.GetId().GetNamespace() != namespace {
+			continue
+		}
+
+		if indexName != "" && index.GetId().GetName() != indexName {
+			continue
+		}
+
+		filtered = append(filtered, index)
+	}
+
+	return filtered
+}
+
+// diffIndexes compares every field of the index definitions in left and right,
+// matching indexes by namespace and name. The result is sorted by name.
+func diffIndexes(left, right []*protos.IndexDefinition) []*indexDiff {
+	rightByName := map[string]*protos.IndexDefinition{}
+	for _, index := range right {
+		rightByName[indexFullName(index.GetId())] = index
+	}
+
+	diffs := []*indexDiff{}
+	seen := map[string]bool{}
+
+	for _, l := range left {
+		name := indexFullName(l.GetId())
+		seen[name] = true
+
+		r, ok := rightByName[name]
+		if !ok {
+			diffs = append(diffs, &indexDiff{name: name, onlyLeft: true})
+			continue
+		}
+
+		diff := &indexDiff{name: name}
+
+		for _, d := range diffMessages("", l.ProtoReflect(), r.ProtoReflect(), false) {
+			if d.field != "id" && !strings.HasPrefix(d.field, "id.") {
+				diff.diffs = append(diff.diffs, d)
+			}
+		}
+
+		diffs = append(diffs, diff)
+	}
+
+	for _, r := range right {
+		name := indexFullName(r.GetId())
+		if !seen[name] {
+			diffs = append(diffs, &indexDiff{name: name, onlyRight: true})
+		}
+	}
+
+	slices.SortFunc(diffs, func(a, b *indexDiff) int {
+		return strings.Compare(a.name, b.name)
+	})
+
+	return diffs
+}
+
+func init() {
+	indexDiffCmd := newIndexDiffCmd()
+	indexCmd.AddCommand(indexDiffCmd)
+
+	flagSet := newIndexDiffFlagSet()
+	indexDiffCmd.Flags().AddFlagSet(flagSet)
+}
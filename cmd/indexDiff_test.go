@@ -0,0 +1,137 @@
+//go:build unit
+
+package cmd
+
+import (
+	"asvec/cmd/flags"
+	"asvec/utils"
+	"bytes"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/aerospike/avs-client-go/protos"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newDiffTestIndex(ns, name string, ef, maxIndexRecords uint32) *protos.IndexDefinition {
+	return &protos.IndexDefinition{
+		Id:                   &protos.IndexId{Namespace: ns, Name: name},
+		Dimensions:           128,
+		VectorDistanceMetric: utils.Ptr(protos.VectorDistanceMetric_COSINE),
+		Field:                "vector",
+		Params: &protos.IndexDefinition_HnswParams{
+			HnswParams: &protos.HnswParams{
+				Ef: utils.Ptr(ef),
+				BatchingParams: &protos.HnswBatchingParams{
+					MaxIndexRecords: utils.Ptr(maxIndexRecords),
+				},
+			},
+		},
+	}
+}
+
+func TestDiffIndexes(t *testing.T) {
+	left := []*protos.IndexDefinition{
+		newDiffTestIndex("test", "same", 100, 1000),
+		newDiffTestIndex("test", "changed", 100, 1000),
+		newDiffTestIndex("test", "left", 100, 1000),
+	}
+
+	changed := newDiffTestIndex("test", "changed", 200, 1000)
+	changed.Labels = map[string]string{"env": "prod"}
+	changed.GetHnswParams().BatchingParams.MaxIndexRecords = nil
+
+	right := []*protos.IndexDefinition{
+		changed,
+		newDiffTestIndex("test", "same", 100, 1000),
+		newDiffTestIndex("test", "right", 100, 1000),
+	}
+
+	diffs := diffIndexes(left, right)
+
+	assert.Equal(t, []*indexDiff{
+		{
+			name: "test.changed",
+			diffs: []fieldDiff{
+				{field: "hnswParams.ef", old: "100", new: "200"},
+				{field: "hnswParams.batchingParams.maxIndexRecords", old: "1000", new: "<unset>"},
+				{field: "labels", old: "<unset>", new: "{env=prod}"},
+			},
+		},
+		{name: "test.left", onlyLeft: true},
+		{name: "test.right", onlyRight: true},
+		{name: "test.same"},
+	}, diffs)
+
+	assert.True(t, diffs[0].hasDrift())
+	assert.True(t, diffs[1].hasDrift())
+	assert.True(t, diffs[2].hasDrift())
+	assert.False(t, diffs[3].hasDrift())
+}
+
+func TestFilterIndexes(t *testing.T) {
+	indexes := &protos.IndexDefinitionList{
+		Indices: []*protos.IndexDefinition{
+			newDiffTestIndex("a", "x", 1, 1),
+			newDiffTestIndex("a", "y", 1, 1),
+			newDiffTestIndex("b", "x", 1, 1),
+		},
+	}
+
+	assert.Len(t, filterIndexes(indexes, "", ""), 3)
+	assert.Len(t, filterIndexes(indexes, "a", ""), 2)
+	assert.Len(t, filterIndexes(indexes, "", "x"), 2)
+	assert.Equal(t, indexes.Indices[2:], filterIndexes(indexes, "b", "x"))
+}
+
+func TestNewClusterClientFlags(t *testing.T) {
+	viper.Reset()
+	defer viper.Reset()
+
+	viper.Set("prod", map[string]any{
+		"seeds":       "1.1.1.1:5000,2.2.2.2:5000",
+		"credentials": "foo:bar",
+	})
+	viper.Set("bad", map[string]any{
+		"timeout": "forever",
+	})
+
+	base := flags.NewClientFlags()
+	base.Timeout = time.Minute
+
+	clientFlags, err := newClusterClientFlags("prod", base)
+	require.NoError(t, err)
+	assert.Len(t, clientFlags.Seeds.Seeds, 2)
+	assert.Equal(t, "foo", clientFlags.AuthCredentials.User.String())
+	assert.Equal(t, time.Minute, clientFlags.Timeout)
+
+	_, err = newClusterClientFlags("bad", base)
+	assert.Error(t, err)
+
+	_, err = newClusterClientFlags("missing", base)
+	assert.Error(t, err)
+}
+
+func TestViewPrintIndexDiffs(t *testing.T) {
+	diffs := []*indexDiff{
+		{name: "test.a", diffs: []fieldDiff{{field: "hnswParams.ef", old: "100", new: "200"}}},
+		{name: "test.b", onlyLeft: true},
+		{name: "test.c", onlyRight: true},
+		{name: "test.d"},
+	}
+
+	out := &bytes.Buffer{}
+	v := NewView(out, out, slog.Default())
+	v.DisableColor()
+
+	v.PrintIndexDiffs(diffs, "staging", "prod")
+
+	assert.Equal(t, `~ test.a (staging -> prod)
+    hnswParams.ef: 100 -> 200
+- test.b only in staging
++ test.c only in prod
+`, out.String())
+}
@@ -16,8 +16,10 @@ var indexGCFlags = &struct {
 	namespace   string
 	indexName   string
 	cutoffTime  flags.UnixTimestampFlag
+	wait        flags.WaitFlags
 }{
 	clientFlags: rootFlags.clientFlags,
+	wait:        *flags.NewWaitFlags(),
 }
 
 func newIndexGCFlagSet() *pflag.FlagSet {
@@ -25,6 +27,7 @@ func newIndexGCFlagSet() *pflag.FlagSet {
 	flagSet.StringVarP(&indexGCFlags.namespace, flags.Namespace, flags.NamespaceShort, "", "The namespace for the index.") //nolint:lll // For readability
 	flagSet.StringVarP(&indexGCFlags.indexName, flags.IndexName, flags.IndexNameShort, "", "The name of the index.")       //nolint:lll // For readability
 	flagSet.VarP(&indexGCFlags.cutoffTime, flags.CutoffTime, "c", "The cutoff time for gc.")                               //nolint:lll // For readability
+	flagSet.AddFlagSet(indexGCFlags.wait.NewFlagSet())
 
 	return flagSet
 }
@@ -55,6 +58,7 @@ asvec index gc -i myindex -n test -c 1720744696
 		},
 		RunE: func(_ *cobra.Command, _ []string) error {
 			debugFlags := indexGCFlags.clientFlags.NewSLogAttr()
+			debugFlags = append(debugFlags, indexGCFlags.wait.NewSLogAttr()...)
 			logger.Debug("parsed flags",
 				append(debugFlags,
 					slog.String(flags.Namespace, indexGCFlags.namespace),
@@ -89,6 +93,16 @@ asvec index gc -i myindex -n test -c 1720744696
 				indexGCFlags.indexName,
 			)
 
+			if indexGCFlags.wait.Wait {
+				return waitForIndex(
+					client,
+					indexGCFlags.clientFlags.Timeout,
+					indexGCFlags.namespace,
+					indexGCFlags.indexName,
+					&indexGCFlags.wait,
+				)
+			}
+
 			return nil
 		},
 	}
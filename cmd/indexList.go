@@ -2,12 +2,16 @@ package cmd
 
 import (
 	"asvec/cmd/flags"
+	"asvec/cmd/writers"
 	"context"
 	"encoding/json"
 	"fmt"
 	"log/slog"
+	"strings"
 	"sync"
+	"time"
 
+	avs "github.com/aerospike/avs-client-go"
 	"github.com/aerospike/avs-client-go/protos"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
@@ -19,27 +23,32 @@ import (
 var indexListFlags = &struct {
 	clientFlags *flags.ClientFlags
 	verbose     bool
-	format      int // For testing. Hidden
+	output      flags.OutputFlag
 	yaml        bool
+	failIf      flags.FailIfFlag
 }{
 	clientFlags: rootFlags.clientFlags,
+	output:      flags.OutputTable,
 }
 
 func newIndexListFlagSet() *pflag.FlagSet {
 	flagSet := &pflag.FlagSet{}
-	flagSet.BoolVarP(&indexListFlags.verbose, flags.Verbose, "v", false, "Print detailed index information.")                                                    //nolint:lll // For readability
-	flagSet.BoolVar(&indexListFlags.yaml, flags.Yaml, false, "Output indexes in yaml format to later be used with \"asvec index create --file <index-def.yaml>") //nolint:lll // For readability
-
-	err := flags.AddFormatTestFlag(flagSet, &indexListFlags.format)
-	if err != nil {
-		panic(err)
-	}
+	flagSet.BoolVarP(&indexListFlags.verbose, flags.Verbose, "v", false, "Print detailed index information.")                                                         //nolint:lll // For readability
+	flagSet.BoolVar(&indexListFlags.yaml, flags.Yaml, false, "Output indexes in yaml format to later be used with \"asvec index create --file <index-def.yaml>")      //nolint:lll // For readability
+	flagSet.VarP(&indexListFlags.output, flags.Output, flags.OutputShort, fmt.Sprintf("The output format. Valid values: %s", strings.Join(flags.OutputEnum(), ", "))) //nolint:lll // For readability
+	flagSet.Var(&indexListFlags.failIf, flags.FailIf, failIfUsage("any index", indexFailIfFields))                                                                    //nolint:lll // For readability
 
 	return flagSet
 }
 
 var indexListRequiredFlags = []string{}
 
+var indexListFailIf = &failIfCheck{
+	conditions: &indexListFlags.failIf,
+	fields:     indexFailIfFields,
+	keys:       indexItemKeys,
+}
+
 // listIndexCmd represents the listIndex command
 func newIndexListCmd() *cobra.Command {
 	return &cobra.Command{
@@ -47,67 +56,54 @@ func newIndexListCmd() *cobra.Command {
 		Aliases: []string{"list"},
 		Short:   "A command for listing indexes",
 		Long: fmt.Sprintf(`A command for listing useful information about AVS indexes. To display additional
-index information use the --%s flag.
+index information use the --%s flag. Use --%s to exit with code %d when a condition
+is true for any index, e.g. for alerting from cron. %s
 
 For example:
 
 %s
 asvec index ls
-		`, flags.Verbose, HelpTxtSetupEnv),
+asvec index ls --%s 'unmerged>100000' --%s 'status!=READY'
+asvec index ls --%s all
+		`, flags.Verbose, flags.FailIf, flags.ExitCodeConditionFailed, multiClusterHelp("indexes"),
+			HelpTxtSetupEnv, flags.FailIf, flags.FailIf, flags.ClusterName),
 		PreRunE: func(_ *cobra.Command, _ []string) error {
-			return checkSeedsAndHost()
+			if err := checkSeedsAndHost(); err != nil {
+				return err
+			}
+
+			return indexListFailIf.validate()
 		},
 		RunE: func(_ *cobra.Command, _ []string) error {
 			logger.Debug("parsed flags",
 				append(indexListFlags.clientFlags.NewSLogAttr(),
 					slog.Bool(flags.Verbose, indexListFlags.verbose),
+					slog.String(flags.Output, indexListFlags.output.String()),
+					slog.String(flags.FailIf, indexListFlags.failIf.String()),
 				)...,
 			)
 
-			client, err := createClientFromFlags(indexListFlags.clientFlags)
+			clusterNames, err := multiClusterNames()
 			if err != nil {
 				return err
 			}
-			defer client.Close()
 
-			ctx, cancel := context.WithTimeout(context.Background(), indexListFlags.clientFlags.Timeout)
-			defer cancel()
+			if clusterNames != nil {
+				return runIndexListClusters(clusterNames)
+			}
 
-			indexList, err := client.IndexList(ctx, true)
+			client, releaseClient, err := acquireClient(indexListFlags.clientFlags)
 			if err != nil {
-				logger.Error("failed to list indexes", slog.Any("error", err))
 				return err
 			}
+			defer releaseClient()
 
-			indexStatusList := make([]*protos.IndexStatusResponse, len(indexList.GetIndices()))
-
-			cancel()
-
-			ctx, cancel = context.WithTimeout(context.Background(), indexListFlags.clientFlags.Timeout)
-			defer cancel()
-
-			wg := sync.WaitGroup{}
-			for i, index := range indexList.GetIndices() {
-				wg.Add(1)
-				go func(i int, index *protos.IndexDefinition) {
-					defer wg.Done()
-					indexStatus, err := client.IndexGetStatus(ctx, index.Id.Namespace, index.Id.Name)
-					if err != nil {
-						logger.ErrorContext(ctx,
-							"failed to get index status",
-							slog.Any("error", err),
-							slog.String("index", index.Id.String()),
-						)
-						return
-					}
-
-					indexStatusList[i] = indexStatus
-					logger.Debug("server index status", slog.Int("index", i), slog.Any("response", indexStatus))
-				}(i, index)
+			indexList, indexStatusList, err := listIndexesWithStatus(client, indexListFlags.clientFlags.Timeout)
+			if err != nil {
+				logger.Error("failed to list indexes", slog.Any("error", err))
+				return err
 			}
 
-			wg.Wait()
-
 			logger.Debug("server index list", slog.String("response", indexList.String()))
 
 			if indexListFlags.yaml {
@@ -137,18 +133,70 @@ asvec index ls
 
 				view.Print(string(yamlData))
 			} else {
-				view.PrintIndexes(indexList, indexStatusList, indexListFlags.verbose, indexListFlags.format)
+				view.PrintIndexes(indexList, indexStatusList, indexListFlags.verbose, indexListFlags.output)
 
-				if indexListFlags.verbose {
+				if !indexListFlags.output.IsStructured() && (indexListFlags.verbose || indexListFlags.output.IsWide()) {
 					view.Print("Values ending with * can be dynamically configured using the 'asvec index update' command.")
 				}
 			}
 
-			return nil
+			items := make([]any, 0, len(indexList.GetIndices()))
+
+			for i, index := range indexList.GetIndices() {
+				if index.Id.Name == "" || index.Id.Namespace == "" {
+					continue
+				}
+
+				items = append(items, writers.NewIndexJSON(index, indexStatusList[i]))
+			}
+
+			return checkFailIf(indexListFailIf, items)
 		},
 	}
 }
 
+// runIndexListClusters lists the indexes of multiple clusters concurrently.
+func runIndexListClusters(clusterNames []string) error {
+	if indexListFlags.yaml {
+		return fmt.Errorf("--%s can't be used with multiple clusters, use --%s yaml", flags.Yaml, flags.Output)
+	}
+
+	results := forEachCluster(clusterNames, indexListFlags.clientFlags,
+		func(client *avs.Client, clientFlags *flags.ClientFlags) (*indexListResult, error) {
+			indexList, indexStatusList, err := listIndexesWithStatus(client, clientFlags.Timeout)
+			if err != nil {
+				return nil, err
+			}
+
+			return &indexListResult{indexList, indexStatusList}, nil
+		},
+	)
+
+	view.PrintClusterIndexes(results, indexListFlags.verbose, indexListFlags.output)
+
+	if !indexListFlags.output.IsStructured() && (indexListFlags.verbose || indexListFlags.output.IsWide()) {
+		view.Print("Values ending with * can be dynamically configured using the 'asvec index update' command.")
+	}
+
+	items := []any{}
+
+	for _, result := range results {
+		if result.err != nil {
+			continue
+		}
+
+		for i, index := range result.value.indexes.GetIndices() {
+			if index.Id.Name == "" || index.Id.Namespace == "" {
+				continue
+			}
+
+			items = append(items, withCluster(result.cluster, writers.NewIndexJSON(index, result.value.statuses[i])))
+		}
+	}
+
+	return checkFailIf(indexListFailIf, items)
+}
+
 func init() {
 	indexListCmd := newIndexListCmd()
 
@@ -163,5 +211,51 @@ func init() {
 	}
 
 	// Add watch functionality to the index list command
-	wrapCommandWithWatch(indexListCmd)
+	wrapCommandWithWatch(indexListCmd, &watchChanges{keys: indexItemKeys})
+}
+
+// listIndexesWithStatus lists the indexes and gets the status of each. The
+// status of an index is nil if it could not be retrieved.
+func listIndexesWithStatus(
+	client *avs.Client,
+	timeout time.Duration,
+) (*protos.IndexDefinitionList, []*protos.IndexStatusResponse, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	indexList, err := client.IndexList(ctx, true)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	indexStatusList := make([]*protos.IndexStatusResponse, len(indexList.GetIndices()))
+
+	cancel()
+
+	ctx, cancel = context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	wg := sync.WaitGroup{}
+	for i, index := range indexList.GetIndices() {
+		wg.Add(1)
+		go func(i int, index *protos.IndexDefinition) {
+			defer wg.Done()
+			indexStatus, err := client.IndexGetStatus(ctx, index.Id.Namespace, index.Id.Name)
+			if err != nil {
+				logger.ErrorContext(ctx,
+					"failed to get index status",
+					slog.Any("error", err),
+					slog.String("index", index.Id.String()),
+				)
+				return
+			}
+
+			indexStatusList[i] = indexStatus
+			logger.Debug("server index status", slog.Int("index", i), slog.Any("response", indexStatus))
+		}(i, index)
+	}
+
+	wg.Wait()
+
+	return indexList, indexStatusList, nil
 }
@@ -0,0 +1,673 @@
+package cmd
+
+import (
+	"asvec/cmd/flags"
+	"asvec/cmd/records"
+	"asvec/cmd/writers"
+	"asvec/internal/vecmath"
+	"asvec/utils"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aerospike/avs-client-go"
+	"github.com/aerospike/avs-client-go/protos"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+const (
+	defaultRecallK           = 10
+	defaultRecallParallelism = 4
+	groundTruthNeighborField = "neighbors"
+)
+
+//nolint:govet // Padding not a concern for a CLI
+var indexRecallFlags = &struct {
+	clientFlags     *flags.ClientFlags
+	namespace       string
+	indexName       string
+	queryFile       string
+	groundTruthFile string
+	baseFile        string
+	keyField        string
+	k               uint32
+	hnswEf          []uint
+	parallelism     int
+	output          flags.OutputFlag
+}{
+	clientFlags: rootFlags.clientFlags,
+	output:      flags.OutputTable,
+}
+
+func newIndexRecallFlagSet() *pflag.FlagSet {
+	flagSet := &pflag.FlagSet{}
+	flagSet.StringVarP(&indexRecallFlags.namespace, flags.Namespace, flags.NamespaceShort, "", "The namespace for the index.")                                                                                                                  //nolint:lll // For readability
+	flagSet.StringVarP(&indexRecallFlags.indexName, flags.IndexName, flags.IndexNameShort, "", "The name of the index.")                                                                                                                        //nolint:lll // For readability
+	flagSet.StringVar(&indexRecallFlags.queryFile, flags.QueryFile, "", "A JSONL, CSV, npy, fvecs, or bvecs file of query vectors. JSONL and CSV queries have an id and a vector field.")                                                       //nolint:lll // For readability
+	flagSet.StringVar(&indexRecallFlags.groundTruthFile, flags.GroundTruthFile, "", "An ivecs or JSONL file containing each query's exact nearest neighbors. JSONL lines have an id and a neighbors field containing a list of keys.")          //nolint:lll // For readability
+	flagSet.StringVar(&indexRecallFlags.baseFile, flags.BaseFile, "", fmt.Sprintf("A file of the indexed records, e.g. from \"asvec data export\", used to compute exact nearest neighbors when --%s is not provided.", flags.GroundTruthFile)) //nolint:lll // For readability
+	flagSet.StringVar(&indexRecallFlags.keyField, flags.KeyField, "key", fmt.Sprintf("The field, or CSV column, containing each record's key in --%s.", flags.BaseFile))                                                                        //nolint:lll // For readability
+	flagSet.Uint32VarP(&indexRecallFlags.k, flags.MaxResults, "r", defaultRecallK, "The number of neighbors, k, to search for and measure recall@k with.")                                                                                      //nolint:lll // For readability
+	flagSet.UintSliceVar(&indexRecallFlags.hnswEf, flags.HnswEf, nil, "The hnsw-ef values to measure recall with, producing an ef vs recall curve. Uses the index's default if not provided.")                                                  //nolint:lll // For readability
+	flagSet.IntVar(&indexRecallFlags.parallelism, flags.Parallelism, defaultRecallParallelism, "The number of queries to run concurrently.")                                                                                                    //nolint:lll // For readability
+	flagSet.VarP(&indexRecallFlags.output, flags.Output, flags.OutputShort, fmt.Sprintf("The output format. Valid values: %s", strings.Join(flags.OutputEnum(), ", ")))                                                                         //nolint:lll // For readability
+
+	return flagSet
+}
+
+var indexRecallRequiredFlags = []string{
+	flags.Namespace,
+	flags.IndexName,
+	flags.QueryFile,
+}
+
+// recallQuery is a query vector along with its exact nearest neighbors.
+type recallQuery struct {
+	id          any
+	vector      any
+	groundTruth []any
+}
+
+func newIndexRecallCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "recall",
+		Short: "A command for measuring the recall of an index",
+		Long: fmt.Sprintf(`A command for measuring the recall of an index. Each query in --%s is
+searched for and the results are compared against the query's exact nearest
+neighbors. Exact neighbors are read from --%s or computed locally from
+--%s using the index's distance metric.
+
+Reported for each --%s value:
+	Recall: The fraction of the exact k nearest neighbors returned, averaged
+		across queries.
+	MRR: The mean reciprocal rank of each query's exact nearest neighbor.
+	P50, P90, P99: Query latency percentiles.
+	QPS: Queries completed per second.
+
+Ground truth from an ivecs file is matched to queries by position and its
+neighbors are row numbers. This matches records imported from fvecs, bvecs,
+or npy files which use the row number as the key.
+
+For example:
+
+%s
+asvec index recall -n test -i sift -r 10 --%s sift_query.fvecs \
+	--%s sift_groundtruth.ivecs --%s 16,32,64,128,256
+
+asvec index recall -n test -i my-index --%s queries.jsonl \
+	--%s my-index.jsonl -o csv
+			`, flags.QueryFile, flags.GroundTruthFile, flags.BaseFile, flags.HnswEf, HelpTxtSetupEnv,
+			flags.QueryFile, flags.GroundTruthFile, flags.HnswEf, flags.QueryFile, flags.BaseFile),
+		PreRunE: func(_ *cobra.Command, _ []string) error {
+			if indexRecallFlags.groundTruthFile == "" && indexRecallFlags.baseFile == "" {
+				return fmt.Errorf("one of --%s or --%s is required", flags.GroundTruthFile, flags.BaseFile)
+			}
+
+			if indexRecallFlags.parallelism < 1 {
+				return fmt.Errorf("--%s must be at least 1", flags.Parallelism)
+			}
+
+			if indexRecallFlags.k == 0 {
+				return fmt.Errorf("--%s must be at least 1", flags.MaxResults)
+			}
+
+			for _, ef := range indexRecallFlags.hnswEf {
+				if ef > math.MaxUint32 {
+					return fmt.Errorf("--%s value %d is too large", flags.HnswEf, ef)
+				}
+			}
+
+			return checkSeedsAndHost()
+		},
+		RunE: func(_ *cobra.Command, _ []string) error {
+			logger.Debug("parsed flags",
+				append(
+					indexRecallFlags.clientFlags.NewSLogAttr(),
+					slog.String(flags.Namespace, indexRecallFlags.namespace),
+					slog.String(flags.IndexName, indexRecallFlags.indexName),
+					slog.String(flags.QueryFile, indexRecallFlags.queryFile),
+					slog.String(flags.GroundTruthFile, indexRecallFlags.groundTruthFile),
+					slog.String(flags.BaseFile, indexRecallFlags.baseFile),
+					slog.String(flags.KeyField, indexRecallFlags.keyField),
+					slog.Any(flags.MaxResults, indexRecallFlags.k),
+					slog.Any(flags.HnswEf, indexRecallFlags.hnswEf),
+					slog.Int(flags.Parallelism, indexRecallFlags.parallelism),
+					slog.String(flags.Output, indexRecallFlags.output.String()),
+				)...,
+			)
+
+			client, err := createClientFromFlags(indexRecallFlags.clientFlags)
+			if err != nil {
+				return err
+			}
+			defer client.Close()
+
+			ctx, cancel := context.WithTimeout(context.Background(), indexRecallFlags.clientFlags.Timeout)
+			defer cancel()
+
+			indexDef, err := client.IndexGet(ctx, indexRecallFlags.namespace, indexRecallFlags.indexName, false)
+			if err != nil {
+				logger.Error("unable to get index definition", slog.Any("error", err))
+				return err
+			}
+
+			queries, err := readRecallQueries(indexRecallFlags.queryFile)
+			if err != nil {
+				logger.Error("unable to read query file", slog.Any("error", err))
+				return err
+			}
+
+			if len(queries) == 0 {
+				return fmt.Errorf("no queries found in %s", indexRecallFlags.queryFile)
+			}
+
+			for _, q := range queries {
+				if err := checkVectorDimensions(indexDef, q.vector); err != nil {
+					return fmt.Errorf("query %v in %s: %w", q.id, indexRecallFlags.queryFile, err)
+				}
+			}
+
+			k := int(indexRecallFlags.k)
+
+			if indexRecallFlags.groundTruthFile != "" {
+				err = readGroundTruth(indexRecallFlags.groundTruthFile, queries)
+			} else {
+				view.PrintfErr("Computing exact nearest neighbors from %s", indexRecallFlags.baseFile)
+
+				err = computeGroundTruth(
+					indexRecallFlags.baseFile, indexDef, queries, k, indexRecallFlags.parallelism,
+				)
+			}
+
+			if err != nil {
+				logger.Error("unable to determine ground truth", slog.Any("error", err))
+				return err
+			}
+
+			efs := []*uint32{nil}
+
+			if len(indexRecallFlags.hnswEf) > 0 {
+				efs = make([]*uint32, len(indexRecallFlags.hnswEf))
+
+				for i, ef := range indexRecallFlags.hnswEf {
+					//nolint:gosec // Overflow is checked in PreRunE
+					efs[i] = utils.Ptr(uint32(ef))
+				}
+			}
+
+			results := make([]*writers.RecallResult, 0, len(efs))
+
+			for _, ef := range efs {
+				result := measureRecall(client, queries, k, ef, indexRecallFlags.parallelism)
+				results = append(results, result)
+			}
+
+			view.PrintRecallResults(results, k, indexRecallFlags.output)
+
+			failed := 0
+			for _, r := range results {
+				failed += r.Failed
+			}
+
+			if failed > 0 {
+				view.Warningf("%d queries failed, run with --%s debug for details", failed, flags.LogLevel)
+			}
+
+			return nil
+		},
+	}
+}
+
+func readRecallQueries(file string) ([]*recallQuery, error) {
+	format, err := records.FormatFromFilename(file)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	reader, err := records.NewReader(f, format, records.ReaderOptions{
+		KeyField:    queryFileIDField,
+		VectorField: queryFileVectorField,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	queries := []*recallQuery{}
+
+	for {
+		record, err := reader.Read()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return queries, nil
+			}
+
+			return nil, err
+		}
+
+		vector, ok := record.Data[queryFileVectorField]
+		if !ok {
+			return nil, fmt.Errorf("query %v is missing field %s", record.Key, queryFileVectorField)
+		}
+
+		if _, ok := records.VectorLen(vector); !ok {
+			return nil, fmt.Errorf("query %v field %s is not a vector", record.Key, queryFileVectorField)
+		}
+
+		queries = append(queries, &recallQuery{id: record.Key, vector: vector})
+	}
+}
+
+// readGroundTruth sets each query's ground truth from an ivecs file, matched
+// by position, or a JSONL file, matched by id.
+func readGroundTruth(file string, queries []*recallQuery) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if strings.EqualFold(filepath.Ext(file), ".ivecs") {
+		vectors, err := records.ReadIvecs(f)
+		if err != nil {
+			return err
+		}
+
+		if len(vectors) < len(queries) {
+			return fmt.Errorf("ground truth has %d entries but there are %d queries", len(vectors), len(queries))
+		}
+
+		for i, q := range queries {
+			q.groundTruth = make([]any, len(vectors[i]))
+			for j, id := range vectors[i] {
+				q.groundTruth[j] = id
+			}
+		}
+
+		return nil
+	}
+
+	reader, err := records.NewReader(f, records.FormatJSONL, records.ReaderOptions{KeyField: queryFileIDField})
+	if err != nil {
+		return err
+	}
+
+	truth := map[any][]any{}
+
+	for {
+		record, err := reader.Read()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+
+			return err
+		}
+
+		neighbors, ok := record.Data[groundTruthNeighborField].([]any)
+		if !ok {
+			return fmt.Errorf("ground truth for query %v is missing field %s", record.Key, groundTruthNeighborField)
+		}
+
+		// Neighbors are compared to keys using a map so must be keys too.
+		for _, n := range neighbors {
+			switch n.(type) {
+			case string, int64:
+			default:
+				return &records.RecordError{
+					Line: reader.(records.LineReader).Line(),
+					Err:  fmt.Errorf("ground truth neighbor %v of query %v is not a string or integer key", n, record.Key),
+				}
+			}
+		}
+
+		truth[record.Key] = neighbors
+	}
+
+	for _, q := range queries {
+		neighbors, ok := truth[q.id]
+		if !ok {
+			return fmt.Errorf("no ground truth found for query %v", q.id)
+		}
+
+		q.groundTruth = neighbors
+	}
+
+	return nil
+}
+
+// computeGroundTruth sets each query's ground truth by computing the exact k
+// nearest neighbors from every vector in file.
+func computeGroundTruth(
+	file string,
+	indexDef *protos.IndexDefinition,
+	queries []*recallQuery,
+	k int,
+	parallelism int,
+) error {
+	format, err := records.FormatFromFilename(file)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	reader, err := records.NewReader(f, format, records.ReaderOptions{
+		KeyField:    indexRecallFlags.keyField,
+		VectorField: indexDef.Field,
+	})
+	if err != nil {
+		return err
+	}
+
+	var (
+		keys    []any
+		vectors [][]float32
+	)
+
+	for {
+		record, err := reader.Read()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+
+			return err
+		}
+
+		vector, ok := record.Data[indexDef.Field]
+		if !ok {
+			continue
+		}
+
+		if err := checkVectorDimensions(indexDef, vector); err != nil {
+			return fmt.Errorf("record %v in %s: %w", record.Key, file, err)
+		}
+
+		v, err := vecmath.ToFloat32(vector)
+		if err != nil {
+			return fmt.Errorf("record %v: %w", record.Key, err)
+		}
+
+		keys = append(keys, record.Key)
+		vectors = append(vectors, v)
+	}
+
+	metric := indexDef.GetVectorDistanceMetric()
+	errs := make([]error, len(queries))
+	indexCh := make(chan int)
+
+	var wg sync.WaitGroup
+
+	for w := 0; w < parallelism; w++ {
+		wg.Add(1)
+
+		// Workers keep draining indexCh after an error so that sending the
+		// remaining queries never blocks.
+		go func() {
+			defer wg.Done()
+
+			for i := range indexCh {
+				q := queries[i]
+
+				neighbors, err := exactNeighbors(metric, q.vector, keys, vectors, k)
+				if err != nil {
+					errs[i] = fmt.Errorf("query %v: %w", q.id, err)
+					continue
+				}
+
+				q.groundTruth = neighbors
+			}
+		}()
+	}
+
+	for i := range queries {
+		indexCh <- i
+	}
+
+	close(indexCh)
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// exactNeighbors returns the keys of the k vectors closest to query.
+func exactNeighbors(
+	metric protos.VectorDistanceMetric,
+	query any,
+	keys []any,
+	vectors [][]float32,
+	k int,
+) ([]any, error) {
+	q, err := vecmath.ToFloat32(query)
+	if err != nil {
+		return nil, err
+	}
+
+	type neighbor struct {
+		key      any
+		distance float32
+	}
+
+	// nearest is kept sorted by distance and holds at most k neighbors.
+	nearest := make([]neighbor, 0, k+1)
+
+	for i, v := range vectors {
+		d, err := vecmath.Float32Distance(metric, q, v)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(nearest) == k && d >= nearest[k-1].distance {
+			continue
+		}
+
+		pos, _ := slices.BinarySearchFunc(nearest, d, func(n neighbor, d float32) int {
+			if n.distance <= d {
+				return -1
+			}
+
+			return 1
+		})
+
+		nearest = slices.Insert(nearest, pos, neighbor{key: keys[i], distance: d})
+
+		if len(nearest) > k {
+			nearest = nearest[:k]
+		}
+	}
+
+	result := make([]any, len(nearest))
+	for i, n := range nearest {
+		result[i] = n.key
+	}
+
+	return result, nil
+}
+
+// measureRecall runs every query with the given ef and compares the results
+// against each query's ground truth.
+func measureRecall(
+	client *avs.Client,
+	queries []*recallQuery,
+	k int,
+	ef *uint32,
+	parallelism int,
+) *writers.RecallResult {
+	type queryResult struct {
+		err          error
+		latency      time.Duration
+		recall       float64
+		reciprocalRR float64
+	}
+
+	results := make([]queryResult, len(queries))
+	indexCh := make(chan int)
+	params := &protos.HnswSearchParams{Ef: ef}
+
+	var wg sync.WaitGroup
+
+	start := time.Now()
+
+	for w := 0; w < parallelism; w++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for i := range indexCh {
+				q := queries[i]
+
+				ctx, cancel := context.WithTimeout(context.Background(), indexRecallFlags.clientFlags.Timeout)
+				queryStart := time.Now()
+				neighbors, err := vectorSearch(
+					ctx,
+					client,
+					indexRecallFlags.namespace,
+					indexRecallFlags.indexName,
+					q.vector,
+					uint32(k), //nolint:gosec // k comes from a uint32 flag
+					params,
+					[]string{},
+				)
+				latency := time.Since(queryStart)
+
+				cancel()
+
+				if err != nil {
+					logger.Debug("query failed", slog.Any("id", q.id), slog.Any("error", err))
+					results[i] = queryResult{err: err}
+
+					continue
+				}
+
+				keys := make([]any, len(neighbors))
+				for j, n := range neighbors {
+					keys[j] = n.Key
+				}
+
+				recall, rr := recallAndReciprocalRank(keys, q.groundTruth, k)
+				results[i] = queryResult{latency: latency, recall: recall, reciprocalRR: rr}
+			}
+		}()
+	}
+
+	for i := range queries {
+		indexCh <- i
+	}
+
+	close(indexCh)
+	wg.Wait()
+
+	elapsed := time.Since(start)
+	result := &writers.RecallResult{Ef: ef, K: k, Queries: len(queries)}
+	latencies := make([]time.Duration, 0, len(queries))
+
+	for _, r := range results {
+		if r.err != nil {
+			result.Failed++
+			continue
+		}
+
+		latencies = append(latencies, r.latency)
+		result.Recall += r.recall
+		result.MRR += r.reciprocalRR
+	}
+
+	if succeeded := len(latencies); succeeded > 0 {
+		result.Recall /= float64(succeeded)
+		result.MRR /= float64(succeeded)
+		result.QPS = float64(succeeded) / elapsed.Seconds()
+	}
+
+	result.P50 = percentile(latencies, 50)
+	result.P90 = percentile(latencies, 90)
+	result.P99 = percentile(latencies, 99)
+
+	return result
+}
+
+// recallAndReciprocalRank returns the fraction of the first k ground truth
+// neighbors found in results and the reciprocal rank of the exact nearest
+// neighbor in results, or 0 if it was not found.
+func recallAndReciprocalRank(results, groundTruth []any, k int) (recall, reciprocalRank float64) {
+	truth := groundTruth[:min(k, len(groundTruth))]
+	if len(truth) == 0 {
+		return 0, 0
+	}
+
+	truthSet := make(map[any]struct{}, len(truth))
+	for _, key := range truth {
+		truthSet[normalizeKey(key)] = struct{}{}
+	}
+
+	nearest := normalizeKey(truth[0])
+	found := 0
+
+	for i, key := range results {
+		key = normalizeKey(key)
+
+		if _, ok := truthSet[key]; ok {
+			found++
+		}
+
+		if reciprocalRank == 0 && key == nearest {
+			reciprocalRank = 1 / float64(i+1)
+		}
+	}
+
+	return float64(found) / float64(len(truth)), reciprocalRank
+}
+
+// normalizeKey converts a record key into a comparable value so that keys
+// read from files compare equal to keys returned by AVS.
+func normalizeKey(key any) any {
+	switch k := key.(type) {
+	case int:
+		return int64(k)
+	case int32:
+		return int64(k)
+	case []byte:
+		return string(k)
+	default:
+		return k
+	}
+}
+
+func init() {
+	indexRecallCmd := newIndexRecallCmd()
+	indexCmd.AddCommand(indexRecallCmd)
+	indexRecallCmd.Flags().AddFlagSet(newIndexRecallFlagSet())
+
+	for _, flag := range indexRecallRequiredFlags {
+		err := indexRecallCmd.MarkFlagRequired(flag)
+		if err != nil {
+			panic(err)
+		}
+	}
+}
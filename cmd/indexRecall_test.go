@@ -0,0 +1,154 @@
+//go:build unit
+
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aerospike/avs-client-go/protos"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecallAndReciprocalRank(t *testing.T) {
+	testCases := []struct {
+		name           string
+		results        []any
+		groundTruth    []any
+		k              int
+		recall         float64
+		reciprocalRank float64
+	}{
+		{
+			name:           "perfect",
+			results:        []any{int64(1), int64(2), int64(3)},
+			groundTruth:    []any{int64(1), int64(2), int64(3)},
+			k:              3,
+			recall:         1,
+			reciprocalRank: 1,
+		},
+		{
+			name:           "nearest second",
+			results:        []any{int64(4), int64(1), int64(2)},
+			groundTruth:    []any{int64(1), int64(2), int64(3)},
+			k:              3,
+			recall:         2.0 / 3.0,
+			reciprocalRank: 0.5,
+		},
+		{
+			name:           "ground truth longer than k",
+			results:        []any{int64(1), int64(2)},
+			groundTruth:    []any{int64(1), int64(2), int64(3), int64(4)},
+			k:              2,
+			recall:         1,
+			reciprocalRank: 1,
+		},
+		{
+			name:           "mixed integer types",
+			results:        []any{1, int32(2)},
+			groundTruth:    []any{int64(2), int64(1)},
+			k:              2,
+			recall:         1,
+			reciprocalRank: 0.5,
+		},
+		{
+			name:           "none found",
+			results:        []any{"a", "b"},
+			groundTruth:    []any{"c", "d"},
+			k:              2,
+			recall:         0,
+			reciprocalRank: 0,
+		},
+		{
+			name:        "no ground truth",
+			results:     []any{"a"},
+			groundTruth: []any{},
+			k:           2,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			recall, rr := recallAndReciprocalRank(tc.results, tc.groundTruth, tc.k)
+			assert.InDelta(t, tc.recall, recall, 1e-9)
+			assert.InDelta(t, tc.reciprocalRank, rr, 1e-9)
+		})
+	}
+}
+
+func TestExactNeighbors(t *testing.T) {
+	keys := []any{"a", "b", "c", "d"}
+	vectors := [][]float32{{0, 0}, {3, 3}, {1, 1}, {2, 2}}
+
+	neighbors, err := exactNeighbors(
+		protos.VectorDistanceMetric_SQUARED_EUCLIDEAN, []float32{0, 0}, keys, vectors, 3,
+	)
+	require.NoError(t, err)
+	assert.Equal(t, []any{"a", "c", "d"}, neighbors)
+
+	neighbors, err = exactNeighbors(
+		protos.VectorDistanceMetric_SQUARED_EUCLIDEAN, []float32{3, 3}, keys, vectors, 10,
+	)
+	require.NoError(t, err)
+	assert.Equal(t, []any{"b", "d", "c", "a"}, neighbors)
+
+	_, err = exactNeighbors(
+		protos.VectorDistanceMetric_SQUARED_EUCLIDEAN, []float32{0, 0, 0}, keys, vectors, 3,
+	)
+	assert.Error(t, err)
+}
+
+func TestReadGroundTruth(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "truth.jsonl")
+	content := "{\"id\":\"q2\",\"neighbors\":[3,4]}\n{\"id\":\"q1\",\"neighbors\":[\"a\",\"b\"]}\n"
+	require.NoError(t, os.WriteFile(file, []byte(content), 0o600))
+
+	queries := []*recallQuery{{id: "q1"}, {id: "q2"}}
+	require.NoError(t, readGroundTruth(file, queries))
+	assert.Equal(t, []any{"a", "b"}, queries[0].groundTruth)
+	assert.Equal(t, []any{int64(3), int64(4)}, queries[1].groundTruth)
+
+	queries = []*recallQuery{{id: "q3"}}
+	assert.Error(t, readGroundTruth(file, queries))
+
+	content = "{\"id\":\"q1\",\"neighbors\":[\"a\"]}\n\n{\"id\":\"q2\",\"neighbors\":[\"a\",[1,2]]}\n"
+	require.NoError(t, os.WriteFile(file, []byte(content), 0o600))
+
+	err := readGroundTruth(file, []*recallQuery{{id: "q1"}, {id: "q2"}})
+	assert.EqualError(t, err, "line 3: ground truth neighbor [1 2] of query q2 is not a string or integer key")
+}
+
+func TestComputeGroundTruthErrors(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "base.jsonl")
+	content := "{\"id\":\"a\",\"vec\":[0.5,0.5,0.5]}\n{\"id\":\"b\",\"vec\":[1.5,1.5,1.5]}\n"
+	require.NoError(t, os.WriteFile(file, []byte(content), 0o600))
+
+	indexRecallFlags.keyField = "id"
+	indexDef := &protos.IndexDefinition{
+		Id:                   &protos.IndexId{Namespace: "test", Name: "index"},
+		Field:                "vec",
+		Dimensions:           3,
+		VectorDistanceMetric: protos.VectorDistanceMetric_SQUARED_EUCLIDEAN.Enum(),
+	}
+
+	// Every query fails so all workers must keep draining the queries.
+	queries := make([]*recallQuery, 10)
+	for i := range queries {
+		queries[i] = &recallQuery{id: i, vector: []float32{0, 0}}
+	}
+
+	err := computeGroundTruth(file, indexDef, queries, 1, 2)
+	assert.ErrorContains(t, err, "query 0")
+
+	queries = []*recallQuery{{id: "q1", vector: []float32{0, 0, 0}}}
+	require.NoError(t, computeGroundTruth(file, indexDef, queries, 1, 2))
+	assert.Equal(t, []any{"a"}, queries[0].groundTruth)
+
+	indexDef.Dimensions = 2
+	err = computeGroundTruth(file, indexDef, queries, 1, 2)
+	assert.ErrorContains(t, err, "the vector has 3 dimensions but index test.index has 2")
+}
@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"asvec/cmd/flags"
+	"asvec/cmd/writers"
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+//nolint:govet // Padding not a concern for a CLI
+var indexStatusFlags = &struct {
+	clientFlags *flags.ClientFlags
+	namespace   string
+	indexName   string
+	output      flags.OutputFlag
+	failIf      flags.FailIfFlag
+}{
+	clientFlags: rootFlags.clientFlags,
+	output:      flags.OutputTable,
+}
+
+func newIndexStatusFlagSet() *pflag.FlagSet {
+	flagSet := &pflag.FlagSet{}
+	flagSet.StringVarP(&indexStatusFlags.namespace, flags.Namespace, flags.NamespaceShort, "", "The namespace for the index.")                                          //nolint:lll // For readability
+	flagSet.StringVarP(&indexStatusFlags.indexName, flags.IndexName, flags.IndexNameShort, "", "The name of the index.")                                                //nolint:lll // For readability
+	flagSet.VarP(&indexStatusFlags.output, flags.Output, flags.OutputShort, fmt.Sprintf("The output format. Valid values: %s", strings.Join(flags.OutputEnum(), ", "))) //nolint:lll // For readability
+	flagSet.Var(&indexStatusFlags.failIf, flags.FailIf, failIfUsage("the index", indexFailIfFields))                                                                    //nolint:lll // For readability
+
+	return flagSet
+}
+
+var indexStatusRequiredFlags = []string{
+	flags.Namespace,
+	flags.IndexName,
+}
+
+var indexStatusFailIf = &failIfCheck{
+	conditions: &indexStatusFlags.failIf,
+	fields:     indexFailIfFields,
+	keys:       indexItemKeys,
+}
+
+func newIndexStatusCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "status",
+		Short: "A command for displaying the status of an index",
+		Long: fmt.Sprintf(`A command for displaying the status of a single index. Use --%s to exit
+with code %d when a condition is true, e.g. to check an index from cron or Nagios.
+The exit code is 1 if the status could not be retrieved.
+
+For example:
+
+%s
+asvec index status -n test -i myindex
+asvec index status -n test -i myindex --%s 'status!=READY' --%s 'unmerged>100000'
+			`, flags.FailIf, flags.ExitCodeConditionFailed, HelpTxtSetupEnv, flags.FailIf, flags.FailIf),
+		PreRunE: func(_ *cobra.Command, _ []string) error {
+			if err := checkSeedsAndHost(); err != nil {
+				return err
+			}
+
+			return indexStatusFailIf.validate()
+		},
+		RunE: func(_ *cobra.Command, _ []string) error {
+			logger.Debug("parsed flags",
+				append(indexStatusFlags.clientFlags.NewSLogAttr(),
+					slog.String(flags.Namespace, indexStatusFlags.namespace),
+					slog.String(flags.IndexName, indexStatusFlags.indexName),
+					slog.String(flags.Output, indexStatusFlags.output.String()),
+					slog.String(flags.FailIf, indexStatusFlags.failIf.String()),
+				)...,
+			)
+
+			client, releaseClient, err := acquireClient(indexStatusFlags.clientFlags)
+			if err != nil {
+				return err
+			}
+			defer releaseClient()
+
+			ctx, cancel := context.WithTimeout(context.Background(), indexStatusFlags.clientFlags.Timeout)
+			defer cancel()
+
+			index, err := client.IndexGet(ctx, indexStatusFlags.namespace, indexStatusFlags.indexName, false)
+			if err != nil {
+				logger.Error("unable to get index definition", slog.Any("error", err))
+				return err
+			}
+
+			status, err := client.IndexGetStatus(ctx, indexStatusFlags.namespace, indexStatusFlags.indexName)
+			if err != nil {
+				logger.Error("unable to get index status", slog.Any("error", err))
+				return err
+			}
+
+			logger.Debug("server index status", slog.Any("response", status))
+
+			view.PrintIndexStatus(index, status, indexStatusFlags.output)
+
+			return checkFailIf(indexStatusFailIf, []any{writers.NewIndexJSON(index, status)})
+		},
+	}
+}
+
+func init() {
+	indexStatusCmd := newIndexStatusCmd()
+
+	indexCmd.AddCommand(indexStatusCmd)
+	indexStatusCmd.Flags().AddFlagSet(newIndexStatusFlagSet())
+
+	for _, flag := range indexStatusRequiredFlags {
+		err := indexStatusCmd.MarkFlagRequired(flag)
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	wrapCommandWithWatch(indexStatusCmd, &watchChanges{keys: indexItemKeys})
+}
@@ -0,0 +1,304 @@
+package cmd
+
+import (
+	"asvec/cmd/flags"
+	"asvec/cmd/writers"
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	avs "github.com/aerospike/avs-client-go"
+	"github.com/aerospike/avs-client-go/protos"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"golang.org/x/term"
+)
+
+const (
+	defaultIndexTopInterval = 2 * time.Second
+
+	// Rates are computed over at most this many samples. Merges and builds
+	// progress in batches, so rates between consecutive samples are noisy.
+	indexTopWindow = 10
+)
+
+//nolint:govet // Padding not a concern for a CLI
+var indexTopFlags = &struct {
+	clientFlags *flags.ClientFlags
+	namespace   string
+	indexName   string
+	interval    time.Duration
+	iterations  int
+}{
+	clientFlags: rootFlags.clientFlags,
+}
+
+func newIndexTopFlagSet() *pflag.FlagSet {
+	flagSet := &pflag.FlagSet{}
+	flagSet.StringVarP(&indexTopFlags.namespace, flags.Namespace, flags.NamespaceShort, "", "Only display indexes in this namespace.")              //nolint:lll // For readability
+	flagSet.StringVarP(&indexTopFlags.indexName, flags.IndexName, flags.IndexNameShort, "", "Only display indexes with this name.")                 //nolint:lll // For readability
+	flagSet.DurationVar(&indexTopFlags.interval, flags.Interval, defaultIndexTopInterval, "How often to sample the status of each index.")          //nolint:lll // For readability
+	flagSet.IntVar(&indexTopFlags.iterations, flags.Iterations, 0, "The number of times to sample before exiting. If 0, sample until interrupted.") //nolint:lll // For readability
+
+	return flagSet
+}
+
+// indexTopSample is the status of an index at a point in time.
+type indexTopSample struct {
+	time   time.Time
+	status *protos.IndexStatusResponse
+}
+
+func newIndexTopCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "top",
+		Short: "A command for monitoring index merge and build progress",
+		Long: fmt.Sprintf(`A command for monitoring the status of indexes. The status of each index is
+sampled every --%s using a single connection, and rates are computed from
+the last %d samples:
+
+  Merge Rate: Unmerged records merged per second. Merge ETA is when the
+    unmerged record count will reach 0 at this rate.
+  Vertex Rate: Vertices added to the main index per second by the healer.
+  Size Growth: Growth per second of the approximate index size.
+  Build Progress: For standalone indexes, the percentage of scanned vector
+    records which have been indexed. Build ETA is when all scanned records
+    will be indexed at the current Build Rate.
+
+Rates and ETAs are displayed as "-" until there are enough samples.
+
+For example:
+
+%s
+asvec index top -n test
+asvec index top -n test -i myindex --%s 5s
+			`, flags.Interval, indexTopWindow, HelpTxtSetupEnv, flags.Interval),
+		PreRunE: func(_ *cobra.Command, _ []string) error {
+			if indexTopFlags.interval <= 0 {
+				return fmt.Errorf("--%s must be greater than 0", flags.Interval)
+			}
+
+			if indexTopFlags.iterations < 0 {
+				return fmt.Errorf("--%s must not be negative", flags.Iterations)
+			}
+
+			return checkSeedsAndHost()
+		},
+		RunE: func(_ *cobra.Command, _ []string) error {
+			logger.Debug("parsed flags",
+				append(indexTopFlags.clientFlags.NewSLogAttr(),
+					slog.String(flags.Namespace, indexTopFlags.namespace),
+					slog.String(flags.IndexName, indexTopFlags.indexName),
+					slog.Duration(flags.Interval, indexTopFlags.interval),
+					slog.Int(flags.Iterations, indexTopFlags.iterations),
+				)...,
+			)
+
+			client, err := createClientFromFlags(indexTopFlags.clientFlags)
+			if err != nil {
+				return err
+			}
+			defer client.Close()
+
+			ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer cancel()
+
+			ticker := time.NewTicker(indexTopFlags.interval)
+			defer ticker.Stop()
+
+			// Output is only redrawn in place on a terminal. Otherwise, each
+			// sample is appended so the output can be logged.
+			redraw := term.IsTerminal(int(os.Stdout.Fd()))
+			history := map[string][]indexTopSample{}
+			previousLineCount := 0
+
+			for i := 1; ; i++ {
+				rows, err := sampleIndexTop(client, history)
+				if err != nil {
+					return err
+				}
+
+				if redraw {
+					clearLines(view.out, previousLineCount)
+				}
+
+				lineCounter := &LineCountingWriter{Writer: view.out}
+				originalOut := view.out
+				view.out = lineCounter
+
+				view.Printf(
+					"Sampling every %s (press Ctrl+C to exit) - Last update: %s",
+					indexTopFlags.interval, time.Now().Format("2006-01-02 15:04:05"),
+				)
+				view.PrintIndexTop(rows)
+
+				view.out = originalOut
+				previousLineCount = lineCounter.LineCount
+
+				if i == indexTopFlags.iterations {
+					return nil
+				}
+
+				select {
+				case <-ctx.Done():
+					logger.Debug("index top terminated by user")
+					return nil
+				case <-ticker.C:
+				}
+			}
+		},
+	}
+}
+
+// sampleIndexTop gets the status of each index, appends it to the index's
+// history, and returns the rows to display.
+func sampleIndexTop(client *avs.Client, history map[string][]indexTopSample) ([]*writers.IndexTopRow, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), indexTopFlags.clientFlags.Timeout)
+	defer cancel()
+
+	indexList, err := client.IndexList(ctx, true)
+	if err != nil {
+		logger.Error("failed to list indexes", slog.Any("error", err))
+		return nil, err
+	}
+
+	indexes := filterIndexes(indexList, indexTopFlags.namespace, indexTopFlags.indexName)
+	statuses := make([]*protos.IndexStatusResponse, len(indexes))
+	now := time.Now()
+
+	wg := sync.WaitGroup{}
+	for i, index := range indexes {
+		wg.Add(1)
+
+		go func(i int, index *protos.IndexDefinition) {
+			defer wg.Done()
+
+			status, err := client.IndexGetStatus(ctx, index.GetId().GetNamespace(), index.GetId().GetName())
+			if err != nil {
+				logger.Error(
+					"failed to get index status",
+					slog.String("index", indexFullName(index.GetId())),
+					slog.Any("error", err),
+				)
+
+				return
+			}
+
+			statuses[i] = status
+		}(i, index)
+	}
+
+	wg.Wait()
+
+	rows := make([]*writers.IndexTopRow, 0, len(indexes))
+	seen := map[string]bool{}
+
+	for i, index := range indexes {
+		name := indexFullName(index.GetId())
+		seen[name] = true
+
+		if statuses[i] != nil {
+			history[name] = append(history[name], indexTopSample{time: now, status: statuses[i]})
+			if len(history[name]) > indexTopWindow {
+				history[name] = history[name][len(history[name])-indexTopWindow:]
+			}
+		}
+
+		if samples := history[name]; len(samples) > 0 {
+			rows = append(rows, newIndexTopRow(index, samples))
+		}
+	}
+
+	// Dropped indexes are forgotten so a recreated index starts over.
+	for name := range history {
+		if !seen[name] {
+			delete(history, name)
+		}
+	}
+
+	return rows, nil
+}
+
+// newIndexTopRow returns the latest status of index and the rates between the
+// oldest and newest samples.
+func newIndexTopRow(index *protos.IndexDefinition, samples []indexTopSample) *writers.IndexTopRow {
+	first := samples[0]
+	last := samples[len(samples)-1]
+
+	row := &writers.IndexTopRow{
+		Namespace: index.GetId().GetNamespace(),
+		Name:      index.GetId().GetName(),
+		Status:    last.status.GetStatus(),
+		Unmerged:  last.status.GetUnmergedRecordCount(),
+		Vertices:  last.status.GetIndexHealerVerticesValid(),
+		Size:      writers.CalculateIndexSize(index, last.status),
+	}
+
+	metrics := last.status.GetStandaloneIndexMetrics()
+	if metrics != nil {
+		state := metrics.GetState()
+		row.StandaloneState = &state
+
+		if scanned := metrics.GetScannedVectorRecordCount(); scanned > 0 {
+			progress := float64(metrics.GetIndexedVectorRecordCount()) / float64(scanned) * 100
+			row.BuildProgress = &progress
+		}
+	}
+
+	elapsed := last.time.Sub(first.time).Seconds()
+	if elapsed <= 0 {
+		return row
+	}
+
+	mergeRate := float64(first.status.GetUnmergedRecordCount()-row.Unmerged) / elapsed
+	row.MergeRate = &mergeRate
+	row.MergeETA = estimateRemaining(float64(row.Unmerged), mergeRate)
+
+	vertexRate := float64(row.Vertices-first.status.GetIndexHealerVerticesValid()) / elapsed
+	row.VertexRate = &vertexRate
+
+	sizeRate := float64(row.Size-writers.CalculateIndexSize(index, first.status)) / elapsed
+	row.SizeRate = &sizeRate
+
+	if metrics != nil && first.status.GetStandaloneIndexMetrics() != nil {
+		indexed := metrics.GetIndexedVectorRecordCount()
+		buildRate := (float64(indexed) - float64(first.status.GetStandaloneIndexMetrics().GetIndexedVectorRecordCount())) / elapsed
+		row.BuildRate = &buildRate
+
+		if scanned := metrics.GetScannedVectorRecordCount(); scanned >= indexed {
+			row.BuildETA = estimateRemaining(float64(scanned-indexed), buildRate)
+		}
+	}
+
+	return row
+}
+
+// estimateRemaining returns how long it will take to process remaining items
+// at rate items per second, or nil if it will never finish at this rate.
+func estimateRemaining(remaining, rate float64) *time.Duration {
+	if remaining <= 0 {
+		eta := time.Duration(0)
+		return &eta
+	}
+
+	if rate <= 0 {
+		return nil
+	}
+
+	eta := time.Duration(remaining / rate * float64(time.Second))
+
+	return &eta
+}
+
+func init() {
+	indexTopCmd := newIndexTopCmd()
+	indexCmd.AddCommand(indexTopCmd)
+
+	flagSet := newIndexTopFlagSet()
+	indexTopCmd.Flags().AddFlagSet(flagSet)
+}
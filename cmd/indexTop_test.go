@@ -0,0 +1,94 @@
+//go:build unit
+
+package cmd
+
+import (
+	"asvec/utils"
+	"testing"
+	"time"
+
+	"github.com/aerospike/avs-client-go/protos"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewIndexTopRow(t *testing.T) {
+	index := &protos.IndexDefinition{
+		Id:         &protos.IndexId{Namespace: "test", Name: "top"},
+		Dimensions: 10,
+		Params: &protos.IndexDefinition_HnswParams{
+			HnswParams: &protos.HnswParams{M: utils.Ptr(uint32(16))},
+		},
+	}
+	start := time.Now()
+
+	first := indexTopSample{
+		time: start,
+		status: &protos.IndexStatusResponse{
+			Status:                   protos.Status_READY,
+			UnmergedRecordCount:      1000,
+			IndexHealerVerticesValid: 100,
+		},
+	}
+	last := indexTopSample{
+		time: start.Add(10 * time.Second),
+		status: &protos.IndexStatusResponse{
+			Status:                   protos.Status_READY,
+			UnmergedRecordCount:      600,
+			IndexHealerVerticesValid: 150,
+		},
+	}
+
+	t.Run("single sample", func(t *testing.T) {
+		row := newIndexTopRow(index, []indexTopSample{first})
+
+		assert.Equal(t, int64(1000), row.Unmerged)
+		assert.Equal(t, int64(100), row.Vertices)
+		assert.Nil(t, row.MergeRate)
+		assert.Nil(t, row.MergeETA)
+		assert.Nil(t, row.VertexRate)
+		assert.Nil(t, row.SizeRate)
+		assert.Nil(t, row.StandaloneState)
+	})
+
+	t.Run("rates", func(t *testing.T) {
+		row := newIndexTopRow(index, []indexTopSample{first, last})
+
+		assert.Equal(t, "top", row.Name)
+		assert.Equal(t, protos.Status_READY, row.Status)
+		require.NotNil(t, row.MergeRate)
+		assert.InDelta(t, 40, *row.MergeRate, 0.001)
+		require.NotNil(t, row.MergeETA)
+		assert.Equal(t, 15*time.Second, *row.MergeETA)
+		require.NotNil(t, row.VertexRate)
+		assert.InDelta(t, 5, *row.VertexRate, 0.001)
+		require.NotNil(t, row.SizeRate)
+		assert.Positive(t, *row.SizeRate)
+		assert.Nil(t, row.BuildRate)
+	})
+
+	t.Run("growing unmerged", func(t *testing.T) {
+		row := newIndexTopRow(index, []indexTopSample{last, {time: last.time.Add(time.Second), status: first.status}})
+
+		require.NotNil(t, row.MergeRate)
+		assert.Negative(t, *row.MergeRate)
+		assert.Nil(t, row.MergeETA)
+	})
+
+	t.Run("standalone", func(t *testing.T) {
+		status := &protos.IndexStatusResponse{
+			StandaloneIndexMetrics: &protos.StandaloneIndexMetrics{State: protos.StandaloneIndexState_CREATING},
+		}
+		row := newIndexTopRow(index, []indexTopSample{{time: start, status: status}})
+
+		require.NotNil(t, row.StandaloneState)
+		assert.Equal(t, protos.StandaloneIndexState_CREATING, *row.StandaloneState)
+	})
+}
+
+func TestEstimateRemaining(t *testing.T) {
+	assert.Equal(t, time.Duration(0), *estimateRemaining(0, 0))
+	assert.Equal(t, 2*time.Second, *estimateRemaining(100, 50))
+	assert.Nil(t, estimateRemaining(100, 0))
+	assert.Nil(t, estimateRemaining(100, -5))
+}
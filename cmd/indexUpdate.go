@@ -28,6 +28,7 @@ var indexUpdateFlags = &struct {
 	hnswMerge                flags.MergeFlags
 	hnswVectorIntegrityCheck flags.BoolOptionalFlag
 	indexMode                flags.IndexModeOptionalFlag
+	wait                     flags.WaitFlags
 }{
 	clientFlags:              rootFlags.clientFlags,
 	hnswMaxMemQueueSize:      flags.Uint32OptionalFlag{},
@@ -38,6 +39,7 @@ var indexUpdateFlags = &struct {
 	hnswMerge:                *flags.NewHnswMergeFlags(),
 	hnswVectorIntegrityCheck: flags.BoolOptionalFlag{},
 	indexMode:                flags.IndexModeOptionalFlag{},
+	wait:                     *flags.NewWaitFlags(),
 }
 
 func newIndexUpdateFlagSet() *pflag.FlagSet {
@@ -54,6 +56,7 @@ func newIndexUpdateFlagSet() *pflag.FlagSet {
 	flagSet.AddFlagSet(indexUpdateFlags.hnswHealer.NewFlagSet())
 	flagSet.AddFlagSet(indexUpdateFlags.hnswMerge.NewFlagSet())
 	flagSet.Var(&indexUpdateFlags.indexMode, flags.IndexMode, fmt.Sprintf("The index mode. Valid values: %s", strings.Join(flags.IndexModeFlagEnum(), ", "))) //nolint:lll // For readability
+	flagSet.AddFlagSet(indexUpdateFlags.wait.NewFlagSet())
 
 	return flagSet
 }
@@ -89,6 +92,7 @@ asvec index update -i myindex -n test --%s 10000 --%s 10000ms --%s 10s --%s 16 -
 			debugFlags = append(debugFlags, indexUpdateFlags.hnswRecordCache.NewSLogAttr()...)
 			debugFlags = append(debugFlags, indexUpdateFlags.hnswHealer.NewSLogAttr()...)
 			debugFlags = append(debugFlags, indexUpdateFlags.hnswMerge.NewSLogAttr()...)
+			debugFlags = append(debugFlags, indexUpdateFlags.wait.NewSLogAttr()...)
 			logger.Debug("parsed flags",
 				append(debugFlags,
 					slog.Bool(flags.Yes, indexUpdateFlags.yes),
@@ -166,6 +170,17 @@ asvec index update -i myindex -n test --%s 10000 --%s 10000ms --%s 10s --%s 16 -
 			}
 
 			view.Printf("Successfully updated index %s.%s", indexUpdateFlags.namespace, indexUpdateFlags.indexName)
+
+			if indexUpdateFlags.wait.Wait {
+				return waitForIndex(
+					client,
+					indexUpdateFlags.clientFlags.Timeout,
+					indexUpdateFlags.namespace,
+					indexUpdateFlags.indexName,
+					&indexUpdateFlags.wait,
+				)
+			}
+
 			return nil
 		},
 	}
@@ -0,0 +1,226 @@
+package cmd
+
+import (
+	"asvec/cmd/flags"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	avs "github.com/aerospike/avs-client-go"
+	"github.com/aerospike/avs-client-go/protos"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+const (
+	indexWaitInitialInterval = 500 * time.Millisecond
+	indexWaitMaxInterval     = 10 * time.Second
+	indexWaitBackoff         = 1.5
+
+	// Conditions on the unmerged record count must hold for this many
+	// consecutive polls. Records written just before waiting may not be
+	// counted as unmerged by the first poll.
+	indexWaitUnmergedPolls = 2
+)
+
+//nolint:govet // Padding not a concern for a CLI
+var indexWaitFlags = &struct {
+	clientFlags *flags.ClientFlags
+	namespace   string
+	indexName   string
+	wait        flags.WaitFlags
+}{
+	clientFlags: rootFlags.clientFlags,
+	wait:        *flags.NewWaitFlags(),
+}
+
+func newIndexWaitFlagSet() *pflag.FlagSet {
+	flagSet := &pflag.FlagSet{}
+	flagSet.StringVarP(&indexWaitFlags.namespace, flags.Namespace, flags.NamespaceShort, "", "The namespace for the index.") //nolint:lll // For readability
+	flagSet.StringVarP(&indexWaitFlags.indexName, flags.IndexName, flags.IndexNameShort, "", "The name of the index.")       //nolint:lll // For readability
+	flagSet.AddFlagSet(indexWaitFlags.wait.NewUntilFlagSet())
+
+	return flagSet
+}
+
+var indexWaitRequiredFlags = []string{
+	flags.Namespace,
+	flags.IndexName,
+}
+
+func newIndexWaitCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "wait",
+		Short: "A command for waiting until an index is ready or merged",
+		Long: fmt.Sprintf(`A command for waiting until an index reaches a condition. The index status
+is polled with an increasing interval until the condition is met or --%s
+passes. Valid conditions are:
+
+  %s: The index status is READY and, for standalone indexes, the index
+    has been built.
+  %s: The index is ready and has no unmerged records.
+  %s=N: The index is ready and has fewer than N unmerged records.
+
+For example:
+
+%s
+asvec index wait -n test -i myindex --%s %s --%s 30m
+			`, flags.WaitTimeout, flags.WaitReady, flags.WaitMerged, flags.WaitUnmergedBelow,
+			HelpTxtSetupEnv, flags.WaitUntil, flags.WaitMerged, flags.WaitTimeout),
+		PreRunE: func(_ *cobra.Command, _ []string) error {
+			return checkSeedsAndHost()
+		},
+		RunE: func(_ *cobra.Command, _ []string) error {
+			logger.Debug("parsed flags",
+				append(
+					append(indexWaitFlags.clientFlags.NewSLogAttr(), indexWaitFlags.wait.NewSLogAttr()...),
+					slog.String(flags.Namespace, indexWaitFlags.namespace),
+					slog.String(flags.IndexName, indexWaitFlags.indexName),
+				)...,
+			)
+
+			client, err := createClientFromFlags(indexWaitFlags.clientFlags)
+			if err != nil {
+				return err
+			}
+			defer client.Close()
+
+			return waitForIndex(
+				client,
+				indexWaitFlags.clientFlags.Timeout,
+				indexWaitFlags.namespace,
+				indexWaitFlags.indexName,
+				&indexWaitFlags.wait,
+			)
+		},
+	}
+}
+
+// waitForIndex polls the status of an index until it meets the wait
+// condition or the wait timeout passes. Each status request uses
+// requestTimeout. Progress is printed to stderr whenever the status changes.
+func waitForIndex(
+	client *avs.Client,
+	requestTimeout time.Duration,
+	namespace, indexName string,
+	waitFlags *flags.WaitFlags,
+) error {
+	ctx, cancel := context.WithTimeout(context.Background(), waitFlags.Timeout)
+	defer cancel()
+
+	name := fmt.Sprintf("%s.%s", namespace, indexName)
+	start := time.Now()
+	interval := indexWaitInitialInterval
+	polls := 0
+	lastProgress := ""
+
+	for {
+		reqCtx, reqCancel := context.WithTimeout(ctx, requestTimeout)
+		status, err := client.IndexGetStatus(reqCtx, namespace, indexName)
+
+		reqCancel()
+
+		switch {
+		case err == nil:
+			progress := formatIndexWaitStatus(status)
+			if progress != lastProgress {
+				view.PrintfErr("Waiting for index %s to be %s: %s", name, waitFlags.Until.String(), progress)
+				lastProgress = progress
+			}
+
+			met, err := indexWaitConditionMet(status, &waitFlags.Until)
+			if err != nil {
+				logger.Error("index failed", slog.String("index", name), slog.Any("error", err))
+				return fmt.Errorf("index %s %w", name, err)
+			}
+
+			if !met {
+				polls = 0
+			} else if polls++; polls >= indexWaitRequiredPolls(&waitFlags.Until) {
+				view.Printf("Index %s is %s after %s", name, waitFlags.Until.String(), time.Since(start).Round(time.Second))
+				return nil
+			}
+		case grpcCode(err) == "NotFound":
+			logger.Error("failed to get index status", slog.String("index", name), slog.Any("error", err))
+			return err
+		case ctx.Err() == nil:
+			// The wait continues through transient errors until it times out.
+			logger.Warn("failed to get index status", slog.String("index", name), slog.Any("error", err))
+		}
+
+		select {
+		case <-ctx.Done():
+			err := fmt.Errorf(
+				"timed out after %s waiting for index %s to be %s",
+				waitFlags.Timeout, name, waitFlags.Until.String(),
+			)
+			logger.Error(err.Error(), slog.String("lastStatus", lastProgress))
+
+			return err
+		case <-time.After(interval):
+		}
+
+		interval = min(time.Duration(float64(interval)*indexWaitBackoff), indexWaitMaxInterval)
+	}
+}
+
+// indexWaitConditionMet reports whether status satisfies the condition. An
+// error is returned when the index can never satisfy it.
+func indexWaitConditionMet(status *protos.IndexStatusResponse, cond *flags.WaitConditionFlag) (bool, error) {
+	if status.GetStatus() != protos.Status_READY {
+		return false, nil
+	}
+
+	if metrics := status.GetStandaloneIndexMetrics(); metrics != nil {
+		switch metrics.GetState() {
+		case protos.StandaloneIndexState_FAILED:
+			return false, errors.New("standalone index construction failed")
+		case protos.StandaloneIndexState_CREATING, protos.StandaloneIndexState_UPDATING:
+			return false, nil
+		}
+	}
+
+	switch cond.Condition {
+	case flags.WaitMerged:
+		return status.GetUnmergedRecordCount() == 0, nil
+	case flags.WaitUnmergedBelow:
+		return status.GetUnmergedRecordCount() < cond.Threshold, nil
+	default:
+		return true, nil
+	}
+}
+
+func indexWaitRequiredPolls(cond *flags.WaitConditionFlag) int {
+	if cond.Condition == flags.WaitReady {
+		return 1
+	}
+
+	return indexWaitUnmergedPolls
+}
+
+func formatIndexWaitStatus(status *protos.IndexStatusResponse) string {
+	s := fmt.Sprintf("status=%s unmerged=%d", status.GetStatus(), status.GetUnmergedRecordCount())
+
+	if metrics := status.GetStandaloneIndexMetrics(); metrics != nil {
+		s += fmt.Sprintf(" standalone=%s inserted=%d", metrics.GetState(), metrics.GetInsertedRecordCount())
+	}
+
+	return s
+}
+
+func init() {
+	indexWaitCmd := newIndexWaitCmd()
+	indexCmd.AddCommand(indexWaitCmd)
+
+	flagSet := newIndexWaitFlagSet()
+	indexWaitCmd.Flags().AddFlagSet(flagSet)
+
+	for _, flag := range indexWaitRequiredFlags {
+		err := indexWaitCmd.MarkFlagRequired(flag)
+		if err != nil {
+			panic(err)
+		}
+	}
+}
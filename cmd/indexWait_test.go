@@ -0,0 +1,62 @@
+//go:build unit
+
+package cmd
+
+import (
+	"asvec/cmd/flags"
+	"testing"
+
+	"github.com/aerospike/avs-client-go/protos"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIndexWaitConditionMet(t *testing.T) {
+	ready := flags.WaitConditionFlag{Condition: flags.WaitReady}
+	merged := flags.WaitConditionFlag{Condition: flags.WaitMerged}
+	below := flags.WaitConditionFlag{Condition: flags.WaitUnmergedBelow, Threshold: 100}
+
+	standalone := func(state protos.StandaloneIndexState) *protos.IndexStatusResponse {
+		return &protos.IndexStatusResponse{
+			Status:                 protos.Status_READY,
+			StandaloneIndexMetrics: &protos.StandaloneIndexMetrics{State: state},
+		}
+	}
+
+	testCases := []struct {
+		name     string
+		status   *protos.IndexStatusResponse
+		cond     flags.WaitConditionFlag
+		expected bool
+		err      bool
+	}{
+		{name: "not ready", status: &protos.IndexStatusResponse{Status: protos.Status_NOT_READY}, cond: ready},
+		{name: "ready", status: &protos.IndexStatusResponse{UnmergedRecordCount: 10}, cond: ready, expected: true},
+		{name: "not merged", status: &protos.IndexStatusResponse{UnmergedRecordCount: 10}, cond: merged},
+		{name: "merged", status: &protos.IndexStatusResponse{}, cond: merged, expected: true},
+		{name: "not below", status: &protos.IndexStatusResponse{UnmergedRecordCount: 100}, cond: below},
+		{name: "below", status: &protos.IndexStatusResponse{UnmergedRecordCount: 99}, cond: below, expected: true},
+		{name: "standalone creating", status: standalone(protos.StandaloneIndexState_CREATING), cond: ready},
+		{name: "standalone updating", status: standalone(protos.StandaloneIndexState_UPDATING), cond: ready},
+		{name: "standalone persisted", status: standalone(protos.StandaloneIndexState_PERSISTED), cond: merged, expected: true},
+		{name: "standalone failed", status: standalone(protos.StandaloneIndexState_FAILED), cond: ready, err: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			met, err := indexWaitConditionMet(tc.status, &tc.cond)
+			if tc.err {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, met)
+		})
+	}
+}
+
+func TestIndexWaitRequiredPolls(t *testing.T) {
+	assert.Equal(t, 1, indexWaitRequiredPolls(&flags.WaitConditionFlag{Condition: flags.WaitReady}))
+	assert.Equal(t, 2, indexWaitRequiredPolls(&flags.WaitConditionFlag{Condition: flags.WaitMerged}))
+	assert.Equal(t, 2, indexWaitRequiredPolls(&flags.WaitConditionFlag{Condition: flags.WaitUnmergedBelow, Threshold: 1}))
+}
@@ -0,0 +1,130 @@
+// Package jsonpath executes the JSONPath templates accepted by kubectl's
+// "--output jsonpath=..." flag using kubectl's own implementation,
+// k8s.io/client-go/util/jsonpath. Templates mix plain text with expressions
+// in braces, for example:
+//
+//	{range [*]}{.definition.id.name}{"\t"}{.status.unmergedRecordCount}{"\n"}{end}
+//
+// See https://kubernetes.io/docs/reference/kubectl/jsonpath/ for the syntax.
+// As with kubectl, expressions which match nothing produce no output.
+//
+// Data is expected to be JSON decoded with UseNumber. Protobuf JSON writes
+// 64-bit integers as strings, so strings holding an integer are converted to
+// numbers, which lets filters such as [?(@.status.unmergedRecordCount > 1000)]
+// compare them. They print the same either way.
+package jsonpath
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+
+	"k8s.io/client-go/util/jsonpath"
+)
+
+// Template is a parsed JSONPath template.
+type Template struct {
+	jp *jsonpath.JSONPath
+}
+
+// Parse parses a JSONPath template. Unlike kubectl, a {range} without an
+// {end}, or the reverse, is rejected rather than silently ignored.
+func Parse(text string) (*Template, error) {
+	parser, err := jsonpath.Parse("jsonpath", text)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkRanges(parser.Root); err != nil {
+		return nil, err
+	}
+
+	jp := jsonpath.New("jsonpath").AllowMissingKeys(true)
+
+	if err := jp.Parse(text); err != nil {
+		return nil, err
+	}
+
+	return &Template{jp: jp}, nil
+}
+
+// checkRanges returns an error unless each {range} has a matching {end}.
+func checkRanges(root *jsonpath.ListNode) error {
+	depth := 0
+
+	for _, n := range root.Nodes {
+		list, ok := n.(*jsonpath.ListNode)
+		if !ok || len(list.Nodes) == 0 {
+			continue
+		}
+
+		id, ok := list.Nodes[0].(*jsonpath.IdentifierNode)
+		if !ok {
+			continue
+		}
+
+		switch id.Name {
+		case "range":
+			depth++
+		case "end":
+			if depth == 0 {
+				return fmt.Errorf("{end} without a matching {range}")
+			}
+
+			depth--
+		}
+	}
+
+	if depth > 0 {
+		return fmt.Errorf("{range} without a matching {end}")
+	}
+
+	return nil
+}
+
+// Execute writes the template, evaluated against data, to w.
+func (t *Template) Execute(w io.Writer, data any) error {
+	if err := t.jp.Execute(w, normalize(data)); err != nil {
+		return fmt.Errorf("failed to execute jsonpath template: %w", err)
+	}
+
+	return nil
+}
+
+// normalize converts json.Numbers, and strings holding an integer, into
+// int64 or float64 so that they can be compared by filters.
+func normalize(val any) any {
+	switch v := val.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+
+		f, _ := v.Float64()
+
+		return f
+	case string:
+		if i, err := strconv.ParseInt(v, 10, 64); err == nil && strconv.FormatInt(i, 10) == v {
+			return i
+		}
+
+		return v
+	case map[string]any:
+		m := make(map[string]any, len(v))
+		for k, e := range v {
+			m[k] = normalize(e)
+		}
+
+		return m
+	case []any:
+		l := make([]any, len(v))
+		for i, e := range v {
+			l[i] = normalize(e)
+		}
+
+		return l
+	default:
+		return v
+	}
+}
@@ -0,0 +1,202 @@
+//go:build unit
+
+package jsonpath
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testData = `[
+	{
+		"definition": {"id": {"namespace": "test", "name": "a"}, "dimensions": 3, "labels": {"env": "prod"}},
+		"status": {"unmergedRecordCount": "10", "status": "READY"}
+	},
+	{
+		"definition": {"id": {"namespace": "test", "name": "b"}, "dimensions": 128, "labels": {}},
+		"status": {"unmergedRecordCount": "2000000", "status": "CREATING"}
+	}
+]`
+
+func decode(t *testing.T, s string) any {
+	t.Helper()
+
+	decoder := json.NewDecoder(strings.NewReader(s))
+	decoder.UseNumber()
+
+	var data any
+
+	require.NoError(t, decoder.Decode(&data))
+
+	return data
+}
+
+func TestExecute(t *testing.T) {
+	data := decode(t, testData)
+
+	testCases := []struct {
+		name     string
+		template string
+		expected string
+	}{
+		{
+			name:     "wildcard field",
+			template: "{[*].definition.id.name}",
+			expected: "a b",
+		},
+		{
+			name:     "root and index",
+			template: "{$[0].status.unmergedRecordCount}",
+			expected: "10",
+		},
+		{
+			name:     "negative index",
+			template: "{[-1].definition.dimensions}",
+			expected: "128",
+		},
+		{
+			name:     "slice",
+			template: "{[0:1].definition.id.name}",
+			expected: "a",
+		},
+		{
+			name:     "slice with step",
+			template: "{[0:2:2].definition.id.name}",
+			expected: "a",
+		},
+		{
+			name:     "bracket field",
+			template: "{[1]['definition']['id'].name}",
+			expected: "b",
+		},
+		{
+			name:     "text and literals",
+			template: `names: {[*].definition.id.name}{"\n"}`,
+			expected: "names: a b\n",
+		},
+		{
+			name:     "field union",
+			template: "{[0].definition.id['namespace','name']}",
+			expected: "test a",
+		},
+		{
+			name:     "index union",
+			template: "{[1,0].definition.id.name}",
+			expected: "b a",
+		},
+		{
+			name:     "range",
+			template: `{range [*]}{.definition.id.name}{"\t"}{.status.status}{"\n"}{end}`,
+			expected: "a\tREADY\nb\tCREATING\n",
+		},
+		{
+			name:     "range over filter",
+			template: `{range [?(@.definition.dimensions > 3)]}{.definition.id.name}{"\n"}{end}`,
+			expected: "b\n",
+		},
+		{
+			name:     "nested range",
+			template: `{range [*]}{range .definition.id['name','namespace']}{@}{","}{end}{end}`,
+			expected: "a,test,b,test,",
+		},
+		{
+			name:     "filter string",
+			template: `{[?(@.status.status=="READY")].definition.id.name}`,
+			expected: "a",
+		},
+		{
+			name:     "filter not equal",
+			template: `{[?(@.status.status!="READY")].definition.id.name}`,
+			expected: "b",
+		},
+		{
+			name:     "filter number",
+			template: `{[?(@.definition.dimensions <= 3)].definition.id.name}`,
+			expected: "a",
+		},
+		{
+			name:     "filter integer string",
+			template: `{[?(@.status.unmergedRecordCount > 1000)].definition.id.name}`,
+			expected: "b",
+		},
+		{
+			name:     "filter exists",
+			template: `{[?(@.definition.labels.env)].definition.id.name}`,
+			expected: "a",
+		},
+		{
+			name:     "recursive descent",
+			template: "{..id.name}",
+			expected: "a b",
+		},
+		{
+			name:     "missing field",
+			template: "x{[0].missing}y",
+			expected: "xy",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tmpl, err := Parse(tc.template)
+			require.NoError(t, err)
+
+			out := &bytes.Buffer{}
+			require.NoError(t, tmpl.Execute(out, data))
+			assert.Equal(t, tc.expected, out.String())
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	testCases := []string{
+		"{.a",
+		"{.a[}",
+		"{.a[x]}",
+		`{"unterminated}`,
+		"{.a[?(@.b ~ 1)]}",
+		"{end}",
+		"{range [*]}{.a}",
+		"{range [*]}{range .a}{.b}{end}",
+		"{range [*]}{.a}{end}{end}",
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc, func(t *testing.T) {
+			_, err := Parse(tc)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestExecuteErrors(t *testing.T) {
+	data := decode(t, testData)
+
+	testCases := []struct {
+		name     string
+		template string
+	}{
+		{
+			name:     "filter on an object",
+			template: `{[0].definition[?(@.dimensions > 1)]}`,
+		},
+		{
+			name:     "incompatible comparison",
+			template: `{[?(@.status.status > 1)].definition.id.name}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tmpl, err := Parse(tc.template)
+			require.NoError(t, err)
+
+			assert.ErrorContains(t, tmpl.Execute(&bytes.Buffer{}, data), "failed to execute jsonpath template")
+		})
+	}
+}
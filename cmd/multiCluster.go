@@ -0,0 +1,153 @@
+package cmd
+
+import (
+	"asvec/cmd/flags"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+
+	avs "github.com/aerospike/avs-client-go"
+	"github.com/aerospike/avs-client-go/protos"
+	"github.com/spf13/viper"
+)
+
+// allClusters is the --cluster-name which selects every cluster in the
+// configuration file.
+const allClusters = "all"
+
+// clusterResult is the result of running a command against one of multiple
+// clusters.
+//
+//nolint:govet // Padding not a concern for a CLI
+type clusterResult[T any] struct {
+	cluster string
+	isLB    bool
+	value   T
+	err     error
+}
+
+// indexListResult is the indexes of a cluster and the status of each.
+type indexListResult struct {
+	indexes  *protos.IndexDefinitionList
+	statuses []*protos.IndexStatusResponse
+}
+
+// multiClusterNames returns the clusters selected by --cluster-name when it
+// is a comma separated list, e.g. "us,eu", or "all". It returns nil when a
+// single cluster is selected.
+func multiClusterNames() ([]string, error) {
+	clusterName := rootFlags.clusterName
+
+	if clusterName != allClusters && !strings.Contains(clusterName, ",") {
+		return nil, nil
+	}
+
+	if viper.IsSet(flags.Host) || viper.IsSet(flags.Seeds) {
+		return nil, fmt.Errorf(
+			"--%s and --%s can't be used with multiple clusters, each cluster's hosts are read from the configuration file",
+			flags.Host, flags.Seeds,
+		)
+	}
+
+	names := []string{}
+
+	if clusterName == allClusters {
+		for name, section := range viper.AllSettings() {
+			// Flags are bound under the selected cluster name so "all" is
+			// not a section of the configuration file.
+			if _, ok := section.(map[string]any); ok && name != allClusters {
+				names = append(names, name)
+			}
+		}
+
+		if len(names) == 0 {
+			return nil, fmt.Errorf("no clusters found in the configuration file")
+		}
+
+		slices.Sort(names)
+
+		return names, nil
+	}
+
+	for _, name := range strings.Split(clusterName, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			return nil, fmt.Errorf("--%s %q contains an empty cluster name", flags.ClusterName, clusterName)
+		}
+
+		if !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+
+	return names, nil
+}
+
+// multiClusterHelp returns the help text of a command which supports multiple
+// clusters. subject is what the command lists, e.g. "indexes".
+func multiClusterHelp(subject string) string {
+	return fmt.Sprintf(`To list the %s of multiple clusters, set --%s to a
+comma separated list of clusters from the configuration file, or %q for every
+cluster. Results are merged with a leading Cluster column.`, subject, flags.ClusterName, allClusters)
+}
+
+// forEachCluster runs fn concurrently against each cluster, connecting with
+// the cluster's section of the configuration file and the timeout of base.
+// In watch mode each cluster's client is cached between runs. Results are in
+// the order of names. A cluster which fails is reported as an
+// error and does not stop the others.
+func forEachCluster[T any](
+	names []string,
+	base *flags.ClientFlags,
+	fn func(client *avs.Client, clientFlags *flags.ClientFlags) (T, error),
+) []*clusterResult[T] {
+	results := make([]*clusterResult[T], len(names))
+	wg := sync.WaitGroup{}
+
+	for i, name := range names {
+		wg.Add(1)
+
+		go func(i int, name string) {
+			defer wg.Done()
+
+			result := &clusterResult[T]{cluster: name}
+			results[i] = result
+
+			clientFlags, err := newClusterClientFlags(name, base)
+			if err != nil {
+				result.err = err
+				return
+			}
+
+			result.isLB = isLoadBalancer(clientFlags.Seeds)
+
+			client, releaseClient, err := acquireClusterClient(name, clientFlags)
+			if err != nil {
+				result.err = err
+				return
+			}
+			defer releaseClient()
+
+			result.value, result.err = fn(client, clientFlags)
+			if result.err != nil && clients != nil {
+				clients.cluster(name).lostConnection(clientFlags.Timeout, result.err)
+			}
+		}(i, name)
+	}
+
+	wg.Wait()
+
+	for _, result := range results {
+		if result.err != nil {
+			logger.Error("failed to run against cluster",
+				slog.String("cluster", result.cluster),
+				slog.Any("error", result.err),
+			)
+			view.Errorf("Cluster %s: %s", result.cluster, result.err)
+		}
+	}
+
+	return results
+}
@@ -0,0 +1,186 @@
+//go:build unit
+
+package cmd
+
+import (
+	"asvec/cmd/flags"
+	"asvec/cmd/writers"
+	"bytes"
+	"log/slog"
+	"testing"
+	"time"
+
+	avs "github.com/aerospike/avs-client-go"
+	"github.com/aerospike/avs-client-go/protos"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMultiClusterNames(t *testing.T) {
+	clusterName := rootFlags.clusterName
+
+	defer func() {
+		rootFlags.clusterName = clusterName
+
+		viper.Reset()
+	}()
+
+	viper.Reset()
+	viper.Set("us", map[string]any{"host": "1.1.1.1:5000"})
+	viper.Set("eu", map[string]any{"host": "2.2.2.2:5000"})
+	viper.Set("timeout", "5s")
+
+	testCases := []struct {
+		clusterName string
+		expected    []string
+		errContains string
+	}{
+		{clusterName: "default"},
+		{clusterName: "us"},
+		{clusterName: "all", expected: []string{"eu", "us"}},
+		{clusterName: "us, eu,us", expected: []string{"us", "eu"}},
+		{clusterName: "us,,eu", errContains: "empty cluster name"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.clusterName, func(t *testing.T) {
+			rootFlags.clusterName = tc.clusterName
+
+			names, err := multiClusterNames()
+			if tc.errContains != "" {
+				assert.ErrorContains(t, err, tc.errContains)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, names)
+		})
+	}
+
+	viper.Set(flags.Host, "3.3.3.3:5000")
+
+	rootFlags.clusterName = "us,eu"
+
+	_, err := multiClusterNames()
+	assert.ErrorContains(t, err, "can't be used with multiple clusters")
+
+	viper.Reset()
+
+	rootFlags.clusterName = allClusters
+
+	_, err = multiClusterNames()
+	assert.ErrorContains(t, err, "no clusters found")
+}
+
+func TestForEachCluster(t *testing.T) {
+	defer errCode.Store(0)
+	defer viper.Reset()
+
+	out := &bytes.Buffer{}
+	errBuf := &bytes.Buffer{}
+	originalView := view
+	view = NewView(out, errBuf, slog.Default())
+
+	defer func() {
+		view = originalView
+	}()
+
+	addr := newFakeAVSServer(t).String()
+
+	viper.Reset()
+	viper.Set("us", map[string]any{"host": addr})
+	viper.Set("eu", map[string]any{"host": addr})
+
+	base := flags.NewClientFlags()
+	base.Timeout = 5 * time.Second
+
+	results := forEachCluster([]string{"us", "eu", "missing"}, base,
+		func(client *avs.Client, clientFlags *flags.ClientFlags) (*indexListResult, error) {
+			indexList, indexStatusList, err := listIndexesWithStatus(client, clientFlags.Timeout)
+			if err != nil {
+				return nil, err
+			}
+
+			return &indexListResult{indexList, indexStatusList}, nil
+		},
+	)
+
+	require.Len(t, results, 3)
+	assert.Equal(t, "us", results[0].cluster)
+	assert.True(t, results[0].isLB)
+	require.NoError(t, results[0].err)
+	assert.Len(t, results[0].value.indexes.GetIndices(), 1)
+	assert.Equal(t, "eu", results[1].cluster)
+	require.NoError(t, results[1].err)
+	assert.ErrorContains(t, results[2].err, `cluster "missing" not found`)
+
+	assert.Contains(t, errBuf.String(), `Cluster missing: cluster "missing" not found`)
+	assert.Equal(t, uint32(1), errCode.Load())
+
+	view.PrintClusterIndexes(results, false, flags.OutputJSONL)
+
+	lines := bytes.Split(bytes.TrimSpace(out.Bytes()), []byte("\n"))
+	require.Len(t, lines, 2)
+	assert.Contains(t, string(lines[0]), `{"cluster":"us","definition":`)
+	assert.Contains(t, string(lines[1]), `{"cluster":"eu","definition":`)
+}
+
+func TestForEachClusterWatch(t *testing.T) {
+	defer viper.Reset()
+
+	addr := newFakeAVSServer(t).String()
+
+	viper.Reset()
+	viper.Set("us", map[string]any{"host": addr})
+	viper.Set("eu", map[string]any{"host": addr})
+
+	clients = newClientCache()
+
+	defer func() {
+		clients = nil
+	}()
+
+	base := flags.NewClientFlags()
+	base.Timeout = 5 * time.Second
+
+	run := func() []*clusterResult[*avs.Client] {
+		return forEachCluster([]string{"us", "eu"}, base,
+			func(client *avs.Client, _ *flags.ClientFlags) (*avs.Client, error) {
+				return client, nil
+			},
+		)
+	}
+
+	first := run()
+	second := run()
+
+	require.NoError(t, first[0].err)
+	require.NoError(t, first[1].err)
+	assert.Same(t, first[0].value, second[0].value, "client is reused between runs")
+	assert.Same(t, first[1].value, second[1].value, "client is reused between runs")
+	assert.NotSame(t, first[0].value, first[1].value, "each cluster has its own client")
+
+	clients.Close()
+
+	assert.Nil(t, clients.cluster("us").client)
+	assert.Nil(t, clients.cluster("eu").client)
+}
+
+func TestFailIfCheckClusters(t *testing.T) {
+	index := writers.NewIndexJSON(
+		&protos.IndexDefinition{Id: &protos.IndexId{Namespace: "test", Name: "a"}},
+		&protos.IndexStatusResponse{Status: protos.Status_NOT_READY},
+	)
+	items := []any{withCluster("us", index), withCluster("eu", index)}
+
+	check := newTestFailIfCheck(t, indexFailIfFields, indexItemKeys, "status!=READY")
+
+	alerts, err := check.eval(items)
+	require.NoError(t, err)
+	require.Len(t, alerts, 2)
+
+	assert.Equal(t, "us/test.a: status!=READY (status is NOT_READY)", alerts[0].String())
+	assert.Equal(t, "eu/test.a: status!=READY (status is NOT_READY)", alerts[1].String())
+	assert.NotEqual(t, alerts[0].id(), alerts[1].id())
+}
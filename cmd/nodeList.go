@@ -20,103 +20,178 @@ import (
 
 var nodeListFlags = &struct {
 	clientFlags *flags.ClientFlags
-	format      int // For testing. Hidden
+	output      flags.OutputFlag
+	failIf      flags.FailIfFlag
 }{
 	clientFlags: rootFlags.clientFlags,
+	output:      flags.OutputTable,
 }
 
 func newNodeListFlagSet() *pflag.FlagSet {
 	flagSet := &pflag.FlagSet{}
 	flagSet.AddFlagSet(nodeListFlags.clientFlags.NewClientFlagSet())
-
-	err := flags.AddFormatTestFlag(flagSet, &nodeListFlags.format)
-	if err != nil {
-		panic(err)
-	}
+	flagSet.VarP(&nodeListFlags.output, flags.Output, flags.OutputShort, fmt.Sprintf("The output format. Valid values: %s", strings.Join(flags.OutputEnum(), ", "))) //nolint:lll // For readability
+	flagSet.Var(&nodeListFlags.failIf, flags.FailIf, failIfUsage("any node", nodeFailIfFields))                                                                      //nolint:lll // For readability
 
 	return flagSet
 }
 
+var nodeListFailIf = &failIfCheck{
+	conditions: &nodeListFlags.failIf,
+	fields:     nodeFailIfFields,
+	keys:       nodeItemKeys,
+}
+
 // nodeListCmd creates a new cobra command for listing nodes.
 func newNodeListCmd() *cobra.Command {
 	return &cobra.Command{
 		Use:     "ls",
 		Aliases: []string{"list"},
 		Short:   "A command for listing nodes.",
-		Long: fmt.Sprintf(`A command for listing useful information about AVS nodes.
+		Long: fmt.Sprintf(`A command for listing useful information about AVS nodes. Use --%s
+to exit with code %d when a condition is true for any node, e.g. for alerting
+from cron. %s
 
 For example:
 
 %s
 asvec node ls
-		`, HelpTxtSetupEnv),
+asvec node ls --%s 'visible-nodes<3'
+asvec node ls --%s us,eu
+		`, flags.FailIf, flags.ExitCodeConditionFailed, multiClusterHelp("nodes"), HelpTxtSetupEnv, flags.FailIf,
+			flags.ClusterName),
 		PreRunE: func(_ *cobra.Command, _ []string) error {
-			return checkSeedsAndHost()
+			if err := checkSeedsAndHost(); err != nil {
+				return err
+			}
+
+			return nodeListFailIf.validate()
 		},
 		RunE: func(_ *cobra.Command, _ []string) error {
 			logger := logger.With("cmd", "listNodeCmd")
 			logger.Debug("parsed flags",
-				nodeListFlags.clientFlags.NewSLogAttr()...,
+				append(
+					nodeListFlags.clientFlags.NewSLogAttr(),
+					slog.String(flags.Output, nodeListFlags.output.String()),
+					slog.String(flags.FailIf, nodeListFlags.failIf.String()),
+				)...,
 			)
 
-			client, err := createClientFromFlags(nodeListFlags.clientFlags)
+			clusterNames, err := multiClusterNames()
 			if err != nil {
 				return err
 			}
-			defer client.Close()
+
+			if clusterNames != nil {
+				return runNodeListClusters(clusterNames)
+			}
+
+			client, releaseClient, err := acquireClient(nodeListFlags.clientFlags)
+			if err != nil {
+				return err
+			}
+			defer releaseClient()
 
 			ctx, cancel := context.WithTimeout(context.Background(), nodeListFlags.clientFlags.Timeout)
 			defer cancel()
 
-			nodeInfos := getAllNodesInfo(ctx, client)
+			nodeInfos := getAllNodesInfo(ctx, client, nodeListFlags.clientFlags.ListenerName.Val)
 
 			logger.Debug("received node states", slog.Any("nodeStates", nodeInfos))
 
 			isLB := isLoadBalancer(nodeListFlags.clientFlags.Seeds)
 
-			view.PrintNodeInfoList(nodeInfos, isLB, nodeListFlags.format)
+			view.PrintNodeInfoList(nodeInfos, isLB, nodeListFlags.output)
 
-			idsVisibleToAllNodes := getIDsVisibleToAllNodes(nodeInfos)
-			idsVisibleToClient := map[uint64]struct{}{}
+			warnNodeVisibility(nodeInfos, isLB, "")
 
-			for _, nodeState := range nodeInfos {
-				idsVisibleToClient[nodeState.NodeID.GetId()] = struct{}{}
+			items := make([]any, len(nodeInfos))
+			for i, node := range nodeInfos {
+				items[i] = node
 			}
 
-			idsNotVisibleToClient := getNodesNotVisibleToClient(idsVisibleToAllNodes, idsVisibleToClient)
+			return checkFailIf(nodeListFailIf, items)
+		},
+	}
+}
+
+// runNodeListClusters lists the nodes of multiple clusters concurrently.
+func runNodeListClusters(clusterNames []string) error {
+	results := forEachCluster(clusterNames, nodeListFlags.clientFlags,
+		func(client *avs.Client, clientFlags *flags.ClientFlags) ([]*writers.NodeInfo, error) {
+			ctx, cancel := context.WithTimeout(context.Background(), clientFlags.Timeout)
+			defer cancel()
+
+			return getAllNodesInfo(ctx, client, clientFlags.ListenerName.Val), nil
+		},
+	)
+
+	view.PrintClusterNodeInfoLists(results, nodeListFlags.output)
+
+	items := []any{}
+
+	for _, result := range results {
+		if result.err != nil {
+			continue
+		}
+
+		warnNodeVisibility(result.value, result.isLB, result.cluster)
+
+		for _, node := range result.value {
+			items = append(items, withCluster(result.cluster, node))
+		}
+	}
+
+	return checkFailIf(nodeListFailIf, items)
+}
+
+// warnNodeVisibility warns if asvec can't reach every node or the nodes can't
+// all see each other. cluster prefixes the warnings if not empty.
+func warnNodeVisibility(nodeInfos []*writers.NodeInfo, isLB bool, cluster string) {
+	prefix := ""
+	if cluster != "" {
+		prefix = fmt.Sprintf("Cluster %s: ", cluster)
+	}
 
-			if len(idsNotVisibleToClient) != 0 {
-				if !isLB {
-					// TODO handle case where only seedConn are available.
-					view.Warningf(`Not all nodes are visible to asvec. 
+	idsVisibleToAllNodes := getIDsVisibleToAllNodes(nodeInfos)
+	idsVisibleToClient := map[uint64]struct{}{}
+
+	for _, nodeState := range nodeInfos {
+		idsVisibleToClient[nodeState.NodeID.GetId()] = struct{}{}
+	}
+
+	idsNotVisibleToClient := getNodesNotVisibleToClient(idsVisibleToAllNodes, idsVisibleToClient)
+
+	if len(idsNotVisibleToClient) != 0 {
+		if !isLB {
+			// TODO handle case where only seedConn are available.
+			view.Warningf(`%sNot all nodes are visible to asvec. 
 Asvec can't reach: %s
 Possible scenarios:
 1. You should use --host instead of --seeds to indicate you are connection through a load balancer.
 2. Asvec was able to connect to your seeds but the server(s) are returning unreachable endpoints.
    Did you forget --listener-name?
-`, strings.Join(idsNotVisibleToClient, ", "))
-				}
-			}
-
-			idsVisibleToEachNode := getIDsVisibleToEachNode(nodeInfos)
-			nodesNotVisibleToEachNode := getNodesNotVisibleToEachNode(idsVisibleToEachNode, idsVisibleToAllNodes)
+`, prefix, strings.Join(idsNotVisibleToClient, ", "))
+		}
+	}
 
-			if len(nodesNotVisibleToEachNode) != 0 {
-				msg := "Not all nodes are visible to each other. The following nodes are not visible to each other:\n"
+	idsVisibleToEachNode := getIDsVisibleToEachNode(nodeInfos)
+	nodesNotVisibleToEachNode := getNodesNotVisibleToEachNode(idsVisibleToEachNode, idsVisibleToAllNodes)
 
-				for id, nodesNotVisible := range nodesNotVisibleToEachNode {
-					msg += fmt.Sprintf("Node %d can't see: %s\n", id, strings.Join(nodesNotVisible, ", "))
-				}
+	if len(nodesNotVisibleToEachNode) != 0 {
+		msg := prefix + "Not all nodes are visible to each other. The following nodes are not visible to each other:\n"
 
-				view.Warning(msg)
-			}
+		for id, nodesNotVisible := range nodesNotVisibleToEachNode {
+			msg += fmt.Sprintf("Node %d can't see: %s\n", id, strings.Join(nodesNotVisible, ", "))
+		}
 
-			return nil
-		},
+		view.Warning(msg)
 	}
 }
 
-func getAllNodesInfo(ctx context.Context, client *avs.Client) []*writers.NodeInfo {
+// getAllNodesInfo gets the information of every node. Endpoints are those
+// advertised for listenerName, or the default listener if it is nil.
+func getAllNodesInfo(ctx context.Context, client *avs.Client, listenerName *string) []*writers.NodeInfo {
 	nodeIDs := client.NodeIDs(ctx)
 
 	logger.Debug("received node ids", slog.Any("nodeIds", nodeIDs))
@@ -174,7 +249,7 @@ func getAllNodesInfo(ctx context.Context, client *avs.Client) []*writers.NodeInf
 				endpoints, err := client.ClusterEndpoints(
 					ctx,
 					nodeId,
-					nodeListFlags.clientFlags.ListenerName.Val, // TODO: May want to request more names.
+					listenerName, // TODO: May want to request more names.
 				)
 				if err != nil {
 					l.ErrorContext(ctx,
@@ -318,5 +393,5 @@ func init() {
 	nodeListCmd.Flags().AddFlagSet(newNodeListFlagSet())
 
 	// Add watch functionality to the node list command
-	wrapCommandWithWatch(nodeListCmd)
+	wrapCommandWithWatch(nodeListCmd, &watchChanges{keys: nodeItemKeys})
 }
@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"os"
+	"time"
+
+	"github.com/jedib0t/go-pretty/v6/progress"
+	"golang.org/x/term"
+)
+
+// newProgressTracker returns a tracker that is rendered as a progress bar on
+// stderr when stderr is a terminal. A total of 0 renders an indeterminate
+// progress bar. The returned function stops rendering and must be called once
+// the work being tracked is done.
+func newProgressTracker(message string, total int64, units progress.Units) (*progress.Tracker, func()) {
+	tracker := &progress.Tracker{
+		Message: message,
+		Total:   total,
+		Units:   units,
+	}
+
+	if !term.IsTerminal(int(os.Stderr.Fd())) {
+		return tracker, func() {}
+	}
+
+	pw := progress.NewWriter()
+	pw.SetOutputWriter(view.err)
+	pw.SetAutoStop(false)
+	pw.SetTrackerPosition(progress.PositionRight)
+	pw.SetUpdateFrequency(200 * time.Millisecond)
+	pw.SetMessageLength(40)
+	pw.ShowETA(true)
+	pw.Style().Visibility.Speed = true
+	pw.AppendTracker(tracker)
+
+	go pw.Render()
+
+	return tracker, func() {
+		tracker.MarkAsDone()
+
+		// Give the writer a chance to render the final state before stopping.
+		time.Sleep(250 * time.Millisecond)
+		pw.Stop()
+
+		for pw.IsRenderInProgress() {
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+}
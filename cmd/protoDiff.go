@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// fieldDiff is a field which has a different value in two proto messages.
+// The field is the lowerCamelCase path used by the protobuf JSON mapping.
+type fieldDiff struct {
+	field string
+	old   string
+	new   string
+}
+
+// diffMessages returns the fields which differ between from and to in
+// declaration order. When partial is true only fields which are set in to are
+// compared, so that e.g. server defaults missing from a manifest are not
+// reported as differences.
+func diffMessages(prefix string, from, to protoreflect.Message, partial bool) []fieldDiff {
+	diffs := []fieldDiff{}
+	fields := to.Descriptor().Fields()
+
+	for i := range fields.Len() {
+		fd := fields.Get(i)
+
+		if !to.Has(fd) && (partial || !from.Has(fd)) {
+			continue
+		}
+
+		field := fd.JSONName()
+		if prefix != "" {
+			field = prefix + "." + field
+		}
+
+		if fd.Message() != nil && !fd.IsList() && !fd.IsMap() {
+			diffs = append(diffs, diffMessages(field, from.Get(fd).Message(), to.Get(fd).Message(), partial)...)
+			continue
+		}
+
+		if from.Get(fd).Equal(to.Get(fd)) {
+			continue
+		}
+
+		diffs = append(diffs, fieldDiff{
+			field: field,
+			old:   formatProtoField(from, fd),
+			new:   formatProtoField(to, fd),
+		})
+	}
+
+	return diffs
+}
+
+func formatProtoField(m protoreflect.Message, fd protoreflect.FieldDescriptor) string {
+	if !m.Has(fd) {
+		return "<unset>"
+	}
+
+	v := m.Get(fd)
+
+	switch {
+	case fd.IsMap():
+		entries := map[string]string{}
+
+		v.Map().Range(func(k protoreflect.MapKey, val protoreflect.Value) bool {
+			entries[k.String()] = val.String()
+			return true
+		})
+
+		pairs := make([]string, 0, len(entries))
+		for _, k := range slices.Sorted(maps.Keys(entries)) {
+			pairs = append(pairs, k+"="+entries[k])
+		}
+
+		return "{" + strings.Join(pairs, ", ") + "}"
+	case fd.IsList():
+		items := make([]string, v.List().Len())
+		for i := range items {
+			items[i] = v.List().Get(i).String()
+		}
+
+		return "[" + strings.Join(items, ", ") + "]"
+	case fd.Enum() != nil:
+		if e := fd.Enum().Values().ByNumber(v.Enum()); e != nil {
+			return string(e.Name())
+		}
+
+		return fmt.Sprint(v.Enum())
+	default:
+		return v.String()
+	}
+}
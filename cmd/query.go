@@ -1,12 +1,17 @@
 package cmd
 
 import (
+	"asvec/cmd/embed"
 	"asvec/cmd/flags"
+	"asvec/cmd/records"
+	"asvec/cmd/writers"
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"math"
 	"reflect"
+	"strings"
 
 	"github.com/aerospike/avs-client-go"
 	"github.com/aerospike/avs-client-go/protos"
@@ -24,40 +29,63 @@ var queryFlags = &struct {
 	keyString       flags.StringOptionalFlag
 	keyInt          flags.IntOptionalFlag
 	vector          flags.VectorFlag
+	text            string
+	embedding       *flags.EmbeddingFlags
 	maxResults      uint32
 	maxDataKeys     uint
 	maxDataColWidth uint
 	includeFields   []string
 	hnswEf          flags.Uint32OptionalFlag
-	format          int // For testing. Hidden
+	queryFile       string
+	fileFormat      flags.FileFormatFlag
+	parallelism     int
+	filter          flags.FilterFlag
+	maxDistance     flags.Float32OptionalFlag
+	dedupBy         string
+	rerank          flags.RerankFlag
+	output          flags.OutputFlag
 }{
 	clientFlags: rootFlags.clientFlags,
+	embedding:   flags.NewEmbeddingFlags(),
+	output:      flags.OutputTable,
 }
 
 const (
 	defaultMaxResults             = 5
 	defaultMaxDataKeys            = 5
 	failedToRunVectorSearchErrMsg = "failed to run vector search"
+
+	// searchOverFetch is how many times more neighbors than needed are
+	// searched for when --filter or --dedup-by is set, since some are
+	// expected to be dropped. The search is repeated with a limit this many
+	// times larger until enough remain or maxSearchCandidates is reached.
+	searchOverFetch     = 4
+	maxSearchCandidates = 10000
 )
 
 func newQueryFlagSet() *pflag.FlagSet {
 	flagSet := &pflag.FlagSet{}
-	flagSet.StringVarP(&queryFlags.namespace, flags.Namespace, flags.NamespaceShort, "", "The namespace for the index to query.")                                                                                          //nolint:lll // For readability
-	flagSet.VarP(&queryFlags.set, flags.Set, flags.SetShort, fmt.Sprintf("When a --%s query is done you may also need to provide a set so the appropriate record is retrieved.", flags.KeyString))                         //nolint:lll // For readability
-	flagSet.StringVarP(&queryFlags.indexName, flags.IndexName, flags.IndexNameShort, "", "The name of the index to query.")                                                                                                //nolint:lll // For readability
-	flagSet.VarP(&queryFlags.keyString, flags.KeyString, flags.KeyStrShort, "Optionally use the vector from the given string key to perform a query.")                                                                     //nolint:lll // For readability
-	flagSet.VarP(&queryFlags.keyInt, flags.KeyInt, flags.KeyIntShort, "Optionally use the vector from the given integer key to perform a query.")                                                                          //nolint:lll // For readability
-	flagSet.VarP(&queryFlags.vector, flags.Vector, flags.VectorShort, "The vector to use as a query. Values true/false and 1/0 will result in a binary vector. Values containing a decimal will result in a float vector") //nolint:lll // For readability
-	flagSet.Uint32VarP(&queryFlags.maxResults, flags.MaxResults, "r", defaultMaxResults, "The maximum number of records to return.")                                                                                       //nolint:lll // For readability
-	flagSet.UintVarP(&queryFlags.maxDataKeys, flags.MaxDataKeys, "m", defaultMaxDataKeys, "The maximum number of record data keys to display before truncating.")                                                          //nolint:lll // For readability
-	flagSet.UintVarP(&queryFlags.maxDataColWidth, flags.MaxDataColWidth, flags.MaxDataColWidthShort, 50, "The maximum column width for record data before wrapping. To display long values on a single line set to 0.")    //nolint:lll // For readability
-	flagSet.StringSliceVarP(&queryFlags.includeFields, flags.Fields, "f", nil, "Fields names to include when displaying record data.")                                                                                     //nolint:lll // For readability
-	flagSet.Var(&queryFlags.hnswEf, flags.HnswEf, "The default number of candidate nearest neighbors shortlisted during search. Larger values provide better recall at the cost of longer search times.")                  //nolint:lll // For readability
-
-	err := flags.AddFormatTestFlag(flagSet, &queryFlags.format)
-	if err != nil {
-		panic(err)
-	}
+	flagSet.StringVarP(&queryFlags.namespace, flags.Namespace, flags.NamespaceShort, "", "The namespace for the index to query.")                                                                                                                            //nolint:lll // For readability
+	flagSet.VarP(&queryFlags.set, flags.Set, flags.SetShort, fmt.Sprintf("When a --%s query is done you may also need to provide a set so the appropriate record is retrieved.", flags.KeyString))                                                           //nolint:lll // For readability
+	flagSet.StringVarP(&queryFlags.indexName, flags.IndexName, flags.IndexNameShort, "", "The name of the index to query.")                                                                                                                                  //nolint:lll // For readability
+	flagSet.VarP(&queryFlags.keyString, flags.KeyString, flags.KeyStrShort, "Optionally use the vector from the given string key to perform a query.")                                                                                                       //nolint:lll // For readability
+	flagSet.VarP(&queryFlags.keyInt, flags.KeyInt, flags.KeyIntShort, "Optionally use the vector from the given integer key to perform a query.")                                                                                                            //nolint:lll // For readability
+	flagSet.VarP(&queryFlags.vector, flags.Vector, flags.VectorShort, fmt.Sprintf("The vector to use as a query. Values true/false and 1/0 will result in a binary vector. Values containing a decimal will result in a float vector. %s", vectorInputHelp)) //nolint:lll // For readability
+	flagSet.StringVar(&queryFlags.text, flags.Text, "", fmt.Sprintf("Text to embed with the index's model and use as the query. Requires --%s or --%s.", flags.EmbeddingURL, flags.EmbeddingCommand))                                                        //nolint:lll // For readability
+	flagSet.Uint32VarP(&queryFlags.maxResults, flags.MaxResults, "r", defaultMaxResults, "The maximum number of records to return.")                                                                                                                         //nolint:lll // For readability
+	flagSet.UintVarP(&queryFlags.maxDataKeys, flags.MaxDataKeys, "m", defaultMaxDataKeys, "The maximum number of record data keys to display before truncating.")                                                                                            //nolint:lll // For readability
+	flagSet.UintVarP(&queryFlags.maxDataColWidth, flags.MaxDataColWidth, flags.MaxDataColWidthShort, 50, "The maximum column width for record data before wrapping. To display long values on a single line set to 0.")                                      //nolint:lll // For readability
+	flagSet.StringSliceVarP(&queryFlags.includeFields, flags.Fields, "f", nil, "Fields names to include when displaying record data.")                                                                                                                       //nolint:lll // For readability
+	flagSet.Var(&queryFlags.hnswEf, flags.HnswEf, "The default number of candidate nearest neighbors shortlisted during search. Larger values provide better recall at the cost of longer search times.")                                                    //nolint:lll // For readability
+	flagSet.StringVar(&queryFlags.queryFile, flags.QueryFile, "", "A JSONL, CSV, or fvecs file of queries to run. Each query has an id and either a vector or the key of a record whose vector is used.")                                                    //nolint:lll // For readability
+	flagSet.Var(&queryFlags.fileFormat, flags.FileFormat, fmt.Sprintf("The format of --%s. Inferred from the file extension if not provided.", flags.QueryFile))                                                                                             //nolint:lll // For readability
+	flagSet.IntVar(&queryFlags.parallelism, flags.Parallelism, defaultQueryParallelism, fmt.Sprintf("The number of queries from --%s to run concurrently, and of records read concurrently by --%s exact.", flags.QueryFile, flags.Rerank))                  //nolint:lll // For readability
+	flagSet.Var(&queryFlags.filter, flags.Filter, "Only return records whose bins match a filter expression, e.g. 'tenant == acme and lang in (en, de)'. See the examples for the syntax.")                                                                  //nolint:lll // For readability
+	flagSet.Var(&queryFlags.maxDistance, flags.MaxDistance, "Only return records within this distance of the query.")                                                                                                                                        //nolint:lll // For readability
+	flagSet.StringVar(&queryFlags.dedupBy, flags.DedupBy, "", "Only return the closest record for each value of this bin, e.g. one chunk per document.")                                                                                                     //nolint:lll // For readability
+	flagSet.Var(&queryFlags.rerank, flags.Rerank, fmt.Sprintf("Re-rank the results by recomputing each distance from the record's stored vector. Both distances are displayed. Valid values: %s", strings.Join(flags.RerankEnum(), ", ")))                   //nolint:lll // For readability
+	flagSet.VarP(&queryFlags.output, flags.Output, flags.OutputShort, fmt.Sprintf("The output format. Valid values: %s", strings.Join(flags.OutputEnum(), ", ")))                                                                                            //nolint:lll // For readability
+	flagSet.AddFlagSet(queryFlags.embedding.NewFlagSet())
 
 	return flagSet
 }
@@ -92,17 +120,59 @@ asvec query -i my-index -n my-namespace -v "[0.5,0.1,0.3,0.4,1.0]" --max-width 1
 # Query using your own bool vector and change the number of DATA rows displayed to 10.
 asvec query -i my-index -n my-namespace -v "[1,0,1,0,0,0,1,0,1,1]" --max-keys 10
 
-		`, HelpTxtSetupEnv),
+# Query with text. The text is embedded with the model in the index's "%s"
+# label, e.g. all-MiniLM-L6-v2, using an OpenAI compatible endpoint such as a
+# local Ollama server, or a command which reads the text on stdin and prints
+# a JSON array. The command finds the model name in $%s.
+asvec query -i my-index -n my-namespace --%s "red running shoes" --%s http://localhost:11434/v1
+asvec query -i my-index -n my-namespace --%s "red running shoes" --%s ./embed.sh
+
+# Query with a long vector, e.g. saved from a notebook with numpy.save, read
+# from a file or stdin, or given as base64 little-endian float32s.
+asvec query -i my-index -n my-namespace -v @query.npy
+python embed.py | asvec query -i my-index -n my-namespace -v -
+asvec query -i my-index -n my-namespace -v "b64:AAAAPwAAgD8AAABA"
+
+# Run each query in a file, 8 at a time, printing one line of JSON per query.
+# Each line of queries.jsonl is either {"id": "q1", "vector": [0.5, 0.1, ...]}
+# or {"id": "q2", "key": "my-key", "set": "my-set"}. CSV files use the same
+# column names and fvecs queries use the row number as the id.
+asvec query -i my-index -n my-namespace --query-file queries.jsonl --parallelism 8 -o jsonl
+
+# Only return records of the acme tenant in English or German with a price
+# under 100. Comparisons are ==, !=, <, <=, >, >=, in, and not in, combined
+# with and, or, not, and parentheses. A list bin matches if any element does
+# and map bins are searched with dots, e.g. meta.region == eu.
+asvec query -i my-index -n my-namespace -v "[0.5,0.1,0.3]" --%s 'tenant == acme and lang in (en, de) and price < 100'
+
+# Return one chunk per document, at most 0.3 from the query, and compare the
+# index's distances to the exact distances of the stored vectors. A large
+# difference points at the index rather than the embeddings.
+asvec query -i my-index -n my-namespace -v "[0.5,0.1,0.3]" --%s doc_id --%s 0.3 --%s exact
+
+The --%s and --%s options are applied by asvec to the search results. Up to
+%d nearest neighbors are searched for records to return, so a filter which
+matches very few records may return fewer than --%s. --%s applies to the
+exact distance when re-ranking.
+		`, HelpTxtSetupEnv, embed.ModelLabel, embed.ModelEnv, flags.Text, flags.EmbeddingURL,
+			flags.Text, flags.EmbeddingCommand, flags.Filter, flags.DedupBy, flags.MaxDistance, flags.Rerank,
+			flags.Filter, flags.DedupBy, maxSearchCandidates, flags.MaxResults, flags.MaxDistance),
 		PreRunE: func(_ *cobra.Command, _ []string) error {
-			if viper.IsSet(flags.Set) && !(viper.IsSet(flags.KeyString) || viper.IsSet(flags.KeyInt)) {
+			if viper.IsSet(flags.Set) &&
+				!(viper.IsSet(flags.KeyString) || viper.IsSet(flags.KeyInt) || viper.IsSet(flags.QueryFile)) {
 				view.Warningf(
-					"The --%s flag is only used when the --%s or --%s flag is set.",
+					"The --%s flag is only used when the --%s, --%s, or --%s flag is set.",
 					flags.Set,
 					flags.KeyString,
 					flags.KeyInt,
+					flags.QueryFile,
 				)
 			}
 
+			if queryFlags.parallelism < 1 {
+				return fmt.Errorf("--%s must be at least 1", flags.Parallelism)
+			}
+
 			return checkSeedsAndHost()
 		},
 		RunE: func(_ *cobra.Command, _ []string) error {
@@ -114,96 +184,135 @@ asvec query -i my-index -n my-namespace -v "[1,0,1,0,0,0,1,0,1,1]" --max-keys 10
 					slog.Any(flags.KeyString, queryFlags.keyString.Val),
 					slog.Any(flags.KeyInt, queryFlags.keyInt.Val),
 					slog.Any(flags.Vector, queryFlags.vector),
+					slog.String(flags.Text, queryFlags.text),
 					slog.Any(flags.MaxResults, queryFlags.maxResults),
 					slog.Any(flags.MaxDataKeys, queryFlags.maxDataKeys),
 					slog.Any(flags.Fields, queryFlags.includeFields),
+					slog.String(flags.QueryFile, queryFlags.queryFile),
+					slog.String(flags.FileFormat, queryFlags.fileFormat.String()),
+					slog.Int(flags.Parallelism, queryFlags.parallelism),
+					slog.String(flags.Filter, queryFlags.filter.String()),
+					slog.Any(flags.MaxDistance, queryFlags.maxDistance.Val),
+					slog.String(flags.DedupBy, queryFlags.dedupBy),
+					slog.String(flags.Rerank, queryFlags.rerank.String()),
+					slog.String(flags.Output, queryFlags.output.String()),
 				)...,
 			)
 
-			client, err := createClientFromFlags(rootFlags.clientFlags)
-			if err != nil {
+			logger.Debug("parsed embedding flags", queryFlags.embedding.NewSLogAttr()...)
+
+			if queryFlags.includeFields != nil {
+				// If the user has specified fields to include, we should not limit
+				queryFlags.maxDataKeys = 0
+			}
+
+			if queryFlags.maxDataKeys > math.MaxInt {
+				err := fmt.Errorf("maxDataKeys value is larger than the maximum integer: %d", queryFlags.maxDataKeys)
+				logger.Error("unable to convert maxDataKeys to int", slog.Any("error", err))
+				view.Errorf("Failed to get index definition: %s", err)
+
 				return err
 			}
-			defer client.Close()
 
-			ctx, cancel := context.WithTimeout(context.Background(), rootFlags.clientFlags.Timeout)
-			defer cancel()
+			if queryFlags.maxDataColWidth > math.MaxInt {
+				err := fmt.Errorf("maxDataColWidth value is larger than the maximum integer: %d", queryFlags.maxDataColWidth)
+				logger.Error("unable to convert maxDataColWidth to int", slog.Any("error", err))
+				view.Errorf("Failed to get index definition: %s", err)
+
+				return err
+			}
+
+			client, releaseClient, err := acquireClient(rootFlags.clientFlags)
+			if err != nil {
+				return err
+			}
+			defer releaseClient()
 
 			hnswSearchParams := &protos.HnswSearchParams{
 				Ef: queryFlags.hnswEf.Val,
 			}
 
+			if queryFlags.queryFile != "" {
+				//nolint:gosec // Overflow is checked above
+				return runQueryFile(
+					client, hnswSearchParams, int(queryFlags.maxDataKeys), int(queryFlags.maxDataColWidth),
+				)
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), rootFlags.clientFlags.Timeout)
+			defer cancel()
+
 			var (
-				neighbors []*avs.Neighbor
+				neighbors []*writers.Neighbor
+				indexDef  *protos.IndexDefinition
 			)
 
-			if queryFlags.vector.IsSet() {
-				neighbors, err = queryVectorByVector(ctx, client, hnswSearchParams)
+			// The index definition is needed to check the dimensions of the
+			// query vector, to query by key or text, and to re-rank.
+			indexDef, err = client.IndexGet(ctx, queryFlags.namespace, queryFlags.indexName, false)
+			if err != nil {
+				logger.ErrorContext(ctx, "unable to get index definition", slog.Any("error", err))
+				view.Errorf("Failed to get index definition: %s", err)
+
+				return err
+			}
 
+			switch {
+			case queryFlags.vector.IsSet():
+				neighbors, err = queryVectorByVector(ctx, client, indexDef, hnswSearchParams)
 				if err != nil {
 					logger.ErrorContext(ctx, "unable to get vector using provided vector", slog.Any("error", err))
 					view.Errorf("Failed to get vector using vector: %s", err)
 
 					return err
 				}
-			} else {
-				indexDef, err := client.IndexGet(ctx, queryFlags.namespace, queryFlags.indexName, false)
+			case queryFlags.text != "":
+				neighbors, err = queryVectorByText(ctx, client, indexDef, hnswSearchParams)
 				if err != nil {
-					logger.ErrorContext(ctx, "unable to get index definition", slog.Any("error", err))
-					view.Errorf("Failed to get index definition: %s", err)
+					logger.ErrorContext(ctx, "unable to get vector using provided text", slog.Any("error", err))
+					view.Errorf("Failed to get vector using text: %s", err)
 
 					return err
 				}
+			case queryFlags.keyString.Val != nil || queryFlags.keyInt.Val != nil:
+				neighbors, err = queryVectorByKey(ctx, client, indexDef, hnswSearchParams)
+				if err != nil {
+					logger.ErrorContext(ctx, "unable to get vector using provided key", slog.Any("error", err))
+					view.Errorf("Failed to get vector using key: %s", err)
 
-				if queryFlags.keyString.Val != nil || queryFlags.keyInt.Val != nil {
-					neighbors, err = queryVectorByKey(ctx, client, indexDef, hnswSearchParams)
-					if err != nil {
-						logger.ErrorContext(ctx, "unable to get vector using provided key", slog.Any("error", err))
-						view.Errorf("Failed to get vector using key: %s", err)
-
-						return err
-					}
-				} else {
-					neighbors, err = trialAndErrorQuery(ctx, client, int(indexDef.Dimensions), hnswSearchParams)
-					if err != nil {
-						logger.ErrorContext(ctx, "unable to get vector using zero vector", slog.Any("error", err))
-						view.Errorf("Failed to get vector using zero vector: %s", err)
+					return err
+				}
+			default:
+				neighbors, err = trialAndErrorQuery(ctx, client, indexDef, hnswSearchParams)
+				if err != nil {
+					logger.ErrorContext(ctx, "unable to get vector using zero vector", slog.Any("error", err))
+					view.Errorf("Failed to get vector using zero vector: %s", err)
 
-						return err
-					}
+					return err
 				}
 			}
 
 			logger.DebugContext(ctx, "server vector search", slog.Any("response", neighbors))
 
-			if len(neighbors) == 0 {
-				view.Warning("Query returned zero results.")
-				return nil
-			}
+			exact := queryFlags.rerank.IsExact()
 
-			if queryFlags.includeFields != nil {
-				// If the user has specified fields to include, we should not limit
-				queryFlags.maxDataKeys = 0
-			}
-
-			if queryFlags.maxDataKeys > math.MaxInt {
-				err := fmt.Errorf("maxDataKeys value is larger than the maximum integer: %d", queryFlags.maxDataKeys)
-				logger.ErrorContext(ctx, "unable to convert maxDataKeys to int", slog.Any("error", err))
-				view.Errorf("Failed to get index definition: %s", err)
-
-				return err
+			if queryFlags.output.IsStructured() {
+				view.PrintQueryResults(neighbors, exact, queryFlags.output, 0, 0)
+				return nil
 			}
 
-			if queryFlags.maxDataColWidth > math.MaxInt {
-				err := fmt.Errorf("maxDataColWidth value is larger than the maximum integer: %d", queryFlags.maxDataColWidth)
-				logger.ErrorContext(ctx, "unable to convert maxDataColWidth to int", slog.Any("error", err))
-				view.Errorf("Failed to get index definition: %s", err)
+			if len(neighbors) == 0 {
+				if queryFlags.filter.Filter != nil || queryFlags.maxDistance.Val != nil {
+					view.Warningf("Query returned zero results matching --%s or --%s.", flags.Filter, flags.MaxDistance)
+				} else {
+					view.Warning("Query returned zero results.")
+				}
 
-				return err
+				return nil
 			}
 
 			//nolint:gosec // Overflow is checked above
-			view.PrintQueryResults(neighbors, queryFlags.format, int(queryFlags.maxDataKeys), int(queryFlags.maxDataColWidth))
+			view.PrintQueryResults(neighbors, exact, queryFlags.output, int(queryFlags.maxDataKeys), int(queryFlags.maxDataColWidth))
 
 			if !viper.IsSet(flags.MaxResults) {
 				view.Printf("Hint: To increase the number of records returned, use the --%s flag.", flags.MaxResults)
@@ -225,31 +334,113 @@ asvec query -i my-index -n my-namespace -v "[1,0,1,0,0,0,1,0,1,1]" --max-keys 10
 func queryVectorByVector(
 	ctx context.Context,
 	client *avs.Client,
+	indexDef *protos.IndexDefinition,
 	hnswSearchParams *protos.HnswSearchParams,
+) ([]*writers.Neighbor, error) {
+	vector := queryFlags.vector.Vector()
+
+	if err := checkVectorDimensions(indexDef, vector); err != nil {
+		return nil, err
+	}
+
+	return querySearch(ctx, client, indexDef, vector, queryFlags.maxResults, hnswSearchParams)
+}
+
+// checkVectorDimensions returns an error if vector doesn't have the index's
+// dimensions, which the server would otherwise reject after a round trip.
+func checkVectorDimensions(indexDef *protos.IndexDefinition, vector any) error {
+	dims, ok := records.VectorLen(vector)
+	if ok && dims != int(indexDef.GetDimensions()) {
+		return fmt.Errorf(
+			"the vector has %d dimensions but index %s has %d",
+			dims, indexFullName(indexDef.GetId()), indexDef.GetDimensions(),
+		)
+	}
+
+	return nil
+}
+
+// queryVectorByText embeds --text with the index's embedding model and
+// searches with the embedding.
+func queryVectorByText(
+	ctx context.Context,
+	client *avs.Client,
+	indexDef *protos.IndexDefinition,
+	hnswSearchParams *protos.HnswSearchParams,
+) ([]*writers.Neighbor, error) {
+	provider, model, err := embed.Resolve(indexDef.GetLabels(), queryFlags.embedding.Config())
+	if err != nil {
+		switch {
+		case errors.Is(err, embed.ErrNoModel):
+			view.Printf(
+				"Hint: Use the --%s flag, or label the index with its model using \"asvec index update --%s %s=<model>\".",
+				flags.EmbeddingModel, flags.IndexLabels, embed.ModelLabel,
+			)
+		case errors.Is(err, embed.ErrNoProvider):
+			view.Printf("Hint: Use the --%s or --%s flag to embed text.", flags.EmbeddingURL, flags.EmbeddingCommand)
+		}
+
+		return nil, err
+	}
+
+	logger := logger.With(slog.String("model", model), slog.String("provider", provider.Name()))
+	logger.DebugContext(ctx, "embedding query text")
+
+	vector, err := provider.Embed(ctx, queryFlags.text)
+	if err != nil {
+		return nil, fmt.Errorf("unable to embed text with model %s: %w", model, err)
+	}
+
+	if len(vector) != int(indexDef.GetDimensions()) {
+		return nil, fmt.Errorf(
+			"model %s returned a vector with %d dimensions but the index has %d",
+			model, len(vector), indexDef.GetDimensions(),
+		)
+	}
+
+	logger.DebugContext(ctx, "embedded query text", slog.Int("dimensions", len(vector)))
+
+	return querySearch(ctx, client, indexDef, vector, queryFlags.maxResults, hnswSearchParams)
+}
+
+// vectorSearch runs a float or bool vector search depending on the type of
+// vector.
+func vectorSearch(
+	ctx context.Context,
+	client *avs.Client,
+	namespace,
+	indexName string,
+	vector any,
+	maxResults uint32,
+	hnswSearchParams *protos.HnswSearchParams,
+	includeFields []string,
 ) ([]*avs.Neighbor, error) {
-	if queryFlags.vector.FloatSlice != nil {
+	switch v := vector.(type) {
+	case []float32:
 		return client.VectorSearchFloat32(
 			ctx,
-			queryFlags.namespace,
-			queryFlags.indexName,
-			queryFlags.vector.FloatSlice,
-			queryFlags.maxResults,
+			namespace,
+			indexName,
+			v,
+			maxResults,
 			hnswSearchParams,
-			queryFlags.includeFields,
+			includeFields,
 			nil,
 		)
+	case []bool:
+		return client.VectorSearchBool(
+			ctx,
+			namespace,
+			indexName,
+			v,
+			maxResults,
+			hnswSearchParams,
+			includeFields,
+			nil,
+		)
+	default:
+		return nil, fmt.Errorf("unsupported vector type %T", vector)
 	}
-
-	return client.VectorSearchBool(
-		ctx,
-		queryFlags.namespace,
-		queryFlags.indexName,
-		queryFlags.vector.BoolSlice,
-		queryFlags.maxResults,
-		hnswSearchParams,
-		queryFlags.includeFields,
-		nil,
-	)
 }
 
 func queryVectorByKey(
@@ -257,7 +448,7 @@ func queryVectorByKey(
 	client *avs.Client,
 	indexDef *protos.IndexDefinition,
 	hnswSearchParams *protos.HnswSearchParams,
-) ([]*avs.Neighbor, error) {
+) ([]*writers.Neighbor, error) {
 	logger := logger.With(
 		slog.String("key-str", queryFlags.keyString.String()),
 		slog.String("key-int", queryFlags.keyInt.String()),
@@ -311,34 +502,14 @@ func queryVectorByKey(
 		return nil, fmt.Errorf("%s: %w", msg, err)
 	}
 
-	var neighbors []*avs.Neighbor
-
-	switch v := queryVector.(type) {
-	case []float32:
-		neighbors, err = client.VectorSearchFloat32(
-			ctx,
-			queryFlags.namespace,
-			queryFlags.indexName,
-			v,
-			queryFlags.maxResults+1, // we will remove queried vector from results
-			hnswSearchParams,
-			queryFlags.includeFields,
-			nil,
-		)
-
-	case []bool:
-		neighbors, err = client.VectorSearchBool(
-			ctx,
-			queryFlags.namespace,
-			queryFlags.indexName,
-			v,
-			queryFlags.maxResults+1, // we will remove queried vector from results
-			hnswSearchParams,
-			queryFlags.includeFields,
-			nil,
-		)
-	}
-
+	neighbors, err := querySearch(
+		ctx,
+		client,
+		indexDef,
+		queryVector,
+		queryFlags.maxResults+1, // we will remove queried vector from results
+		hnswSearchParams,
+	)
 	if err != nil {
 		logger.ErrorContext(ctx, failedToRunVectorSearchErrMsg, slog.Any("error", err))
 		view.Errorf("Unable to run vector query: %s", err)
@@ -347,7 +518,7 @@ func queryVectorByKey(
 	}
 
 	// Remove the queried vector from the results
-	newNeighbors := make([]*avs.Neighbor, 0, len(neighbors)-1)
+	newNeighbors := make([]*writers.Neighbor, 0, len(neighbors))
 
 	for _, n := range neighbors {
 		if !reflect.DeepEqual(n.Key, key) {
@@ -363,9 +534,11 @@ func queryVectorByKey(
 func trialAndErrorQuery(
 	ctx context.Context,
 	client *avs.Client,
-	dimension int,
+	indexDef *protos.IndexDefinition,
 	hnswSearchParams *protos.HnswSearchParams,
-) ([]*avs.Neighbor, error) {
+) ([]*writers.Neighbor, error) {
+	dimension := int(indexDef.Dimensions)
+
 	logger := logger.With(
 		slog.String("index", queryFlags.indexName),
 		slog.String("namespace", queryFlags.namespace),
@@ -373,16 +546,7 @@ func trialAndErrorQuery(
 	)
 
 	queryFloat32 := make([]float32, dimension)
-	neighbors, err := client.VectorSearchFloat32(
-		ctx,
-		queryFlags.namespace,
-		queryFlags.indexName,
-		queryFloat32,
-		queryFlags.maxResults,
-		hnswSearchParams,
-		queryFlags.includeFields,
-		nil,
-	)
+	neighbors, err := querySearch(ctx, client, indexDef, queryFloat32, queryFlags.maxResults, hnswSearchParams)
 
 	if err != nil {
 		logger.WarnContext(ctx, failedToRunVectorSearchErrMsg, slog.Any("error", err))
@@ -390,16 +554,7 @@ func trialAndErrorQuery(
 
 	if err != nil || len(neighbors) == 0 {
 		queryBool := make([]bool, dimension)
-		neighbors, err = client.VectorSearchBool(
-			ctx,
-			queryFlags.namespace,
-			queryFlags.indexName,
-			queryBool,
-			queryFlags.maxResults,
-			hnswSearchParams,
-			queryFlags.includeFields,
-			nil,
-		)
+		neighbors, err = querySearch(ctx, client, indexDef, queryBool, queryFlags.maxResults, hnswSearchParams)
 
 		if err != nil {
 			logger.ErrorContext(ctx, failedToRunVectorSearchErrMsg, slog.Any("error", err))
@@ -425,8 +580,9 @@ func init() {
 		}
 	}
 
-	queryCmd.MarkFlagsMutuallyExclusive(flags.Vector, flags.KeyString, flags.KeyInt)
+	queryCmd.MarkFlagsMutuallyExclusive(flags.Vector, flags.Text, flags.KeyString, flags.KeyInt, flags.QueryFile)
+	queryCmd.MarkFlagsMutuallyExclusive(flags.EmbeddingURL, flags.EmbeddingCommand)
 
 	// Add watch functionality to the query command
-	wrapCommandWithWatch(queryCmd)
+	wrapCommandWithWatch(queryCmd, &watchChanges{items: "neighbors", keys: []string{"namespace", "set", "key"}})
 }
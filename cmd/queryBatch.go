@@ -0,0 +1,328 @@
+package cmd
+
+import (
+	"asvec/cmd/flags"
+	"asvec/cmd/records"
+	"asvec/cmd/writers"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"reflect"
+	"sync"
+
+	"github.com/aerospike/avs-client-go"
+	"github.com/aerospike/avs-client-go/protos"
+)
+
+const (
+	defaultQueryParallelism = 4
+	queryFileIDField        = "id"
+	queryFileVectorField    = "vector"
+	queryFileKeyField       = "key"
+	queryFileSetField       = "set"
+)
+
+// batchQuery is a single query read from a query file.
+type batchQuery struct {
+	id     any
+	vector any
+	key    any
+	set    *string
+	err    error
+	seq    int
+}
+
+// batchQueryResult is the outcome of running a batchQuery.
+type batchQueryResult struct {
+	id        any
+	neighbors []*writers.Neighbor
+	err       error
+	seq       int
+}
+
+// runQueryFile runs each query in --query-file using a pool of workers and
+// prints the results in the same order as the file.
+func runQueryFile(
+	client *avs.Client,
+	hnswSearchParams *protos.HnswSearchParams,
+	maxDataKeys,
+	maxDataColWidth int,
+) error {
+	format, err := importFileFormat(queryFlags.queryFile, &queryFlags.fileFormat)
+	if err != nil {
+		logger.Error("unable to determine query file format", slog.Any("error", err))
+		return err
+	}
+
+	input, _, err := openImportFile(queryFlags.queryFile)
+	if err != nil {
+		logger.Error("unable to open query file", slog.Any("error", err))
+		return err
+	}
+	defer input.Close()
+
+	reader, err := records.NewReader(input, format, records.ReaderOptions{
+		KeyField:    queryFileIDField,
+		VectorField: queryFileVectorField,
+	})
+	if err != nil {
+		logger.Error("unable to read query file", slog.Any("error", err))
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryFlags.clientFlags.Timeout)
+	defer cancel()
+
+	indexDef, err := client.IndexGet(ctx, queryFlags.namespace, queryFlags.indexName, false)
+	if err != nil {
+		logger.Error("unable to get index definition", slog.Any("error", err))
+		view.Errorf("Failed to get index definition: %s", err)
+
+		return err
+	}
+
+	queryCh := make(chan *batchQuery, queryFlags.parallelism)
+	resultCh := make(chan *batchQueryResult, queryFlags.parallelism)
+
+	var wg sync.WaitGroup
+
+	for w := 0; w < queryFlags.parallelism; w++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for query := range queryCh {
+				resultCh <- runBatchQuery(client, indexDef, query, hnswSearchParams)
+			}
+		}()
+	}
+
+	var readErr error
+
+	go func() {
+		defer func() {
+			close(queryCh)
+			wg.Wait()
+			close(resultCh)
+		}()
+
+		for seq := 0; ; seq++ {
+			record, err := reader.Read()
+			if err != nil {
+				if errors.Is(err, io.EOF) {
+					return
+				}
+
+				if records.IsRecordError(err) {
+					queryCh <- &batchQuery{seq: seq, err: err}
+					continue
+				}
+
+				readErr = err
+
+				return
+			}
+
+			queryCh <- newBatchQuery(seq, record)
+		}
+	}()
+
+	// Results arrive out of order so they are held until all earlier results
+	// have been printed.
+	pending := map[int]*batchQueryResult{}
+	next := 0
+	total := 0
+	failed := 0
+
+	// JSON, YAML, and template results are printed together as a single
+	// document once all queries have run.
+	printTogether := queryFlags.output.IsStructured() && queryFlags.output != flags.OutputJSONL
+	structured := []*writers.QueryResult{}
+
+	for result := range resultCh {
+		pending[result.seq] = result
+
+		for {
+			result, ok := pending[next]
+			if !ok {
+				break
+			}
+
+			delete(pending, next)
+			next++
+			total++
+
+			if result.err != nil {
+				failed++
+
+				logger.Error("query failed", slog.Any("id", result.id), slog.Any("error", result.err))
+			}
+
+			if printTogether {
+				structured = append(structured, writers.NewQueryResult(result.id, result.neighbors, result.err))
+				continue
+			}
+
+			printBatchQueryResult(result, maxDataKeys, maxDataColWidth)
+		}
+	}
+
+	if printTogether {
+		view.PrintQueryResultList(structured, queryFlags.output)
+	}
+
+	if readErr != nil {
+		logger.Error("unable to read query file", slog.Any("error", readErr))
+		return readErr
+	}
+
+	if failed > 0 {
+		view.Warningf("%d of %d queries failed", failed, total)
+	}
+
+	return nil
+}
+
+func printBatchQueryResult(result *batchQueryResult, maxDataKeys, maxDataColWidth int) {
+	if queryFlags.output == flags.OutputJSONL {
+		view.PrintQueryResultsJSONL(result.id, result.neighbors, result.err)
+		return
+	}
+
+	if result.err != nil {
+		if result.id == nil {
+			view.Errorf("Failed to run query: %s", result.err)
+		} else {
+			view.Errorf("Failed to run query %v: %s", result.id, result.err)
+		}
+
+		return
+	}
+
+	view.PrintBatchQueryResults(
+		result.id, result.neighbors, queryFlags.rerank.IsExact(), queryFlags.output, maxDataKeys, maxDataColWidth,
+	)
+}
+
+// newBatchQuery creates a query from a record read from a query file. A
+// query uses either its vector field or the vector of the record with its
+// key.
+func newBatchQuery(seq int, record *records.Record) *batchQuery {
+	query := &batchQuery{
+		seq: seq,
+		id:  record.Key,
+	}
+
+	if vector, ok := record.Data[queryFileVectorField]; ok {
+		if _, ok := records.VectorLen(vector); !ok {
+			query.err = fmt.Errorf("field %s is not a vector", queryFileVectorField)
+		}
+
+		query.vector = vector
+
+		return query
+	}
+
+	key, ok := record.Data[queryFileKeyField]
+	if !ok {
+		query.err = fmt.Errorf("query must have a %s or %s field", queryFileVectorField, queryFileKeyField)
+		return query
+	}
+
+	switch k := key.(type) {
+	case string, int64:
+		query.key = k
+	default:
+		query.err = fmt.Errorf("unsupported key type %T", key)
+		return query
+	}
+
+	if set, ok := record.Data[queryFileSetField].(string); ok {
+		query.set = &set
+	}
+
+	return query
+}
+
+func runBatchQuery(
+	client *avs.Client,
+	indexDef *protos.IndexDefinition,
+	query *batchQuery,
+	hnswSearchParams *protos.HnswSearchParams,
+) *batchQueryResult {
+	result := &batchQueryResult{
+		seq: query.seq,
+		id:  query.id,
+		err: query.err,
+	}
+
+	if result.err != nil {
+		return result
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryFlags.clientFlags.Timeout)
+	defer cancel()
+
+	if query.vector != nil {
+		if result.err = checkVectorDimensions(indexDef, query.vector); result.err != nil {
+			return result
+		}
+
+		result.neighbors, result.err = querySearch(
+			ctx, client, indexDef, query.vector, queryFlags.maxResults, hnswSearchParams,
+		)
+
+		return result
+	}
+
+	set := query.set
+	if set == nil {
+		set = queryFlags.set.Val
+	}
+
+	if set == nil {
+		set = indexDef.SetFilter
+	}
+
+	record, err := client.Get(ctx, queryFlags.namespace, set, query.key, []string{indexDef.Field}, nil)
+	if err != nil {
+		result.err = fmt.Errorf("unable to get record with key %v: %w", query.key, err)
+		return result
+	}
+
+	vector, ok := record.Data[indexDef.Field]
+	if !ok {
+		result.err = fmt.Errorf("record with key %v does not contain field %s", query.key, indexDef.Field)
+		return result
+	}
+
+	neighbors, err := querySearch(
+		ctx,
+		client,
+		indexDef,
+		vector,
+		queryFlags.maxResults+1, // we will remove queried vector from results
+		hnswSearchParams,
+	)
+	if err != nil {
+		result.err = err
+		return result
+	}
+
+	result.neighbors = make([]*writers.Neighbor, 0, len(neighbors))
+
+	for _, n := range neighbors {
+		if !reflect.DeepEqual(n.Key, query.key) {
+			result.neighbors = append(result.neighbors, n)
+		}
+	}
+
+	if len(result.neighbors) > int(queryFlags.maxResults) {
+		result.neighbors = result.neighbors[:queryFlags.maxResults]
+	}
+
+	return result
+}
@@ -0,0 +1,64 @@
+//go:build unit
+
+package cmd
+
+import (
+	"asvec/cmd/records"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewBatchQuery(t *testing.T) {
+	set := "myset"
+
+	testCases := []struct {
+		name     string
+		record   *records.Record
+		expected *batchQuery
+		err      bool
+	}{
+		{
+			name:     "vector query",
+			record:   &records.Record{Key: "q1", Data: map[string]any{"vector": []float32{1, 2}}},
+			expected: &batchQuery{seq: 1, id: "q1", vector: []float32{1, 2}},
+		},
+		{
+			name:     "key query",
+			record:   &records.Record{Key: int64(2), Data: map[string]any{"key": int64(10)}},
+			expected: &batchQuery{seq: 1, id: int64(2), key: int64(10)},
+		},
+		{
+			name:     "key query with set",
+			record:   &records.Record{Key: "q3", Data: map[string]any{"key": "k", "set": "myset"}},
+			expected: &batchQuery{seq: 1, id: "q3", key: "k", set: &set},
+		},
+		{
+			name:   "vector is not a vector",
+			record: &records.Record{Key: "q4", Data: map[string]any{"vector": "foo"}},
+			err:    true,
+		},
+		{
+			name:   "no vector or key",
+			record: &records.Record{Key: "q5", Data: map[string]any{"name": "foo"}},
+			err:    true,
+		},
+		{
+			name:   "invalid key",
+			record: &records.Record{Key: "q6", Data: map[string]any{"key": 1.5}},
+			err:    true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			query := newBatchQuery(1, tc.record)
+			if tc.err {
+				assert.Error(t, query.err)
+				return
+			}
+
+			assert.Equal(t, tc.expected, query)
+		})
+	}
+}
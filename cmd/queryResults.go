@@ -0,0 +1,304 @@
+package cmd
+
+import (
+	"asvec/cmd/filter"
+	"asvec/cmd/flags"
+	"asvec/cmd/writers"
+	"asvec/internal/vecmath"
+	"cmp"
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sync"
+
+	"github.com/aerospike/avs-client-go"
+	"github.com/aerospike/avs-client-go/protos"
+)
+
+// querySearch runs a vector search with the query flags, returning at most
+// limit neighbors. Neighbors which don't match --filter, are further than
+// --max-distance, or repeat a --dedup-by value are dropped, and the rest are
+// re-ranked by --rerank. indexDef is only used to re-rank.
+func querySearch(
+	ctx context.Context,
+	client *avs.Client,
+	indexDef *protos.IndexDefinition,
+	vector any,
+	limit uint32,
+	hnswSearchParams *protos.HnswSearchParams,
+) ([]*writers.Neighbor, error) {
+	search := func(limit uint32, includeFields []string) ([]*avs.Neighbor, error) {
+		return vectorSearch(
+			ctx,
+			client,
+			queryFlags.namespace,
+			queryFlags.indexName,
+			vector,
+			limit,
+			hnswSearchParams,
+			includeFields,
+		)
+	}
+
+	exact := queryFlags.rerank.IsExact()
+	selector := &neighborSelector{filter: queryFlags.filter.Filter, dedupBy: queryFlags.dedupBy}
+
+	// When re-ranking the maximum distance applies to the exact distance.
+	if !exact {
+		selector.maxDistance = queryFlags.maxDistance.Val
+	}
+
+	neighbors, err := selectSearch(search, selector, limit, queryFlags.includeFields)
+	if err != nil {
+		return nil, err
+	}
+
+	if !exact {
+		return writers.NewNeighbors(neighbors), nil
+	}
+
+	return rerankExact(ctx, client, indexDef, vector, neighbors, queryFlags.maxDistance.Val, queryFlags.parallelism)
+}
+
+// neighborSelector selects which search results are returned.
+type neighborSelector struct {
+	filter      *filter.Filter
+	maxDistance *float32
+	dedupBy     string
+}
+
+// overFetch reports whether some neighbors are expected to be dropped, so
+// more should be searched for than are needed. Neighbors further than the
+// maximum distance are always last so don't need replacing.
+func (s *neighborSelector) overFetch() bool {
+	return s.filter != nil || s.dedupBy != ""
+}
+
+// bins returns the bins needed to select neighbors.
+func (s *neighborSelector) bins() []string {
+	bins := []string{}
+
+	if s.filter != nil {
+		bins = append(bins, s.filter.Bins()...)
+	}
+
+	if s.dedupBy != "" && !slices.Contains(bins, s.dedupBy) {
+		bins = append(bins, s.dedupBy)
+	}
+
+	return bins
+}
+
+// selectNeighbors returns the neighbors to keep in order. Of neighbors with
+// the same --dedup-by value the closest is kept. Neighbors without the bin
+// are never duplicates.
+func (s *neighborSelector) selectNeighbors(neighbors []*avs.Neighbor) []*avs.Neighbor {
+	selected := make([]*avs.Neighbor, 0, len(neighbors))
+	seen := map[string]struct{}{}
+
+	for _, n := range neighbors {
+		if s.maxDistance != nil && n.Distance > *s.maxDistance {
+			continue
+		}
+
+		var data map[string]any
+		if n.Record != nil {
+			data = n.Record.Data
+		}
+
+		if s.filter != nil && !s.filter.Match(data) {
+			continue
+		}
+
+		if s.dedupBy != "" {
+			if value, ok := data[s.dedupBy]; ok && value != nil {
+				key := fmt.Sprintf("%T:%v", value, value)
+
+				if _, ok := seen[key]; ok {
+					continue
+				}
+
+				seen[key] = struct{}{}
+			}
+		}
+
+		selected = append(selected, n)
+	}
+
+	return selected
+}
+
+// selectSearch runs search until limit neighbors are selected. When
+// neighbors are expected to be dropped each search asks for searchOverFetch
+// times more neighbors than the last, up to maxSearchCandidates, and stops
+// early if the index has no more neighbors or they are beyond the maximum
+// distance. The bins needed to select neighbors are fetched even if not in
+// includeFields, and removed again before the neighbors are returned.
+func selectSearch(
+	search func(limit uint32, includeFields []string) ([]*avs.Neighbor, error),
+	selector *neighborSelector,
+	limit uint32,
+	includeFields []string,
+) ([]*avs.Neighbor, error) {
+	var extraBins []string
+
+	fetchFields := includeFields
+	if includeFields != nil {
+		for _, bin := range selector.bins() {
+			if !slices.Contains(includeFields, bin) {
+				extraBins = append(extraBins, bin)
+			}
+		}
+
+		fetchFields = append(slices.Clone(includeFields), extraBins...)
+	}
+
+	candidates := limit
+	if selector.overFetch() {
+		candidates = max(min(limit*searchOverFetch, maxSearchCandidates), limit)
+	}
+
+	for {
+		neighbors, err := search(candidates, fetchFields)
+		if err != nil {
+			return nil, err
+		}
+
+		selected := selector.selectNeighbors(neighbors)
+
+		beyondMaxDistance := selector.maxDistance != nil && len(neighbors) > 0 &&
+			neighbors[len(neighbors)-1].Distance > *selector.maxDistance
+
+		if len(selected) >= int(limit) || len(neighbors) < int(candidates) ||
+			candidates >= maxSearchCandidates || beyondMaxDistance || !selector.overFetch() {
+			logger.Debug("selected search results",
+				slog.String(flags.Filter, fmt.Sprint(selector.filter)),
+				slog.String(flags.DedupBy, selector.dedupBy),
+				slog.Any(flags.MaxDistance, selector.maxDistance),
+				slog.Int("candidates", len(neighbors)),
+				slog.Int("selected", len(selected)),
+			)
+
+			if len(selected) > int(limit) {
+				selected = selected[:limit]
+			}
+
+			for _, n := range selected {
+				if n.Record == nil {
+					continue
+				}
+
+				for _, bin := range extraBins {
+					delete(n.Record.Data, bin)
+				}
+			}
+
+			return selected, nil
+		}
+
+		candidates = min(candidates*searchOverFetch, maxSearchCandidates)
+	}
+}
+
+// rerankExact reads the stored vector of each neighbor and sorts the
+// neighbors by their exact distance to vector using the index's distance
+// metric. Neighbors further than maxDistance, if not nil, are dropped.
+// Neighbors whose vector can't be read are logged and sorted last. At most
+// parallelism vectors are read at once.
+func rerankExact(
+	ctx context.Context,
+	client *avs.Client,
+	indexDef *protos.IndexDefinition,
+	vector any,
+	neighbors []*avs.Neighbor,
+	maxDistance *float32,
+	parallelism int,
+) ([]*writers.Neighbor, error) {
+	query, err := vecmath.ToFloat32(vector)
+	if err != nil {
+		return nil, err
+	}
+
+	metric := indexDef.GetVectorDistanceMetric()
+	field := indexDef.GetField()
+	ranked := writers.NewNeighbors(neighbors)
+	wg := sync.WaitGroup{}
+	sem := make(chan struct{}, parallelism)
+
+	for _, n := range ranked {
+		wg.Add(1)
+
+		sem <- struct{}{}
+
+		go func(n *writers.Neighbor) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			distance, err := exactDistance(ctx, client, n.Neighbor, field, metric, query)
+			if err != nil {
+				logger.WarnContext(ctx, "unable to compute exact distance",
+					slog.Any("key", n.Key),
+					slog.Any("error", err),
+				)
+
+				return
+			}
+
+			n.ExactDistance = &distance
+		}(n)
+	}
+
+	wg.Wait()
+
+	if maxDistance != nil {
+		ranked = slices.DeleteFunc(ranked, func(n *writers.Neighbor) bool {
+			return n.ExactDistance == nil || *n.ExactDistance > *maxDistance
+		})
+	}
+
+	slices.SortStableFunc(ranked, func(a, b *writers.Neighbor) int {
+		switch {
+		case a.ExactDistance == nil && b.ExactDistance == nil:
+			return 0
+		case a.ExactDistance == nil:
+			return 1
+		case b.ExactDistance == nil:
+			return -1
+		default:
+			return cmp.Compare(*a.ExactDistance, *b.ExactDistance)
+		}
+	})
+
+	return ranked, nil
+}
+
+// exactDistance reads the vector stored in field of a neighbor's record and
+// returns its distance to query.
+func exactDistance(
+	ctx context.Context,
+	client *avs.Client,
+	n *avs.Neighbor,
+	field string,
+	metric protos.VectorDistanceMetric,
+	query []float32,
+) (float32, error) {
+	record, err := client.Get(ctx, n.Namespace, n.Set, n.Key, []string{field}, nil)
+	if err != nil {
+		return 0, fmt.Errorf("unable to get record: %w", err)
+	}
+
+	stored, ok := record.Data[field]
+	if !ok {
+		return 0, fmt.Errorf("record does not contain field %s", field)
+	}
+
+	v, err := vecmath.ToFloat32(stored)
+	if err != nil {
+		return 0, err
+	}
+
+	return vecmath.Float32Distance(metric, query, v)
+}
@@ -0,0 +1,185 @@
+//go:build unit
+
+package cmd
+
+import (
+	"asvec/cmd/filter"
+	"errors"
+	"testing"
+
+	avs "github.com/aerospike/avs-client-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newFilterTestSearch returns a search over n neighbors where every tenth
+// belongs to the "acme" tenant and the distance is the index, and records the
+// limit of each search.
+func newFilterTestSearch(n int, limits *[]uint32, fields *[]string) func(uint32, []string) ([]*avs.Neighbor, error) {
+	return func(limit uint32, includeFields []string) ([]*avs.Neighbor, error) {
+		*limits = append(*limits, limit)
+		*fields = includeFields
+
+		neighbors := []*avs.Neighbor{}
+
+		for i := 0; i < n && i < int(limit); i++ {
+			tenant := "other"
+			if i%10 == 0 {
+				tenant = "acme"
+			}
+
+			neighbors = append(neighbors, &avs.Neighbor{
+				Key:      i,
+				Distance: float32(i),
+				Record:   &avs.Record{Data: map[string]any{"tenant": tenant, "name": "n", "doc": int64(i / 3)}},
+			})
+		}
+
+		return neighbors, nil
+	}
+}
+
+func TestSelectSearchFilter(t *testing.T) {
+	f, err := filter.Parse("tenant == acme")
+	require.NoError(t, err)
+
+	t.Run("over-fetches until enough match", func(t *testing.T) {
+		var (
+			limits []uint32
+			fields []string
+		)
+
+		neighbors, err := selectSearch(newFilterTestSearch(1000, &limits, &fields), &neighborSelector{filter: f}, 5, nil)
+		require.NoError(t, err)
+
+		assert.Equal(t, []uint32{20, 80}, limits)
+		assert.Nil(t, fields)
+		require.Len(t, neighbors, 5)
+
+		for i, n := range neighbors {
+			assert.Equal(t, i*10, n.Key)
+		}
+	})
+
+	t.Run("stops when the index has no more neighbors", func(t *testing.T) {
+		var (
+			limits []uint32
+			fields []string
+		)
+
+		neighbors, err := selectSearch(newFilterTestSearch(30, &limits, &fields), &neighborSelector{filter: f}, 5, nil)
+		require.NoError(t, err)
+
+		assert.Equal(t, []uint32{20, 80}, limits)
+		assert.Len(t, neighbors, 3)
+	})
+
+	t.Run("stops at the maximum candidates", func(t *testing.T) {
+		var (
+			limits []uint32
+			fields []string
+		)
+
+		never, err := filter.Parse("tenant == nobody")
+		require.NoError(t, err)
+
+		neighbors, err := selectSearch(newFilterTestSearch(100000, &limits, &fields), &neighborSelector{filter: never}, 5, nil)
+		require.NoError(t, err)
+
+		assert.Equal(t, []uint32{20, 80, 320, 1280, 5120, maxSearchCandidates}, limits)
+		assert.Empty(t, neighbors)
+	})
+
+	t.Run("fetches and removes filter bins", func(t *testing.T) {
+		var (
+			limits []uint32
+			fields []string
+		)
+
+		neighbors, err := selectSearch(
+			newFilterTestSearch(1000, &limits, &fields), &neighborSelector{filter: f}, 1, []string{"name"},
+		)
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"name", "tenant"}, fields)
+		require.Len(t, neighbors, 1)
+		assert.NotContains(t, neighbors[0].Record.Data, "tenant")
+	})
+
+	t.Run("returns search errors", func(t *testing.T) {
+		_, err := selectSearch(func(uint32, []string) ([]*avs.Neighbor, error) {
+			return nil, errors.New("boom")
+		}, &neighborSelector{filter: f}, 5, nil)
+		assert.EqualError(t, err, "boom")
+	})
+}
+
+func TestSelectSearchDedupAndMaxDistance(t *testing.T) {
+	t.Run("keeps the closest neighbor of each value", func(t *testing.T) {
+		var (
+			limits []uint32
+			fields []string
+		)
+
+		neighbors, err := selectSearch(
+			newFilterTestSearch(1000, &limits, &fields), &neighborSelector{dedupBy: "doc"}, 3, []string{"name"},
+		)
+		require.NoError(t, err)
+
+		assert.Equal(t, []uint32{12}, limits)
+		assert.Equal(t, []string{"name", "doc"}, fields)
+		require.Len(t, neighbors, 3)
+
+		for i, n := range neighbors {
+			assert.Equal(t, i*3, n.Key)
+			assert.NotContains(t, n.Record.Data, "doc")
+		}
+	})
+
+	t.Run("keeps neighbors without a record", func(t *testing.T) {
+		search := func(uint32, []string) ([]*avs.Neighbor, error) {
+			return []*avs.Neighbor{{Key: "a"}, {Key: "b"}}, nil
+		}
+
+		neighbors, err := selectSearch(search, &neighborSelector{dedupBy: "doc"}, 2, []string{"name"})
+		require.NoError(t, err)
+		assert.Len(t, neighbors, 2)
+	})
+
+	t.Run("drops neighbors beyond the maximum distance", func(t *testing.T) {
+		var (
+			limits []uint32
+			fields []string
+		)
+
+		maxDistance := float32(2)
+
+		neighbors, err := selectSearch(
+			newFilterTestSearch(1000, &limits, &fields), &neighborSelector{maxDistance: &maxDistance}, 5, nil,
+		)
+		require.NoError(t, err)
+
+		assert.Equal(t, []uint32{5}, limits, "neighbors beyond the maximum distance are not replaced")
+		assert.Len(t, neighbors, 3)
+	})
+
+	t.Run("stops over-fetching beyond the maximum distance", func(t *testing.T) {
+		var (
+			limits []uint32
+			fields []string
+		)
+
+		f, err := filter.Parse("tenant == acme")
+		require.NoError(t, err)
+
+		maxDistance := float32(15)
+
+		neighbors, err := selectSearch(
+			newFilterTestSearch(1000, &limits, &fields), &neighborSelector{filter: f, maxDistance: &maxDistance}, 5, nil,
+		)
+		require.NoError(t, err)
+
+		assert.Equal(t, []uint32{20}, limits)
+		assert.Len(t, neighbors, 2)
+	})
+}
@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"asvec/cmd/flags"
+	"context"
+	"io"
+	"log/slog"
+	"os"
+
+	"github.com/aerospike/avs-client-go"
+	"github.com/aerospike/avs-client-go/protos"
+	"github.com/spf13/cobra"
+)
+
+// recordCmd represents the record command
+var recordCmd = &cobra.Command{
+	Use:     "record",
+	Aliases: []string{"records"},
+	Short:   "A parent command for writing, reading, and removing records.",
+	Long: `A parent command for writing, reading, and removing individual vector records.
+
+For example:
+
+	asvec record --help
+		`,
+}
+
+// readRecordDataFile reads record data from the provided file. If the file is
+// StdIn and data is being piped to asvec, the data is read from stdin instead.
+// A nil map is returned when there is no data to read.
+func readRecordDataFile(file string) (map[string]any, error) {
+	var data []byte
+
+	if file != StdIn {
+		logger.Info("reading record data file", slog.String("file", file))
+
+		b, err := os.ReadFile(file)
+		if err != nil {
+			logger.Error("failed to read record data file", slog.Any("error", err))
+			return nil, err
+		}
+
+		data = b
+	} else {
+		logger.Debug("checking if record data is being piped from stdin")
+
+		stat, _ := os.Stdin.Stat()
+		if (stat.Mode() & os.ModeCharDevice) != 0 {
+			logger.Debug("no data is being piped from stdin")
+			return nil, nil
+		}
+
+		b, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			logger.Error("failed to read record data from stdin", slog.Any("error", err))
+			return nil, err
+		}
+
+		data = b
+	}
+
+	recordData, err := flags.ParseRecordData(data)
+	if err != nil {
+		logger.Error("failed to parse record data", slog.Any("error", err))
+		return nil, err
+	}
+
+	return recordData, nil
+}
+
+// writeRecord writes a record using the client method matching writeType.
+func writeRecord(
+	ctx context.Context,
+	client *avs.Client,
+	writeType protos.WriteType,
+	namespace string,
+	set *string,
+	key any,
+	recordData map[string]any,
+	ignoreMemQueueFull bool,
+) error {
+	switch writeType {
+	case protos.WriteType_INSERT_ONLY:
+		return client.Insert(ctx, namespace, set, key, recordData, ignoreMemQueueFull)
+	case protos.WriteType_UPDATE_ONLY:
+		return client.Update(ctx, namespace, set, key, recordData, ignoreMemQueueFull)
+	default:
+		return client.Upsert(ctx, namespace, set, key, recordData, ignoreMemQueueFull)
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(recordCmd)
+}
@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"asvec/cmd/flags"
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+//nolint:govet // Padding not a concern for a CLI
+var recordDeleteFlags = &struct {
+	clientFlags *flags.ClientFlags
+	recordKey   *flags.RecordKeyFlags
+	yes         bool
+}{
+	clientFlags: rootFlags.clientFlags,
+	recordKey:   flags.NewRecordKeyFlags(),
+}
+
+func newRecordDeleteFlagSet() *pflag.FlagSet {
+	flagSet := &pflag.FlagSet{}
+	flagSet.BoolVarP(&recordDeleteFlags.yes, flags.Yes, flags.YesShort, false, "When true do not prompt for confirmation.") //nolint:lll // For readability
+	flagSet.AddFlagSet(recordDeleteFlags.recordKey.NewFlagSet())
+
+	return flagSet
+}
+
+var recordDeleteRequiredFlags = []string{
+	flags.Namespace,
+}
+
+func newRecordDeleteCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:     "delete",
+		Aliases: []string{"rm"},
+		Short:   "A command for deleting a record",
+		Long: fmt.Sprintf(`A command for deleting a single record. The record is also 
+removed from any index it belongs to.
+
+For example:
+
+%s
+asvec record delete -n test -s testset -k my-key
+			`, HelpTxtSetupEnv),
+		PreRunE: func(_ *cobra.Command, _ []string) error {
+			_, err := recordDeleteFlags.recordKey.Key()
+			if err != nil {
+				return err
+			}
+
+			return checkSeedsAndHost()
+		},
+		RunE: func(_ *cobra.Command, _ []string) error {
+			logger.Debug("parsed flags",
+				append(
+					append(recordDeleteFlags.clientFlags.NewSLogAttr(), recordDeleteFlags.recordKey.NewSLogAttr()...),
+					slog.Bool(flags.Yes, recordDeleteFlags.yes),
+				)...,
+			)
+
+			key, err := recordDeleteFlags.recordKey.Key()
+			if err != nil {
+				return err
+			}
+
+			namespace := recordDeleteFlags.recordKey.Namespace
+			set := recordDeleteFlags.recordKey.Set.Val
+
+			if !recordDeleteFlags.yes && !confirm(fmt.Sprintf(
+				"Are you sure you want to delete the record %s with key %v?",
+				nsAndSetString(namespace, set),
+				key,
+			)) {
+				return nil
+			}
+
+			client, err := createClientFromFlags(recordDeleteFlags.clientFlags)
+			if err != nil {
+				return err
+			}
+			defer client.Close()
+
+			ctx, cancel := context.WithTimeout(context.Background(), recordDeleteFlags.clientFlags.Timeout)
+			defer cancel()
+
+			err = client.Delete(ctx, namespace, set, key)
+			if err != nil {
+				logger.Error("unable to delete record", slog.Any("key", key), slog.Any("error", err))
+				return err
+			}
+
+			view.Printf("Successfully deleted record %s with key %v", nsAndSetString(namespace, set), key)
+
+			return nil
+		},
+	}
+}
+
+func init() {
+	recordDeleteCmd := newRecordDeleteCmd()
+	recordCmd.AddCommand(recordDeleteCmd)
+	recordDeleteCmd.Flags().AddFlagSet(newRecordDeleteFlagSet())
+
+	for _, flag := range recordDeleteRequiredFlags {
+		err := recordDeleteCmd.MarkFlagRequired(flag)
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	recordDeleteCmd.MarkFlagsMutuallyExclusive(flags.KeyString, flags.KeyInt)
+}
@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"asvec/cmd/flags"
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+var recordExistsFlags = &struct {
+	clientFlags *flags.ClientFlags
+	recordKey   *flags.RecordKeyFlags
+}{
+	clientFlags: rootFlags.clientFlags,
+	recordKey:   flags.NewRecordKeyFlags(),
+}
+
+func newRecordExistsFlagSet() *pflag.FlagSet {
+	flagSet := &pflag.FlagSet{}
+	flagSet.AddFlagSet(recordExistsFlags.recordKey.NewFlagSet())
+
+	return flagSet
+}
+
+var recordExistsRequiredFlags = []string{
+	flags.Namespace,
+}
+
+func newRecordExistsCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "exists",
+		Short: "A command for checking if a record exists",
+		Long: fmt.Sprintf(`A command for checking if a record exists. If the record does not 
+exist a warning is displayed and a non-zero exit code is returned.
+
+For example:
+
+%s
+asvec record exists -n test -s testset -k my-key
+			`, HelpTxtSetupEnv),
+		PreRunE: func(_ *cobra.Command, _ []string) error {
+			_, err := recordExistsFlags.recordKey.Key()
+			if err != nil {
+				return err
+			}
+
+			return checkSeedsAndHost()
+		},
+		RunE: func(_ *cobra.Command, _ []string) error {
+			logger.Debug("parsed flags",
+				append(recordExistsFlags.clientFlags.NewSLogAttr(), recordExistsFlags.recordKey.NewSLogAttr()...)...,
+			)
+
+			key, err := recordExistsFlags.recordKey.Key()
+			if err != nil {
+				return err
+			}
+
+			client, err := createClientFromFlags(recordExistsFlags.clientFlags)
+			if err != nil {
+				return err
+			}
+			defer client.Close()
+
+			ctx, cancel := context.WithTimeout(context.Background(), recordExistsFlags.clientFlags.Timeout)
+			defer cancel()
+
+			namespace := recordExistsFlags.recordKey.Namespace
+			set := recordExistsFlags.recordKey.Set.Val
+
+			exists, err := client.Exists(ctx, namespace, set, key)
+			if err != nil {
+				logger.Error("unable to check if record exists", slog.Any("key", key), slog.Any("error", err))
+				return err
+			}
+
+			if !exists {
+				view.Warningf("Record %s with key %v does not exist", nsAndSetString(namespace, set), key)
+				return nil
+			}
+
+			view.Printf("Record %s with key %v exists", nsAndSetString(namespace, set), key)
+
+			return nil
+		},
+	}
+}
+
+func init() {
+	recordExistsCmd := newRecordExistsCmd()
+	recordCmd.AddCommand(recordExistsCmd)
+	recordExistsCmd.Flags().AddFlagSet(newRecordExistsFlagSet())
+
+	for _, flag := range recordExistsRequiredFlags {
+		err := recordExistsCmd.MarkFlagRequired(flag)
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	recordExistsCmd.MarkFlagsMutuallyExclusive(flags.KeyString, flags.KeyInt)
+}
@@ -0,0 +1,144 @@
+package cmd
+
+import (
+	"asvec/cmd/flags"
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+//nolint:govet // Padding not a concern for a CLI
+var recordGetFlags = &struct {
+	clientFlags     *flags.ClientFlags
+	recordKey       *flags.RecordKeyFlags
+	includeFields   []string
+	maxDataKeys     uint
+	maxDataColWidth uint
+	output          flags.OutputFlag
+}{
+	clientFlags: rootFlags.clientFlags,
+	recordKey:   flags.NewRecordKeyFlags(),
+	output:      flags.OutputTable,
+}
+
+func newRecordGetFlagSet() *pflag.FlagSet {
+	flagSet := &pflag.FlagSet{}
+	flagSet.AddFlagSet(recordGetFlags.recordKey.NewFlagSet())
+	flagSet.StringSliceVarP(&recordGetFlags.includeFields, flags.Fields, "f", nil, "Fields names to include when displaying record data.")                                                                                  //nolint:lll // For readability
+	flagSet.UintVarP(&recordGetFlags.maxDataKeys, flags.MaxDataKeys, "m", 0, "The maximum number of record data keys to display before truncating. By default all keys are displayed.")                                     //nolint:lll // For readability
+	flagSet.UintVarP(&recordGetFlags.maxDataColWidth, flags.MaxDataColWidth, flags.MaxDataColWidthShort, 50, "The maximum column width for record data before wrapping. To display long values on a single line set to 0.") //nolint:lll // For readability
+	flagSet.VarP(&recordGetFlags.output, flags.Output, flags.OutputShort, fmt.Sprintf("The output format. Valid values: %s", strings.Join(flags.OutputEnum(), ", ")))                                                       //nolint:lll // For readability
+
+	return flagSet
+}
+
+var recordGetRequiredFlags = []string{
+	flags.Namespace,
+}
+
+func newRecordGetCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "get",
+		Short: "A command for reading a record",
+		Long: fmt.Sprintf(`A command for reading a single record and displaying its data.
+
+For example:
+
+%s
+asvec record get -n test -s testset -k my-key
+asvec record get -n test -s testset -t 1 -f name,age
+			`, HelpTxtSetupEnv),
+		PreRunE: func(_ *cobra.Command, _ []string) error {
+			_, err := recordGetFlags.recordKey.Key()
+			if err != nil {
+				return err
+			}
+
+			return checkSeedsAndHost()
+		},
+		RunE: func(_ *cobra.Command, _ []string) error {
+			logger.Debug("parsed flags",
+				append(
+					append(recordGetFlags.clientFlags.NewSLogAttr(), recordGetFlags.recordKey.NewSLogAttr()...),
+					slog.Any(flags.Fields, recordGetFlags.includeFields),
+					slog.Any(flags.MaxDataKeys, recordGetFlags.maxDataKeys),
+					slog.Any(flags.MaxDataColWidth, recordGetFlags.maxDataColWidth),
+					slog.String(flags.Output, recordGetFlags.output.String()),
+				)...,
+			)
+
+			key, err := recordGetFlags.recordKey.Key()
+			if err != nil {
+				return err
+			}
+
+			if recordGetFlags.maxDataKeys > math.MaxInt {
+				return fmt.Errorf("maxDataKeys value is larger than the maximum integer: %d", recordGetFlags.maxDataKeys)
+			}
+
+			if recordGetFlags.maxDataColWidth > math.MaxInt {
+				return fmt.Errorf("maxDataColWidth value is larger than the maximum integer: %d", recordGetFlags.maxDataColWidth)
+			}
+
+			client, err := createClientFromFlags(recordGetFlags.clientFlags)
+			if err != nil {
+				return err
+			}
+			defer client.Close()
+
+			ctx, cancel := context.WithTimeout(context.Background(), recordGetFlags.clientFlags.Timeout)
+			defer cancel()
+
+			namespace := recordGetFlags.recordKey.Namespace
+			set := recordGetFlags.recordKey.Set.Val
+
+			record, err := client.Get(ctx, namespace, set, key, recordGetFlags.includeFields, nil)
+			if err != nil {
+				logger.Error("unable to get record", slog.Any("key", key), slog.Any("error", err))
+
+				if set == nil {
+					view.Warningf(
+						"If the record is in a set, you may also need to provide the --%s flag.",
+						flags.Set,
+					)
+				}
+
+				return err
+			}
+
+			logger.Debug("server record", slog.Any("record", record))
+
+			view.PrintRecord(
+				namespace,
+				set,
+				key,
+				record,
+				recordGetFlags.output,
+				int(recordGetFlags.maxDataKeys),     //nolint:gosec // Overflow is checked above
+				int(recordGetFlags.maxDataColWidth), //nolint:gosec // Overflow is checked above
+			)
+
+			return nil
+		},
+	}
+}
+
+func init() {
+	recordGetCmd := newRecordGetCmd()
+	recordCmd.AddCommand(recordGetCmd)
+	recordGetCmd.Flags().AddFlagSet(newRecordGetFlagSet())
+
+	for _, flag := range recordGetRequiredFlags {
+		err := recordGetCmd.MarkFlagRequired(flag)
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	recordGetCmd.MarkFlagsMutuallyExclusive(flags.KeyString, flags.KeyInt)
+}
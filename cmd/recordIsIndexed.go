@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"asvec/cmd/flags"
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+//nolint:govet // Padding not a concern for a CLI
+var recordIsIndexedFlags = &struct {
+	clientFlags *flags.ClientFlags
+	recordKey   *flags.RecordKeyFlags
+	indexName   string
+}{
+	clientFlags: rootFlags.clientFlags,
+	recordKey:   flags.NewRecordKeyFlags(),
+}
+
+func newRecordIsIndexedFlagSet() *pflag.FlagSet {
+	flagSet := &pflag.FlagSet{}
+	flagSet.AddFlagSet(recordIsIndexedFlags.recordKey.NewFlagSet())
+	flagSet.StringVarP(&recordIsIndexedFlags.indexName, flags.IndexName, flags.IndexNameShort, "", "The name of the index.") //nolint:lll // For readability
+
+	return flagSet
+}
+
+var recordIsIndexedRequiredFlags = []string{
+	flags.Namespace,
+	flags.IndexName,
+}
+
+func newRecordIsIndexedCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "is-indexed",
+		Short: "A command for checking if a record is indexed",
+		Long: fmt.Sprintf(`A command for checking if a record has been added to an index. 
+Newly written records are not searchable until they are indexed. If the 
+record is not indexed a warning is displayed and a non-zero exit code is returned.
+
+For example:
+
+%s
+asvec record is-indexed -n test -s testset -k my-key -i my-index
+			`, HelpTxtSetupEnv),
+		PreRunE: func(_ *cobra.Command, _ []string) error {
+			_, err := recordIsIndexedFlags.recordKey.Key()
+			if err != nil {
+				return err
+			}
+
+			return checkSeedsAndHost()
+		},
+		RunE: func(_ *cobra.Command, _ []string) error {
+			logger.Debug("parsed flags",
+				append(
+					append(recordIsIndexedFlags.clientFlags.NewSLogAttr(), recordIsIndexedFlags.recordKey.NewSLogAttr()...),
+					slog.String(flags.IndexName, recordIsIndexedFlags.indexName),
+				)...,
+			)
+
+			key, err := recordIsIndexedFlags.recordKey.Key()
+			if err != nil {
+				return err
+			}
+
+			client, err := createClientFromFlags(recordIsIndexedFlags.clientFlags)
+			if err != nil {
+				return err
+			}
+			defer client.Close()
+
+			ctx, cancel := context.WithTimeout(context.Background(), recordIsIndexedFlags.clientFlags.Timeout)
+			defer cancel()
+
+			namespace := recordIsIndexedFlags.recordKey.Namespace
+			set := recordIsIndexedFlags.recordKey.Set.Val
+			indexName := recordIsIndexedFlags.indexName
+
+			indexed, err := client.IsIndexed(ctx, namespace, set, indexName, key)
+			if err != nil {
+				logger.Error("unable to check if record is indexed", slog.Any("key", key), slog.Any("error", err))
+				return err
+			}
+
+			if !indexed {
+				view.Warningf(
+					"Record %s with key %v is not indexed by %s",
+					nsAndSetString(namespace, set),
+					key,
+					indexName,
+				)
+
+				return nil
+			}
+
+			view.Printf("Record %s with key %v is indexed by %s", nsAndSetString(namespace, set), key, indexName)
+
+			return nil
+		},
+	}
+}
+
+func init() {
+	recordIsIndexedCmd := newRecordIsIndexedCmd()
+	recordCmd.AddCommand(recordIsIndexedCmd)
+	recordIsIndexedCmd.Flags().AddFlagSet(newRecordIsIndexedFlagSet())
+
+	for _, flag := range recordIsIndexedRequiredFlags {
+		err := recordIsIndexedCmd.MarkFlagRequired(flag)
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	recordIsIndexedCmd.MarkFlagsMutuallyExclusive(flags.KeyString, flags.KeyInt)
+}
@@ -0,0 +1,176 @@
+package cmd
+
+import (
+	"asvec/cmd/flags"
+	"asvec/cmd/records"
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/aerospike/avs-client-go/protos"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+//nolint:govet // Padding not a concern for a CLI
+var recordPutFlags = &struct {
+	clientFlags        *flags.ClientFlags
+	recordKey          *flags.RecordKeyFlags
+	data               flags.RecordDataFlag
+	inputFile          string
+	vectorField        string
+	vector             flags.VectorFlag
+	writeType          flags.WriteTypeFlag
+	ignoreMemQueueFull bool
+}{
+	clientFlags: rootFlags.clientFlags,
+	recordKey:   flags.NewRecordKeyFlags(),
+	writeType:   flags.WriteTypeFlag(protos.WriteType_UPSERT.String()),
+}
+
+func newRecordPutFlagSet() *pflag.FlagSet {
+	flagSet := &pflag.FlagSet{}
+	flagSet.AddFlagSet(recordPutFlags.recordKey.NewFlagSet())
+	flagSet.Var(&recordPutFlags.data, flags.Data, "The record data as a JSON or YAML object. Example: '{\"name\": \"foo\", \"age\": 30}'")                                                                                                              //nolint:lll // For readability
+	flagSet.StringVar(&recordPutFlags.inputFile, flags.InputFile, StdIn, fmt.Sprintf("A JSON or YAML file containing the record data. Merged with --%s when both are provided.", flags.Data))                                                           //nolint:lll // For readability
+	flagSet.StringVarP(&recordPutFlags.vectorField, flags.VectorField, flags.VectorFieldShort, "", fmt.Sprintf("The name of the vector field. Required with --%s.", flags.Vector))                                                                      //nolint:lll // For readability
+	flagSet.VarP(&recordPutFlags.vector, flags.Vector, flags.VectorShort, fmt.Sprintf("The vector to write. Values true/false and 1/0 will result in a binary vector. Values containing a decimal will result in a float vector. %s", vectorInputHelp)) //nolint:lll // For readability
+	flagSet.Var(&recordPutFlags.writeType, flags.WriteType, fmt.Sprintf("How the record is written. Valid values: %s", strings.Join(flags.WriteTypeEnum(), ", ")))                                                                                      //nolint:lll // For readability
+	flagSet.BoolVar(&recordPutFlags.ignoreMemQueueFull, flags.IgnoreMemQueueFull, false, "Write the record even if the index's in-memory queue is full.")                                                                                               //nolint:lll // For readability
+
+	return flagSet
+}
+
+var recordPutRequiredFlags = []string{
+	flags.Namespace,
+}
+
+func newRecordPutCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "put",
+		Short: "A command for writing a record",
+		Long: fmt.Sprintf(`A command for writing a single record. Record data can be provided 
+as a JSON or YAML object using --%s, read from a file using --%s, or piped 
+from stdin. A vector can be provided separately using --%s and --%s. 
+Lists found in the vector field are written as vectors.
+
+For example:
+
+%s
+asvec record put -n test -s testset -k my-key -f vector -v "[0.1,0.2,0.3]" \
+	--%s '{"name": "foo", "tags": ["a", "b"]}'
+
+cat record.yaml | asvec record put -n test -s testset -t 1 -f vector
+			`, flags.Data, flags.InputFile, flags.VectorField, flags.Vector, HelpTxtSetupEnv, flags.Data),
+		PreRunE: func(_ *cobra.Command, _ []string) error {
+			if recordPutFlags.vector.IsSet() && recordPutFlags.vectorField == "" {
+				return fmt.Errorf("--%s is required when --%s is set", flags.VectorField, flags.Vector)
+			}
+
+			_, err := recordPutFlags.recordKey.Key()
+			if err != nil {
+				return err
+			}
+
+			return checkSeedsAndHost()
+		},
+		RunE: func(_ *cobra.Command, _ []string) error {
+			logger.Debug("parsed flags",
+				append(
+					append(recordPutFlags.clientFlags.NewSLogAttr(), recordPutFlags.recordKey.NewSLogAttr()...),
+					slog.String(flags.Data, recordPutFlags.data.String()),
+					slog.String(flags.InputFile, recordPutFlags.inputFile),
+					slog.String(flags.VectorField, recordPutFlags.vectorField),
+					slog.Any(flags.Vector, recordPutFlags.vector),
+					slog.String(flags.WriteType, recordPutFlags.writeType.String()),
+					slog.Bool(flags.IgnoreMemQueueFull, recordPutFlags.ignoreMemQueueFull),
+				)...,
+			)
+
+			key, err := recordPutFlags.recordKey.Key()
+			if err != nil {
+				return err
+			}
+
+			recordData, err := readRecordDataFile(recordPutFlags.inputFile)
+			if err != nil {
+				return err
+			}
+
+			if recordData == nil {
+				recordData = map[string]any{}
+			}
+
+			for k, v := range recordPutFlags.data.Val {
+				recordData[k] = v
+			}
+
+			if recordPutFlags.vectorField != "" {
+				err = records.ConvertVectorField(recordData, recordPutFlags.vectorField)
+				if err != nil {
+					logger.Error("failed to convert vector field", slog.Any("error", err))
+					return err
+				}
+			}
+
+			if recordPutFlags.vector.BoolSlice != nil {
+				recordData[recordPutFlags.vectorField] = recordPutFlags.vector.BoolSlice
+			} else if recordPutFlags.vector.FloatSlice != nil {
+				recordData[recordPutFlags.vectorField] = recordPutFlags.vector.FloatSlice
+			}
+
+			if len(recordData) == 0 {
+				return fmt.Errorf("no record data provided, use --%s, --%s, or --%s", flags.Data, flags.InputFile, flags.Vector)
+			}
+
+			logger.Debug("record data", slog.Any("data", recordData))
+
+			client, err := createClientFromFlags(recordPutFlags.clientFlags)
+			if err != nil {
+				return err
+			}
+			defer client.Close()
+
+			ctx, cancel := context.WithTimeout(context.Background(), recordPutFlags.clientFlags.Timeout)
+			defer cancel()
+
+			namespace := recordPutFlags.recordKey.Namespace
+			set := recordPutFlags.recordKey.Set.Val
+
+			err = writeRecord(
+				ctx,
+				client,
+				recordPutFlags.writeType.WriteType(),
+				namespace,
+				set,
+				key,
+				recordData,
+				recordPutFlags.ignoreMemQueueFull,
+			)
+			if err != nil {
+				logger.Error("unable to write record", slog.Any("key", key), slog.Any("error", err))
+				return err
+			}
+
+			view.Printf("Successfully wrote record %s with key %v", nsAndSetString(namespace, set), key)
+
+			return nil
+		},
+	}
+}
+
+func init() {
+	recordPutCmd := newRecordPutCmd()
+	recordCmd.AddCommand(recordPutCmd)
+	recordPutCmd.Flags().AddFlagSet(newRecordPutFlagSet())
+
+	for _, flag := range recordPutRequiredFlags {
+		err := recordPutCmd.MarkFlagRequired(flag)
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	recordPutCmd.MarkFlagsMutuallyExclusive(flags.KeyString, flags.KeyInt)
+}
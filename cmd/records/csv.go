@@ -0,0 +1,129 @@
+package records
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+)
+
+// csvReader reads a CSV file with a header row. Each cell is parsed as JSON
+// where possible, e.g. 1.5, true, or [0.1,0.2], and otherwise as a string.
+type csvReader struct {
+	reader   *csv.Reader
+	opts     ReaderOptions
+	header   []string
+	keyIndex int
+	line     int
+}
+
+func newCSVReader(r io.Reader, opts ReaderOptions) (*csvReader, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("missing CSV header row")
+		}
+
+		return nil, fmt.Errorf("failed to read CSV header row: %w", err)
+	}
+
+	keyIndex := slices.Index(header, opts.KeyField)
+	if keyIndex == -1 {
+		return nil, fmt.Errorf("CSV header is missing key column %s", opts.KeyField)
+	}
+
+	return &csvReader{
+		reader:   reader,
+		opts:     opts,
+		header:   header,
+		keyIndex: keyIndex,
+		line:     1,
+	}, nil
+}
+
+func (r *csvReader) Read() (*Record, error) {
+	row, err := r.reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, io.EOF
+		}
+
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			r.line = parseErr.Line
+			return nil, &RecordError{Line: r.line, Err: err}
+		}
+
+		return nil, err
+	}
+
+	r.line++
+
+	record, err := r.parse(row)
+	if err != nil {
+		return nil, &RecordError{Line: r.line, Err: err}
+	}
+
+	return record, nil
+}
+
+func (r *csvReader) Line() int {
+	return r.line
+}
+
+func (r *csvReader) parse(row []string) (*Record, error) {
+	if len(row) != len(r.header) {
+		return nil, fmt.Errorf("expected %d columns but found %d", len(r.header), len(row))
+	}
+
+	data := make(map[string]any, len(row)-1)
+
+	var key any
+
+	for i, cell := range row {
+		if i == r.keyIndex {
+			k, err := toKey(parseCSVCell(cell))
+			if err != nil {
+				return nil, fmt.Errorf("invalid key column %s: %w", r.opts.KeyField, err)
+			}
+
+			key = k
+
+			continue
+		}
+
+		if cell == "" {
+			continue
+		}
+
+		data[r.header[i]] = parseCSVCell(cell)
+	}
+
+	if err := ConvertVectorField(data, r.opts.VectorField); err != nil {
+		return nil, err
+	}
+
+	return &Record{Key: key, Data: data}, nil
+}
+
+func parseCSVCell(cell string) any {
+	decoder := json.NewDecoder(bytes.NewReader([]byte(cell)))
+	decoder.UseNumber()
+
+	var val any
+	if err := decoder.Decode(&val); err != nil || decoder.More() {
+		return cell
+	}
+
+	if val == nil {
+		return cell
+	}
+
+	return normalizeJSON(val)
+}
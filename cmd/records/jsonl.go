@@ -0,0 +1,81 @@
+package records
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+const maxJSONLLineSize = 64 * 1024 * 1024
+
+type jsonlReader struct {
+	scanner *bufio.Scanner
+	opts    ReaderOptions
+	line    int
+}
+
+func newJSONLReader(r io.Reader, opts ReaderOptions) *jsonlReader {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxJSONLLineSize)
+
+	return &jsonlReader{
+		scanner: scanner,
+		opts:    opts,
+	}
+}
+
+func (r *jsonlReader) Read() (*Record, error) {
+	for r.scanner.Scan() {
+		r.line++
+
+		line := bytes.TrimSpace(r.scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		record, err := r.parse(line)
+		if err != nil {
+			return nil, &RecordError{Line: r.line, Err: err}
+		}
+
+		return record, nil
+	}
+
+	if err := r.scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return nil, io.EOF
+}
+
+func (r *jsonlReader) Line() int {
+	return r.line
+}
+
+func (r *jsonlReader) parse(line []byte) (*Record, error) {
+	decoder := json.NewDecoder(bytes.NewReader(line))
+	decoder.UseNumber()
+
+	data := map[string]any{}
+
+	if err := decoder.Decode(&data); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+
+	normalizeJSON(data)
+
+	key, err := toKey(data[r.opts.KeyField])
+	if err != nil {
+		return nil, fmt.Errorf("invalid key field %s: %w", r.opts.KeyField, err)
+	}
+
+	delete(data, r.opts.KeyField)
+
+	if err := ConvertVectorField(data, r.opts.VectorField); err != nil {
+		return nil, err
+	}
+
+	return &Record{Key: key, Data: data}, nil
+}
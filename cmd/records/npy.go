@@ -0,0 +1,195 @@
+package records
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	npyMagic        = []byte("\x93NUMPY")
+	npyDescrRegex   = regexp.MustCompile(`'descr'\s*:\s*'([^']*)'`)
+	npyFortranRegex = regexp.MustCompile(`'fortran_order'\s*:\s*(True|False)`)
+	npyShapeRegex   = regexp.MustCompile(`'shape'\s*:\s*\(([^)]*)\)`)
+)
+
+// npyDType describes how to decode a single element of a .npy array.
+type npyDType struct {
+	size   int
+	isBool bool
+	decode func(b []byte) float32
+}
+
+var npyDTypes = map[string]npyDType{
+	"f4": {size: 4, decode: func(b []byte) float32 { return math.Float32frombits(binary.LittleEndian.Uint32(b)) }},
+	"f8": {size: 8, decode: func(b []byte) float32 { return float32(math.Float64frombits(binary.LittleEndian.Uint64(b))) }},
+	"i4": {size: 4, decode: func(b []byte) float32 { return float32(int32(binary.LittleEndian.Uint32(b))) }},
+	"i8": {size: 8, decode: func(b []byte) float32 { return float32(int64(binary.LittleEndian.Uint64(b))) }},
+	"u1": {size: 1, decode: func(b []byte) float32 { return float32(b[0]) }},
+	"i1": {size: 1, decode: func(b []byte) float32 { return float32(int8(b[0])) }},
+	"b1": {size: 1, isBool: true},
+}
+
+// npyReader reads the rows of a one or two dimensional NumPy array. Each row
+// becomes a record keyed by its row number.
+type npyReader struct {
+	reader io.Reader
+	opts   ReaderOptions
+	dtype  npyDType
+	buf    []byte
+	rows   int
+	row    int
+}
+
+func newNPYReader(r io.Reader, opts ReaderOptions) (*npyReader, error) {
+	reader := bufio.NewReader(r)
+
+	dtype, rows, dims, err := readNPYHeader(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	return &npyReader{
+		reader: reader,
+		opts:   opts,
+		dtype:  dtype,
+		buf:    make([]byte, dims*dtype.size),
+		rows:   rows,
+	}, nil
+}
+
+func readNPYHeader(r io.Reader) (dtype npyDType, rows, dims int, err error) {
+	prefix := make([]byte, len(npyMagic)+2)
+	if _, err = io.ReadFull(r, prefix); err != nil {
+		return dtype, 0, 0, fmt.Errorf("failed to read npy header: %w", err)
+	}
+
+	if string(prefix[:len(npyMagic)]) != string(npyMagic) {
+		return dtype, 0, 0, fmt.Errorf("not a npy file")
+	}
+
+	var headerLen int
+
+	switch major := prefix[len(npyMagic)]; major {
+	case 1:
+		var l uint16
+		err = binary.Read(r, binary.LittleEndian, &l)
+		headerLen = int(l)
+	case 2, 3:
+		var l uint32
+		err = binary.Read(r, binary.LittleEndian, &l)
+		headerLen = int(l)
+	default:
+		return dtype, 0, 0, fmt.Errorf("unsupported npy version %d", major)
+	}
+
+	if err != nil {
+		return dtype, 0, 0, fmt.Errorf("failed to read npy header: %w", err)
+	}
+
+	header := make([]byte, headerLen)
+	if _, err = io.ReadFull(r, header); err != nil {
+		return dtype, 0, 0, fmt.Errorf("failed to read npy header: %w", err)
+	}
+
+	return parseNPYHeader(string(header))
+}
+
+func parseNPYHeader(header string) (dtype npyDType, rows, dims int, err error) {
+	descrMatch := npyDescrRegex.FindStringSubmatch(header)
+	if descrMatch == nil {
+		return dtype, 0, 0, fmt.Errorf("npy header is missing descr")
+	}
+
+	descr := descrMatch[1]
+	if len(descr) != 3 || descr[0] == '>' {
+		return dtype, 0, 0, fmt.Errorf("unsupported npy dtype %q", descr)
+	}
+
+	dtype, ok := npyDTypes[descr[1:]]
+	if !ok {
+		return dtype, 0, 0, fmt.Errorf("unsupported npy dtype %q", descr)
+	}
+
+	if m := npyFortranRegex.FindStringSubmatch(header); m != nil && m[1] == "True" {
+		return dtype, 0, 0, fmt.Errorf("fortran ordered npy arrays are not supported")
+	}
+
+	shapeMatch := npyShapeRegex.FindStringSubmatch(header)
+	if shapeMatch == nil {
+		return dtype, 0, 0, fmt.Errorf("npy header is missing shape")
+	}
+
+	var shape []int
+
+	for _, s := range strings.Split(shapeMatch[1], ",") {
+		s = strings.TrimSpace(s)
+		if s == "" {
+			continue
+		}
+
+		n, convErr := strconv.Atoi(s)
+		if convErr != nil {
+			return dtype, 0, 0, fmt.Errorf("invalid npy shape %q", shapeMatch[1])
+		}
+
+		shape = append(shape, n)
+	}
+
+	switch len(shape) {
+	case 1:
+		return dtype, 1, shape[0], nil
+	case 2:
+		return dtype, shape[0], shape[1], nil
+	default:
+		return dtype, 0, 0, fmt.Errorf("npy array must have 1 or 2 dimensions but has %d", len(shape))
+	}
+}
+
+func (r *npyReader) Read() (*Record, error) {
+	if r.row >= r.rows {
+		return nil, io.EOF
+	}
+
+	if _, err := io.ReadFull(r.reader, r.buf); err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, fmt.Errorf("npy file truncated at row %d", r.row)
+		}
+
+		return nil, err
+	}
+
+	key := int64(r.row)
+	r.row++
+
+	return &Record{
+		Key:  key,
+		Data: map[string]any{r.opts.VectorField: r.decode()},
+	}, nil
+}
+
+func (r *npyReader) decode() any {
+	dims := len(r.buf) / r.dtype.size
+
+	if r.dtype.isBool {
+		vector := make([]bool, dims)
+		for i, b := range r.buf {
+			vector[i] = b != 0
+		}
+
+		return vector
+	}
+
+	vector := make([]float32, dims)
+	for i := range vector {
+		vector[i] = r.dtype.decode(r.buf[i*r.dtype.size : (i+1)*r.dtype.size])
+	}
+
+	return vector
+}
@@ -0,0 +1,91 @@
+package records
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Reader reads records one at a time. Read returns io.EOF once all records
+// have been read. A RecordError means a single record was invalid and
+// reading can continue.
+type Reader interface {
+	Read() (*Record, error)
+}
+
+// LineReader is a Reader of a text format, JSONL or CSV, which knows the line
+// the last record was read from. Callers can use it to report an invalid
+// record in the same way as a RecordError.
+type LineReader interface {
+	Reader
+	Line() int
+}
+
+// ReaderOptions configures how records are read from a file.
+type ReaderOptions struct {
+	// KeyField is the field (or CSV column) holding each record's key.
+	// Formats without named fields i.e. npy, fvecs, and bvecs, use the row
+	// number as the key.
+	KeyField string
+	// VectorField is the field holding each record's vector.
+	VectorField string
+}
+
+// NewReader returns a Reader for the given format.
+func NewReader(r io.Reader, format Format, opts ReaderOptions) (Reader, error) {
+	switch format {
+	case FormatJSONL:
+		return newJSONLReader(r, opts), nil
+	case FormatCSV:
+		return newCSVReader(r, opts)
+	case FormatNPY:
+		return newNPYReader(r, opts)
+	case FormatFvecs:
+		return newVecsReader(r, opts, 4), nil
+	case FormatBvecs:
+		return newVecsReader(r, opts, 1), nil
+	default:
+		return nil, fmt.Errorf("unsupported format %q", format)
+	}
+}
+
+// ReadVector reads a npy, fvecs, or bvecs file holding a single vector, e.g.
+// a query saved from a notebook with numpy.save. The vector is a []float32
+// or, for a npy file of booleans, a []bool.
+func ReadVector(r io.Reader, format Format) (any, error) {
+	if format != FormatNPY && format != FormatFvecs && format != FormatBvecs {
+		return nil, fmt.Errorf("a vector can't be read from a %s file", format)
+	}
+
+	opts := ReaderOptions{VectorField: "vector"}
+
+	reader, err := NewReader(r, format, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	record, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("%s file does not contain a vector", format)
+		}
+
+		return nil, err
+	}
+
+	if _, err := reader.Read(); !errors.Is(err, io.EOF) {
+		if err != nil {
+			return nil, err
+		}
+
+		return nil, fmt.Errorf("%s file contains more than one vector", format)
+	}
+
+	return record.Data[opts.VectorField], nil
+}
+
+// IsNPY reports whether data starts like a npy file.
+func IsNPY(data []byte) bool {
+	return bytes.HasPrefix(data, npyMagic)
+}
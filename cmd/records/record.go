@@ -0,0 +1,252 @@
+// Package records reads and writes vector records in the file formats
+// supported by asvec i.e. JSONL, CSV, NumPy .npy, and fvecs/bvecs.
+package records
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Record is a single record read from or written to a file.
+type Record struct {
+	Key  any
+	Data map[string]any
+}
+
+type Format string
+
+const (
+	FormatJSONL Format = "jsonl"
+	FormatCSV   Format = "csv"
+	FormatNPY   Format = "npy"
+	FormatFvecs Format = "fvecs"
+	FormatBvecs Format = "bvecs"
+)
+
+var formatExtensions = map[string]Format{
+	".jsonl":  FormatJSONL,
+	".ndjson": FormatJSONL,
+	".json":   FormatJSONL,
+	".csv":    FormatCSV,
+	".npy":    FormatNPY,
+	".fvecs":  FormatFvecs,
+	".bvecs":  FormatBvecs,
+}
+
+// FormatNames returns the names of all supported formats.
+func FormatNames() []string {
+	return []string{
+		string(FormatJSONL),
+		string(FormatCSV),
+		string(FormatNPY),
+		string(FormatFvecs),
+		string(FormatBvecs),
+	}
+}
+
+// ParseFormat returns the Format with the given name.
+func ParseFormat(name string) (Format, error) {
+	name = strings.ToLower(name)
+
+	if slices.Contains(FormatNames(), name) {
+		return Format(name), nil
+	}
+
+	return "", fmt.Errorf("unrecognized format %q, valid formats: %s", name, strings.Join(FormatNames(), ", "))
+}
+
+// FormatFromFilename infers the Format from a file's extension.
+func FormatFromFilename(name string) (Format, error) {
+	ext := strings.ToLower(filepath.Ext(name))
+
+	if format, ok := formatExtensions[ext]; ok {
+		return format, nil
+	}
+
+	return "", fmt.Errorf("unable to infer format from file extension %q", ext)
+}
+
+// RecordError is returned by a Reader when a single record could not be
+// parsed. Reading can continue after a RecordError.
+type RecordError struct {
+	Err  error
+	Line int
+}
+
+func (e *RecordError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Err)
+}
+
+func (e *RecordError) Unwrap() error {
+	return e.Err
+}
+
+// IsRecordError returns true if err is a recoverable RecordError.
+func IsRecordError(err error) bool {
+	var recordErr *RecordError
+	return errors.As(err, &recordErr)
+}
+
+// ToVector converts a list parsed from JSON or YAML into a vector. A list of
+// only booleans and the integers 0 and 1 becomes a []bool, the same as
+// --vector, so [1,0,1] is a bool vector and [1.0,0.0,1.0] a float vector. Any
+// other list of numbers becomes a []float32. Vectors that are already a
+// []float32 or []bool are returned as is.
+func ToVector(val any) (any, error) {
+	switch v := val.(type) {
+	case []float32, []bool:
+		return v, nil
+	case []any:
+		if len(v) == 0 {
+			return nil, fmt.Errorf("empty vector not allowed")
+		}
+
+		if vector, ok := toBoolVector(v); ok {
+			return vector, nil
+		}
+
+		vector := make([]float32, len(v))
+
+		for i, e := range v {
+			f, err := toFloat32(e)
+			if err != nil {
+				return nil, err
+			}
+
+			vector[i] = f
+		}
+
+		return vector, nil
+	default:
+		return nil, fmt.Errorf("unsupported vector type: %T", val)
+	}
+}
+
+// toBoolVector converts a list of booleans and the integers 0 and 1 into a
+// []bool. It returns false if any element is something else.
+func toBoolVector(list []any) ([]bool, bool) {
+	vector := make([]bool, len(list))
+
+	for i, e := range list {
+		var n int64
+
+		switch v := e.(type) {
+		case bool:
+			vector[i] = v
+			continue
+		case int:
+			n = int64(v)
+		case int64:
+			n = v
+		default:
+			return nil, false
+		}
+
+		if n != 0 && n != 1 {
+			return nil, false
+		}
+
+		vector[i] = n == 1
+	}
+
+	return vector, true
+}
+
+func toFloat32(val any) (float32, error) {
+	switch v := val.(type) {
+	case float32:
+		return v, nil
+	case float64:
+		return float32(v), nil
+	case int:
+		return float32(v), nil
+	case int64:
+		return float32(v), nil
+	case json.Number:
+		f, err := v.Float64()
+		return float32(f), err
+	default:
+		return 0, fmt.Errorf("vector contains a non-numeric value: %v", val)
+	}
+}
+
+// ConvertVectorField converts the list stored in field, if any, into a
+// vector so that it is written as a vector rather than a list.
+func ConvertVectorField(data map[string]any, field string) error {
+	val, ok := data[field]
+	if !ok {
+		return nil
+	}
+
+	if _, ok := val.([]any); !ok {
+		return nil
+	}
+
+	vector, err := ToVector(val)
+	if err != nil {
+		return fmt.Errorf("failed to parse field %s as a vector: %w", field, err)
+	}
+
+	data[field] = vector
+
+	return nil
+}
+
+// VectorLen returns the number of dimensions of a []float32 or []bool vector.
+func VectorLen(val any) (int, bool) {
+	switch v := val.(type) {
+	case []float32:
+		return len(v), true
+	case []bool:
+		return len(v), true
+	default:
+		return 0, false
+	}
+}
+
+// normalizeJSON converts json.Number values, as produced by a json.Decoder
+// with UseNumber enabled, into int64 or float64.
+func normalizeJSON(val any) any {
+	switch v := val.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+
+		f, _ := v.Float64()
+
+		return f
+	case map[string]any:
+		for k, e := range v {
+			v[k] = normalizeJSON(e)
+		}
+
+		return v
+	case []any:
+		for i, e := range v {
+			v[i] = normalizeJSON(e)
+		}
+
+		return v
+	default:
+		return v
+	}
+}
+
+// toKey validates that a key parsed from a file is a supported key type.
+func toKey(val any) (any, error) {
+	switch v := val.(type) {
+	case string, int64:
+		return v, nil
+	case float64:
+		return nil, fmt.Errorf("decimal keys are not supported: %v", v)
+	case nil:
+		return nil, fmt.Errorf("missing key")
+	default:
+		return nil, fmt.Errorf("unsupported key type %T", val)
+	}
+}
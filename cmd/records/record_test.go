@@ -0,0 +1,313 @@
+//go:build unit
+
+package records
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestConvertVectorField(t *testing.T) {
+	testCases := []struct {
+		name     string
+		data     map[string]any
+		field    string
+		expected map[string]any
+		err      bool
+	}{
+		{
+			name:     "float vector",
+			data:     map[string]any{"vec": []any{0.5, 1, int64(2)}, "name": "foo"},
+			field:    "vec",
+			expected: map[string]any{"vec": []float32{0.5, 1, 2}, "name": "foo"},
+		},
+		{
+			name:     "float vector of 0s and 1s",
+			data:     map[string]any{"vec": []any{1.0, 0.0, 1.0}},
+			field:    "vec",
+			expected: map[string]any{"vec": []float32{1, 0, 1}},
+		},
+		{
+			name:     "bool vector",
+			data:     map[string]any{"vec": []any{true, false, 1, 0}},
+			field:    "vec",
+			expected: map[string]any{"vec": []bool{true, false, true, false}},
+		},
+		{
+			name:     "bool vector of 0s and 1s",
+			data:     map[string]any{"vec": []any{int64(1), int64(0), int64(1)}},
+			field:    "vec",
+			expected: map[string]any{"vec": []bool{true, false, true}},
+		},
+		{
+			name:     "missing field",
+			data:     map[string]any{"name": "foo"},
+			field:    "vec",
+			expected: map[string]any{"name": "foo"},
+		},
+		{
+			name:     "not a list",
+			data:     map[string]any{"vec": "foo"},
+			field:    "vec",
+			expected: map[string]any{"vec": "foo"},
+		},
+		{
+			name:  "mixed vector",
+			data:  map[string]any{"vec": []any{true, 0.5}},
+			field: "vec",
+			err:   true,
+		},
+		{
+			name:  "invalid vector",
+			data:  map[string]any{"vec": []any{"a", "b"}},
+			field: "vec",
+			err:   true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ConvertVectorField(tc.data, tc.field)
+			if tc.err {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, tc.data)
+		})
+	}
+}
+
+type ReaderTestSuite struct {
+	suite.Suite
+}
+
+func TestReaderSuite(t *testing.T) {
+	suite.Run(t, new(ReaderTestSuite))
+}
+
+var testOpts = ReaderOptions{KeyField: "id", VectorField: "vec"}
+
+func readAll(r Reader) ([]*Record, []error) {
+	var (
+		recs []*Record
+		errs []error
+	)
+
+	for {
+		rec, err := r.Read()
+		if err == io.EOF {
+			return recs, errs
+		}
+
+		if err != nil {
+			errs = append(errs, err)
+
+			if !IsRecordError(err) {
+				return recs, errs
+			}
+
+			continue
+		}
+
+		recs = append(recs, rec)
+	}
+}
+
+func (suite *ReaderTestSuite) TestFormatFromFilename() {
+	format, err := FormatFromFilename("/tmp/data.JSONL")
+	suite.NoError(err)
+	suite.Equal(FormatJSONL, format)
+
+	format, err = FormatFromFilename("sift_base.fvecs")
+	suite.NoError(err)
+	suite.Equal(FormatFvecs, format)
+
+	_, err = FormatFromFilename("data.parquet")
+	suite.Error(err)
+}
+
+func (suite *ReaderTestSuite) TestJSONL() {
+	input := `{"id": "a", "vec": [0.5, 1], "count": 3, "ratio": 0.25}
+
+{"id": 7, "vec": [true, false], "tags": ["x", 2]}
+{"vec": [1, 2]}
+not json
+{"id": 1.5, "vec": [1, 2]}
+`
+	r, err := NewReader(bytes.NewBufferString(input), FormatJSONL, testOpts)
+	suite.Require().NoError(err)
+
+	recs, errs := readAll(r)
+	suite.Equal([]*Record{
+		{Key: "a", Data: map[string]any{"vec": []float32{0.5, 1}, "count": int64(3), "ratio": 0.25}},
+		{Key: int64(7), Data: map[string]any{"vec": []bool{true, false}, "tags": []any{"x", int64(2)}}},
+	}, recs)
+	suite.Len(errs, 3)
+
+	for _, err := range errs {
+		suite.True(IsRecordError(err))
+	}
+}
+
+func (suite *ReaderTestSuite) TestCSV() {
+	input := `id,vec,name,count
+a,"[0.5,1]",foo,3
+2,"[true,false]","""quoted""",
+3,[1,2]
+`
+	r, err := NewReader(bytes.NewBufferString(input), FormatCSV, testOpts)
+	suite.Require().NoError(err)
+
+	recs, errs := readAll(r)
+	suite.Equal([]*Record{
+		{Key: "a", Data: map[string]any{"vec": []float32{0.5, 1}, "name": "foo", "count": int64(3)}},
+		{Key: int64(2), Data: map[string]any{"vec": []bool{true, false}, "name": "quoted"}},
+	}, recs)
+	suite.Len(errs, 1)
+
+	_, err = NewReader(bytes.NewBufferString("key,vec\n"), FormatCSV, testOpts)
+	suite.Error(err)
+}
+
+func npyFile(descr, shape string, data []byte) []byte {
+	header := "{'descr': '" + descr + "', 'fortran_order': False, 'shape': " + shape + ", }"
+	for (len(npyMagic)+4+len(header)+1)%64 != 0 {
+		header += " "
+	}
+
+	header += "\n"
+
+	buf := bytes.NewBuffer(nil)
+	buf.Write(npyMagic)
+	buf.Write([]byte{1, 0})
+	_ = binary.Write(buf, binary.LittleEndian, uint16(len(header)))
+	buf.WriteString(header)
+	buf.Write(data)
+
+	return buf.Bytes()
+}
+
+func (suite *ReaderTestSuite) TestNPY() {
+	data := bytes.NewBuffer(nil)
+	_ = binary.Write(data, binary.LittleEndian, []float32{1, 2, 3, 4, 5, 6})
+
+	r, err := NewReader(bytes.NewReader(npyFile("<f4", "(2, 3)", data.Bytes())), FormatNPY, testOpts)
+	suite.Require().NoError(err)
+
+	recs, errs := readAll(r)
+	suite.Empty(errs)
+	suite.Equal([]*Record{
+		{Key: int64(0), Data: map[string]any{"vec": []float32{1, 2, 3}}},
+		{Key: int64(1), Data: map[string]any{"vec": []float32{4, 5, 6}}},
+	}, recs)
+
+	r, err = NewReader(bytes.NewReader(npyFile("|b1", "(2,)", []byte{1, 0})), FormatNPY, testOpts)
+	suite.Require().NoError(err)
+
+	recs, errs = readAll(r)
+	suite.Empty(errs)
+	suite.Equal([]*Record{
+		{Key: int64(0), Data: map[string]any{"vec": []bool{true, false}}},
+	}, recs)
+
+	_, err = NewReader(bytes.NewReader(npyFile(">f4", "(2, 3)", nil)), FormatNPY, testOpts)
+	suite.Error(err)
+
+	_, err = NewReader(bytes.NewReader(npyFile("<f4", "(2, 3, 4)", nil)), FormatNPY, testOpts)
+	suite.Error(err)
+
+	r, err = NewReader(bytes.NewReader(npyFile("<f4", "(2, 3)", data.Bytes()[:16])), FormatNPY, testOpts)
+	suite.Require().NoError(err)
+
+	recs, errs = readAll(r)
+	suite.Len(recs, 1)
+	suite.Len(errs, 1)
+}
+
+func (suite *ReaderTestSuite) TestReadVector() {
+	data := bytes.NewBuffer(nil)
+	_ = binary.Write(data, binary.LittleEndian, []float32{1, 2, 3, 4, 5, 6})
+
+	npy := npyFile("<f4", "(6,)", data.Bytes())
+	suite.True(IsNPY(npy))
+	suite.False(IsNPY([]byte("[1,2]")))
+
+	vector, err := ReadVector(bytes.NewReader(npy), FormatNPY)
+	suite.NoError(err)
+	suite.Equal([]float32{1, 2, 3, 4, 5, 6}, vector)
+
+	vector, err = ReadVector(bytes.NewReader(npyFile("<f4", "(1, 6)", data.Bytes())), FormatNPY)
+	suite.NoError(err)
+	suite.Equal([]float32{1, 2, 3, 4, 5, 6}, vector)
+
+	_, err = ReadVector(bytes.NewReader(npyFile("<f4", "(2, 3)", data.Bytes())), FormatNPY)
+	suite.EqualError(err, "npy file contains more than one vector")
+
+	fvecs := bytes.NewBuffer(nil)
+	_ = binary.Write(fvecs, binary.LittleEndian, int32(2))
+	_ = binary.Write(fvecs, binary.LittleEndian, []float32{0.5, 1})
+
+	vector, err = ReadVector(fvecs, FormatFvecs)
+	suite.NoError(err)
+	suite.Equal([]float32{0.5, 1}, vector)
+
+	_, err = ReadVector(bytes.NewReader(nil), FormatFvecs)
+	suite.EqualError(err, "fvecs file does not contain a vector")
+
+	_, err = ReadVector(bytes.NewBufferString("[1,2]"), FormatJSONL)
+	suite.EqualError(err, "a vector can't be read from a jsonl file")
+}
+
+func (suite *ReaderTestSuite) TestVecs() {
+	fvecs := bytes.NewBuffer(nil)
+	_ = binary.Write(fvecs, binary.LittleEndian, int32(2))
+	_ = binary.Write(fvecs, binary.LittleEndian, []float32{0.5, float32(math.Pi)})
+	_ = binary.Write(fvecs, binary.LittleEndian, int32(2))
+	_ = binary.Write(fvecs, binary.LittleEndian, []float32{1, 2})
+
+	r, err := NewReader(fvecs, FormatFvecs, testOpts)
+	suite.Require().NoError(err)
+
+	recs, errs := readAll(r)
+	suite.Empty(errs)
+	suite.Equal([]*Record{
+		{Key: int64(0), Data: map[string]any{"vec": []float32{0.5, float32(math.Pi)}}},
+		{Key: int64(1), Data: map[string]any{"vec": []float32{1, 2}}},
+	}, recs)
+
+	bvecs := bytes.NewBuffer(nil)
+	_ = binary.Write(bvecs, binary.LittleEndian, int32(3))
+	bvecs.Write([]byte{0, 128, 255})
+
+	r, err = NewReader(bvecs, FormatBvecs, testOpts)
+	suite.Require().NoError(err)
+
+	recs, errs = readAll(r)
+	suite.Empty(errs)
+	suite.Equal([]*Record{
+		{Key: int64(0), Data: map[string]any{"vec": []float32{0, 128, 255}}},
+	}, recs)
+}
+
+func (suite *ReaderTestSuite) TestReadIvecs() {
+	buf := bytes.NewBuffer(nil)
+	_ = binary.Write(buf, binary.LittleEndian, int32(2))
+	_ = binary.Write(buf, binary.LittleEndian, []int32{5, 1 << 30})
+	_ = binary.Write(buf, binary.LittleEndian, int32(1))
+	_ = binary.Write(buf, binary.LittleEndian, []int32{7})
+
+	vectors, err := ReadIvecs(buf)
+	suite.NoError(err)
+	suite.Equal([][]int64{{5, 1 << 30}, {7}}, vectors)
+
+	_, err = ReadIvecs(bytes.NewBuffer([]byte{2, 0, 0, 0, 1}))
+	suite.Error(err)
+}
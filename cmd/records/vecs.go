@@ -0,0 +1,103 @@
+package records
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+// vecsReader reads the fvecs and bvecs formats used by the TEXMEX ANN
+// datasets. Each vector is stored as a little-endian int32 dimension count
+// followed by that many float32 (fvecs) or uint8 (bvecs) components.
+type vecsReader struct {
+	reader        *bufio.Reader
+	opts          ReaderOptions
+	componentSize int
+	row           int
+}
+
+func newVecsReader(r io.Reader, opts ReaderOptions, componentSize int) *vecsReader {
+	return &vecsReader{
+		reader:        bufio.NewReader(r),
+		opts:          opts,
+		componentSize: componentSize,
+	}
+}
+
+func (r *vecsReader) Read() (*Record, error) {
+	var dims int32
+
+	if err := binary.Read(r.reader, binary.LittleEndian, &dims); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, io.EOF
+		}
+
+		return nil, fmt.Errorf("failed to read vector %d: %w", r.row, err)
+	}
+
+	if dims <= 0 {
+		return nil, fmt.Errorf("invalid dimension count %d for vector %d", dims, r.row)
+	}
+
+	buf := make([]byte, int(dims)*r.componentSize)
+	if _, err := io.ReadFull(r.reader, buf); err != nil {
+		return nil, fmt.Errorf("failed to read vector %d: %w", r.row, err)
+	}
+
+	vector := make([]float32, dims)
+
+	for i := range vector {
+		if r.componentSize == 1 {
+			vector[i] = float32(buf[i])
+		} else {
+			vector[i] = math.Float32frombits(binary.LittleEndian.Uint32(buf[i*4:]))
+		}
+	}
+
+	key := int64(r.row)
+	r.row++
+
+	return &Record{
+		Key:  key,
+		Data: map[string]any{r.opts.VectorField: vector},
+	}, nil
+}
+
+// ReadIvecs reads every vector of an ivecs file, the format used for the
+// ground truth neighbors of the TEXMEX ANN datasets. Each vector holds the
+// row numbers of a query's nearest neighbors.
+func ReadIvecs(r io.Reader) ([][]int64, error) {
+	reader := bufio.NewReader(r)
+	vectors := [][]int64{}
+
+	for row := 0; ; row++ {
+		var dims int32
+
+		if err := binary.Read(reader, binary.LittleEndian, &dims); err != nil {
+			if errors.Is(err, io.EOF) {
+				return vectors, nil
+			}
+
+			return nil, fmt.Errorf("failed to read vector %d: %w", row, err)
+		}
+
+		if dims < 0 {
+			return nil, fmt.Errorf("invalid dimension count %d for vector %d", dims, row)
+		}
+
+		ids := make([]int32, dims)
+		if err := binary.Read(reader, binary.LittleEndian, ids); err != nil {
+			return nil, fmt.Errorf("failed to read vector %d: %w", row, err)
+		}
+
+		vector := make([]int64, dims)
+		for i, id := range ids {
+			vector[i] = int64(id)
+		}
+
+		vectors = append(vectors, vector)
+	}
+}
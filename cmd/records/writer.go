@@ -0,0 +1,276 @@
+package records
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Writer writes records one at a time. Flush must be called once all records
+// have been written.
+type Writer interface {
+	Write(record *Record) error
+	Flush() error
+}
+
+// WriterOptions configures how records are written to a file.
+type WriterOptions struct {
+	// KeyField is the field (or CSV column) each record's key is written to.
+	KeyField string
+	// VectorField is the field holding each record's vector.
+	VectorField string
+	// Columns are the CSV columns written after the key column. Fields not
+	// in Columns are not written.
+	Columns []string
+	// Append skips writing any header e.g. when resuming a previous export.
+	Append bool
+}
+
+// WriteFormatNames returns the names of the formats records can be written to.
+func WriteFormatNames() []string {
+	return []string{
+		string(FormatJSONL),
+		string(FormatCSV),
+		string(FormatFvecs),
+	}
+}
+
+// NewWriter returns a Writer for the given format. Records written with a
+// Writer can be read back using a Reader for the same format without losing
+// type information e.g. whether a vector is a float or bool vector.
+func NewWriter(w io.Writer, format Format, opts WriterOptions) (Writer, error) {
+	switch format {
+	case FormatJSONL:
+		return newJSONLWriter(w, opts), nil
+	case FormatCSV:
+		return newCSVWriter(w, opts)
+	case FormatFvecs:
+		return newFvecsWriter(w, opts), nil
+	case FormatNPY, FormatBvecs:
+		return nil, fmt.Errorf("writing %s files is not supported, valid formats: %v", format, WriteFormatNames())
+	default:
+		return nil, fmt.Errorf("unsupported format %q", format)
+	}
+}
+
+type jsonlWriter struct {
+	buf     *bufio.Writer
+	encoder *json.Encoder
+	opts    WriterOptions
+}
+
+func newJSONLWriter(w io.Writer, opts WriterOptions) *jsonlWriter {
+	buf := bufio.NewWriter(w)
+	encoder := json.NewEncoder(buf)
+	encoder.SetEscapeHTML(false)
+
+	return &jsonlWriter{
+		buf:     buf,
+		encoder: encoder,
+		opts:    opts,
+	}
+}
+
+func (w *jsonlWriter) Write(record *Record) error {
+	data := make(map[string]any, len(record.Data)+1)
+
+	for k, v := range record.Data {
+		data[k] = ToJSONCompatible(v)
+	}
+
+	if vector, ok := record.Data[w.opts.VectorField].([]float32); ok {
+		data[w.opts.VectorField] = floatVector(vector)
+	}
+
+	data[w.opts.KeyField] = record.Key
+
+	return w.encoder.Encode(data)
+}
+
+func (w *jsonlWriter) Flush() error {
+	return w.buf.Flush()
+}
+
+type csvWriter struct {
+	writer *csv.Writer
+	opts   WriterOptions
+}
+
+func newCSVWriter(w io.Writer, opts WriterOptions) (*csvWriter, error) {
+	writer := csv.NewWriter(w)
+
+	if slices.Contains(opts.Columns, opts.KeyField) {
+		return nil, fmt.Errorf("column %s is already used for the record key", opts.KeyField)
+	}
+
+	if !opts.Append {
+		if err := writer.Write(append([]string{opts.KeyField}, opts.Columns...)); err != nil {
+			return nil, err
+		}
+	}
+
+	return &csvWriter{
+		writer: writer,
+		opts:   opts,
+	}, nil
+}
+
+func (w *csvWriter) Write(record *Record) error {
+	row := make([]string, len(w.opts.Columns)+1)
+
+	key, err := formatCSVCell(record.Key)
+	if err != nil {
+		return err
+	}
+
+	row[0] = key
+
+	for i, column := range w.opts.Columns {
+		val, ok := record.Data[column]
+		if !ok {
+			continue
+		}
+
+		if vector, ok := val.([]float32); ok && column == w.opts.VectorField {
+			val = floatVector(vector)
+		}
+
+		cell, err := formatCSVCell(val)
+		if err != nil {
+			return fmt.Errorf("unable to write column %s: %w", column, err)
+		}
+
+		row[i+1] = cell
+	}
+
+	return w.writer.Write(row)
+}
+
+func (w *csvWriter) Flush() error {
+	w.writer.Flush()
+	return w.writer.Error()
+}
+
+// formatCSVCell formats a value so that parseCSVCell returns the same value.
+// Strings are written as is unless they would be parsed as something other
+// than the same string, in which case they are written as a JSON string.
+func formatCSVCell(val any) (string, error) {
+	if s, ok := val.(string); ok {
+		if parsed, ok := parseCSVCell(s).(string); ok && parsed == s && s != "" {
+			return s, nil
+		}
+	}
+
+	b, err := json.Marshal(ToJSONCompatible(val))
+	if err != nil {
+		return "", err
+	}
+
+	return string(b), nil
+}
+
+type fvecsWriter struct {
+	buf  *bufio.Writer
+	opts WriterOptions
+}
+
+func newFvecsWriter(w io.Writer, opts WriterOptions) *fvecsWriter {
+	return &fvecsWriter{
+		buf:  bufio.NewWriter(w),
+		opts: opts,
+	}
+}
+
+// Write writes only the record's vector. Keys and other fields are not
+// stored in fvecs files.
+func (w *fvecsWriter) Write(record *Record) error {
+	val, ok := record.Data[w.opts.VectorField]
+	if !ok {
+		return fmt.Errorf("record %v is missing vector field %s", record.Key, w.opts.VectorField)
+	}
+
+	vector, ok := val.([]float32)
+	if !ok {
+		return fmt.Errorf("record %v: fvecs files can only store float vectors, found %T", record.Key, val)
+	}
+
+	b := make([]byte, 4*(len(vector)+1))
+	binary.LittleEndian.PutUint32(b, uint32(len(vector)))
+
+	for i, f := range vector {
+		binary.LittleEndian.PutUint32(b[4*(i+1):], math.Float32bits(f))
+	}
+
+	_, err := w.buf.Write(b)
+
+	return err
+}
+
+func (w *fvecsWriter) Flush() error {
+	return w.buf.Flush()
+}
+
+// floatVector is a float vector encoded as JSON with a decimal point in every
+// element, so that a vector of 0s and 1s is read back as a float vector
+// rather than a bool vector.
+type floatVector []float32
+
+func (v floatVector) MarshalJSON() ([]byte, error) {
+	b := []byte{'['}
+
+	for i, f := range v {
+		if math.IsNaN(float64(f)) || math.IsInf(float64(f), 0) {
+			return nil, fmt.Errorf("unsupported vector value %v", f)
+		}
+
+		if i > 0 {
+			b = append(b, ',')
+		}
+
+		s := strconv.FormatFloat(float64(f), 'g', -1, 32)
+		if !strings.ContainsAny(s, ".e") {
+			s += ".0"
+		}
+
+		b = append(b, s...)
+	}
+
+	return append(b, ']'), nil
+}
+
+// ToJSONCompatible converts values returned by AVS into values that can be
+// encoded as JSON e.g. maps with non-string keys.
+func ToJSONCompatible(val any) any {
+	switch v := val.(type) {
+	case map[any]any:
+		m := make(map[string]any, len(v))
+		for k, e := range v {
+			m[fmt.Sprintf("%v", k)] = ToJSONCompatible(e)
+		}
+
+		return m
+	case map[string]any:
+		m := make(map[string]any, len(v))
+		for k, e := range v {
+			m[k] = ToJSONCompatible(e)
+		}
+
+		return m
+	case []any:
+		l := make([]any, len(v))
+		for i, e := range v {
+			l[i] = ToJSONCompatible(e)
+		}
+
+		return l
+	default:
+		return v
+	}
+}
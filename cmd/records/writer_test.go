@@ -0,0 +1,131 @@
+//go:build unit
+
+package records
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type WriterTestSuite struct {
+	suite.Suite
+}
+
+func TestWriterSuite(t *testing.T) {
+	suite.Run(t, new(WriterTestSuite))
+}
+
+var roundTripRecords = []*Record{
+	{
+		Key: "a",
+		Data: map[string]any{
+			"vec":   []float32{0.1, 1, 3.4028235e+38},
+			"name":  "foo",
+			"count": int64(3),
+			"ratio": 0.25,
+		},
+	},
+	{
+		Key: int64(2),
+		Data: map[string]any{
+			"vec":  []bool{true, false, true},
+			"name": "123",
+			"tags": []any{"x", int64(2)},
+		},
+	},
+	{
+		Key: "3",
+		Data: map[string]any{
+			"vec":  []float32{1, 0, 1},
+			"name": "",
+		},
+	},
+}
+
+func (suite *WriterTestSuite) roundTrip(format Format, columns []string) []*Record {
+	buf := &bytes.Buffer{}
+
+	w, err := NewWriter(buf, format, WriterOptions{KeyField: "id", VectorField: "vec", Columns: columns})
+	suite.Require().NoError(err)
+
+	for _, record := range roundTripRecords {
+		suite.Require().NoError(w.Write(record))
+	}
+
+	suite.Require().NoError(w.Flush())
+
+	r, err := NewReader(buf, format, ReaderOptions{KeyField: "id", VectorField: "vec"})
+	suite.Require().NoError(err)
+
+	recs, errs := readAll(r)
+	suite.Require().Empty(errs)
+
+	return recs
+}
+
+func (suite *WriterTestSuite) TestJSONLRoundTrip() {
+	suite.Equal(roundTripRecords, suite.roundTrip(FormatJSONL, nil))
+}
+
+func (suite *WriterTestSuite) TestCSVRoundTrip() {
+	recs := suite.roundTrip(FormatCSV, []string{"vec", "name", "count", "ratio", "tags"})
+	suite.Equal(roundTripRecords, recs)
+}
+
+func (suite *WriterTestSuite) TestCSVColumns() {
+	recs := suite.roundTrip(FormatCSV, []string{"name"})
+	suite.Equal([]*Record{
+		{Key: "a", Data: map[string]any{"name": "foo"}},
+		{Key: int64(2), Data: map[string]any{"name": "123"}},
+		{Key: "3", Data: map[string]any{"name": ""}},
+	}, recs)
+
+	_, err := NewWriter(io.Discard, FormatCSV, WriterOptions{KeyField: "id", Columns: []string{"id"}})
+	suite.Error(err)
+}
+
+func (suite *WriterTestSuite) TestCSVAppend() {
+	buf := &bytes.Buffer{}
+
+	w, err := NewWriter(buf, FormatCSV, WriterOptions{KeyField: "id", Columns: []string{"name"}, Append: true})
+	suite.Require().NoError(err)
+	suite.Require().NoError(w.Write(roundTripRecords[0]))
+	suite.Require().NoError(w.Flush())
+	suite.Equal("a,foo\n", buf.String())
+}
+
+func (suite *WriterTestSuite) TestFvecs() {
+	buf := &bytes.Buffer{}
+
+	w, err := NewWriter(buf, FormatFvecs, WriterOptions{VectorField: "vec"})
+	suite.Require().NoError(err)
+	suite.NoError(w.Write(roundTripRecords[0]))
+	suite.Error(w.Write(roundTripRecords[1]))
+	suite.NoError(w.Write(roundTripRecords[2]))
+	suite.NoError(w.Flush())
+
+	r, err := NewReader(buf, FormatFvecs, ReaderOptions{VectorField: "vec"})
+	suite.Require().NoError(err)
+
+	recs, errs := readAll(r)
+	suite.Empty(errs)
+	suite.Equal([]*Record{
+		{Key: int64(0), Data: map[string]any{"vec": roundTripRecords[0].Data["vec"]}},
+		{Key: int64(1), Data: map[string]any{"vec": roundTripRecords[2].Data["vec"]}},
+	}, recs)
+}
+
+func (suite *WriterTestSuite) TestUnsupportedFormat() {
+	_, err := NewWriter(io.Discard, FormatNPY, WriterOptions{})
+	suite.Error(err)
+}
+
+func (suite *WriterTestSuite) TestToJSONCompatible() {
+	suite.Equal(
+		map[string]any{"1": "a", "b": []any{map[string]any{"true": int64(1)}}},
+		ToJSONCompatible(map[any]any{int64(1): "a", "b": []any{map[any]any{true: int64(1)}}}),
+	)
+}
@@ -5,6 +5,7 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"strings"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
@@ -12,18 +13,15 @@ import (
 
 var rolesListFlags = &struct {
 	clientFlags *flags.ClientFlags
-	format      int // For testing. Hidden
+	output      flags.OutputFlag
 }{
 	clientFlags: rootFlags.clientFlags,
+	output:      flags.OutputTable,
 }
 
 func newRoleListFlagSet() *pflag.FlagSet {
 	flagSet := &pflag.FlagSet{}
-
-	err := flags.AddFormatTestFlag(flagSet, &rolesListFlags.format)
-	if err != nil {
-		panic(err)
-	}
+	flagSet.VarP(&rolesListFlags.output, flags.Output, flags.OutputShort, fmt.Sprintf("The output format. Valid values: %s", strings.Join(flags.OutputEnum(), ", "))) //nolint:lll // For readability
 
 	return flagSet
 }
@@ -46,7 +44,10 @@ asvec role ls
 		},
 		RunE: func(_ *cobra.Command, _ []string) error {
 			logger.Debug("parsed flags",
-				rolesListFlags.clientFlags.NewSLogAttr()...,
+				append(
+					rolesListFlags.clientFlags.NewSLogAttr(),
+					slog.String(flags.Output, rolesListFlags.output.String()),
+				)...,
 			)
 
 			client, err := createClientFromFlags(rolesListFlags.clientFlags)
@@ -66,7 +67,7 @@ asvec role ls
 
 			logger.Debug("server role list", slog.String("response", userList.String()))
 
-			view.PrintRoles(userList, rolesListFlags.format)
+			view.PrintRoles(userList, rolesListFlags.output)
 
 			return nil
 		},
@@ -11,6 +11,7 @@ import (
 	"github.com/aerospike/tools-common-go/config"
 	common "github.com/aerospike/tools-common-go/flags"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	"golang.org/x/term"
 )
 
@@ -80,40 +81,9 @@ asvec --help
 			logger.Info("Loading configuration parameters from file", slog.String("file", configFile))
 		}
 
-		bindEnvs := []string{
-			flags.Host,
-			flags.Seeds,
-			flags.AuthUser,
-			flags.AuthPassword,
-			flags.AuthCredentials,
-			flags.TLSCaFile,
-			flags.TLSCaPath,
-			flags.TLSCertFile,
-			flags.TLSKeyFile,
-			flags.TLSKeyFilePass,
-		}
-
-		flagToEnv := func(flag string) string {
-			env := strings.ReplaceAll(flag, "-", "_")
-			env = strings.ToUpper(env)
-			env = "ASVEC_" + env
-			return env
-		}
-
 		// Bind specified flags to ASVEC_*. Viper fails to set it correctly
 		// because of the funky stuff we do in config.SetFlags()
-		for _, flagName := range bindEnvs {
-			env := flagToEnv(flagName)
-
-			if value := os.Getenv(env); value != "" {
-				err := cmd.Flags().Lookup(flagName).Value.Set(value)
-				if err != nil {
-					return err
-				}
-			}
-		}
-
-		return nil
+		return bindEnvs(cmd.Flags())
 	},
 	PersistentPostRun: func(_ *cobra.Command, _ []string) {
 		code := errCode.Load()
@@ -124,6 +94,49 @@ asvec --help
 	},
 }
 
+// envFlags are the flags which can be set with an ASVEC_* environment
+// variable, e.g. ASVEC_HOST for --host.
+var envFlags = []string{
+	flags.Host,
+	flags.Seeds,
+	flags.AuthUser,
+	flags.AuthPassword,
+	flags.AuthCredentials,
+	flags.TLSCaFile,
+	flags.TLSCaPath,
+	flags.TLSCertFile,
+	flags.TLSKeyFile,
+	flags.TLSKeyFilePass,
+	flags.EmbeddingAPIKey,
+}
+
+// bindEnvs sets each of envFlags in flagSet from its environment variable.
+// Flags which the command does not have are skipped.
+func bindEnvs(flagSet *pflag.FlagSet) error {
+	flagToEnv := func(flag string) string {
+		env := strings.ReplaceAll(flag, "-", "_")
+		env = strings.ToUpper(env)
+		env = "ASVEC_" + env
+		return env
+	}
+
+	for _, flagName := range envFlags {
+		flag := flagSet.Lookup(flagName)
+		if flag == nil {
+			continue
+		}
+
+		if value := os.Getenv(flagToEnv(flagName)); value != "" {
+			err := flag.Value.Set(value)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 func Execute() {
@@ -0,0 +1,22 @@
+//go:build unit
+
+package cmd
+
+import (
+	"asvec/cmd/flags"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBindEnvs(t *testing.T) {
+	t.Setenv("ASVEC_EMBEDDING_API_KEY", "secret")
+	t.Setenv("ASVEC_HOST", "1.1.1.1:5000")
+
+	embeddingFlags := flags.NewEmbeddingFlags()
+
+	// The embedding flags don't include --host, which is skipped.
+	require.NoError(t, bindEnvs(embeddingFlags.NewFlagSet()))
+	assert.Equal(t, "secret", embeddingFlags.APIKey)
+}
@@ -0,0 +1,21 @@
+package cmd
+
+import (
+	"slices"
+	"time"
+)
+
+// percentile returns the pth percentile, 0 <= p <= 100, of latencies using
+// the nearest-rank method. latencies is sorted in place.
+func percentile(latencies []time.Duration, p float64) time.Duration {
+	if len(latencies) == 0 {
+		return 0
+	}
+
+	slices.Sort(latencies)
+
+	rank := int(p/100*float64(len(latencies))+0.5) - 1
+	rank = max(0, min(rank, len(latencies)-1))
+
+	return latencies[rank]
+}
@@ -0,0 +1,23 @@
+//go:build unit
+
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPercentile(t *testing.T) {
+	latencies := []time.Duration{}
+	for i := 100; i > 0; i-- {
+		latencies = append(latencies, time.Duration(i)*time.Millisecond)
+	}
+
+	assert.Equal(t, 50*time.Millisecond, percentile(latencies, 50))
+	assert.Equal(t, 99*time.Millisecond, percentile(latencies, 99))
+	assert.Equal(t, 100*time.Millisecond, percentile(latencies, 100))
+	assert.Equal(t, 1*time.Millisecond, percentile(latencies, 0))
+	assert.Equal(t, time.Duration(0), percentile(nil, 50))
+}
@@ -5,16 +5,20 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"strings"
 
+	avs "github.com/aerospike/avs-client-go"
+	"github.com/aerospike/avs-client-go/protos"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 )
 
 var userListFlags = &struct {
 	clientFlags *flags.ClientFlags
-	format      int // For testing. Hidden
+	output      flags.OutputFlag
 }{
 	clientFlags: rootFlags.clientFlags,
+	output:      flags.OutputTable,
 }
 
 func newUserListFlagSet() *pflag.FlagSet {
@@ -22,10 +26,7 @@ func newUserListFlagSet() *pflag.FlagSet {
 
 	flagSet.AddFlagSet(userListFlags.clientFlags.NewClientFlagSet())
 
-	err := flags.AddFormatTestFlag(flagSet, &userListFlags.format)
-	if err != nil {
-		panic(err)
-	}
+	flagSet.VarP(&userListFlags.output, flags.Output, flags.OutputShort, fmt.Sprintf("The output format. Valid values: %s", strings.Join(flags.OutputEnum(), ", "))) //nolint:lll // For readability
 
 	return flagSet
 }
@@ -40,25 +41,50 @@ func newUserListCmd() *cobra.Command {
 For more information on managing users, refer to: 
 https://aerospike.com/docs/vector/operate/user-management
 
+%s
 
 For example:
 
 %s
 asvec user ls
-		`, HelpTxtSetupEnv),
+asvec user ls --%s all
+		`, multiClusterHelp("users"), HelpTxtSetupEnv, flags.ClusterName),
 		PreRunE: func(_ *cobra.Command, _ []string) error {
 			return checkSeedsAndHost()
 		},
 		RunE: func(_ *cobra.Command, _ []string) error {
 			logger.Debug("parsed flags",
-				userListFlags.clientFlags.NewSLogAttr()...,
+				append(
+					userListFlags.clientFlags.NewSLogAttr(),
+					slog.String(flags.Output, userListFlags.output.String()),
+				)...,
 			)
 
-			client, err := createClientFromFlags(userListFlags.clientFlags)
+			clusterNames, err := multiClusterNames()
+			if err != nil {
+				return err
+			}
+
+			if clusterNames != nil {
+				results := forEachCluster(clusterNames, userListFlags.clientFlags,
+					func(client *avs.Client, clientFlags *flags.ClientFlags) (*protos.ListUsersResponse, error) {
+						ctx, cancel := context.WithTimeout(context.Background(), clientFlags.Timeout)
+						defer cancel()
+
+						return client.ListUsers(ctx)
+					},
+				)
+
+				view.PrintClusterUsers(results, userListFlags.output)
+
+				return nil
+			}
+
+			client, releaseClient, err := acquireClient(userListFlags.clientFlags)
 			if err != nil {
 				return err
 			}
-			defer client.Close()
+			defer releaseClient()
 
 			ctx, cancel := context.WithTimeout(context.Background(), userListFlags.clientFlags.Timeout)
 			defer cancel()
@@ -71,8 +97,11 @@ asvec user ls
 
 			logger.Debug("server user list", slog.String("response", userList.String()))
 
-			view.PrintUsers(userList, userListFlags.format)
-			view.Print("Use 'role list' to view available roles")
+			view.PrintUsers(userList, userListFlags.output)
+
+			if !userListFlags.output.IsStructured() {
+				view.Print("Use 'role list' to view available roles")
+			}
 
 			return nil
 		},
@@ -84,4 +113,7 @@ func init() {
 
 	userCmd.AddCommand(userListCmd)
 	userListCmd.Flags().AddFlagSet(newUserListFlagSet())
+
+	// Add watch functionality to the user list command
+	wrapCommandWithWatch(userListCmd, &watchChanges{keys: []string{"username"}})
 }
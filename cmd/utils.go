@@ -7,14 +7,24 @@ import (
 	"log/slog"
 	"os"
 	"strings"
+	"sync"
 
 	"golang.org/x/term"
 
+	as "github.com/aerospike/aerospike-client-go/v7"
 	avs "github.com/aerospike/avs-client-go"
+	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 )
 
+// promptMu serializes password prompts, e.g. when connecting to multiple
+// clusters concurrently.
+var promptMu sync.Mutex
+
 func passwordPrompt(prompt string) (string, error) {
+	promptMu.Lock()
+	defer promptMu.Unlock()
+
 	fmt.Print(prompt)
 
 	bytePassword, err := term.ReadPassword(int(os.Stdin.Fd()))
@@ -54,6 +64,9 @@ func createClientFromFlags(clientFlags *flags.ClientFlags) (*avs.Client, error)
 			}
 
 			password = &pass
+
+			// Remember the password so that reconnecting does not prompt again.
+			_ = clientFlags.AuthCredentials.Password.Set(pass)
 		}
 	}
 
@@ -78,6 +91,64 @@ func createClientFromFlags(clientFlags *flags.ClientFlags) (*avs.Client, error)
 
 	return client, nil
 }
+
+// newClusterClientFlags returns client flags populated from the clusterName
+// section of the configuration file. Values missing from the section use the
+// flag defaults, except for the timeout which is copied from base.
+func newClusterClientFlags(clusterName string, base *flags.ClientFlags) (*flags.ClientFlags, error) {
+	if !viper.IsSet(clusterName) {
+		return nil, fmt.Errorf("cluster %q not found in the configuration file", clusterName)
+	}
+
+	clientFlags := flags.NewClientFlags()
+	flagSet := clientFlags.NewClientFlagSet()
+	clientFlags.Timeout = base.Timeout
+
+	var err error
+
+	flagSet.VisitAll(func(f *pflag.Flag) {
+		key := clusterName + "." + f.Name
+
+		if err != nil || !viper.IsSet(key) {
+			return
+		}
+
+		if setErr := f.Value.Set(viper.GetString(key)); setErr != nil {
+			err = fmt.Errorf("failed to parse %s for cluster %q: %w", f.Name, clusterName, setErr)
+		}
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return clientFlags, nil
+}
+
+// createAerospikeClientFromFlags creates a client connected directly to the
+// Aerospike Database cluster backing AVS.
+func createAerospikeClientFromFlags(aerospikeFlags *flags.AerospikeFlags) (*as.Client, error) {
+	conf := aerospikeFlags.NewAerospikeConfig()
+
+	if len(conf.Seeds) == 0 {
+		return nil, fmt.Errorf("--%shost is required to connect to Aerospike Database", flags.AerospikeFlagPrefix)
+	}
+
+	policy, err := conf.NewClientPolicy()
+	if err != nil {
+		logger.Error("failed to create Aerospike Database client policy", slog.Any("error", err))
+		return nil, err
+	}
+
+	client, err := as.NewClientWithPolicyAndHost(policy, conf.NewHosts()...)
+	if err != nil {
+		logger.Error("failed to create Aerospike Database client", slog.Any("error", err))
+		return nil, err
+	}
+
+	return client, nil
+}
+
 func parseBothHostSeedsFlag(seeds *flags.SeedsSliceFlag, host *flags.HostPortFlag) avs.HostPortSlice {
 	hosts := avs.HostPortSlice{}
 
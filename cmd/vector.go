@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"asvec/cmd/flags"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// vectorCmd represents the vector command
+var vectorCmd = &cobra.Command{
+	Use:   "vector",
+	Short: "A parent command for vector math.",
+	Long: `A parent command for computing distances between vectors and normalizing
+vectors on the client, without connecting to a cluster. Useful to sanity check
+the distances returned by a query.
+
+For example:
+
+	asvec vector --help
+		`,
+}
+
+// vectorInputHelp describes the ways to pass a vector flag other than a list.
+var vectorInputHelp = fmt.Sprintf(
+	"Use %sfile to read a .npy, .fvecs, or text file, %s to read stdin, or %s for base64 little-endian float32s.",
+	flags.VectorFilePrefix, flags.VectorStdin, flags.VectorBase64Prefix,
+)
+
+// formatVector formats v as a vector accepted by --vector, with the fewest
+// digits needed to read back the same float32s.
+func formatVector(v []float32) string {
+	vals := make([]string, len(v))
+	for i, f := range v {
+		vals[i] = formatFloat32(f)
+	}
+
+	return "[" + strings.Join(vals, ",") + "]"
+}
+
+func formatFloat32(f float32) string {
+	return strconv.FormatFloat(float64(f), 'g', -1, 32)
+}
+
+func init() {
+	rootCmd.AddCommand(vectorCmd)
+}
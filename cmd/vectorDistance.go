@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"asvec/cmd/flags"
+	"asvec/internal/vecmath"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/aerospike/avs-client-go/protos"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+var vectorDistanceFlags = &struct {
+	a              flags.VectorFlag
+	b              flags.VectorFlag
+	distanceMetric flags.DistanceMetricFlag
+}{}
+
+func newVectorDistanceFlagSet() *pflag.FlagSet {
+	flagSet := &pflag.FlagSet{}
+	flagSet.Var(&vectorDistanceFlags.a, flags.VectorA, fmt.Sprintf("The first vector, e.g. [0.5,0.1,0.3] or [1,0,1]. %s", vectorInputHelp))                                                                  //nolint:lll // For readability
+	flagSet.Var(&vectorDistanceFlags.b, flags.VectorB, "The second vector, with the same dimension as the first.")                                                                                           //nolint:lll // For readability
+	flagSet.VarP(&vectorDistanceFlags.distanceMetric, flags.DistanceMetric, flags.DistanceMetricShort, fmt.Sprintf("The distance metric. Valid values: %s", strings.Join(flags.DistanceMetricEnum(), ", "))) //nolint:lll // For readability
+
+	return flagSet
+}
+
+var vectorDistanceRequiredFlags = []string{
+	flags.VectorA,
+	flags.VectorB,
+	flags.DistanceMetric,
+}
+
+func newVectorDistanceCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "distance",
+		Short: "A command for computing the distance between two vectors",
+		Long: `A command for computing the distance between two vectors with one of the
+distance metrics of an index. The distance is computed the same way as
+"asvec index recall" and "asvec query --rerank exact": smaller is always closer,
+so the dot product distance is the negated dot product. Vectors of booleans are
+treated as vectors of 0s and 1s.
+
+For example:
+
+asvec vector distance --a "[0.5,0.1,0.3]" --b "[0.4,0.2,0.3]" -m COSINE
+asvec vector distance --a "[1,0,1,1]" --b "[1,1,0,1]" -m HAMMING
+			`,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			logger.Debug("parsed flags",
+				slog.String(flags.VectorA, vectorDistanceFlags.a.String()),
+				slog.String(flags.VectorB, vectorDistanceFlags.b.String()),
+				slog.Any(flags.DistanceMetric, vectorDistanceFlags.distanceMetric),
+			)
+
+			metric := protos.VectorDistanceMetric(
+				protos.VectorDistanceMetric_value[vectorDistanceFlags.distanceMetric.String()],
+			)
+
+			distance, err := vecmath.Distance(metric, vectorDistanceFlags.a.Vector(), vectorDistanceFlags.b.Vector())
+			if err != nil {
+				logger.Error("unable to compute distance", slog.Any("error", err))
+				return err
+			}
+
+			view.Print(formatFloat32(distance))
+
+			return nil
+		},
+	}
+}
+
+func init() {
+	vectorDistanceCmd := newVectorDistanceCmd()
+	vectorCmd.AddCommand(vectorDistanceCmd)
+	vectorDistanceCmd.Flags().AddFlagSet(newVectorDistanceFlagSet())
+
+	for _, flag := range vectorDistanceRequiredFlags {
+		err := vectorDistanceCmd.MarkFlagRequired(flag)
+		if err != nil {
+			panic(err)
+		}
+	}
+}
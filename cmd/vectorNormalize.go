@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"asvec/cmd/flags"
+	"asvec/internal/vecmath"
+	"fmt"
+	"log/slog"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+var vectorNormalizeFlags = &struct {
+	vector flags.VectorFlag
+}{}
+
+func newVectorNormalizeFlagSet() *pflag.FlagSet {
+	flagSet := &pflag.FlagSet{}
+	flagSet.VarP(&vectorNormalizeFlags.vector, flags.Vector, flags.VectorShort, fmt.Sprintf("The vector to normalize, e.g. [3,0,4]. %s", vectorInputHelp)) //nolint:lll // For readability
+
+	return flagSet
+}
+
+var vectorNormalizeRequiredFlags = []string{
+	flags.Vector,
+}
+
+func newVectorNormalizeCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "normalize",
+		Short: "A command for scaling a vector to a length of 1",
+		Long: `A command for scaling a vector to a length of 1. The result is printed in
+the format accepted by --vector, so it can be passed to other commands. Dot
+product indexes usually expect normalized vectors, and the cosine distance of
+two vectors is the same as that of their normalized copies.
+
+For example:
+
+asvec vector normalize -v "[3,0,4]"
+asvec query -i my-index -n my-namespace -v "$(asvec vector normalize -v "[3,0,4]")"
+			`,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			logger.Debug("parsed flags",
+				slog.String(flags.Vector, vectorNormalizeFlags.vector.String()),
+			)
+
+			vector, err := vecmath.ToFloat32(vectorNormalizeFlags.vector.Vector())
+			if err != nil {
+				return err
+			}
+
+			normalized, err := vecmath.Normalize(vector)
+			if err != nil {
+				logger.Error("unable to normalize vector", slog.Any("error", err))
+				return err
+			}
+
+			view.Print(formatVector(normalized))
+
+			return nil
+		},
+	}
+}
+
+func init() {
+	vectorNormalizeCmd := newVectorNormalizeCmd()
+	vectorCmd.AddCommand(vectorNormalizeCmd)
+	vectorNormalizeCmd.Flags().AddFlagSet(newVectorNormalizeFlagSet())
+
+	for _, flag := range vectorNormalizeRequiredFlags {
+		err := vectorNormalizeCmd.MarkFlagRequired(flag)
+		if err != nil {
+			panic(err)
+		}
+	}
+}
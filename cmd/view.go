@@ -1,20 +1,37 @@
 package cmd
 
 import (
+	"asvec/cmd/flags"
+	"asvec/cmd/jsonpath"
 	"asvec/cmd/writers"
+	"bytes"
+	"encoding/json"
 	"fmt"
 	"io"
 	"log/slog"
 	"sync/atomic"
+	"text/template"
 
 	tableColor "github.com/jedib0t/go-pretty/v6/text"
 
 	"github.com/aerospike/avs-client-go"
 	"github.com/aerospike/avs-client-go/protos"
+	"gopkg.in/yaml.v3"
 )
 
 var errCode atomic.Uint32
 
+// setErrCode sets the exit code unless a higher one was already set, so that
+// a failed --fail-if condition is not hidden by a later warning.
+func setErrCode(code uint32) {
+	for {
+		current := errCode.Load()
+		if current >= code || errCode.CompareAndSwap(current, code) {
+			return
+		}
+	}
+}
+
 type View struct {
 	out    io.Writer
 	err    io.Writer
@@ -67,28 +84,154 @@ func (v *View) Newline() {
 }
 
 func (v *View) Warning(f string) {
-	errCode.Store(1)
+	setErrCode(1)
 	v.PrintErr(v.yellowString("Warning: %s", f))
 }
 
 func (v *View) Warningf(f string, a ...any) {
-	errCode.Store(1)
+	setErrCode(1)
 	//nolint:govet // these need to be dynamic
 	v.PrintfErr(v.yellowString("Warning: "+f, a...))
 }
 
 func (v *View) Error(f string) {
-	errCode.Store(1)
+	setErrCode(1)
 	//nolint:govet // these need to be dynamic
 	v.PrintfErr(v.redString("Error: %s", f))
 }
 
 func (v *View) Errorf(f string, a ...any) {
-	errCode.Store(1)
+	setErrCode(1)
 	//nolint:govet // these need to be dynamic
 	v.PrintfErr(v.redString("Error: "+f, a...))
 }
 
+// printStructured prints data as JSON, YAML, a single line of JSON, or using
+// a template.
+func (v *View) printStructured(output flags.OutputFlag, data any) {
+	if kind, text, ok := output.Template(); ok {
+		if err := v.printTemplate(kind, text, data); err != nil {
+			v.logger.Error("failed to render output template", slog.Any("error", err))
+			v.Errorf("Failed to render %s output: %s", kind, err)
+		}
+
+		return
+	}
+
+	switch output {
+	case flags.OutputJSON:
+		encoder := json.NewEncoder(v.out)
+		encoder.SetIndent("", "  ")
+
+		if err := encoder.Encode(data); err != nil {
+			panic(err)
+		}
+	case flags.OutputYAML:
+		v.printYAML(data)
+	default:
+		if err := json.NewEncoder(v.out).Encode(data); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// printTemplate executes a go-template or jsonpath template against data.
+// Templates see the same fields as the JSON output so data is first
+// marshaled to JSON. Numbers are kept as json.Number so they print exactly as
+// they appear in the JSON output.
+func (v *View) printTemplate(kind flags.OutputFlag, text string, data any) error {
+	out, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(out))
+	decoder.UseNumber()
+
+	var generic any
+
+	if err := decoder.Decode(&generic); err != nil {
+		return err
+	}
+
+	if kind == flags.OutputJSONPath {
+		tmpl, err := jsonpath.Parse(text)
+		if err != nil {
+			return err
+		}
+
+		return tmpl.Execute(v.out, generic)
+	}
+
+	tmpl, err := template.New(string(kind)).Parse(text)
+	if err != nil {
+		return err
+	}
+
+	return tmpl.Execute(v.out, generic)
+}
+
+// printStructuredList prints items as a JSON array, a YAML sequence, or one
+// line of JSON per item.
+func (v *View) printStructuredList(output flags.OutputFlag, items []any) {
+	if output != flags.OutputJSONL {
+		// Templates are executed once against the entire list.
+		v.printStructured(output, items)
+		return
+	}
+
+	encoder := json.NewEncoder(v.out)
+
+	for _, item := range items {
+		if err := encoder.Encode(item); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// printYAML prints data as YAML. data is first marshaled to JSON so that the
+// YAML output has the same keys, in the same order, as the JSON output.
+func (v *View) printYAML(data any) {
+	out, err := json.Marshal(data)
+	if err != nil {
+		panic(err)
+	}
+
+	var node yaml.Node
+
+	if err := yaml.Unmarshal(out, &node); err != nil {
+		panic(err)
+	}
+
+	clearYAMLStyle(&node)
+
+	encoder := yaml.NewEncoder(v.out)
+	encoder.SetIndent(2)
+
+	if err := encoder.Encode(&node); err != nil {
+		panic(err)
+	}
+
+	if err := encoder.Close(); err != nil {
+		panic(err)
+	}
+}
+
+// clearYAMLStyle resets the flow style YAML nodes have when parsed from JSON
+// so they are printed in block style. Strings keep their quoting so that
+// values such as "true" or "1" are not printed as a bool or number.
+func clearYAMLStyle(node *yaml.Node) {
+	if node.Kind != yaml.ScalarNode {
+		node.Style = 0
+	} else if node.Style == yaml.DoubleQuotedStyle && node.Tag == "!!str" {
+		node.Style = 0
+	}
+
+	for _, n := range node.Content {
+		clearYAMLStyle(n)
+	}
+}
+
 func (v *View) getIndexListWriter(verbose bool) *writers.IndexTableWriter {
 	return writers.NewIndexTableWriter(v.out, verbose, v.logger)
 }
@@ -97,16 +240,64 @@ func (v *View) PrintIndexes(
 	indexList *protos.IndexDefinitionList,
 	indexStatusList []*protos.IndexStatusResponse,
 	verbose bool,
-	format int,
+	output flags.OutputFlag,
+) {
+	v.PrintClusterIndexes(
+		[]*clusterResult[*indexListResult]{{value: &indexListResult{indexList, indexStatusList}}},
+		verbose,
+		output,
+	)
+}
+
+// PrintClusterIndexes prints the indexes of multiple clusters in a single
+// table with a leading Cluster column. Results without a cluster name are
+// printed without it.
+func (v *View) PrintClusterIndexes(
+	results []*clusterResult[*indexListResult],
+	verbose bool,
+	output flags.OutputFlag,
 ) {
-	t := v.getIndexListWriter(verbose)
+	if output.IsStructured() {
+		items := []any{}
+
+		for _, result := range results {
+			if result.err != nil {
+				continue
+			}
+
+			for i, index := range result.value.indexes.GetIndices() {
+				if index.Id.Name == "" || index.Id.Namespace == "" {
+					continue
+				}
+
+				items = append(items, withCluster(result.cluster, writers.NewIndexJSON(index, result.value.statuses[i])))
+			}
+		}
 
-	for i, index := range indexList.Indices {
-		if index.Id.Name == "" || index.Id.Namespace == "" {
+		v.printStructuredList(output, items)
+
+		return
+	}
+
+	t := v.getIndexListWriter(verbose || output.IsWide())
+	format := output.RenderFormat()
+
+	for _, result := range results {
+		if result.err != nil {
 			continue
 		}
 
-		t.AppendIndexRow(index, indexStatusList[i], format)
+		if result.cluster != "" {
+			t.SetCluster(result.cluster)
+		}
+
+		for i, index := range result.value.indexes.GetIndices() {
+			if index.Id.Name == "" || index.Id.Namespace == "" {
+				continue
+			}
+
+			t.AppendIndexRow(index, result.value.statuses[i], format)
+		}
 	}
 
 	t.Render(format)
@@ -116,55 +307,157 @@ func (v *View) getUserListWriter() *writers.UserTableWriter {
 	return writers.NewUserTableWriter(v.out, v.logger)
 }
 
-func (v *View) PrintUsers(usersList *protos.ListUsersResponse, format int) {
+func (v *View) PrintUsers(usersList *protos.ListUsersResponse, output flags.OutputFlag) {
+	v.PrintClusterUsers([]*clusterResult[*protos.ListUsersResponse]{{value: usersList}}, output)
+}
+
+// PrintClusterUsers prints the users of multiple clusters in a single table
+// with a leading Cluster column. Results without a cluster name are printed
+// without it.
+func (v *View) PrintClusterUsers(results []*clusterResult[*protos.ListUsersResponse], output flags.OutputFlag) {
+	if output.IsStructured() {
+		items := []any{}
+
+		for _, result := range results {
+			if result.err != nil {
+				continue
+			}
+
+			for _, user := range result.value.GetUsers() {
+				items = append(items, withCluster(result.cluster, writers.ProtoJSON{Message: user}))
+			}
+		}
+
+		v.printStructuredList(output, items)
+
+		return
+	}
+
 	t := v.getUserListWriter()
 
-	for _, user := range usersList.GetUsers() {
-		t.AppendUserRow(user)
+	for _, result := range results {
+		if result.err != nil {
+			continue
+		}
+
+		if result.cluster != "" {
+			t.SetCluster(result.cluster)
+		}
+
+		for _, user := range result.value.GetUsers() {
+			t.AppendUserRow(user)
+		}
 	}
 
-	t.Render(format)
+	t.Render(output.RenderFormat())
 }
 
 func (v *View) getRoleListWriter() *writers.RoleTableWriter {
 	return writers.NewRoleTableWriter(v.out, v.logger)
 }
 
-func (v *View) PrintRoles(usersList *protos.ListRolesResponse, format int) {
+func (v *View) PrintRoles(usersList *protos.ListRolesResponse, output flags.OutputFlag) {
+	if output.IsStructured() {
+		items := make([]any, 0, len(usersList.GetRoles()))
+
+		for _, role := range usersList.GetRoles() {
+			items = append(items, writers.ProtoJSON{Message: role})
+		}
+
+		v.printStructuredList(output, items)
+
+		return
+	}
+
 	t := v.getRoleListWriter()
 
 	for _, role := range usersList.GetRoles() {
 		t.AppendRoleRow(role)
 	}
 
-	t.Render(format)
+	t.Render(output.RenderFormat())
 }
 
 func (v *View) getNodeInfoListWriter(isLB bool) *writers.NodeTableWriter {
 	return writers.NewNodeTableWriter(v.out, isLB, v.logger)
 }
 
-func (v *View) PrintNodeInfoList(nodeInfos []*writers.NodeInfo, isLB bool, format int) {
-	t := v.getNodeInfoListWriter(isLB)
+func (v *View) PrintNodeInfoList(nodeInfos []*writers.NodeInfo, isLB bool, output flags.OutputFlag) {
+	v.PrintClusterNodeInfoLists([]*clusterResult[[]*writers.NodeInfo]{{isLB: isLB, value: nodeInfos}}, output)
+}
+
+// PrintClusterNodeInfoLists prints the nodes of multiple clusters in a single
+// table with a leading Cluster column. Results without a cluster name are
+// printed without it.
+func (v *View) PrintClusterNodeInfoLists(results []*clusterResult[[]*writers.NodeInfo], output flags.OutputFlag) {
+	if output.IsStructured() {
+		items := []any{}
+
+		for _, result := range results {
+			if result.err != nil {
+				continue
+			}
+
+			for _, node := range result.value {
+				items = append(items, withCluster(result.cluster, node))
+			}
+		}
+
+		v.printStructuredList(output, items)
 
-	for _, node := range nodeInfos {
-		t.AppendNodeRow(node)
+		return
 	}
 
-	t.Render(format)
+	t := v.getNodeInfoListWriter(false)
+
+	for _, result := range results {
+		if result.err != nil {
+			continue
+		}
+
+		if result.cluster != "" {
+			t.SetCluster(result.cluster)
+		}
+
+		t.SetLoadBalancer(result.isLB)
+
+		for _, node := range result.value {
+			t.AppendNodeRow(node)
+		}
+	}
+
+	t.Render(output.RenderFormat())
 }
 
 func (v *View) getNeighborTableWriter() *writers.NeighborTableWriter {
 	return writers.NewNeighborTableWriter(v.out, v.logger)
 }
 
+// PrintQueryResults prints the results of a single query. Wide output
+// displays all record data without truncating or wrapping. exact adds the
+// exact distance of re-ranked results.
 func (v *View) PrintQueryResults(
-	neighbors []*avs.Neighbor,
-	format int,
+	neighbors []*writers.Neighbor,
+	exact bool,
+	output flags.OutputFlag,
 	maxDataKeys,
 	maxDataValueColWidth int,
 ) {
+	if output.IsStructured() {
+		v.printStructured(output, writers.NewQueryResult(nil, neighbors, nil))
+		return
+	}
+
+	if output.IsWide() {
+		maxDataKeys, maxDataValueColWidth = 0, 0
+	}
+
 	t := v.getNeighborTableWriter()
+	format := output.RenderFormat()
+
+	if exact {
+		t.WithExactDistance()
+	}
 
 	for _, n := range neighbors {
 		t.AppendNeighborRow(n, maxDataKeys, format, maxDataValueColWidth)
@@ -173,6 +466,260 @@ func (v *View) PrintQueryResults(
 	t.Render(format)
 }
 
+// PrintBatchQueryResults prints the results of one of many queries. Each
+// result is tagged with the query's ID.
+func (v *View) PrintBatchQueryResults(
+	queryID any,
+	neighbors []*writers.Neighbor,
+	exact bool,
+	output flags.OutputFlag,
+	maxDataKeys,
+	maxDataValueColWidth int,
+) {
+	if output.IsWide() {
+		maxDataKeys, maxDataValueColWidth = 0, 0
+	}
+
+	t := writers.NewQueryNeighborTableWriter(v.out, queryID, v.logger)
+	format := output.RenderFormat()
+
+	if exact {
+		t.WithExactDistance()
+	}
+
+	for _, n := range neighbors {
+		t.AppendNeighborRow(n, maxDataKeys, format, maxDataValueColWidth)
+	}
+
+	t.Render(format)
+}
+
+// PrintQueryResultsJSONL prints the results of a query as a single line of
+// JSON. queryID is omitted when nil.
+func (v *View) PrintQueryResultsJSONL(queryID any, neighbors []*writers.Neighbor, queryErr error) {
+	v.printStructured(flags.OutputJSONL, writers.NewQueryResult(queryID, neighbors, queryErr))
+}
+
+// PrintQueryResultList prints the results of many queries as a JSON array or
+// YAML sequence.
+func (v *View) PrintQueryResultList(results []*writers.QueryResult, output flags.OutputFlag) {
+	items := make([]any, len(results))
+	for i, r := range results {
+		items[i] = r
+	}
+
+	v.printStructuredList(output, items)
+}
+
+func (v *View) PrintRecallResults(results []*writers.RecallResult, k int, output flags.OutputFlag) {
+	if output.IsStructured() {
+		items := make([]any, len(results))
+		for i, r := range results {
+			items[i] = r
+		}
+
+		v.printStructuredList(output, items)
+
+		return
+	}
+
+	t := writers.NewRecallTableWriter(v.out, k, v.logger)
+
+	for _, r := range results {
+		t.AppendRecallRow(r)
+	}
+
+	t.Render(output.RenderFormat())
+}
+
+func (v *View) PrintBenchResult(result *writers.BenchResult, output flags.OutputFlag) {
+	if output.IsStructured() {
+		v.printStructured(output, result)
+		return
+	}
+
+	t := writers.NewBenchTableWriter(v.out, v.logger)
+	t.AppendBenchRow(result)
+	t.Render(output.RenderFormat())
+
+	if len(result.ErrorsByCode) == 0 {
+		return
+	}
+
+	et := writers.NewBenchErrorTableWriter(v.out, v.logger)
+	et.AppendErrorRows(result.ErrorsByCode)
+	et.Render(output.RenderFormat())
+}
+
+// PrintIndexStatus prints the status of a single index. Structured output is
+// the same as an item of the index list.
+func (v *View) PrintIndexStatus(
+	index *protos.IndexDefinition,
+	status *protos.IndexStatusResponse,
+	output flags.OutputFlag,
+) {
+	if output.IsStructured() {
+		v.printStructured(output, writers.NewIndexJSON(index, status))
+		return
+	}
+
+	t := writers.NewIndexStatusTableWriter(v.out, v.logger)
+	t.AppendIndexStatus(index, status)
+	t.Render(output.RenderFormat())
+}
+
+// PrintAlerts prints the --fail-if conditions which are true to stderr.
+func (v *View) PrintAlerts(alerts []*failIfAlert) {
+	for _, alert := range alerts {
+		// Written directly so the newline does not go to stdout with PrintErr
+		if _, err := fmt.Fprintln(v.err, v.redString("Alert: %s", alert)); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// PrintAlertsInline prints the --fail-if conditions which are true with the
+// command's output, so they are displayed on each refresh in watch mode.
+func (v *View) PrintAlertsInline(alerts []*failIfAlert) {
+	for _, alert := range alerts {
+		v.Print(v.redString("Alert: %s", alert))
+	}
+}
+
+// PrintDoctorReport prints the result of each cluster doctor check.
+// Structured output is the entire report.
+func (v *View) PrintDoctorReport(report *writers.DoctorReport, output flags.OutputFlag) {
+	if output.IsStructured() {
+		v.printStructured(output, report)
+		return
+	}
+
+	t := writers.NewDoctorTableWriter(v.out, v.logger)
+
+	for _, check := range report.Checks {
+		t.AppendCheckRow(check)
+	}
+
+	t.AppendSummary(report.Summary)
+	t.Render(output.RenderFormat())
+}
+
+func (v *View) PrintIndexTop(rows []*writers.IndexTopRow) {
+	t := writers.NewIndexTopTableWriter(v.out, v.logger)
+
+	for _, row := range rows {
+		t.AppendIndexTopRow(row)
+	}
+
+	t.Render()
+}
+
+func (v *View) getRecordTableWriter() *writers.RecordTableWriter {
+	return writers.NewRecordTableWriter(v.out, v.logger)
+}
+
+// PrintRecord prints a single record. Wide output displays all record data
+// without truncating or wrapping.
+func (v *View) PrintRecord(
+	namespace string,
+	set *string,
+	key any,
+	record *avs.Record,
+	output flags.OutputFlag,
+	maxDataKeys,
+	maxDataValueColWidth int,
+) {
+	if output.IsStructured() {
+		v.printStructured(output, writers.NewRecordJSON(namespace, set, key, record))
+		return
+	}
+
+	if output.IsWide() {
+		maxDataKeys, maxDataValueColWidth = 0, 0
+	}
+
+	t := v.getRecordTableWriter()
+	format := output.RenderFormat()
+
+	t.AppendRecordRow(namespace, set, key, record, maxDataKeys, format, maxDataValueColWidth)
+
+	t.Render(format)
+}
+
+// PrintApplyPlan prints the changes of an apply plan followed by a summary.
+func (v *View) PrintApplyPlan(plan *applyPlan) {
+	creates, updates, unchanged := 0, 0, 0
+
+	for _, change := range plan.changes {
+		switch change.action {
+		case applyActionCreate:
+			creates++
+
+			if change.user != nil {
+				v.Print(v.greenString("+ create %s %s with roles %s", change.resource, change.name, formatRoles(change.user.Roles)))
+			} else {
+				v.Print(v.greenString("+ create %s %s", change.resource, change.name))
+			}
+		case applyActionUpdate:
+			updates++
+
+			v.Print(v.yellowString("~ update %s %s", change.resource, change.name))
+		case applyActionNone:
+			unchanged++
+
+			if len(change.destructive) > 0 {
+				v.Printf("  %s %s is unchanged", change.resource, change.name)
+			}
+		}
+
+		for _, diff := range change.diffs {
+			v.Printf("    %s: %s -> %s", diff.field, diff.old, diff.new)
+		}
+
+		for _, diff := range change.destructive {
+			v.Print(v.redString(
+				"  ! %s: %s -> %s (requires dropping and recreating the %s, not applied)",
+				diff.field, diff.old, diff.new, change.resource,
+			))
+		}
+	}
+
+	v.Printf("Plan: %d to create, %d to update, %d unchanged.", creates, updates, unchanged)
+}
+
+// PrintIndexDiffs prints the indexes which differ between the left and right
+// sources.
+func (v *View) PrintIndexDiffs(diffs []*indexDiff, left, right string) {
+	for _, diff := range diffs {
+		switch {
+		case diff.onlyLeft:
+			v.Print(v.redString("- %s only in %s", diff.name, left))
+		case diff.onlyRight:
+			v.Print(v.greenString("+ %s only in %s", diff.name, right))
+		case len(diff.diffs) > 0:
+			v.Print(v.yellowString("~ %s (%s -> %s)", diff.name, left, right))
+
+			for _, d := range diff.diffs {
+				v.Printf("    %s: %s -> %s", d.field, d.old, d.new)
+			}
+		}
+	}
+}
+
+// withCluster adds the cluster an item came from to its structured output.
+// Items are unchanged if cluster is empty.
+func withCluster(cluster string, item any) any {
+	if cluster == "" {
+		return item
+	}
+
+	return writers.ClusterJSON{Cluster: cluster, Item: item}
+}
+
+func (v *View) greenString(f string, a ...any) string {
+	return tableColor.FgGreen.Sprint(fmt.Sprintf(f, a...))
+}
+
 func (v *View) redString(f string, a ...any) string {
 	return tableColor.FgRed.Sprint(fmt.Sprintf(f, a...))
 }
@@ -0,0 +1,137 @@
+//go:build unit
+
+package cmd
+
+import (
+	"asvec/cmd/flags"
+	"bytes"
+	"log/slog"
+	"testing"
+
+	"github.com/aerospike/avs-client-go/protos"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestViewPrintUsers(t *testing.T) {
+	users := &protos.ListUsersResponse{
+		Users: []*protos.User{
+			{Username: "admin", Roles: []string{"admin", "read-write"}},
+			{Username: "true", Roles: []string{"read"}},
+		},
+	}
+
+	testCases := []struct {
+		output   flags.OutputFlag
+		expected string
+	}{
+		{
+			output: flags.OutputJSON,
+			expected: `[
+  {
+    "username": "admin",
+    "roles": [
+      "admin",
+      "read-write"
+    ]
+  },
+  {
+    "username": "true",
+    "roles": [
+      "read"
+    ]
+  }
+]
+`,
+		},
+		{
+			output: flags.OutputJSONL,
+			expected: `{"username":"admin","roles":["admin","read-write"]}
+{"username":"true","roles":["read"]}
+`,
+		},
+		{
+			output: flags.OutputYAML,
+			expected: `- username: admin
+  roles:
+    - admin
+    - read-write
+- username: "true"
+  roles:
+    - read
+`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.output.String(), func(t *testing.T) {
+			out := &bytes.Buffer{}
+			v := NewView(out, out, slog.Default())
+
+			v.PrintUsers(users, tc.output)
+
+			assert.Equal(t, tc.expected, out.String())
+		})
+	}
+}
+
+func TestViewPrintStructuredEmptyList(t *testing.T) {
+	out := &bytes.Buffer{}
+	v := NewView(out, out, slog.Default())
+
+	v.PrintRoles(&protos.ListRolesResponse{}, flags.OutputJSON)
+	assert.Equal(t, "[]\n", out.String())
+
+	out.Reset()
+	v.PrintRoles(&protos.ListRolesResponse{}, flags.OutputYAML)
+	assert.Equal(t, "[]\n", out.String())
+
+	out.Reset()
+	v.PrintRoles(&protos.ListRolesResponse{}, flags.OutputJSONL)
+	assert.Equal(t, "", out.String())
+}
+
+func TestViewPrintTemplates(t *testing.T) {
+	indexes := &protos.IndexDefinitionList{
+		Indices: []*protos.IndexDefinition{
+			{Id: &protos.IndexId{Namespace: "test", Name: "a"}, Dimensions: 3},
+			{Id: &protos.IndexId{Namespace: "test", Name: "b"}, Dimensions: 128},
+		},
+	}
+	statuses := []*protos.IndexStatusResponse{
+		{UnmergedRecordCount: 10},
+		{UnmergedRecordCount: 2000000},
+	}
+
+	testCases := []struct {
+		output   string
+		expected string
+	}{
+		{
+			output:   `go-template={{range .}}{{.definition.id.name}} {{.status.unmergedRecordCount}}{{"\n"}}{{end}}`,
+			expected: "a 10\nb 2000000\n",
+		},
+		{
+			output:   `jsonpath={range [*]}{.definition.id.name}{" "}{.definition.dimensions}{"\n"}{end}`,
+			expected: "a 3\nb 128\n",
+		},
+		{
+			output:   `jsonpath={[?(@.status.unmergedRecordCount > 1000)].definition.id.name}`,
+			expected: "b",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.output, func(t *testing.T) {
+			var output flags.OutputFlag
+
+			assert.NoError(t, output.Set(tc.output))
+
+			out := &bytes.Buffer{}
+			v := NewView(out, out, slog.Default())
+
+			v.PrintIndexes(indexes, statuses, false, output)
+
+			assert.Equal(t, tc.expected, out.String())
+		})
+	}
+}
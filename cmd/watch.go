@@ -15,12 +15,16 @@ import (
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
+	"golang.org/x/term"
 )
 
 // WatchFlags contains the flags for watch functionality
 type WatchFlags struct {
 	Watch         bool
 	WatchInterval int
+	// changes describes the command's structured output. Commands with
+	// structured output log changes as JSONL when stdout is not a terminal.
+	changes *watchChanges
 }
 
 // NewWatchFlags creates a new WatchFlags instance with default values
@@ -65,6 +69,18 @@ func RunWithWatch(cmd *cobra.Command, args []string, watchFlags *WatchFlags,
 		slog.Int("interval", watchFlags.WatchInterval),
 		slog.String("command", cmd.CommandPath()))
 
+	// Share one client between runs instead of reconnecting on every refresh
+	clients = newClientCache()
+
+	// Display alerts with the output instead of on stderr between refreshes
+	alertSink = view.PrintAlertsInline
+
+	defer func() {
+		clients.Close()
+		clients = nil
+		alertSink = nil
+	}()
+
 	// Set up signal handling for clean exit
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -86,30 +102,40 @@ func RunWithWatch(cmd *cobra.Command, args []string, watchFlags *WatchFlags,
 	ticker := time.NewTicker(time.Duration(watchFlags.WatchInterval) * time.Second)
 	defer ticker.Stop()
 
+	// Use the full-screen TUI when it is possible to read keys from the terminal
+	if isWatchTUISupported() {
+		return runWatchTUI(
+			ctx, cmd, args, strings.Join(cmdArgs, " "),
+			time.Duration(watchFlags.WatchInterval)*time.Second, runFunc,
+		)
+	}
+
+	// Log changes to the structured output when the output is not displayed
+	if watchFlags.changes != nil && !term.IsTerminal(int(os.Stdout.Fd())) {
+		return runWatchChangeLog(
+			ctx, cmd, args, strings.Join(cmdArgs, " "),
+			time.Duration(watchFlags.WatchInterval)*time.Second, watchFlags.changes, runFunc,
+		)
+	}
+
 	// Create a line counting writer to track output lines
 	lineCounter := &LineCountingWriter{Writer: view.out}
 
-	// Save the original stdout and stderr
-	originalOut := view.out
-	originalErr := view.err
-
 	// Count how many header lines we're printing
 	headerLineCount := 0
 
-	// Helper function to print the header with updated timestamp
+	// Helper function to print the header with updated timestamp and connection state
 	printHeader := func() {
 		timestamp := time.Now().Format("2006-01-02 15:04:05")
 		headerText := fmt.Sprintf("Watch mode: refresh every %d seconds (press Ctrl+C to exit) - Last update: %s",
 			watchFlags.WatchInterval, timestamp)
 		view.Print(headerText)
+		view.Printf("Connection: %s", clients.state())
 		view.Printf("> %s", strings.Join(cmdArgs, " "))
 		view.Print("") // Add a blank line after the command for better readability
 	}
 
-	// Print the header for the first time
-	printHeader()
-
-	headerLineCount = 3 // Three lines: header text, command, and blank line
+	headerLineCount = 4 // Four lines: header text, connection, command, and blank line
 
 	// Log watch mode information
 	logger.Info("running command in watch mode",
@@ -117,27 +143,25 @@ func RunWithWatch(cmd *cobra.Command, args []string, watchFlags *WatchFlags,
 		slog.String("command", strings.Join(cmdArgs, " ")),
 	)
 
-	// Helper function to run the command and handle output
-	runCommandAndCaptureOutput := func() (int, error) {
-		// Set the line counter as the output writer
-		lineCounter.LineCount = 0
-		view.out = lineCounter
+	// Helper function to run the command and handle output. The output is
+	// buffered so that the header reflects the connection state after the run.
+	runCommandAndCaptureOutput := func(linesToClear int) (int, error) {
+		output, err := runWatchedCommand(cmd, args, runFunc, view.err)
 
-		// Run the command
-		err := runFunc(cmd, args)
+		// Move cursor up to the beginning of the previous output (including the header)
+		clearLines(view.out, linesToClear)
 
-		// Store the number of lines
-		lineCount := lineCounter.LineCount
+		printHeader()
 
-		// Restore original stdout/stderr
-		view.out = originalOut
-		view.err = originalErr
+		// Write the output through the line counter to store the number of lines
+		lineCounter.LineCount = 0
+		_, _ = lineCounter.Write(output.Bytes())
 
-		return lineCount, err
+		return lineCounter.LineCount, err
 	}
 
 	// Run the command for the first time
-	previousLineCount, err := runCommandAndCaptureOutput()
+	previousLineCount, err := runCommandAndCaptureOutput(0)
 	if err != nil {
 		return err
 	}
@@ -149,26 +173,16 @@ func RunWithWatch(cmd *cobra.Command, args []string, watchFlags *WatchFlags,
 			logger.Debug("Watch mode terminated by user")
 			return nil
 		case <-ticker.C:
-			// Move cursor up to the beginning of the previous output (including the header)
-			totalLinesToClear := previousLineCount + headerLineCount
-			for i := 0; i < totalLinesToClear; i++ {
-				fmt.Fprint(view.out, "\033[1A") // Move cursor up one line
-				fmt.Fprint(view.out, "\033[2K") // Clear the entire line
-			}
-
 			// Log refresh information
 			logger.Debug("Refreshing command output",
 				slog.Int("refresh_interval_seconds", watchFlags.WatchInterval),
 				slog.String("command", strings.Join(cmdArgs, " ")),
 			)
 
-			// Print the header with updated timestamp
-			printHeader()
-
 			// Run the command again
 			var err error
 
-			previousLineCount, err = runCommandAndCaptureOutput()
+			previousLineCount, err = runCommandAndCaptureOutput(previousLineCount + headerLineCount)
 			if err != nil {
 				logger.Error("Error executing command in watch mode", slog.Any("error", err))
 				return err
@@ -177,6 +191,48 @@ func RunWithWatch(cmd *cobra.Command, args []string, watchFlags *WatchFlags,
 	}
 }
 
+// runWatchedCommand runs the watched command, returning its output. Errors
+// written by the command go to errWriter. An error caused by losing the
+// connection to the cluster is not returned, so that the command is rerun
+// once the client reconnects.
+func runWatchedCommand(
+	cmd *cobra.Command,
+	args []string,
+	runFunc func(cmd *cobra.Command, args []string) error,
+	errWriter io.Writer,
+) (*bytes.Buffer, error) {
+	output := &bytes.Buffer{}
+
+	// Save the original stdout and stderr
+	originalOut := view.out
+	originalErr := view.err
+
+	view.out = output
+	view.err = errWriter
+
+	err := runFunc(cmd, args)
+
+	// Restore original stdout/stderr
+	view.out = originalOut
+	view.err = originalErr
+
+	if err != nil && clients.lostConnection(rootFlags.clientFlags.Timeout, err) {
+		logger.Warn("watched command failed while disconnected", slog.Any("error", err))
+		return output, nil
+	}
+
+	return output, err
+}
+
+// clearLines moves the cursor up n lines, clearing each one, so that the
+// next output overwrites them.
+func clearLines(w io.Writer, n int) {
+	for i := 0; i < n; i++ {
+		fmt.Fprint(w, "\033[1A") // Move cursor up one line
+		fmt.Fprint(w, "\033[2K") // Clear the entire line
+	}
+}
+
 // removeWatchFlags removes watch-related flags from the command arguments
 func removeWatchFlags(args []string) []string {
 	result := make([]string, 0, len(args))
@@ -213,9 +269,11 @@ func removeWatchFlags(args []string) []string {
 
 // wrapCommandWithWatch adds watch functionality to a command
 // This function should be called in the init() function of commands that want to support watch mode
-func wrapCommandWithWatch(cmd *cobra.Command) {
+// changes describes the command's structured output, or is nil if it has none
+func wrapCommandWithWatch(cmd *cobra.Command, changes *watchChanges) {
 	// Add watch flags to the command
 	watchFlags := NewWatchFlags()
+	watchFlags.changes = changes
 	AddWatchFlagSet(cmd.Flags(), watchFlags)
 
 	// Save the original RunE function
@@ -0,0 +1,377 @@
+package cmd
+
+import (
+	"asvec/cmd/flags"
+	"asvec/cmd/writers"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// Events in the watch change log.
+const (
+	watchEventSnapshot     = "snapshot"
+	watchEventAdded        = "added"
+	watchEventRemoved      = "removed"
+	watchEventChanged      = "changed"
+	watchEventDisconnected = "disconnected"
+	watchEventReconnected  = "reconnected"
+	watchEventAlert        = "alert"
+	watchEventResolved     = "resolved"
+)
+
+// watchChanges describes the structured output of a watched command so that
+// changes to each item can be logged when stdout is not a terminal.
+type watchChanges struct {
+	// items is the field holding the list of items when the output is a
+	// single object. If empty, each line of JSONL output is an item.
+	items string
+	// keys are the dot separated paths of the fields which identify an item.
+	keys []string
+}
+
+// watchChangeRecord is a line of the watch change log.
+//
+//nolint:govet // Padding not a concern for a CLI
+type watchChangeRecord struct {
+	Time      string              `json:"time"`
+	Command   string              `json:"command"`
+	Event     string              `json:"event"`
+	Key       map[string]any      `json:"key,omitempty"`
+	Changes   []watchChangedField `json:"changes,omitempty"`
+	Item      any                 `json:"item,omitempty"`
+	Condition string              `json:"condition,omitempty"`
+	Value     any                 `json:"value,omitempty"`
+	Error     string              `json:"error,omitempty"`
+}
+
+type watchChangedField struct {
+	Field string `json:"field"`
+	Old   any    `json:"old"`
+	New   any    `json:"new"`
+}
+
+// watchItem is an item of a watched command's output and its identity.
+type watchItem struct {
+	id    string
+	key   map[string]any
+	value any
+}
+
+// parseWatchItems returns the items in the JSONL output of a watched command.
+func (c *watchChanges) parseWatchItems(output []byte) ([]*watchItem, error) {
+	decoder := json.NewDecoder(bytes.NewReader(output))
+	decoder.UseNumber()
+
+	values := []any{}
+
+	for {
+		var v any
+
+		err := decoder.Decode(&v)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		if c.items == "" {
+			values = append(values, v)
+			continue
+		}
+
+		list, ok := jsonField(v, c.items).([]any)
+		if !ok {
+			return nil, fmt.Errorf("output does not contain a list of %s", c.items)
+		}
+
+		values = append(values, list...)
+	}
+
+	items := make([]*watchItem, 0, len(values))
+
+	for _, v := range values {
+		key := make(map[string]any, len(c.keys))
+		for _, k := range c.keys {
+			key[k] = jsonField(v, k)
+		}
+
+		// Items merged from multiple clusters are also keyed by their cluster.
+		if cluster := jsonField(v, writers.ClusterKey); cluster != nil {
+			key[writers.ClusterKey] = cluster
+		}
+
+		// Maps are marshaled with sorted keys so the id is stable
+		id, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+
+		items = append(items, &watchItem{id: string(id), key: key, value: v})
+	}
+
+	return items, nil
+}
+
+// jsonField returns the value at a dot separated path in a decoded JSON
+// value, or nil if it does not exist.
+func jsonField(v any, path string) any {
+	for _, name := range strings.Split(path, ".") {
+		m, ok := v.(map[string]any)
+		if !ok {
+			return nil
+		}
+
+		v = m[name]
+	}
+
+	return v
+}
+
+// diffWatchItems returns the change log records for going from prev to cur.
+// Removed items are reported after added and changed items.
+func diffWatchItems(prev, cur []*watchItem) []*watchChangeRecord {
+	prevByID := make(map[string]*watchItem, len(prev))
+	for _, item := range prev {
+		prevByID[item.id] = item
+	}
+
+	records := []*watchChangeRecord{}
+	seen := make(map[string]bool, len(cur))
+
+	for _, item := range cur {
+		seen[item.id] = true
+
+		old, ok := prevByID[item.id]
+		if !ok {
+			records = append(records, &watchChangeRecord{Event: watchEventAdded, Key: item.key, Item: item.value})
+			continue
+		}
+
+		changes := diffJSONValues("", old.value, item.value, nil)
+		if len(changes) > 0 {
+			records = append(records, &watchChangeRecord{Event: watchEventChanged, Key: item.key, Changes: changes})
+		}
+	}
+
+	for _, item := range prev {
+		if !seen[item.id] {
+			records = append(records, &watchChangeRecord{Event: watchEventRemoved, Key: item.key, Item: item.value})
+		}
+	}
+
+	return records
+}
+
+// diffJSONValues appends the leaf fields which differ between two decoded
+// JSON values to changes. Objects are compared field by field in sorted order
+// and lists element by element.
+func diffJSONValues(path string, old, cur any, changes []watchChangedField) []watchChangedField {
+	join := func(name string) string {
+		if path == "" {
+			return name
+		}
+
+		return path + "." + name
+	}
+
+	switch o := old.(type) {
+	case map[string]any:
+		c, ok := cur.(map[string]any)
+		if !ok {
+			break
+		}
+
+		names := make([]string, 0, len(o)+len(c))
+		for name := range o {
+			names = append(names, name)
+		}
+
+		for name := range c {
+			if _, ok := o[name]; !ok {
+				names = append(names, name)
+			}
+		}
+
+		slices.Sort(names)
+
+		for _, name := range names {
+			changes = diffJSONValues(join(name), o[name], c[name], changes)
+		}
+
+		return changes
+	case []any:
+		c, ok := cur.([]any)
+		if !ok {
+			break
+		}
+
+		for i := 0; i < max(len(o), len(c)); i++ {
+			var ov, cv any
+			if i < len(o) {
+				ov = o[i]
+			}
+
+			if i < len(c) {
+				cv = c[i]
+			}
+
+			changes = diffJSONValues(join(fmt.Sprint(i)), ov, cv, changes)
+		}
+
+		return changes
+	}
+
+	if !reflect.DeepEqual(old, cur) {
+		changes = append(changes, watchChangedField{Field: path, Old: old, New: cur})
+	}
+
+	return changes
+}
+
+// diffWatchAlerts returns an alert record for each --fail-if alert in cur which
+// is not in prev, and a resolved record for each alert in prev which is not in
+// cur.
+func diffWatchAlerts(prev, cur []*failIfAlert) []*watchChangeRecord {
+	records := []*watchChangeRecord{}
+	prevIDs := make(map[string]bool, len(prev))
+	curIDs := make(map[string]bool, len(cur))
+
+	for _, alert := range prev {
+		prevIDs[alert.id()] = true
+	}
+
+	for _, alert := range cur {
+		curIDs[alert.id()] = true
+
+		if !prevIDs[alert.id()] {
+			records = append(records, &watchChangeRecord{
+				Event:     watchEventAlert,
+				Key:       alert.Key,
+				Condition: alert.Condition,
+				Value:     alert.Value,
+			})
+		}
+	}
+
+	for _, alert := range prev {
+		if !curIDs[alert.id()] {
+			records = append(records, &watchChangeRecord{
+				Event:     watchEventResolved,
+				Key:       alert.Key,
+				Condition: alert.Condition,
+			})
+		}
+	}
+
+	return records
+}
+
+// runWatchChangeLog runs the watched command at the watch interval and prints
+// a line of JSON for each item which is added, removed, or changed. The first
+// run prints a snapshot of every item. A line is also printed when a --fail-if
+// condition becomes true or false for an item.
+func runWatchChangeLog(
+	ctx context.Context,
+	cmd *cobra.Command,
+	args []string,
+	command string,
+	interval time.Duration,
+	changes *watchChanges,
+	runFunc func(cmd *cobra.Command, args []string) error,
+) error {
+	// The change log is computed from the command's JSONL output
+	if err := cmd.Flags().Set(flags.Output, string(flags.OutputJSONL)); err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(view.out)
+
+	emit := func(records ...*watchChangeRecord) {
+		now := time.Now().UTC().Format(time.RFC3339Nano)
+
+		for _, r := range records {
+			r.Time = now
+			r.Command = command
+
+			if err := encoder.Encode(r); err != nil {
+				logger.Error("failed to write watch change log", slog.Any("error", err))
+			}
+		}
+	}
+
+	var (
+		prev         []*watchItem
+		prevAlerts   []*failIfAlert
+		alerts       []*failIfAlert
+		disconnected bool
+	)
+
+	alertSink = func(a []*failIfAlert) {
+		alerts = append(alerts, a...)
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for first := true; ; first = false {
+		alerts = nil
+
+		output, err := runWatchedCommand(cmd, args, runFunc, view.err)
+		if err != nil {
+			logger.Error("Error executing command in watch mode", slog.Any("error", err))
+			return err
+		}
+
+		// Items are not reported as removed while the cluster is unreachable
+		if clients.lost() {
+			if !disconnected {
+				emit(&watchChangeRecord{Event: watchEventDisconnected, Error: clients.lastError()})
+				disconnected = true
+			}
+		} else {
+			items, err := changes.parseWatchItems(output.Bytes())
+			if err != nil {
+				logger.Error("failed to parse watched command output", slog.Any("error", err))
+				return err
+			}
+
+			if disconnected {
+				emit(&watchChangeRecord{Event: watchEventReconnected})
+				disconnected = false
+			}
+
+			if first {
+				for _, item := range items {
+					emit(&watchChangeRecord{Event: watchEventSnapshot, Key: item.key, Item: item.value})
+				}
+			} else {
+				emit(diffWatchItems(prev, items)...)
+			}
+
+			emit(diffWatchAlerts(prevAlerts, alerts)...)
+
+			prev = items
+			prevAlerts = alerts
+		}
+
+		select {
+		case <-ctx.Done():
+			logger.Debug("Watch mode terminated by user")
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
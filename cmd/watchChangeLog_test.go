@@ -0,0 +1,153 @@
+//go:build unit
+
+package cmd
+
+import (
+	"asvec/cmd/flags"
+	"bytes"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseWatchItems(t *testing.T) {
+	changes := &watchChanges{keys: []string{"id.namespace", "id.name"}}
+
+	items, err := changes.parseWatchItems([]byte(`{"id":{"namespace":"test","name":"a"},"status":"READY"}
+{"id":{"namespace":"test","name":"b"},"status":"NOT_READY"}
+`))
+	require.NoError(t, err)
+	require.Len(t, items, 2)
+	assert.Equal(t, map[string]any{"id.namespace": "test", "id.name": "b"}, items[1].key)
+	assert.Equal(t, `{"id.name":"b","id.namespace":"test"}`, items[1].id)
+
+	neighbors := &watchChanges{items: "neighbors", keys: []string{"key"}}
+
+	items, err = neighbors.parseWatchItems([]byte(`{"neighbors":[{"key":1},{"key":2}]}`))
+	require.NoError(t, err)
+	assert.Len(t, items, 2)
+
+	_, err = neighbors.parseWatchItems([]byte(`{"error":"failed"}`))
+	assert.Error(t, err)
+}
+
+func TestDiffWatchItems(t *testing.T) {
+	changes := &watchChanges{keys: []string{"nodeId"}}
+
+	prev, err := changes.parseWatchItems([]byte(`{"nodeId":1,"state":{"healthy":true},"peers":[2,3]}
+{"nodeId":2,"state":{"healthy":true},"peers":[1,3]}
+{"nodeId":3,"state":{"healthy":true},"peers":[1,2]}
+`))
+	require.NoError(t, err)
+
+	cur, err := changes.parseWatchItems([]byte(`{"nodeId":1,"state":{"healthy":true},"peers":[2]}
+{"nodeId":2,"state":{"healthy":true},"peers":[1]}
+{"nodeId":4,"state":{"healthy":false}}
+`))
+	require.NoError(t, err)
+
+	records := diffWatchItems(prev, cur)
+	require.Len(t, records, 4)
+
+	assert.Equal(t, watchEventChanged, records[0].Event)
+	assert.Equal(t, []watchChangedField{{Field: "peers.1", Old: json.Number("3"), New: nil}}, records[0].Changes)
+	assert.Equal(t, watchEventChanged, records[1].Event)
+	assert.Equal(t, watchEventAdded, records[2].Event)
+	assert.Equal(t, map[string]any{"nodeId": json.Number("4")}, records[2].Key)
+	assert.Equal(t, watchEventRemoved, records[3].Event)
+	assert.Equal(t, map[string]any{"nodeId": json.Number("3")}, records[3].Key)
+
+	assert.Empty(t, diffWatchItems(cur, cur))
+}
+
+func TestRunWithWatchChangeLog(t *testing.T) {
+	var output flags.OutputFlag
+
+	testCmd := &cobra.Command{Use: "test"}
+	testCmd.Flags().Var(&output, flags.Output, "")
+
+	states := []string{
+		`{"name":"a","status":"NOT_READY"}`,
+		`{"name":"a","status":"READY"}`,
+		`{"name":"a","status":"READY"}`,
+	}
+	runCount := 0
+
+	runFunc := func(_ *cobra.Command, _ []string) error {
+		assert.Equal(t, flags.OutputJSONL, output)
+
+		view.Print(states[min(runCount, len(states)-1)])
+
+		runCount++
+		if runCount == len(states) {
+			go func() {
+				time.Sleep(100 * time.Millisecond)
+
+				p, _ := os.FindProcess(os.Getpid())
+				_ = p.Signal(os.Interrupt)
+			}()
+		}
+
+		return nil
+	}
+
+	outBuf := &bytes.Buffer{}
+	originalOut := view.out
+	view.out = outBuf
+
+	defer func() {
+		view.out = originalOut
+	}()
+
+	watchFlags := &WatchFlags{
+		Watch:         true,
+		WatchInterval: 1,
+		changes:       &watchChanges{keys: []string{"name"}},
+	}
+
+	err := RunWithWatch(testCmd, nil, watchFlags, runFunc)
+	require.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(outBuf.String()), "\n")
+	require.Len(t, lines, 2, "unchanged results are not logged")
+
+	records := make([]map[string]any, len(lines))
+	for i, line := range lines {
+		require.NoError(t, json.Unmarshal([]byte(line), &records[i]))
+	}
+
+	assert.Equal(t, watchEventSnapshot, records[0]["event"])
+	assert.Equal(t, watchEventChanged, records[1]["event"])
+	assert.Equal(t, map[string]any{"name": "a"}, records[1]["key"])
+	assert.Equal(
+		t,
+		[]any{map[string]any{"field": "status", "old": "NOT_READY", "new": "READY"}},
+		records[1]["changes"],
+	)
+	assert.NotEmpty(t, records[1]["time"])
+}
+
+func TestDiffWatchAlerts(t *testing.T) {
+	a := &failIfAlert{Key: map[string]any{"name": "a"}, Condition: "unmerged>10", Field: "unmerged", Value: 20}
+	b := &failIfAlert{Key: map[string]any{"name": "b"}, Condition: "unmerged>10", Field: "unmerged", Value: 30}
+	bChanged := &failIfAlert{Key: map[string]any{"name": "b"}, Condition: "unmerged>10", Field: "unmerged", Value: 40}
+
+	records := diffWatchAlerts(nil, []*failIfAlert{a})
+	require.Len(t, records, 1)
+	assert.Equal(t, watchEventAlert, records[0].Event)
+	assert.Equal(t, "unmerged>10", records[0].Condition)
+	assert.Equal(t, 20, records[0].Value)
+
+	assert.Empty(t, diffWatchAlerts([]*failIfAlert{a, b}, []*failIfAlert{a, bChanged}), "alerts are only logged when they trip")
+
+	records = diffWatchAlerts([]*failIfAlert{a, b}, []*failIfAlert{b})
+	require.Len(t, records, 1)
+	assert.Equal(t, watchEventResolved, records[0].Event)
+	assert.Equal(t, map[string]any{"name": "a"}, records[0].Key)
+}
@@ -0,0 +1,483 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/jedib0t/go-pretty/v6/text"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+const (
+	// How often the terminal size is checked for changes
+	watchTUIResizeInterval = 250 * time.Millisecond
+
+	watchTUIEnterScreen = "\033[?1049h\033[?25l" // Alternate screen, hide cursor
+	watchTUIExitScreen  = "\033[?25h\033[?1049l" // Show cursor, main screen
+	watchTUIHome        = "\033[H"
+	watchTUIClearLine   = "\033[K"
+	watchTUIClearBelow  = "\033[J"
+	watchTUIHighlight   = "\033[7m" // Reverse video
+	watchTUIReset       = "\033[0m"
+
+	watchTUIHelp = "q quit  space pause  +/- interval  r refresh  ↑↓ PgUp/PgDn scroll  ←→ pan"
+)
+
+// Keys recognized by the watch TUI. Printable keys are their own character.
+const (
+	keyUp       = "up"
+	keyDown     = "down"
+	keyLeft     = "left"
+	keyRight    = "right"
+	keyPageUp   = "pgup"
+	keyPageDown = "pgdn"
+	keyHome     = "home"
+	keyEnd      = "end"
+	keyCtrlC    = "ctrl+c"
+)
+
+var watchTUIEscapeKeys = map[string]string{
+	"\x1b[A":  keyUp,
+	"\x1b[B":  keyDown,
+	"\x1b[C":  keyRight,
+	"\x1b[D":  keyLeft,
+	"\x1b[5~": keyPageUp,
+	"\x1b[6~": keyPageDown,
+	"\x1b[H":  keyHome,
+	"\x1b[1~": keyHome,
+	"\x1b[F":  keyEnd,
+	"\x1b[4~": keyEnd,
+}
+
+// watchTUI renders the output of a watched command in the terminal's
+// alternate screen. Output longer or wider than the terminal is scrolled
+// rather than wrapped, and cells which changed since the previous refresh are
+// highlighted.
+//
+//nolint:govet // Padding not a concern for a CLI
+type watchTUI struct {
+	command    string
+	interval   time.Duration
+	paused     bool
+	updated    time.Time
+	connection string
+	lines      []string
+	changed    [][]bool
+	scrollX    int
+	scrollY    int
+	width      int
+	height     int
+}
+
+func newWatchTUI(command string, interval time.Duration) *watchTUI {
+	return &watchTUI{command: command, interval: interval}
+}
+
+// update replaces the displayed output, marking the characters of each cell
+// which differ from the previous output.
+func (t *watchTUI) update(output string) {
+	lines := strings.Split(strings.TrimRight(text.StripEscape(output), "\n"), "\n")
+
+	if t.lines != nil {
+		t.changed = diffWatchCells(t.lines, lines)
+	}
+
+	t.lines = lines
+	t.updated = time.Now()
+}
+
+// diffWatchCells returns, for each rune of each line in cur, whether it is
+// part of a table cell which differs from the same cell in prev. Cells are
+// separated by table borders. New lines are entirely changed.
+func diffWatchCells(prev, cur []string) [][]bool {
+	changed := make([][]bool, len(cur))
+
+	for i, line := range cur {
+		runes := []rune(line)
+		changed[i] = make([]bool, len(runes))
+
+		if i >= len(prev) {
+			for j := range changed[i] {
+				changed[i][j] = true
+			}
+
+			continue
+		}
+
+		prevCells := splitWatchCells([]rune(prev[i]))
+
+		for c, cell := range splitWatchCells(runes) {
+			if c < len(prevCells) && string(prevCells[c].text) == string(cell.text) {
+				continue
+			}
+
+			for j := cell.start; j < cell.start+len(cell.text); j++ {
+				changed[i][j] = true
+			}
+		}
+	}
+
+	return changed
+}
+
+type watchCell struct {
+	start int
+	text  []rune
+}
+
+// splitWatchCells splits a line of a table into the cells between borders.
+func splitWatchCells(line []rune) []watchCell {
+	cells := []watchCell{}
+	start := 0
+
+	for i := 0; i <= len(line); i++ {
+		if i == len(line) || line[i] == '│' || line[i] == '|' {
+			cells = append(cells, watchCell{start: start, text: line[start:i]})
+			start = i + 1
+		}
+	}
+
+	return cells
+}
+
+func (t *watchTUI) bodyHeight() int {
+	// The header, connection, and help lines are always displayed
+	return max(1, t.height-3)
+}
+
+// handleKey applies key and reports whether the TUI should quit, refresh
+// immediately, or restart its timer because the interval changed.
+func (t *watchTUI) handleKey(key string) (quit, refresh, intervalChanged bool) {
+	page := t.bodyHeight()
+
+	switch key {
+	case "q", keyCtrlC:
+		return true, false, false
+	case " ", "p":
+		t.paused = !t.paused
+	case "r":
+		return false, true, false
+	case "+":
+		t.interval += time.Second
+		return false, false, true
+	case "-":
+		if t.interval > time.Second {
+			t.interval -= time.Second
+			return false, false, true
+		}
+	case keyUp, "k":
+		t.scrollY--
+	case keyDown, "j":
+		t.scrollY++
+	case keyPageUp:
+		t.scrollY -= page
+	case keyPageDown:
+		t.scrollY += page
+	case keyHome, "g":
+		t.scrollY = 0
+	case keyEnd, "G":
+		t.scrollY = len(t.lines)
+	case keyLeft, "h":
+		t.scrollX -= max(1, t.width/4)
+	case keyRight, "l":
+		t.scrollX += max(1, t.width/4)
+	}
+
+	t.clampScroll()
+
+	return false, false, false
+}
+
+func (t *watchTUI) clampScroll() {
+	t.scrollY = max(0, min(t.scrollY, len(t.lines)-t.bodyHeight()))
+
+	widest := 0
+	for _, line := range t.lines {
+		widest = max(widest, text.StringWidth(line))
+	}
+
+	t.scrollX = max(0, min(t.scrollX, widest-t.width))
+}
+
+// render returns a frame which redraws the whole screen.
+func (t *watchTUI) render() string {
+	t.clampScroll()
+
+	state := ""
+	if t.paused {
+		state = "[PAUSED] "
+	}
+
+	frame := []string{
+		fmt.Sprintf(
+			"%sEvery %s - Last update: %s - %s",
+			state, t.interval, t.updated.Format("2006-01-02 15:04:05"), t.command,
+		),
+		fmt.Sprintf("Connection: %s", t.connection),
+	}
+
+	body := t.bodyHeight()
+	end := min(len(t.lines), t.scrollY+body)
+
+	for i := t.scrollY; i < end; i++ {
+		var changed []bool
+		if i < len(t.changed) {
+			changed = t.changed[i]
+		}
+
+		frame = append(frame, cropWatchLine(t.lines[i], changed, t.scrollX, t.width))
+	}
+
+	for i := end - t.scrollY; i < body; i++ {
+		frame = append(frame, "")
+	}
+
+	position := fmt.Sprintf("lines %d-%d of %d", min(t.scrollY+1, end), end, len(t.lines))
+	frame = append(frame, fmt.Sprintf("%s  %s", watchTUIHelp, position))
+
+	for i := range frame {
+		if i < 2 || i == len(frame)-1 {
+			frame[i] = cropWatchLine(frame[i], nil, 0, t.width)
+		}
+	}
+
+	// The terminal is in raw mode, so lines need a carriage return
+	return watchTUIHome + strings.Join(frame, watchTUIClearLine+"\r\n") + watchTUIClearLine + watchTUIClearBelow
+}
+
+// cropWatchLine returns the columns of line from offset to offset+width,
+// highlighting the runes marked as changed.
+func cropWatchLine(line string, changed []bool, offset, width int) string {
+	var (
+		sb          strings.Builder
+		col         int
+		highlighted bool
+	)
+
+	for i, r := range []rune(line) {
+		w := text.RuneWidth(r)
+		if col < offset {
+			col += w
+			continue
+		}
+
+		if col+w > offset+width {
+			break
+		}
+
+		highlight := i < len(changed) && changed[i] && r != ' '
+		if highlight != highlighted {
+			if highlight {
+				sb.WriteString(watchTUIHighlight)
+			} else {
+				sb.WriteString(watchTUIReset)
+			}
+
+			highlighted = highlight
+		}
+
+		sb.WriteRune(r)
+
+		col += w
+	}
+
+	if highlighted {
+		sb.WriteString(watchTUIReset)
+	}
+
+	return sb.String()
+}
+
+// parseWatchKeys splits the bytes read from the terminal into keys.
+func parseWatchKeys(b []byte) []string {
+	keys := []string{}
+
+	for len(b) > 0 {
+		if b[0] == 0x1b {
+			matched := false
+
+			for seq, key := range watchTUIEscapeKeys {
+				if strings.HasPrefix(string(b), seq) {
+					keys = append(keys, key)
+					b = b[len(seq):]
+					matched = true
+
+					break
+				}
+			}
+
+			if !matched {
+				// Ignore unrecognized escape sequences
+				b = b[1:]
+				for len(b) > 0 && (b[0] == '[' || b[0] == ';' || (b[0] >= '0' && b[0] <= '9')) {
+					b = b[1:]
+				}
+
+				if len(b) > 0 {
+					b = b[1:]
+				}
+			}
+
+			continue
+		}
+
+		if b[0] == 0x03 {
+			keys = append(keys, keyCtrlC)
+		} else {
+			keys = append(keys, string(b[0]))
+		}
+
+		b = b[1:]
+	}
+
+	return keys
+}
+
+func readWatchKeys(r io.Reader, keys chan<- string) {
+	buf := make([]byte, 64)
+
+	for {
+		n, err := r.Read(buf)
+		if err != nil {
+			close(keys)
+			return
+		}
+
+		for _, key := range parseWatchKeys(buf[:n]) {
+			keys <- key
+		}
+	}
+}
+
+// isWatchTUISupported reports whether both stdin and stdout are terminals,
+// which is needed to read keys and draw the TUI.
+func isWatchTUISupported() bool {
+	return term.IsTerminal(int(os.Stdin.Fd())) && term.IsTerminal(int(os.Stdout.Fd()))
+}
+
+// runWatchTUI runs the watched command at the watch interval and displays its
+// output in the alternate screen until the user quits or ctx is done.
+func runWatchTUI(
+	ctx context.Context,
+	cmd *cobra.Command,
+	args []string,
+	command string,
+	interval time.Duration,
+	runFunc func(cmd *cobra.Command, args []string) error,
+) error {
+	stdin := int(os.Stdin.Fd())
+	stdout := view.out
+
+	tui := newWatchTUI(command, interval)
+
+	// Errors are displayed after leaving the alternate screen
+	var errOutput strings.Builder
+
+	refresh := func() error {
+		var cmdErr strings.Builder
+
+		output, err := runWatchedCommand(cmd, args, runFunc, &cmdErr)
+
+		tui.update(output.String() + cmdErr.String())
+		tui.connection = clients.state()
+
+		if err != nil {
+			errOutput.WriteString(cmdErr.String())
+		}
+
+		return err
+	}
+
+	// The first run happens before entering raw mode so that a password
+	// prompt can read from the terminal.
+	if err := refresh(); err != nil {
+		fmt.Fprint(view.err, errOutput.String())
+		return err
+	}
+
+	oldState, err := term.MakeRaw(stdin)
+	if err != nil {
+		logger.Error("failed to put the terminal into raw mode", slog.Any("error", err))
+		return err
+	}
+
+	fmt.Fprint(stdout, watchTUIEnterScreen)
+
+	defer func() {
+		fmt.Fprint(stdout, watchTUIExitScreen)
+
+		if err := term.Restore(stdin, oldState); err != nil {
+			logger.Error("failed to restore the terminal", slog.Any("error", err))
+		}
+
+		fmt.Fprint(view.err, errOutput.String())
+	}()
+
+	tui.width, tui.height, _ = term.GetSize(int(os.Stdout.Fd()))
+
+	keys := make(chan string)
+	go readWatchKeys(os.Stdin, keys)
+
+	ticker := time.NewTicker(tui.interval)
+	defer ticker.Stop()
+
+	resizeTicker := time.NewTicker(watchTUIResizeInterval)
+	defer resizeTicker.Stop()
+
+	redraw := true
+
+	for {
+		if redraw {
+			fmt.Fprint(stdout, tui.render())
+		}
+
+		redraw = true
+
+		select {
+		case <-ctx.Done():
+			logger.Debug("Watch mode terminated by user")
+			return nil
+		case key, ok := <-keys:
+			if !ok {
+				return nil
+			}
+
+			quit, refreshNow, intervalChanged := tui.handleKey(key)
+
+			switch {
+			case quit:
+				return nil
+			case intervalChanged:
+				ticker.Reset(tui.interval)
+			case refreshNow:
+				if err := refresh(); err != nil {
+					return err
+				}
+			}
+		case <-ticker.C:
+			if tui.paused {
+				redraw = false
+				continue
+			}
+
+			if err := refresh(); err != nil {
+				logger.Error("Error executing command in watch mode", slog.Any("error", err))
+				return err
+			}
+		case <-resizeTicker.C:
+			width, height, err := term.GetSize(int(os.Stdout.Fd()))
+			if err != nil || (width == tui.width && height == tui.height) {
+				redraw = false
+				continue
+			}
+
+			tui.width, tui.height = width, height
+		}
+	}
+}
@@ -0,0 +1,91 @@
+//go:build unit
+
+package cmd
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseWatchKeys(t *testing.T) {
+	assert.Equal(t, []string{"q"}, parseWatchKeys([]byte("q")))
+	assert.Equal(t, []string{keyUp, keyDown, "j"}, parseWatchKeys([]byte("\x1b[A\x1b[Bj")))
+	assert.Equal(t, []string{keyPageUp, keyPageDown}, parseWatchKeys([]byte("\x1b[5~\x1b[6~")))
+	assert.Equal(t, []string{keyCtrlC}, parseWatchKeys([]byte{0x03}))
+	assert.Equal(t, []string{"+"}, parseWatchKeys([]byte("\x1b[1;5P+")), "unrecognized escape sequences are skipped")
+}
+
+func TestDiffWatchCells(t *testing.T) {
+	prev := []string{
+		"│ a │ 1 │",
+		"│ b │ 2 │",
+	}
+	cur := []string{
+		"│ a │ 1 │",
+		"│ b │ 3 │",
+		"│ c │ 4 │",
+	}
+
+	changed := diffWatchCells(prev, cur)
+
+	assert.NotContains(t, changed[0], true)
+	assert.Equal(t, []bool{false, false, false, false, false, true, true, true, false}, changed[1])
+	assert.NotContains(t, changed[2], false)
+}
+
+func TestCropWatchLine(t *testing.T) {
+	assert.Equal(t, "cdef", cropWatchLine("abcdefgh", nil, 2, 4))
+	assert.Equal(t, "", cropWatchLine("ab", nil, 4, 4))
+	assert.Equal(
+		t,
+		"a"+watchTUIHighlight+"bc"+watchTUIReset+" d",
+		cropWatchLine("abc d", []bool{false, true, true, true, false}, 0, 10),
+	)
+}
+
+func TestWatchTUI(t *testing.T) {
+	tui := newWatchTUI("asvec index ls", 2*time.Second)
+	tui.width = 20
+	tui.height = 5
+
+	tui.update("line 1\nline 2\nline 3\nline 4\n")
+
+	frame := tui.render()
+	assert.Contains(t, frame, "line 1")
+	assert.Contains(t, frame, "line 2")
+	assert.NotContains(t, frame, "line 3", "only the body height is displayed")
+
+	tui.handleKey(keyDown)
+	tui.handleKey(keyDown)
+	tui.handleKey(keyDown)
+	assert.Equal(t, 2, tui.scrollY, "scrolling stops at the last line")
+
+	frame = tui.render()
+	assert.Contains(t, frame, "line 4")
+	assert.NotContains(t, frame, "line 2")
+
+	tui.handleKey(keyHome)
+	assert.Equal(t, 0, tui.scrollY)
+
+	tui.handleKey(" ")
+	assert.True(t, tui.paused)
+	assert.Contains(t, tui.render(), "[PAUSED]")
+
+	_, _, intervalChanged := tui.handleKey("+")
+	assert.True(t, intervalChanged)
+	assert.Equal(t, 3*time.Second, tui.interval)
+
+	tui.interval = time.Second
+	_, _, intervalChanged = tui.handleKey("-")
+	assert.False(t, intervalChanged, "the interval is at least 1 second")
+
+	quit, _, _ := tui.handleKey("q")
+	assert.True(t, quit)
+
+	for _, line := range strings.Split(tui.render(), "\r\n") {
+		assert.LessOrEqual(t, len(strings.TrimPrefix(line, watchTUIHome)), tui.width+len(watchTUIClearLine+watchTUIClearBelow))
+	}
+}
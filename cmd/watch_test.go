@@ -191,7 +191,7 @@ func TestWrapCommandWithWatch(t *testing.T) {
 	// Test wrapping a command with Run function
 	t.Run("Wrap command with Run function", func(t *testing.T) {
 		// Apply watch functionality
-		wrapCommandWithWatch(cmdWithRun)
+		wrapCommandWithWatch(cmdWithRun, nil)
 
 		// Verify that watch flags were added
 		watchFlag := cmdWithRun.Flags().Lookup(flags.Watch)
@@ -213,7 +213,7 @@ func TestWrapCommandWithWatch(t *testing.T) {
 	// Test wrapping a command with RunE function
 	t.Run("Wrap command with RunE function", func(t *testing.T) {
 		// Apply watch functionality
-		wrapCommandWithWatch(cmdWithRunE)
+		wrapCommandWithWatch(cmdWithRunE, nil)
 
 		// Verify that watch flags were added
 		watchFlag := cmdWithRunE.Flags().Lookup(flags.Watch)
@@ -0,0 +1,154 @@
+package writers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+)
+
+// BenchResult is the outcome of a benchmark run. A TargetQPS of 0 means
+// requests were sent as fast as Concurrency allowed.
+//
+//nolint:govet // Padding not a concern for a CLI
+type BenchResult struct {
+	Operation    string
+	Topology     string
+	Concurrency  int
+	TargetQPS    int
+	Duration     time.Duration
+	Requests     int
+	Errors       int
+	QPS          float64
+	P50          time.Duration
+	P90          time.Duration
+	P99          time.Duration
+	P999         time.Duration
+	ErrorsByCode map[string]int
+}
+
+// MarshalJSON reports durations in milliseconds.
+func (r *BenchResult) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&struct {
+		Operation    string         `json:"operation"`
+		Topology     string         `json:"topology"`
+		Concurrency  int            `json:"concurrency"`
+		TargetQPS    int            `json:"targetQps"`
+		Duration     float64        `json:"durationMs"`
+		Requests     int            `json:"requests"`
+		Errors       int            `json:"errors"`
+		QPS          float64        `json:"qps"`
+		P50          float64        `json:"latencyP50Ms"`
+		P90          float64        `json:"latencyP90Ms"`
+		P99          float64        `json:"latencyP99Ms"`
+		P999         float64        `json:"latencyP999Ms"`
+		ErrorsByCode map[string]int `json:"errorsByCode"`
+	}{
+		Operation:    r.Operation,
+		Topology:     r.Topology,
+		Concurrency:  r.Concurrency,
+		TargetQPS:    r.TargetQPS,
+		Duration:     durationMs(r.Duration),
+		Requests:     r.Requests,
+		Errors:       r.Errors,
+		QPS:          r.QPS,
+		P50:          durationMs(r.P50),
+		P90:          durationMs(r.P90),
+		P99:          durationMs(r.P99),
+		P999:         durationMs(r.P999),
+		ErrorsByCode: r.ErrorsByCode,
+	})
+}
+
+type BenchTableWriter struct {
+	table  table.Writer
+	logger *slog.Logger
+}
+
+func NewBenchTableWriter(writer io.Writer, logger *slog.Logger) *BenchTableWriter {
+	t := BenchTableWriter{NewDefaultWriter(writer), logger}
+
+	t.table.SetTitle("Benchmark Results")
+	t.table.AppendHeader(
+		table.Row{
+			"Operation",
+			"Topology",
+			"Concurrency",
+			"Target QPS",
+			"Duration",
+			"Requests",
+			"Errors",
+			"QPS",
+			"P50",
+			"P90",
+			"P99",
+			"P999",
+		},
+	)
+
+	return &t
+}
+
+func (itw *BenchTableWriter) AppendBenchRow(result *BenchResult) {
+	targetQPS := "unlimited"
+	if result.TargetQPS > 0 {
+		targetQPS = fmt.Sprintf("%d", result.TargetQPS)
+	}
+
+	itw.table.AppendRow(table.Row{
+		result.Operation,
+		result.Topology,
+		result.Concurrency,
+		targetQPS,
+		result.Duration.Round(time.Millisecond),
+		result.Requests,
+		result.Errors,
+		fmt.Sprintf("%.1f", result.QPS),
+		result.P50.Round(time.Microsecond),
+		result.P90.Round(time.Microsecond),
+		result.P99.Round(time.Microsecond),
+		result.P999.Round(time.Microsecond),
+	})
+}
+
+func (itw *BenchTableWriter) Render(renderFormat int) {
+	if renderFormat == RenderFormatCSV {
+		itw.table.RenderCSV()
+	} else {
+		itw.table.Render()
+	}
+}
+
+type BenchErrorTableWriter struct {
+	table  table.Writer
+	logger *slog.Logger
+}
+
+func NewBenchErrorTableWriter(writer io.Writer, logger *slog.Logger) *BenchErrorTableWriter {
+	t := BenchErrorTableWriter{NewDefaultWriter(writer), logger}
+
+	t.table.SetTitle("Errors")
+	t.table.AppendHeader(table.Row{"Code", "Count"})
+
+	return &t
+}
+
+// AppendErrorRows appends a row for each code in errorsByCode, sorted by code.
+func (itw *BenchErrorTableWriter) AppendErrorRows(errorsByCode map[string]int) {
+	for _, code := range slices.Sorted(maps.Keys(errorsByCode)) {
+		itw.table.AppendRow(table.Row{code, errorsByCode[code]})
+	}
+}
+
+func (itw *BenchErrorTableWriter) Render(renderFormat int) {
+	if renderFormat == RenderFormatCSV {
+		itw.table.RenderCSV()
+	} else {
+		itw.table.Render()
+	}
+}
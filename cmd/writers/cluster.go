@@ -0,0 +1,92 @@
+package writers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+)
+
+// ClusterKey is the field holding the cluster an item came from in results
+// merged from multiple clusters.
+const ClusterKey = "cluster"
+
+// clusterColumn adds a leading Cluster column to a table when the results of
+// multiple clusters are merged into it. Without a call to SetCluster the table
+// is unchanged.
+type clusterColumn struct {
+	table        table.Writer
+	header       table.Row
+	headerConfig []table.RowConfig
+	sortBy       []table.SortBy
+	cluster      *string
+}
+
+func newClusterColumn(
+	t table.Writer,
+	header table.Row,
+	sortBy []table.SortBy,
+	headerConfig ...table.RowConfig,
+) clusterColumn {
+	t.AppendHeader(header, headerConfig...)
+	t.SortBy(sortBy)
+
+	return clusterColumn{table: t, header: header, headerConfig: headerConfig, sortBy: sortBy}
+}
+
+// SetCluster sets the cluster of the rows appended after it. The first call
+// adds the Cluster column, which is sorted before the other columns.
+func (c *clusterColumn) SetCluster(name string) {
+	if c.cluster == nil {
+		c.table.ResetHeaders()
+		c.table.AppendHeader(append(table.Row{"Cluster"}, c.header...), c.headerConfig...)
+		c.table.SortBy(append([]table.SortBy{{Name: "Cluster", Mode: table.Asc}}, c.sortBy...))
+	}
+
+	c.cluster = &name
+}
+
+// row prepends the cluster to row if the Cluster column has been added.
+func (c *clusterColumn) row(row table.Row) table.Row {
+	if c.cluster == nil {
+		return row
+	}
+
+	return append(table.Row{*c.cluster}, row...)
+}
+
+// ClusterJSON adds the cluster an item came from to the item's JSON object.
+type ClusterJSON struct {
+	Cluster string
+	Item    any
+}
+
+func (c ClusterJSON) MarshalJSON() ([]byte, error) {
+	item, err := json.Marshal(c.Item)
+	if err != nil {
+		return nil, err
+	}
+
+	item = bytes.TrimSpace(item)
+	if len(item) < 2 || item[0] != '{' {
+		return nil, fmt.Errorf("cannot add the cluster to non-object JSON: %s", item)
+	}
+
+	cluster, err := json.Marshal(c.Cluster)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+
+	fmt.Fprintf(&buf, "{%q:%s", ClusterKey, cluster)
+
+	if !bytes.Equal(item, []byte("{}")) {
+		buf.WriteString(",")
+	}
+
+	buf.Write(item[1:])
+
+	return buf.Bytes(), nil
+}
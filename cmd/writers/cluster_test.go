@@ -0,0 +1,39 @@
+package writers
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/aerospike/avs-client-go/protos"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClusterJSON(t *testing.T) {
+	out, err := json.Marshal(ClusterJSON{Cluster: "us", Item: map[string]any{"username": "admin"}})
+	require.NoError(t, err)
+	assert.Equal(t, `{"cluster":"us","username":"admin"}`, string(out))
+
+	out, err = json.Marshal(ClusterJSON{Cluster: "us", Item: struct{}{}})
+	require.NoError(t, err)
+	assert.Equal(t, `{"cluster":"us"}`, string(out))
+
+	_, err = json.Marshal(ClusterJSON{Cluster: "us", Item: []string{"admin"}})
+	assert.Error(t, err)
+}
+
+func TestUserTableWriterSetCluster(t *testing.T) {
+	out := &bytes.Buffer{}
+	w := NewUserTableWriter(out, slog.Default())
+
+	w.SetCluster("us")
+	w.AppendUserRow(&protos.User{Username: "admin", Roles: []string{"admin"}})
+	w.SetCluster("eu")
+	w.AppendUserRow(&protos.User{Username: "admin", Roles: []string{"admin"}})
+	w.Render(RenderFormatCSV)
+
+	// Rows are sorted by cluster first.
+	assert.Equal(t, "Users\n,Cluster,User,Roles\n1,eu,admin,admin\n2,us,admin,admin\n", out.String())
+}
@@ -0,0 +1,130 @@
+package writers
+
+import (
+	"io"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+)
+
+// Results of a doctor check.
+const (
+	DoctorPass = "pass"
+	DoctorWarn = "warn"
+	DoctorFail = "fail"
+	DoctorSkip = "skip"
+)
+
+// DoctorCheck is the result of a single cluster health check. Remediation is
+// a hint for fixing a warning or failure.
+type DoctorCheck struct {
+	Name        string `json:"name"`
+	Status      string `json:"status"`
+	Message     string `json:"message"`
+	Remediation string `json:"remediation,omitempty"`
+}
+
+// DoctorSummary is the number of checks with each result.
+type DoctorSummary struct {
+	Pass int `json:"pass"`
+	Warn int `json:"warn"`
+	Fail int `json:"fail"`
+	Skip int `json:"skip"`
+}
+
+// DoctorReport is the result of every cluster health check.
+type DoctorReport struct {
+	Checks  []*DoctorCheck `json:"checks"`
+	Summary DoctorSummary  `json:"summary"`
+}
+
+func NewDoctorReport(checks []*DoctorCheck) *DoctorReport {
+	report := &DoctorReport{Checks: checks}
+
+	for _, check := range checks {
+		switch check.Status {
+		case DoctorPass:
+			report.Summary.Pass++
+		case DoctorWarn:
+			report.Summary.Warn++
+		case DoctorFail:
+			report.Summary.Fail++
+		default:
+			report.Summary.Skip++
+		}
+	}
+
+	return report
+}
+
+type DoctorTableWriter struct {
+	table  table.Writer
+	logger *slog.Logger
+}
+
+func NewDoctorTableWriter(writer io.Writer, logger *slog.Logger) *DoctorTableWriter {
+	t := DoctorTableWriter{NewDefaultWriter(writer), logger}
+
+	t.table.SetTitle("Cluster Doctor")
+	t.table.AppendHeader(table.Row{"Check", "Status", "Details", "Remediation"})
+	t.table.SetColumnConfigs([]table.ColumnConfig{
+		{Name: "Details", WidthMax: 60, WidthMaxEnforcer: text.WrapSoft},
+		{Name: "Remediation", WidthMax: 60, WidthMaxEnforcer: text.WrapSoft},
+	})
+	t.table.Style().Options.SeparateRows = true
+
+	return &t
+}
+
+func (dtw *DoctorTableWriter) AppendCheckRow(check *DoctorCheck) {
+	dtw.table.AppendRow(table.Row{
+		check.Name,
+		formatDoctorStatus(check.Status),
+		check.Message,
+		check.Remediation,
+	})
+}
+
+func (dtw *DoctorTableWriter) AppendSummary(summary DoctorSummary) {
+	dtw.table.AppendFooter(table.Row{
+		"Summary",
+		"",
+		strings.Join([]string{
+			formatDoctorCount(summary.Pass, DoctorPass),
+			formatDoctorCount(summary.Warn, DoctorWarn),
+			formatDoctorCount(summary.Fail, DoctorFail),
+			formatDoctorCount(summary.Skip, DoctorSkip),
+		}, ", "),
+		"",
+	})
+}
+
+func (dtw *DoctorTableWriter) Render(renderFormat int) {
+	if renderFormat == RenderFormatCSV {
+		dtw.table.RenderCSV()
+	} else {
+		dtw.table.Render()
+	}
+}
+
+func formatDoctorStatus(status string) string {
+	s := strings.ToUpper(status)
+
+	switch status {
+	case DoctorPass:
+		return text.FgGreen.Sprint(s)
+	case DoctorWarn:
+		return text.FgYellow.Sprint(s)
+	case DoctorFail:
+		return text.FgRed.Sprint(s)
+	default:
+		return s
+	}
+}
+
+func formatDoctorCount(n int, status string) string {
+	return strconv.Itoa(n) + " " + status
+}
@@ -15,13 +15,14 @@ var rowConfigAutoMerge = table.RowConfig{AutoMerge: true}
 
 //nolint:govet // Padding not a concern for a CLI
 type IndexTableWriter struct {
+	clusterColumn
 	table   table.Writer
 	verbose bool
 	logger  *slog.Logger
 }
 
 func NewIndexTableWriter(writer io.Writer, verbose bool, logger *slog.Logger) *IndexTableWriter {
-	t := IndexTableWriter{NewDefaultWriter(writer), verbose, logger}
+	t := IndexTableWriter{table: NewDefaultWriter(writer), verbose: verbose, logger: logger}
 
 	headings := table.Row{
 		"Name",
@@ -47,22 +48,23 @@ func NewIndexTableWriter(writer io.Writer, verbose bool, logger *slog.Logger) *I
 		"Standalone Index Metrics",
 	)
 
+	sortBy := []table.SortBy{
+		{Name: "Namespace", Mode: table.Asc},
+		{Name: "Set", Mode: table.Asc},
+		{Name: "Name", Mode: table.Asc},
+	}
+
 	if verbose {
-		t.table.AppendHeader(verboseHeadings, rowConfigAutoMerge)
+		t.clusterColumn = newClusterColumn(t.table, verboseHeadings, sortBy, rowConfigAutoMerge)
 	} else {
-		t.table.AppendHeader(headings)
+		t.clusterColumn = newClusterColumn(t.table, headings, sortBy)
 	}
 
 	t.table.SetTitle("Indexes")
 	t.table.SetAutoIndex(true)
-	t.table.SortBy([]table.SortBy{
-		{Name: "Namespace", Mode: table.Asc},
-		{Name: "Set", Mode: table.Asc},
-		{Name: "Name", Mode: table.Asc},
-	})
 	t.table.SetColumnConfigs([]table.ColumnConfig{
 		{
-			Number:      3,
+			Name:        "Set",
 			Transformer: removeNil,
 		},
 	})
@@ -86,7 +88,7 @@ func (itw *IndexTableWriter) AppendIndexRow(
 		index.VectorDistanceMetric,
 		status.GetUnmergedRecordCount(),
 		status.GetIndexHealerVectorRecordsIndexed(),
-		formatBytes(calculateIndexSize(index, status)),
+		formatBytes(CalculateIndexSize(index, status)),
 		getPercentUnmerged(status),
 		index.Mode,
 		status.Status,
@@ -149,7 +151,7 @@ func (itw *IndexTableWriter) AppendIndexRow(
 		}
 	}
 
-	itw.table.AppendRow(row)
+	itw.table.AppendRow(itw.row(row))
 }
 
 func (itw *IndexTableWriter) Render(renderFormat int) {
@@ -168,8 +170,8 @@ func convertFloatToPercentStr(f float32) string {
 	return fmt.Sprintf("%.2f%%", f)
 }
 
-// calculateIndexSize approximates the size of the index in bytes
-func calculateIndexSize(index *protos.IndexDefinition, status *protos.IndexStatusResponse) int64 {
+// CalculateIndexSize approximates the size of the index in bytes
+func CalculateIndexSize(index *protos.IndexDefinition, status *protos.IndexStatusResponse) int64 {
 	// the "m" parameter in the Hnsw index.
 	var m uint32
 	switch v := index.Params.(type) {
@@ -224,7 +224,7 @@ func Test_calculateIndexSizeDetailed(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := calculateIndexSize(tt.args.index, tt.args.status); got != tt.want {
+			if got := CalculateIndexSize(tt.args.index, tt.args.status); got != tt.want {
 				t.Errorf("calculateIndexSizeDetailed() = %v, want %v", got, tt.want)
 			}
 		})
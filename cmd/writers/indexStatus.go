@@ -0,0 +1,54 @@
+package writers
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+
+	"github.com/aerospike/avs-client-go/protos"
+	"github.com/jedib0t/go-pretty/v6/table"
+)
+
+// IndexStatusTableWriter displays the status of a single index with one row
+// per field.
+type IndexStatusTableWriter struct {
+	table  table.Writer
+	logger *slog.Logger
+}
+
+func NewIndexStatusTableWriter(writer io.Writer, logger *slog.Logger) *IndexStatusTableWriter {
+	t := IndexStatusTableWriter{NewDefaultWriter(writer), logger}
+
+	t.table.Style().Options.SeparateRows = true
+
+	return &t
+}
+
+func (itw *IndexStatusTableWriter) AppendIndexStatus(index *protos.IndexDefinition, status *protos.IndexStatusResponse) {
+	itw.table.SetTitle(fmt.Sprintf("Index %s.%s", index.GetId().GetNamespace(), index.GetId().GetName()))
+	itw.table.AppendRows([]table.Row{
+		{"Status", status.GetStatus()},
+		{"Mode", index.GetMode()},
+		{"Unmerged", status.GetUnmergedRecordCount()},
+		{"Unmerged %", getPercentUnmerged(status)},
+		{"Vector Records", status.GetIndexHealerVectorRecordsIndexed()},
+		{"Vertices", status.GetIndexHealerVerticesValid()},
+		{"Size", formatBytes(CalculateIndexSize(index, status))},
+	})
+
+	if metrics := status.GetStandaloneIndexMetrics(); metrics != nil {
+		itw.table.AppendRows([]table.Row{
+			{"Standalone State", metrics.GetState()},
+			{"Scanned Vector Records", metrics.GetScannedVectorRecordCount()},
+			{"Indexed Vector Records", metrics.GetIndexedVectorRecordCount()},
+		})
+	}
+}
+
+func (itw *IndexStatusTableWriter) Render(renderFormat int) {
+	if renderFormat == RenderFormatCSV {
+		itw.table.RenderCSV()
+	} else {
+		itw.table.Render()
+	}
+}
@@ -0,0 +1,125 @@
+package writers
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"time"
+
+	"github.com/aerospike/avs-client-go/protos"
+	"github.com/jedib0t/go-pretty/v6/table"
+)
+
+// IndexTopRow is the latest status of an index and the rates derived from
+// recent samples. Rates and ETAs are nil until there are enough samples to
+// compute them. Standalone fields are nil for distributed indexes.
+//
+//nolint:govet // Padding not a concern for a CLI
+type IndexTopRow struct {
+	Namespace       string
+	Name            string
+	Status          protos.Status
+	Unmerged        int64
+	MergeRate       *float64
+	MergeETA        *time.Duration
+	Vertices        int64
+	VertexRate      *float64
+	Size            int64
+	SizeRate        *float64
+	StandaloneState *protos.StandaloneIndexState
+	BuildProgress   *float64
+	BuildRate       *float64
+	BuildETA        *time.Duration
+}
+
+type IndexTopTableWriter struct {
+	table  table.Writer
+	logger *slog.Logger
+}
+
+func NewIndexTopTableWriter(writer io.Writer, logger *slog.Logger) *IndexTopTableWriter {
+	t := IndexTopTableWriter{NewDefaultWriter(writer), logger}
+
+	t.table.SetTitle("Index Status")
+	t.table.AppendHeader(
+		table.Row{
+			"Namespace",
+			"Name",
+			"Status",
+			"Unmerged",
+			"Merge Rate",
+			"Merge ETA",
+			"Vertices",
+			"Vertex Rate",
+			"Size",
+			"Size Growth",
+			"Standalone State",
+			"Build Progress",
+			"Build Rate",
+			"Build ETA",
+		},
+	)
+	t.table.SortBy([]table.SortBy{
+		{Name: "Namespace", Mode: table.Asc},
+		{Name: "Name", Mode: table.Asc},
+	})
+
+	return &t
+}
+
+func (itw *IndexTopTableWriter) AppendIndexTopRow(row *IndexTopRow) {
+	standaloneState := "-"
+	if row.StandaloneState != nil {
+		standaloneState = row.StandaloneState.String()
+	}
+
+	buildProgress := "-"
+	if row.BuildProgress != nil {
+		buildProgress = fmt.Sprintf("%.1f%%", *row.BuildProgress)
+	}
+
+	sizeRate := "-"
+	if row.SizeRate != nil {
+		sizeRate = formatBytes(int64(*row.SizeRate)) + "/s"
+		if *row.SizeRate < 0 {
+			sizeRate = "-" + formatBytes(-int64(*row.SizeRate)) + "/s"
+		}
+	}
+
+	itw.table.AppendRow(table.Row{
+		row.Namespace,
+		row.Name,
+		row.Status,
+		row.Unmerged,
+		formatRate(row.MergeRate),
+		formatETA(row.MergeETA),
+		row.Vertices,
+		formatRate(row.VertexRate),
+		formatBytes(row.Size),
+		sizeRate,
+		standaloneState,
+		buildProgress,
+		formatRate(row.BuildRate),
+		formatETA(row.BuildETA),
+	})
+}
+
+func (itw *IndexTopTableWriter) Render() {
+	itw.table.Render()
+}
+
+func formatRate(rate *float64) string {
+	if rate == nil {
+		return "-"
+	}
+
+	return fmt.Sprintf("%.1f/s", *rate)
+}
+
+func formatETA(eta *time.Duration) string {
+	if eta == nil {
+		return "-"
+	}
+
+	return eta.Round(time.Second).String()
+}
@@ -0,0 +1,94 @@
+package writers
+
+import (
+	"asvec/cmd/records"
+	"encoding/json"
+	"time"
+
+	"github.com/aerospike/avs-client-go"
+	"github.com/aerospike/avs-client-go/protos"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// protoJSONOptions are used to marshal every proto message. Unpopulated
+// fields are included so the set of keys in the output does not change with
+// the data.
+var protoJSONOptions = protojson.MarshalOptions{EmitUnpopulated: true}
+
+// ProtoJSON marshals a proto message to JSON using protojson. A nil message
+// is marshaled as null.
+type ProtoJSON struct {
+	Message proto.Message
+}
+
+func (p ProtoJSON) MarshalJSON() ([]byte, error) {
+	if p.Message == nil || !p.Message.ProtoReflect().IsValid() {
+		return []byte("null"), nil
+	}
+
+	return protoJSONOptions.Marshal(p.Message)
+}
+
+// IndexJSON is the JSON representation of an index and its status.
+type IndexJSON struct {
+	Definition ProtoJSON `json:"definition"`
+	Status     ProtoJSON `json:"status"`
+}
+
+func NewIndexJSON(index *protos.IndexDefinition, status *protos.IndexStatusResponse) *IndexJSON {
+	return &IndexJSON{
+		Definition: ProtoJSON{index},
+		Status:     ProtoJSON{status},
+	}
+}
+
+// MarshalJSON marshals a node's information. NodeID is null when connected
+// through a load balancer or seed node which did not report its ID.
+func (n *NodeInfo) MarshalJSON() ([]byte, error) {
+	var nodeID *uint64
+
+	if id := n.NodeID.GetId(); id != 0 {
+		nodeID = &id
+	}
+
+	return json.Marshal(&struct {
+		NodeID            *uint64   `json:"nodeId"`
+		ConnectedEndpoint ProtoJSON `json:"connectedEndpoint"`
+		Endpoints         ProtoJSON `json:"endpoints"`
+		State             ProtoJSON `json:"state"`
+		About             ProtoJSON `json:"about"`
+	}{
+		NodeID:            nodeID,
+		ConnectedEndpoint: ProtoJSON{n.ConnectedEndpoint},
+		Endpoints:         ProtoJSON{n.Endpoints},
+		State:             ProtoJSON{n.State},
+		About:             ProtoJSON{n.About},
+	})
+}
+
+// RecordJSON is the JSON representation of a single record.
+type RecordJSON struct {
+	Set        *string        `json:"set,omitempty"`
+	Key        any            `json:"key"`
+	Expiration *time.Time     `json:"expiration,omitempty"`
+	Data       map[string]any `json:"data"`
+	Namespace  string         `json:"namespace"`
+	Generation uint32         `json:"generation"`
+}
+
+func NewRecordJSON(namespace string, set *string, key any, record *avs.Record) *RecordJSON {
+	r := &RecordJSON{
+		Namespace: namespace,
+		Set:       set,
+		Key:       key,
+	}
+
+	if record != nil {
+		r.Expiration = record.Expiration
+		r.Generation = record.Generation
+		r.Data, _ = records.ToJSONCompatible(record.Data).(map[string]any)
+	}
+
+	return r
+}
@@ -0,0 +1,78 @@
+package writers
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/aerospike/avs-client-go"
+	"github.com/aerospike/avs-client-go/protos"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProtoJSON(t *testing.T) {
+	var nilUser *protos.User
+
+	out, err := json.Marshal(ProtoJSON{nilUser})
+	require.NoError(t, err)
+	assert.JSONEq(t, `null`, string(out))
+
+	out, err = json.Marshal(ProtoJSON{&protos.User{Username: "admin"}})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"username":"admin","roles":[]}`, string(out))
+}
+
+func TestNewIndexJSON(t *testing.T) {
+	index := &protos.IndexDefinition{
+		Id:    &protos.IndexId{Namespace: "test", Name: "idx"},
+		Field: "vec",
+	}
+
+	out, err := json.Marshal(NewIndexJSON(index, nil))
+	require.NoError(t, err)
+
+	result := map[string]any{}
+	require.NoError(t, json.Unmarshal(out, &result))
+
+	definition, ok := result["definition"].(map[string]any)
+	require.True(t, ok)
+	assert.Equal(t, "vec", definition["field"])
+	assert.Equal(t, map[string]any{"namespace": "test", "name": "idx"}, definition["id"])
+	assert.Nil(t, result["status"])
+}
+
+func TestNodeInfoMarshalJSON(t *testing.T) {
+	node := &NodeInfo{
+		NodeID: &protos.NodeId{Id: 7},
+		About:  &protos.AboutResponse{Version: "1.0.0"},
+	}
+
+	out, err := json.Marshal(node)
+	require.NoError(t, err)
+
+	result := map[string]any{}
+	require.NoError(t, json.Unmarshal(out, &result))
+	assert.InDelta(t, 7, result["nodeId"], 0)
+	assert.Equal(t, "1.0.0", result["about"].(map[string]any)["version"])
+	assert.Nil(t, result["state"])
+
+	out, err = json.Marshal(&NodeInfo{})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"nodeId":null,"connectedEndpoint":null,"endpoints":null,"state":null,"about":null}`, string(out))
+}
+
+func TestNewRecordJSON(t *testing.T) {
+	set := "myset"
+	record := &avs.Record{
+		Data:       map[string]any{"name": "a", "nested": map[any]any{"x": 1}},
+		Generation: 2,
+	}
+
+	out, err := json.Marshal(NewRecordJSON("test", &set, int64(1), record))
+	require.NoError(t, err)
+	assert.JSONEq(
+		t,
+		`{"namespace":"test","set":"myset","key":1,"generation":2,"data":{"name":"a","nested":{"x":1}}}`,
+		string(out),
+	)
+}
@@ -1,35 +1,61 @@
 package writers
 
 import (
+	"asvec/cmd/records"
+	"fmt"
 	"io"
 	"log/slog"
-	"slices"
+	"time"
 
 	"github.com/aerospike/avs-client-go"
 	"github.com/jedib0t/go-pretty/v6/table"
 )
 
+// Neighbor is a search result. ExactDistance is the distance recomputed from
+// the neighbor's stored vector when results are re-ranked, or nil.
+type Neighbor struct {
+	*avs.Neighbor
+	ExactDistance *float32
+}
+
+// NewNeighbors wraps search results which have not been re-ranked.
+func NewNeighbors(neighbors []*avs.Neighbor) []*Neighbor {
+	wrapped := make([]*Neighbor, len(neighbors))
+	for i, n := range neighbors {
+		wrapped[i] = &Neighbor{Neighbor: n}
+	}
+
+	return wrapped
+}
+
 type NeighborTableWriter struct {
-	table  table.Writer
-	logger *slog.Logger
+	table   table.Writer
+	queryID any
+	logger  *slog.Logger
+	exact   bool
 }
 
 func NewNeighborTableWriter(writer io.Writer, logger *slog.Logger) *NeighborTableWriter {
-	t := NeighborTableWriter{NewDefaultWriter(writer), logger}
-
-	t.table.AppendHeader(
-		table.Row{
-			"Namespace",
-			"Set",
-			"Key",
-			"Distance",
-			"Expiration",
-			"Generation",
-			"Data",
-		},
-	)
+	return newNeighborTableWriter(writer, nil, logger)
+}
+
+// NewQueryNeighborTableWriter returns a NeighborTableWriter for one of many
+// queries. Each row is tagged with the query ID so that results remain
+// distinguishable when rendered as CSV.
+func NewQueryNeighborTableWriter(writer io.Writer, queryID any, logger *slog.Logger) *NeighborTableWriter {
+	return newNeighborTableWriter(writer, queryID, logger)
+}
+
+func newNeighborTableWriter(writer io.Writer, queryID any, logger *slog.Logger) *NeighborTableWriter {
+	t := NeighborTableWriter{table: NewDefaultWriter(writer), queryID: queryID, logger: logger}
+	title := "Query Results"
 
-	t.table.SetTitle("Query Results")
+	if queryID != nil {
+		title = fmt.Sprintf("%s: %v", title, queryID)
+	}
+
+	t.table.AppendHeader(t.header("Distance"))
+	t.table.SetTitle(title)
 	t.table.SetAutoIndex(true)
 	t.table.SortBy([]table.SortBy{
 		{Name: "Distance", Mode: table.AscNumeric},
@@ -52,56 +78,69 @@ func NewNeighborTableWriter(writer io.Writer, logger *slog.Logger) *NeighborTabl
 	return &t
 }
 
+func (itw *NeighborTableWriter) header(distance ...any) table.Row {
+	header := table.Row{}
+
+	if itw.queryID != nil {
+		header = append(header, "Query ID")
+	}
+
+	header = append(header, "Namespace", "Set", "Key")
+	header = append(header, distance...)
+
+	return append(header, "Expiration", "Generation", "Data")
+}
+
+// WithExactDistance shows each neighbor's exact distance next to the
+// distance returned by the index. Rows are kept in the order appended since
+// re-ranked results are already sorted. It must be called before any rows are
+// appended.
+func (itw *NeighborTableWriter) WithExactDistance() *NeighborTableWriter {
+	itw.exact = true
+
+	itw.table.ResetHeaders()
+	itw.table.AppendHeader(itw.header("ANN Distance", "Exact Distance"))
+	itw.table.SortBy(nil)
+
+	return itw
+}
+
 func (itw *NeighborTableWriter) AppendNeighborRow(
-	neighbor *avs.Neighbor,
+	neighbor *Neighbor,
 	maxDataKeys,
 	renderFormat,
 	maxDataValueColWidth int,
 ) {
-	row := table.Row{
+	row := table.Row{}
+
+	if itw.queryID != nil {
+		row = append(row, itw.queryID)
+	}
+
+	row = append(row,
 		neighbor.Namespace,
 		neighbor.Set,
 		neighbor.Key,
 		neighbor.Distance,
-		neighbor.Record.Expiration,
-		neighbor.Record.Generation,
-	}
-
-	tData := NewDefaultWriter(nil)
-	tData.AppendHeader(table.Row{"Key", "Value"})
-	tData.SetColumnConfigs(
-		[]table.ColumnConfig{
-			{
-				Name:        "Value",
-				Transformer: vectorFormat,
-				WidthMax:    maxDataValueColWidth,
-			},
-		},
 	)
 
-	keys := make([]string, 0, len(neighbor.Record.Data))
-
-	for key := range neighbor.Record.Data {
-		keys = append(keys, key)
-	}
-
-	slices.Sort(keys)
-
-	for i, key := range keys {
-		if maxDataKeys != 0 && i >= maxDataKeys {
-			tData.AppendRow(table.Row{"...", "..."})
-
-			break
+	if itw.exact {
+		// The exact distance is missing if the neighbor's vector could not
+		// be read.
+		var exactDistance any = ""
+		if neighbor.ExactDistance != nil {
+			exactDistance = *neighbor.ExactDistance
 		}
 
-		tData.AppendRow(table.Row{key, neighbor.Record.Data[key]})
+		row = append(row, exactDistance)
 	}
 
-	if renderFormat == RenderFormatCSV {
-		row = append(row, tData.RenderCSV())
-	} else {
-		row = append(row, tData.Render())
-	}
+	row = append(row,
+		neighbor.Record.Expiration,
+		neighbor.Record.Generation,
+	)
+
+	row = append(row, renderRecordData(neighbor.Record.Data, maxDataKeys, renderFormat, maxDataValueColWidth))
 
 	itw.table.AppendRow(row)
 }
@@ -113,3 +152,53 @@ func (itw *NeighborTableWriter) Render(renderFormat int) {
 		itw.table.Render()
 	}
 }
+
+// QueryResult is the JSON representation of the results of a single query.
+type QueryResult struct {
+	ID        any             `json:"id,omitempty"`
+	Neighbors []*NeighborJSON `json:"neighbors"`
+	Error     string          `json:"error,omitempty"`
+}
+
+// NeighborJSON is the JSON representation of a single neighbor.
+type NeighborJSON struct {
+	Set           *string        `json:"set,omitempty"`
+	Key           any            `json:"key"`
+	Expiration    *time.Time     `json:"expiration,omitempty"`
+	Data          map[string]any `json:"data"`
+	ExactDistance *float32       `json:"exactDistance,omitempty"`
+	Namespace     string         `json:"namespace"`
+	Distance      float32        `json:"distance"`
+	Generation    uint32         `json:"generation"`
+}
+
+func NewQueryResult(queryID any, neighbors []*Neighbor, queryErr error) *QueryResult {
+	result := &QueryResult{
+		ID:        queryID,
+		Neighbors: make([]*NeighborJSON, 0, len(neighbors)),
+	}
+
+	if queryErr != nil {
+		result.Error = queryErr.Error()
+	}
+
+	for _, n := range neighbors {
+		neighbor := &NeighborJSON{
+			Namespace:     n.Namespace,
+			Set:           n.Set,
+			Key:           n.Key,
+			Distance:      n.Distance,
+			ExactDistance: n.ExactDistance,
+		}
+
+		if n.Record != nil {
+			neighbor.Expiration = n.Record.Expiration
+			neighbor.Generation = n.Record.Generation
+			neighbor.Data, _ = records.ToJSONCompatible(n.Record.Data).(map[string]any)
+		}
+
+		result.Neighbors = append(result.Neighbors, neighbor)
+	}
+
+	return result
+}
@@ -0,0 +1,84 @@
+package writers
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/aerospike/avs-client-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewQueryResult(t *testing.T) {
+	set := "myset"
+	neighbors := []*avs.Neighbor{
+		{
+			Namespace: "test",
+			Set:       &set,
+			Key:       "a",
+			Distance:  1.5,
+			Record: &avs.Record{
+				Data:       map[string]any{"m": map[any]any{int64(1): "b"}},
+				Generation: 2,
+			},
+		},
+	}
+
+	result := NewQueryResult("q1", NewNeighbors(neighbors), nil)
+	assert.Equal(t, &QueryResult{
+		ID: "q1",
+		Neighbors: []*NeighborJSON{
+			{
+				Namespace:  "test",
+				Set:        &set,
+				Key:        "a",
+				Distance:   1.5,
+				Generation: 2,
+				Data:       map[string]any{"m": map[string]any{"1": "b"}},
+			},
+		},
+	}, result)
+
+	result = NewQueryResult(nil, nil, errors.New("failed"))
+	assert.Equal(t, &QueryResult{Neighbors: []*NeighborJSON{}, Error: "failed"}, result)
+}
+
+func TestQueryNeighborTableWriter(t *testing.T) {
+	buf := &bytes.Buffer{}
+	w := NewQueryNeighborTableWriter(buf, "q1", nil)
+	w.AppendNeighborRow(
+		&Neighbor{Neighbor: &avs.Neighbor{Namespace: "test", Key: "a", Record: &avs.Record{}}}, 0, RenderFormatCSV, 0,
+	)
+	w.Render(RenderFormatCSV)
+
+	assert.Contains(t, buf.String(), "Query ID,Namespace")
+	assert.Contains(t, buf.String(), "q1,test")
+}
+
+func TestNeighborTableWriterWithExactDistance(t *testing.T) {
+	exact := float32(0.5)
+	buf := &bytes.Buffer{}
+	w := NewNeighborTableWriter(buf, nil).WithExactDistance()
+
+	w.AppendNeighborRow(&Neighbor{
+		Neighbor:      &avs.Neighbor{Namespace: "test", Key: "b", Distance: 2, Record: &avs.Record{}},
+		ExactDistance: &exact,
+	}, 0, RenderFormatCSV, 0)
+	w.AppendNeighborRow(&Neighbor{
+		Neighbor: &avs.Neighbor{Namespace: "test", Key: "a", Distance: 1, Record: &avs.Record{}},
+	}, 0, RenderFormatCSV, 0)
+	w.Render(RenderFormatCSV)
+
+	// Re-ranked rows are not sorted by the ANN distance.
+	assert.Equal(t, `Query Results
+,Namespace,Key,ANN Distance,Exact Distance,Generation
+1,test,b,2,0.5,0
+2,test,a,1,,0
+`, buf.String())
+
+	result := NewQueryResult(nil, []*Neighbor{{
+		Neighbor:      &avs.Neighbor{Namespace: "test", Key: "b", Distance: 2},
+		ExactDistance: &exact,
+	}}, nil)
+	assert.Equal(t, &exact, result.Neighbors[0].ExactDistance)
+}
@@ -18,16 +18,17 @@ type NodeInfo struct {
 
 //nolint:govet // Padding not a concern for a CLI
 type NodeTableWriter struct {
+	clusterColumn
 	table  table.Writer
 	isLB   bool
 	logger *slog.Logger
 }
 
 func NewNodeTableWriter(writer io.Writer, isLB bool, logger *slog.Logger) *NodeTableWriter {
-	t := NodeTableWriter{NewDefaultWriter(writer), isLB, logger}
+	t := NodeTableWriter{table: NewDefaultWriter(writer), isLB: isLB, logger: logger}
 
 	t.table.SetTitle("Nodes")
-	t.table.AppendHeader(
+	t.clusterColumn = newClusterColumn(t.table,
 		table.Row{
 			"Node",
 			"Roles",
@@ -36,12 +37,12 @@ func NewNodeTableWriter(writer io.Writer, isLB bool, logger *slog.Logger) *NodeT
 			"Version",
 			"Visible Nodes",
 		},
+		[]table.SortBy{
+			{Name: "Node", Mode: table.Asc},
+		},
 		rowConfigAutoMerge,
 	)
 	t.table.SetAutoIndex(true)
-	t.table.SortBy([]table.SortBy{
-		{Name: "Node", Mode: table.Asc},
-	})
 	t.table.SetColumnConfigs([]table.ColumnConfig{
 		{
 			Name:      "Cluster ID",
@@ -96,7 +97,13 @@ func (itw *NodeTableWriter) AppendNodeRow(node *NodeInfo) {
 		row = append(row, "N/A")
 	}
 
-	itw.table.AppendRow(row)
+	itw.table.AppendRow(itw.row(row))
+}
+
+// SetLoadBalancer sets whether the rows appended after it are from a cluster
+// connected to through a load balancer.
+func (itw *NodeTableWriter) SetLoadBalancer(isLB bool) {
+	itw.isLB = isLB
 }
 
 func (itw *NodeTableWriter) Render(renderFormat int) {
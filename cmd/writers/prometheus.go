@@ -0,0 +1,116 @@
+package writers
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// PrometheusContentType is the content type of the Prometheus text
+// exposition format written by PrometheusWriter.
+const PrometheusContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// PrometheusWriter collects gauges and writes them in the Prometheus text
+// exposition format. Metrics are written in the order they were first added
+// with all samples of a metric grouped together.
+type PrometheusWriter struct {
+	metrics []*prometheusMetric
+	byName  map[string]*prometheusMetric
+}
+
+type prometheusMetric struct {
+	name    string
+	help    string
+	samples []string
+}
+
+func NewPrometheusWriter() *PrometheusWriter {
+	return &PrometheusWriter{byName: map[string]*prometheusMetric{}}
+}
+
+// Gauge adds a sample of a gauge. labels are pairs of label names and values,
+// e.g. "namespace", "test", "index", "myindex".
+func (w *PrometheusWriter) Gauge(name, help string, value float64, labels ...string) {
+	metric, ok := w.byName[name]
+	if !ok {
+		metric = &prometheusMetric{name: name, help: help}
+		w.metrics = append(w.metrics, metric)
+		w.byName[name] = metric
+	}
+
+	var sb strings.Builder
+
+	sb.WriteString(name)
+
+	if len(labels) > 0 {
+		sb.WriteString("{")
+
+		for i := 0; i+1 < len(labels); i += 2 {
+			if i > 0 {
+				sb.WriteString(",")
+			}
+
+			fmt.Fprintf(&sb, "%s=\"%s\"", labels[i], escapeLabelValue(labels[i+1]))
+		}
+
+		sb.WriteString("}")
+	}
+
+	sb.WriteString(" ")
+	sb.WriteString(formatSampleValue(value))
+
+	metric.samples = append(metric.samples, sb.String())
+}
+
+// Bool returns 1 for true and 0 for false, for gauges of boolean states.
+func Bool(b bool) float64 {
+	if b {
+		return 1
+	}
+
+	return 0
+}
+
+func (w *PrometheusWriter) WriteTo(out io.Writer) (int64, error) {
+	var sb strings.Builder
+
+	for _, metric := range w.metrics {
+		fmt.Fprintf(&sb, "# HELP %s %s\n", metric.name, escapeHelp(metric.help))
+		fmt.Fprintf(&sb, "# TYPE %s gauge\n", metric.name)
+
+		for _, sample := range metric.samples {
+			sb.WriteString(sample)
+			sb.WriteString("\n")
+		}
+	}
+
+	n, err := io.WriteString(out, sb.String())
+
+	return int64(n), err
+}
+
+func escapeLabelValue(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s)
+}
+
+func escapeHelp(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "\n", `\n`).Replace(s)
+}
+
+func formatSampleValue(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	case v == math.Trunc(v) && math.Abs(v) < 1e15:
+		// Counts and timestamps are written without an exponent
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	}
+}
@@ -0,0 +1,50 @@
+package writers
+
+import (
+	"bytes"
+	"math"
+	"testing"
+)
+
+func TestPrometheusWriter(t *testing.T) {
+	w := NewPrometheusWriter()
+	w.Gauge("asvec_up", "Whether the last collection succeeded.", Bool(true))
+	w.Gauge("asvec_index_unmerged_records", "Unmerged records.", 10, "namespace", "test", "index", "a")
+	w.Gauge("asvec_node_info", "Node information.", 1, "version", "1.0.0\n\"beta\"\\")
+	w.Gauge("asvec_index_unmerged_records", "Unmerged records.", 0.25, "namespace", "test", "index", "b")
+	w.Gauge("asvec_index_size_bytes", "Estimated size.\nApproximate.", math.Inf(1))
+	w.Gauge("asvec_last_collect_timestamp_seconds", "Timestamp.", 1.792217605e+09)
+
+	var buf bytes.Buffer
+
+	n, err := w.WriteTo(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `# HELP asvec_up Whether the last collection succeeded.
+# TYPE asvec_up gauge
+asvec_up 1
+# HELP asvec_index_unmerged_records Unmerged records.
+# TYPE asvec_index_unmerged_records gauge
+asvec_index_unmerged_records{namespace="test",index="a"} 10
+asvec_index_unmerged_records{namespace="test",index="b"} 0.25
+# HELP asvec_node_info Node information.
+# TYPE asvec_node_info gauge
+asvec_node_info{version="1.0.0\n\"beta\"\\"} 1
+# HELP asvec_index_size_bytes Estimated size.\nApproximate.
+# TYPE asvec_index_size_bytes gauge
+asvec_index_size_bytes +Inf
+# HELP asvec_last_collect_timestamp_seconds Timestamp.
+# TYPE asvec_last_collect_timestamp_seconds gauge
+asvec_last_collect_timestamp_seconds 1792217605
+`
+
+	if buf.String() != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, buf.String())
+	}
+
+	if int(n) != buf.Len() {
+		t.Errorf("expected %d bytes written, got %d", buf.Len(), n)
+	}
+}
@@ -0,0 +1,112 @@
+package writers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"time"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+)
+
+// RecallResult is the recall measured for a single hnsw-ef value. A nil Ef
+// means the index's default was used.
+//
+//nolint:govet // Padding not a concern for a CLI
+type RecallResult struct {
+	Ef      *uint32
+	K       int
+	Queries int
+	Failed  int
+	Recall  float64
+	MRR     float64
+	P50     time.Duration
+	P90     time.Duration
+	P99     time.Duration
+	QPS     float64
+}
+
+// MarshalJSON reports latencies in milliseconds.
+func (r *RecallResult) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&struct {
+		Ef      *uint32 `json:"ef,omitempty"`
+		K       int     `json:"k"`
+		Queries int     `json:"queries"`
+		Failed  int     `json:"failed"`
+		Recall  float64 `json:"recall"`
+		MRR     float64 `json:"mrr"`
+		P50     float64 `json:"latencyP50Ms"`
+		P90     float64 `json:"latencyP90Ms"`
+		P99     float64 `json:"latencyP99Ms"`
+		QPS     float64 `json:"qps"`
+	}{
+		Ef:      r.Ef,
+		K:       r.K,
+		Queries: r.Queries,
+		Failed:  r.Failed,
+		Recall:  r.Recall,
+		MRR:     r.MRR,
+		P50:     durationMs(r.P50),
+		P90:     durationMs(r.P90),
+		P99:     durationMs(r.P99),
+		QPS:     r.QPS,
+	})
+}
+
+func durationMs(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+type RecallTableWriter struct {
+	table  table.Writer
+	logger *slog.Logger
+}
+
+func NewRecallTableWriter(writer io.Writer, k int, logger *slog.Logger) *RecallTableWriter {
+	t := RecallTableWriter{NewDefaultWriter(writer), logger}
+
+	t.table.SetTitle(fmt.Sprintf("Recall@%d", k))
+	t.table.AppendHeader(
+		table.Row{
+			"HNSW EF",
+			"Queries",
+			"Failed",
+			"Recall",
+			"MRR",
+			"P50",
+			"P90",
+			"P99",
+			"QPS",
+		},
+	)
+
+	return &t
+}
+
+func (itw *RecallTableWriter) AppendRecallRow(result *RecallResult) {
+	ef := "default"
+	if result.Ef != nil {
+		ef = fmt.Sprintf("%d", *result.Ef)
+	}
+
+	itw.table.AppendRow(table.Row{
+		ef,
+		result.Queries,
+		result.Failed,
+		fmt.Sprintf("%.4f", result.Recall),
+		fmt.Sprintf("%.4f", result.MRR),
+		result.P50.Round(time.Microsecond),
+		result.P90.Round(time.Microsecond),
+		result.P99.Round(time.Microsecond),
+		fmt.Sprintf("%.1f", result.QPS),
+	})
+}
+
+func (itw *RecallTableWriter) Render(renderFormat int) {
+	if renderFormat == RenderFormatCSV {
+		itw.table.RenderCSV()
+	} else {
+		itw.table.Render()
+	}
+}
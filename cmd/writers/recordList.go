@@ -0,0 +1,73 @@
+package writers
+
+import (
+	"io"
+	"log/slog"
+
+	"github.com/aerospike/avs-client-go"
+	"github.com/jedib0t/go-pretty/v6/table"
+)
+
+type RecordTableWriter struct {
+	table  table.Writer
+	logger *slog.Logger
+}
+
+func NewRecordTableWriter(writer io.Writer, logger *slog.Logger) *RecordTableWriter {
+	t := RecordTableWriter{NewDefaultWriter(writer), logger}
+
+	t.table.AppendHeader(
+		table.Row{
+			"Namespace",
+			"Set",
+			"Key",
+			"Expiration",
+			"Generation",
+			"Data",
+		},
+	)
+
+	t.table.SetTitle("Records")
+	t.table.SetAutoIndex(true)
+	t.table.SetColumnConfigs([]table.ColumnConfig{
+		{
+			Name:        "Expiration",
+			Transformer: removeNil,
+		},
+		{
+			Name:        "Set",
+			Transformer: removeNil,
+		},
+	})
+
+	t.table.Style().Options.SeparateRows = true
+
+	return &t
+}
+
+func (itw *RecordTableWriter) AppendRecordRow(
+	namespace string,
+	set *string,
+	key any,
+	record *avs.Record,
+	maxDataKeys,
+	renderFormat,
+	maxDataValueColWidth int,
+) {
+	itw.table.AppendRow(table.Row{
+		namespace,
+		set,
+		key,
+		record.Expiration,
+		record.Generation,
+		renderRecordData(record.Data, maxDataKeys, renderFormat, maxDataValueColWidth),
+	})
+}
+
+func (itw *RecordTableWriter) Render(renderFormat int) {
+	if renderFormat == RenderFormatCSV {
+		itw.table.RenderCSV()
+	} else {
+		itw.table.Render()
+	}
+}
@@ -10,21 +10,25 @@ import (
 )
 
 type UserTableWriter struct {
+	clusterColumn
 	table  table.Writer
 	logger *slog.Logger
 }
 
 func NewUserTableWriter(writer io.Writer, logger *slog.Logger) *UserTableWriter {
-	t := UserTableWriter{NewDefaultWriter(writer), logger}
+	t := UserTableWriter{table: NewDefaultWriter(writer), logger: logger}
 
-	t.table.AppendHeader(table.Row{"User", "Roles"}, rowConfigAutoMerge)
+	t.clusterColumn = newClusterColumn(t.table,
+		table.Row{"User", "Roles"},
+		[]table.SortBy{
+			{Name: "Roles", Mode: table.Asc},
+			{Name: "User", Mode: table.Asc},
+		},
+		rowConfigAutoMerge,
+	)
 
 	t.table.SetTitle("Users")
 	t.table.SetAutoIndex(true)
-	t.table.SortBy([]table.SortBy{
-		{Name: "Roles", Mode: table.Asc},
-		{Name: "User", Mode: table.Asc},
-	})
 
 	t.table.Style().Options.SeparateRows = true
 
@@ -32,7 +36,7 @@ func NewUserTableWriter(writer io.Writer, logger *slog.Logger) *UserTableWriter
 }
 
 func (itw *UserTableWriter) AppendUserRow(user *protos.User) {
-	itw.table.AppendRow(table.Row{user.GetUsername(), strings.Join(user.GetRoles(), ", ")})
+	itw.table.AppendRow(itw.row(table.Row{user.GetUsername(), strings.Join(user.GetRoles(), ", ")}))
 }
 
 func (itw *UserTableWriter) Render(renderFormat int) {
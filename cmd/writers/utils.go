@@ -67,6 +67,42 @@ func formatRole(role protos.NodeRole) string {
 	return role.String()
 }
 
+// renderRecordData renders a record's data as a Key/Value sub table. If
+// maxDataKeys is non-zero the output is truncated after that many keys.
+func renderRecordData(data map[string]any, maxDataKeys, renderFormat, maxDataValueColWidth int) string {
+	tData := NewDefaultWriter(nil)
+	tData.AppendHeader(table.Row{"Key", "Value"})
+	tData.SetColumnConfigs(
+		[]table.ColumnConfig{
+			{
+				Name:        "Value",
+				Transformer: vectorFormat,
+				WidthMax:    maxDataValueColWidth,
+			},
+		},
+	)
+
+	keys := make([]string, 0, len(data))
+
+	for key := range data {
+		keys = append(keys, key)
+	}
+
+	slices.Sort(keys)
+
+	for i, key := range keys {
+		if maxDataKeys != 0 && i >= maxDataKeys {
+			tData.AppendRow(table.Row{"...", "..."})
+
+			break
+		}
+
+		tData.AppendRow(table.Row{key, data[key]})
+	}
+
+	return renderTable(tData, renderFormat)
+}
+
 func renderTable(t table.Writer, format int) string {
 	if format == 0 {
 		return t.Render()
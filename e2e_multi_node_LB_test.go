@@ -57,7 +57,7 @@ func (suite *MultiNodeLBCmdTestSuite) TestNodeListCmd() {
 	}{
 		{
 			"node ls with LB and seeds",
-			fmt.Sprintf("node ls --format 1 --no-color --seeds %s", suite.AvsHostPort.String()),
+			fmt.Sprintf("node ls -o csv --no-color --seeds %s", suite.AvsHostPort.String()),
 			true,
 			`Nodes
 ,Node,Roles,Endpoint,Cluster ID,Version,Visible Nodes
@@ -76,7 +76,7 @@ Possible scenarios:
 		},
 		{
 			"node ls with LB and host",
-			fmt.Sprintf("node ls --format 1 --no-color --host %s", suite.AvsHostPort.String()),
+			fmt.Sprintf("node ls -o csv --no-color --host %s", suite.AvsHostPort.String()),
 			false,
 			`Nodes
 ,Node,Roles,Endpoint,Cluster ID,Version,Visible Nodes
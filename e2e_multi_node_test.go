@@ -53,7 +53,7 @@ func (suite *MultiNodeCmdTestSuite) TestNodeListCmd() {
 	}{
 		{
 			"node ls with multiple nodes and seeds",
-			fmt.Sprintf("node ls --format 1 --no-color --seeds %s", suite.AvsHostPort.String()),
+			fmt.Sprintf("node ls -o csv --no-color --seeds %s", suite.AvsHostPort.String()),
 			`Nodes
 ,Node,Roles,Endpoint,Cluster ID,Version,Visible Nodes
 1,139637976803088,[STANDALONE_INDEXER],127.0.0.1:10000,<cluster-id>,<version>,"{
@@ -3,16 +3,27 @@
 package main
 
 import (
+	"archive/tar"
 	"asvec/cmd/flags"
+	"asvec/cmd/writers"
 	"asvec/tests"
 	"bytes"
+	"compress/gzip"
 	"context"
 	"crypto/tls"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"os/exec"
+	"path"
+	"path/filepath"
 	"regexp"
 	"strconv"
 	"strings"
@@ -763,6 +774,433 @@ func (suite *CmdTestSuite) TestDropIndexFailsDoesNotExistCmd() {
 	suite.Assert().Contains(lines, "server error")
 }
 
+func (suite *CmdTestSuite) TestApplyCmd() {
+	ns := "test"
+	index := "apply-index"
+	manifestFile := suite.T().TempDir() + "/cluster.yaml"
+
+	defer suite.AvsClient.IndexDrop(context.Background(), ns, index)
+
+	writeManifest := func(dimensions, maxIndexRecords int, model string) {
+		err := os.WriteFile(manifestFile, []byte(fmt.Sprintf(`indices:
+  - id:
+      namespace: %s
+      name: %s
+    dimensions: %d
+    vectorDistanceMetric: COSINE
+    field: vector
+    labels:
+      model: %s
+    hnswParams:
+      batchingParams:
+        maxIndexRecords: %d
+`, ns, index, dimensions, model, maxIndexRecords)), 0o600)
+		suite.Require().NoError(err)
+	}
+
+	suite.Run("dry run does not create", func() {
+		writeManifest(10, 100000, "a")
+
+		lines, stderr, err := suite.RunSuiteCmd("apply", "-f", manifestFile, "--dry-run")
+		suite.Require().NoError(err, "stdout: %s stderr: %s", lines, stderr)
+		suite.Assert().Contains(lines, "+ create index test.apply-index")
+
+		_, err = suite.AvsClient.IndexGet(context.Background(), ns, index, false)
+		suite.Assert().Error(err)
+	})
+
+	suite.Run("create", func() {
+		lines, stderr, err := suite.RunSuiteCmd("apply", "-f", manifestFile, "-y")
+		suite.Require().NoError(err, "stdout: %s stderr: %s", lines, stderr)
+		suite.Assert().Contains(lines, "Successfully applied all changes")
+
+		actual, err := suite.AvsClient.IndexGet(context.Background(), ns, index, false)
+		suite.Require().NoError(err)
+		suite.Assert().Equal(uint32(10), actual.GetDimensions())
+	})
+
+	suite.Run("no changes", func() {
+		lines, stderr, err := suite.RunSuiteCmd("apply", "-f", manifestFile, "-y")
+		suite.Require().NoError(err, "stdout: %s stderr: %s", lines, stderr)
+		suite.Assert().Contains(lines, "No changes to apply")
+	})
+
+	suite.Run("update", func() {
+		writeManifest(10, 50000, "b")
+
+		lines, stderr, err := suite.RunSuiteCmd("apply", "-f", manifestFile, "-y")
+		suite.Require().NoError(err, "stdout: %s stderr: %s", lines, stderr)
+		suite.Assert().Contains(lines, "hnswParams.batchingParams.maxIndexRecords: 100000 -> 50000")
+		suite.Assert().Contains(lines, "labels: {model=a} -> {model=b}")
+
+		suite.Assert().Eventually(func() bool {
+			actual, err := suite.AvsClient.IndexGet(context.Background(), ns, index, false)
+			return err == nil &&
+				actual.GetHnswParams().GetBatchingParams().GetMaxIndexRecords() == 50000 &&
+				actual.GetLabels()["model"] == "b"
+		}, time.Second*10, time.Second)
+	})
+
+	suite.Run("destructive change is not applied", func() {
+		writeManifest(20, 50000, "b")
+
+		lines, stderr, err := suite.RunSuiteCmd("apply", "-f", manifestFile, "-y")
+		suite.Assert().Error(err, "stdout: %s stderr: %s", lines, stderr)
+		suite.Assert().Contains(lines, "! dimensions: 10 -> 20")
+		suite.Assert().Contains(stderr, "will not be applied")
+
+		actual, err := suite.AvsClient.IndexGet(context.Background(), ns, index, false)
+		suite.Require().NoError(err)
+		suite.Assert().Equal(uint32(10), actual.GetDimensions())
+	})
+}
+
+func (suite *CmdTestSuite) TestIndexDiffCmd() {
+	ns := "test"
+	index := "diff-index"
+
+	err := suite.AvsClient.IndexCreateFromIndexDef(context.Background(), tests.NewIndexDefinitionBuilder(false,
+		index, ns, 10, protos.VectorDistanceMetric_COSINE, "vector",
+	).Build())
+	suite.Require().NoError(err)
+
+	defer suite.AvsClient.IndexDrop(context.Background(), ns, index)
+
+	lines, stderr, err := suite.RunSuiteCmd("index", "list", "--yaml")
+	suite.Require().NoError(err, "stdout: %s stderr: %s", lines, stderr)
+
+	indexFile := suite.T().TempDir() + "/indexes.yaml"
+
+	suite.Run("file without drift", func() {
+		suite.Require().NoError(os.WriteFile(indexFile, []byte(lines), 0o600))
+
+		stdout, stderr, err := suite.RunSuiteCmd("index", "diff", "-f", indexFile, "-n", ns, "-i", index)
+		suite.Require().NoError(err, "stdout: %s stderr: %s", stdout, stderr)
+		suite.Assert().Contains(stdout, "No differences found in 1 indexes")
+	})
+
+	suite.Run("file with drift", func() {
+		drifted := strings.Replace(lines, "dimensions: 10", "dimensions: 20", 1)
+		suite.Require().NoError(os.WriteFile(indexFile, []byte(drifted), 0o600))
+
+		stdout, stderr, err := suite.RunSuiteCmd("index", "diff", "-f", indexFile, "-n", ns, "-i", index)
+		suite.Assert().Error(err, "stdout: %s stderr: %s", stdout, stderr)
+		suite.Assert().Contains(stdout, "dimensions: 10 -> 20")
+		suite.Assert().Contains(stderr, "1 of 1 indexes differ")
+	})
+
+	suite.Run("same cluster", func() {
+		cmd := fmt.Sprintf(
+			"index diff --config-file tests/asvec_.yml --cluster-name %s --compare-cluster-name %s",
+			suite.configFileClusterName, suite.configFileClusterName,
+		)
+
+		stdout, stderr, err := suite.RunCmd(strings.Split(cmd, " ")...)
+		suite.Require().NoError(err, "stdout: %s stderr: %s", stdout, stderr)
+		suite.Assert().Contains(stdout, "No differences found")
+	})
+}
+
+func (suite *CmdTestSuite) TestIndexWaitCmd() {
+	ns := "test"
+	set := "wait"
+	index := "wait-index"
+
+	stdout, stderr, err := suite.RunSuiteCmd(strings.Split(
+		fmt.Sprintf("index create -y -n %s -i %s -d 3 -m SQUARED_EUCLIDEAN -f vec -s %s --wait", ns, index, set),
+		" ",
+	)...)
+	suite.Require().NoError(err, "stdout: %s stderr: %s", stdout, stderr)
+	suite.Assert().Contains(stdout, fmt.Sprintf("Index %s.%s is ready after", ns, index))
+
+	defer suite.AvsClient.IndexDrop(context.Background(), ns, index)
+
+	for i := 0; i < 10; i++ {
+		err = suite.AvsClient.Upsert(
+			context.Background(), ns, &set, i, map[string]any{"vec": []float32{float32(i), 1, 2}}, false,
+		)
+		suite.Require().NoError(err)
+	}
+
+	suite.Run("until merged", func() {
+		stdout, stderr, err := suite.RunSuiteCmd(strings.Split(
+			fmt.Sprintf("index wait -n %s -i %s --until merged --wait-timeout 1m", ns, index), " ",
+		)...)
+		suite.Require().NoError(err, "stdout: %s stderr: %s", stdout, stderr)
+		suite.Assert().Contains(stderr, fmt.Sprintf("Waiting for index %s.%s to be merged", ns, index))
+		suite.Assert().Contains(stdout, fmt.Sprintf("Index %s.%s is merged after", ns, index))
+	})
+
+	suite.Run("invalid condition", func() {
+		stdout, stderr, err := suite.RunSuiteCmd(strings.Split(
+			fmt.Sprintf("index wait -n %s -i %s --until done", ns, index), " ",
+		)...)
+		suite.Assert().Error(err, "stdout: %s stderr: %s", stdout, stderr)
+		suite.Assert().Contains(stderr, "unrecognized wait condition")
+	})
+
+	suite.Run("index not found", func() {
+		stdout, stderr, err := suite.RunSuiteCmd(strings.Split(
+			fmt.Sprintf("index wait -n %s -i does-not-exist --wait-timeout 1m", ns), " ",
+		)...)
+		suite.Assert().Error(err, "stdout: %s stderr: %s", stdout, stderr)
+		suite.Assert().Contains(stderr, "NotFound")
+	})
+}
+
+func (suite *CmdTestSuite) TestIndexTopCmd() {
+	ns := "test"
+	index := "top-index"
+
+	err := suite.AvsClient.IndexCreateFromIndexDef(context.Background(), tests.NewIndexDefinitionBuilder(false,
+		index, ns, 10, protos.VectorDistanceMetric_COSINE, "vector",
+	).Build())
+	suite.Require().NoError(err)
+
+	defer suite.AvsClient.IndexDrop(context.Background(), ns, index)
+
+	stdout, stderr, err := suite.RunSuiteCmd(strings.Split(
+		fmt.Sprintf("index top -n %s -i %s --interval 200ms --iterations 3", ns, index), " ",
+	)...)
+	suite.Require().NoError(err, "stdout: %s stderr: %s", stdout, stderr)
+	suite.Assert().Equal(3, strings.Count(stdout, "Index Status"))
+	suite.Assert().Contains(stdout, index)
+	suite.Assert().Contains(stdout, "0.0/s")
+
+	stdout, stderr, err = suite.RunSuiteCmd("index", "top", "--interval", "0s")
+	suite.Assert().Error(err, "stdout: %s stderr: %s", stdout, stderr)
+	suite.Assert().Contains(stderr, "--interval must be greater than 0")
+}
+
+func (suite *CmdTestSuite) TestFailIfCmd() {
+	ns := "test"
+	index := "fail-if-index"
+
+	err := suite.AvsClient.IndexCreateFromIndexDef(context.Background(), tests.NewIndexDefinitionBuilder(false,
+		index, ns, 10, protos.VectorDistanceMetric_COSINE, "vector",
+	).Build())
+	suite.Require().NoError(err)
+
+	defer suite.AvsClient.IndexDrop(context.Background(), ns, index)
+
+	suite.Run("index status", func() {
+		stdout, stderr, err := suite.RunSuiteCmd(strings.Split(
+			fmt.Sprintf("index status -n %s -i %s --fail-if unmerged>100000", ns, index), " ",
+		)...)
+		suite.Require().NoError(err, "stdout: %s stderr: %s", stdout, stderr)
+		suite.Assert().Contains(stdout, fmt.Sprintf("Index %s.%s", ns, index))
+		suite.Assert().NotContains(stderr, "Alert")
+	})
+
+	suite.Run("condition tripped", func() {
+		stdout, stderr, err := suite.RunSuiteCmd(strings.Split(
+			fmt.Sprintf("index status -n %s -i %s --fail-if unmerged>=0", ns, index), " ",
+		)...)
+
+		var exitErr *exec.ExitError
+
+		suite.Require().ErrorAs(err, &exitErr, "stdout: %s stderr: %s", stdout, stderr)
+		suite.Assert().Equal(2, exitErr.ExitCode())
+		suite.Assert().Contains(stderr, fmt.Sprintf("Alert: %s.%s: unmerged>=0", ns, index))
+	})
+
+	suite.Run("index ls", func() {
+		stdout, stderr, err := suite.RunSuiteCmd("index", "ls", "--fail-if", "vertices<0", "-o", "jsonl")
+		suite.Require().NoError(err, "stdout: %s stderr: %s", stdout, stderr)
+
+		stdout, stderr, err = suite.RunSuiteCmd("index", "ls", "--fail-if", "name=="+index)
+
+		var exitErr *exec.ExitError
+
+		suite.Require().ErrorAs(err, &exitErr, "stdout: %s stderr: %s", stdout, stderr)
+		suite.Assert().Equal(2, exitErr.ExitCode())
+	})
+
+	suite.Run("node ls", func() {
+		stdout, stderr, err := suite.RunSuiteCmd("node", "ls", "--fail-if", "visible-nodes<0")
+		suite.Require().NoError(err, "stdout: %s stderr: %s", stdout, stderr)
+	})
+
+	suite.Run("unknown field", func() {
+		stdout, stderr, err := suite.RunSuiteCmd("node", "ls", "--fail-if", "unmerged>1")
+		suite.Assert().Error(err, "stdout: %s stderr: %s", stdout, stderr)
+		suite.Assert().Contains(stderr, "unknown --fail-if field")
+	})
+}
+
+func (suite *CmdTestSuite) TestExporterCmd() {
+	ns := "test"
+	index := "exporter-index"
+
+	err := suite.AvsClient.IndexCreateFromIndexDef(context.Background(), tests.NewIndexDefinitionBuilder(false,
+		index, ns, 10, protos.VectorDistanceMetric_COSINE, "vector",
+	).Build())
+	suite.Require().NoError(err)
+
+	defer suite.AvsClient.IndexDrop(context.Background(), ns, index)
+
+	cmd := suite.GetCmd(suite.AddSuiteArgs("exporter", "--listen", "127.0.0.1:19145", "--interval", "1s")...)
+	suite.Require().NoError(cmd.Start())
+
+	defer func() {
+		_ = cmd.Process.Signal(os.Interrupt)
+		_ = cmd.Wait()
+	}()
+
+	var body string
+
+	suite.Require().Eventually(func() bool {
+		resp, err := http.Get("http://127.0.0.1:19145/metrics")
+		if err != nil {
+			return false
+		}
+		defer resp.Body.Close()
+
+		b, err := io.ReadAll(resp.Body)
+		body = string(b)
+
+		return err == nil && strings.Contains(body, "asvec_up 1")
+	}, 30*time.Second, 500*time.Millisecond)
+
+	suite.Assert().Contains(body, "asvec_node_info{")
+	suite.Assert().Contains(body, fmt.Sprintf(`asvec_index_unmerged_records{namespace="%s",index="%s"}`, ns, index))
+	suite.Assert().Contains(body, fmt.Sprintf(`asvec_index_status{namespace="%s",index="%s",status="READY"} 1`, ns, index))
+}
+
+func (suite *CmdTestSuite) TestClusterDoctorCmd() {
+	// Other checks depend on how the suite's cluster is deployed so only the
+	// shape of the report and the connectivity check are asserted.
+	lines, stderr, _ := suite.RunSuiteCmd(strings.Split("cluster doctor -o json", " ")...)
+
+	report := writers.DoctorReport{}
+	suite.Require().NoError(json.Unmarshal([]byte(lines), &report), "stdout: %s stderr: %s", lines, stderr)
+	suite.Require().Len(report.Checks, 10)
+	suite.Assert().Equal("connectivity", report.Checks[0].Name)
+	suite.Assert().Equal(writers.DoctorPass, report.Checks[0].Status, report.Checks[0].Message)
+	suite.Assert().Equal(len(report.Checks),
+		report.Summary.Pass+report.Summary.Warn+report.Summary.Fail+report.Summary.Skip)
+}
+
+func (suite *CmdTestSuite) TestCollectInfoCmd() {
+	file := filepath.Join(suite.T().TempDir(), "support.tar.gz")
+
+	lines, stderr, err := suite.RunSuiteCmd("collectinfo", "--file", file)
+	suite.Require().NoError(err, "stdout: %s stderr: %s", lines, stderr)
+	suite.Assert().Contains(lines, "Wrote support bundle to "+file)
+
+	f, err := os.Open(file)
+	suite.Require().NoError(err)
+
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	suite.Require().NoError(err)
+
+	names := []string{}
+	tr := tar.NewReader(gz)
+
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		suite.Require().NoError(err)
+
+		names = append(names, path.Base(header.Name))
+	}
+
+	suite.Assert().Subset(names, []string{"collectinfo.json", "config.json", "nodes.json", "indexes.json"})
+}
+
+func (suite *CmdTestSuite) TestStructuredOutputCmd() {
+	suite.CleanUpIndexes(context.Background())
+
+	index := tests.NewIndexDefinitionBuilder(false,
+		"structured", "test", 3, protos.VectorDistanceMetric_COSINE, "vector",
+	).Build()
+
+	err := suite.AvsClient.IndexCreateFromIndexDef(context.Background(), index)
+	suite.Require().NoError(err)
+
+	defer suite.AvsClient.IndexDrop(context.Background(), "test", "structured")
+
+	suite.Run("index list json", func() {
+		lines, stderr, err := suite.RunSuiteCmd(strings.Split("index list -o json", " ")...)
+		suite.Require().NoError(err, "stdout: %s stderr: %s", lines, stderr)
+
+		result := []map[string]map[string]any{}
+		suite.Require().NoError(json.Unmarshal([]byte(lines), &result))
+		suite.Require().Len(result, 1)
+		suite.Assert().Equal("vector", result[0]["definition"]["field"])
+		suite.Assert().Contains(result[0]["status"], "unmergedRecordCount")
+	})
+
+	suite.Run("index list jsonl", func() {
+		lines, stderr, err := suite.RunSuiteCmd(strings.Split("index list -o jsonl", " ")...)
+		suite.Require().NoError(err, "stdout: %s stderr: %s", lines, stderr)
+		suite.Assert().Len(strings.Split(strings.TrimSpace(lines), "\n"), 1)
+	})
+
+	suite.Run("index list yaml", func() {
+		lines, stderr, err := suite.RunSuiteCmd(strings.Split("index list -o yaml", " ")...)
+		suite.Require().NoError(err, "stdout: %s stderr: %s", lines, stderr)
+		suite.Assert().Contains(lines, "- definition:")
+		suite.Assert().Contains(lines, "field: vector")
+	})
+
+	suite.Run("role list json", func() {
+		lines, stderr, err := suite.RunSuiteCmd(strings.Split("role list -o json", " ")...)
+		suite.Require().NoError(err, "stdout: %s stderr: %s", lines, stderr)
+
+		result := []map[string]any{}
+		suite.Require().NoError(json.Unmarshal([]byte(lines), &result))
+		suite.Assert().Contains(result, map[string]any{"id": "admin"})
+	})
+
+	suite.Run("user list json has no hints", func() {
+		lines, stderr, err := suite.RunSuiteCmd(strings.Split("user list -o json", " ")...)
+		suite.Require().NoError(err, "stdout: %s stderr: %s", lines, stderr)
+
+		result := []map[string]any{}
+		suite.Require().NoError(json.Unmarshal([]byte(lines), &result))
+		suite.Assert().NotEmpty(result)
+	})
+
+	suite.Run("node list json", func() {
+		lines, stderr, err := suite.RunSuiteCmd(strings.Split("node list -o json", " ")...)
+		suite.Require().NoError(err, "stdout: %s stderr: %s", lines, stderr)
+
+		result := []map[string]any{}
+		suite.Require().NoError(json.Unmarshal([]byte(lines), &result))
+		suite.Require().NotEmpty(result)
+		suite.Assert().Contains(result[0], "about")
+	})
+
+	suite.Run("index list jsonpath", func() {
+		lines, stderr, err := suite.RunSuiteCmd("index", "list", "-o", "jsonpath={[*].definition.id.name}")
+		suite.Require().NoError(err, "stdout: %s stderr: %s", lines, stderr)
+		suite.Assert().Equal("structured", lines)
+	})
+
+	suite.Run("index list go-template", func() {
+		lines, stderr, err := suite.RunSuiteCmd("index", "list", "-o", `go-template={{range .}}{{.definition.id.name}}={{.status.unmergedRecordCount}}{{"\n"}}{{end}}`)
+		suite.Require().NoError(err, "stdout: %s stderr: %s", lines, stderr)
+		suite.Assert().Regexp(`^structured=\d+\n$`, lines)
+	})
+
+	suite.Run("node list jsonpath", func() {
+		lines, stderr, err := suite.RunSuiteCmd("node", "list", "-o", "jsonpath={[*].about.version}")
+		suite.Require().NoError(err, "stdout: %s stderr: %s", lines, stderr)
+		suite.Assert().NotEmpty(lines)
+	})
+
+	suite.Run("invalid template", func() {
+		lines, stderr, err := suite.RunSuiteCmd("index", "list", "-o", "jsonpath={.a")
+		suite.Assert().Error(err, "stdout: %s stderr: %s", lines, stderr)
+	})
+}
+
 func removeANSICodes(input string) string {
 	re := regexp.MustCompile(`\x1b[^m]*m`)
 	return re.ReplaceAllString(input, "")
@@ -784,7 +1222,7 @@ func (suite *CmdTestSuite) TestSuccessfulListIndexCmd() {
 					"list", "test", 256, protos.VectorDistanceMetric_COSINE, "vector",
 				).Build(),
 			},
-			cmd: "index list --no-color --format 1",
+			cmd: "index list --no-color -o csv",
 			expectedTable: `Indexes
 ,Name,Namespace,Field,Dimensions,Distance Metric,Unmerged,Vector Records,Size,Unmerged %,Mode*,Status
 1,list,test,vector,256,COSINE,0,0,0 B,0%,DISTRIBUTED,READY
@@ -800,7 +1238,7 @@ func (suite *CmdTestSuite) TestSuccessfulListIndexCmd() {
 					"list2", "bar", 256, protos.VectorDistanceMetric_HAMMING, "vector",
 				).WithSet("barset").Build(),
 			},
-			cmd: "index list --no-color --format 1",
+			cmd: "index list --no-color -o csv",
 			expectedTable: `Indexes
 ,Name,Namespace,Set,Field,Dimensions,Distance Metric,Unmerged,Vector Records,Size,Unmerged %,Mode*,Status
 1,list2,bar,barset,vector,256,HAMMING,0,0,0 B,0%,DISTRIBUTED,READY
@@ -830,7 +1268,7 @@ func (suite *CmdTestSuite) TestSuccessfulListIndexCmd() {
 					WithHnswRecordCacheMaxEntries(1002).
 					Build(),
 			},
-			cmd: "index list --verbose --no-color --format 1",
+			cmd: "index list --verbose --no-color -o csv",
 			expectedTable: `Indexes
 ,Name,Namespace,Set,Field,Dimensions,Distance Metric,Unmerged,Vector Records,Size,Unmerged %,Mode*,Status,Vertices,Labels*,Storage,Index Parameters,Standalone Index Metrics
 1,list2,bar,barset,vector,256,HAMMING,0,0,0 B,0%,DISTRIBUTED,READY,0,map[],"Namespace\,bar
@@ -1217,7 +1655,7 @@ func (suite *CmdTestSuite) TestSuccessfulListUsersCmd() {
 	}{
 		{
 			name: "users list",
-			cmd:  "users list --no-color --format 1",
+			cmd:  "users list --no-color -o csv",
 			expectedTable: `Users
 ,User,Roles
 1,admin,"admin\, read-write"
@@ -1467,7 +1905,7 @@ func (suite *CmdTestSuite) TestSuccessfulQueryCmd() {
 		{
 			name:    "run query with zero vector",
 			records: records,
-			cmd:     fmt.Sprintf("query -i %s -n test --max-results 3 --fields str,int,float,float32-str,map --no-color --format 1", strIndexName),
+			cmd:     fmt.Sprintf("query -i %s -n test --max-results 3 --fields str,int,float,float32-str,map --no-color -o csv", strIndexName),
 			expectedTable: `Query Results
 ,Namespace,Key,Distance,Generation,Data
 1,test,a,0,0,"Key\,Value
@@ -1485,7 +1923,7 @@ float32-str\,\"[0.0\\,0.0\\,0.0\\,0.0\\,0.0\\,0.0\\,0.0\\,0.0\\,0.0\\,2.0]\""
 		{
 			name:    "run query with custom float32 vector",
 			records: records,
-			cmd:     fmt.Sprintf("query -i %s -n test --vector [0.0,0.0,0.0,0.0,0.0,0.0,0.0,0.0,0.0,1.0]  --no-color --format 1", strIndexName),
+			cmd:     fmt.Sprintf("query -i %s -n test --vector [0.0,0.0,0.0,0.0,0.0,0.0,0.0,0.0,0.0,1.0]  --no-color -o csv", strIndexName),
 			expectedTable: `Query Results
 ,Namespace,Key,Distance,Generation,Data
 1,test,b,0,0,"Key\,Value
@@ -1510,7 +1948,7 @@ Hint: To choose which record keys are displayed, use the --fields flag. By defau
 		{
 			name:    "run query with custom bool vector",
 			records: records,
-			cmd:     fmt.Sprintf("query -i %s -n test --vector [0,0,0,0,0,0,0,0,0,1]  --no-color --format 1", boolIndexName),
+			cmd:     fmt.Sprintf("query -i %s -n test --vector [0,0,0,0,0,0,0,0,0,1]  --no-color -o csv", boolIndexName),
 			expectedTable: `Query Results
 ,Namespace,Key,Distance,Generation,Data
 1,test,10,8,0,"Key\,Value
@@ -1525,7 +1963,7 @@ Hint: To choose which record keys are displayed, use the --fields flag. By defau
 		}, {
 			name:    "run query with using int key with bool vector",
 			records: records,
-			cmd:     fmt.Sprintf("query -i %s -n test --key-int 10  --no-color --format 1", boolIndexName),
+			cmd:     fmt.Sprintf("query -i %s -n test --key-int 10  --no-color -o csv", boolIndexName),
 			expectedTable: `Query Results
 ,Namespace,Key,Distance,Generation,Data
 1,test,11,1,0,"Key\,Value
@@ -1539,7 +1977,7 @@ Hint: To choose which record keys are displayed, use the --fields flag. By defau
 		{
 			name:    "run query with using str key",
 			records: records,
-			cmd:     fmt.Sprintf("query -i %s -n test -k b --no-color --format 1", strIndexName),
+			cmd:     fmt.Sprintf("query -i %s -n test -k b --no-color -o csv", strIndexName),
 			expectedTable: `Query Results
 ,Namespace,Key,Distance,Generation,Data
 1,test,a,1,0,"Key\,Value
@@ -1559,12 +1997,23 @@ float32-str\,\"[0.0\\,0.0\\,0.0\\,0.0\\,0.0\\,0.0\\,0.0\\,0.0\\,0.0\\,4.0]\""
 float32-str\,\"[0.0\\,0.0\\,0.0\\,0.0\\,0.0\\,0.0\\,0.0\\,0.0\\,0.0\\,5.0]\""
 Hint: To increase the number of records returned, use the --max-results flag.
 Hint: To choose which record keys are displayed, use the --fields flag. By default only 5 are displayed.
+`,
+		},
+		{
+			name:    "run query with a filter",
+			records: records,
+			cmd:     fmt.Sprintf("query -i %s -n test -k b --filter int>=1 -f str --no-color -o csv", strIndexName),
+			expectedTable: `Query Results
+,Namespace,Key,Distance,Generation,Data
+1,test,a,1,0,"Key\,Value
+str\,a"
+Hint: To increase the number of records returned, use the --max-results flag.
 `,
 		},
 		{
 			name:    "run query with using int key",
 			records: records,
-			cmd:     fmt.Sprintf("query -i %s -n test -t 1 --no-color --format 1", intIndexName),
+			cmd:     fmt.Sprintf("query -i %s -n test -t 1 --no-color -o csv", intIndexName),
 			expectedTable: `Query Results
 ,Namespace,Key,Distance,Generation,Data
 1,test,0,1,0,"Key\,Value
@@ -1598,6 +2047,437 @@ Hint: To choose which record keys are displayed, use the --fields flag. By defau
 	}
 }
 
+func (suite *CmdTestSuite) TestBatchQueryCmd() {
+	ns := "test"
+	set := "batch-query"
+	index := "batch-query"
+
+	err := suite.AvsClient.IndexCreate(
+		context.Background(), ns, index, "vec", uint32(3), protos.VectorDistanceMetric_SQUARED_EUCLIDEAN,
+		&avs.IndexCreateOpts{Sets: []string{set}},
+	)
+	suite.Require().NoError(err)
+
+	defer suite.AvsClient.IndexDrop(context.Background(), ns, index)
+
+	for i := 0; i < 5; i++ {
+		err = suite.AvsClient.Upsert(
+			context.Background(), ns, &set, int64(i), map[string]any{"vec": []float32{float32(i), 0, 0}}, false,
+		)
+		suite.Require().NoError(err)
+	}
+
+	err = suite.AvsClient.WaitForIndexCompletion(context.Background(), ns, index, time.Second*12)
+	suite.Require().NoError(err)
+
+	queryFile := suite.T().TempDir() + "/queries.jsonl"
+
+	err = os.WriteFile(queryFile, []byte(`{"id": "q1", "vector": [0, 0, 0]}
+{"id": "q2", "key": 4}
+{"id": "q3"}
+`), 0o600)
+	suite.Require().NoError(err)
+
+	lines, stderr, err := suite.RunSuiteCmd(strings.Split(
+		fmt.Sprintf("query -n %s -i %s --query-file %s -r 2 -o jsonl", ns, index, queryFile),
+		" ",
+	)...)
+	suite.Assert().Error(err, "stdout: %s stderr: %s", lines, stderr)
+	suite.Assert().Contains(stderr, "1 of 3 queries failed")
+
+	results := strings.Split(strings.TrimSpace(lines), "\n")
+	suite.Require().Len(results, 3)
+	suite.Assert().Contains(results[0], `"id":"q1"`)
+	suite.Assert().Contains(results[0], `"key":0`)
+	suite.Assert().Contains(results[1], `"id":"q2"`)
+	suite.Assert().Contains(results[1], `"key":3`)
+	suite.Assert().NotContains(results[1], `"key":4`)
+	suite.Assert().Contains(results[2], `"id":"q3"`)
+	suite.Assert().Contains(results[2], `"error"`)
+}
+
+func (suite *CmdTestSuite) TestQueryPostProcessingCmd() {
+	ns := "test"
+	set := "post-processing"
+	index := "post-processing"
+
+	err := suite.AvsClient.IndexCreate(
+		context.Background(), ns, index, "vec", uint32(3), protos.VectorDistanceMetric_SQUARED_EUCLIDEAN,
+		&avs.IndexCreateOpts{Sets: []string{set}},
+	)
+	suite.Require().NoError(err)
+
+	defer suite.AvsClient.IndexDrop(context.Background(), ns, index)
+
+	// Two chunks of each document.
+	for i := 0; i < 10; i++ {
+		err = suite.AvsClient.Upsert(
+			context.Background(), ns, &set, int64(i),
+			map[string]any{"vec": []float32{float32(i), 0, 0}, "doc": int64(i / 2)}, false,
+		)
+		suite.Require().NoError(err)
+	}
+
+	err = suite.AvsClient.WaitForIndexCompletion(context.Background(), ns, index, time.Second*12)
+	suite.Require().NoError(err)
+
+	query := func(args string) *writers.QueryResult {
+		stdout, stderr, err := suite.RunSuiteCmd(strings.Split(
+			fmt.Sprintf("query -n %s -i %s -v [0.0,0.0,0.0] -o json %s", ns, index, args),
+			" ",
+		)...)
+		suite.Require().NoError(err, "stdout: %s stderr: %s", stdout, stderr)
+
+		result := &writers.QueryResult{}
+		suite.Require().NoError(json.Unmarshal([]byte(stdout), result))
+
+		return result
+	}
+
+	keys := func(result *writers.QueryResult) []any {
+		keys := []any{}
+		for _, n := range result.Neighbors {
+			keys = append(keys, n.Key)
+		}
+
+		return keys
+	}
+
+	suite.Run("dedup by", func() {
+		result := query("-r 3 --dedup-by doc")
+		suite.Assert().Equal([]any{float64(0), float64(2), float64(4)}, keys(result))
+	})
+
+	suite.Run("max distance", func() {
+		result := query("-r 5 --max-distance 4")
+		suite.Assert().Equal([]any{float64(0), float64(1), float64(2)}, keys(result))
+	})
+
+	suite.Run("rerank exact", func() {
+		result := query("-r 3 --rerank exact")
+		suite.Require().Len(result.Neighbors, 3)
+
+		for i, n := range result.Neighbors {
+			suite.Assert().Equal(float64(i), n.Key)
+			suite.Require().NotNil(n.ExactDistance)
+			suite.Assert().InDelta(n.Distance, *n.ExactDistance, 0.001)
+		}
+	})
+}
+
+func (suite *CmdTestSuite) TestQueryTextCmd() {
+	ns := "test"
+	set := "query-text"
+	index := "query-text"
+
+	err := suite.AvsClient.IndexCreate(
+		context.Background(), ns, index, "vec", uint32(3), protos.VectorDistanceMetric_SQUARED_EUCLIDEAN,
+		&avs.IndexCreateOpts{Sets: []string{set}, Labels: map[string]string{"model": "stub-model"}},
+	)
+	suite.Require().NoError(err)
+
+	defer suite.AvsClient.IndexDrop(context.Background(), ns, index)
+
+	for i := 0; i < 10; i++ {
+		err = suite.AvsClient.Upsert(
+			context.Background(), ns, &set, int64(i), map[string]any{"vec": []float32{float32(i), 0, 0}}, false,
+		)
+		suite.Require().NoError(err)
+	}
+
+	err = suite.AvsClient.WaitForIndexCompletion(context.Background(), ns, index, time.Second*12)
+	suite.Require().NoError(err)
+
+	// An OpenAI compatible endpoint embedding each number word as a vector
+	// along the first axis.
+	words := map[string]float32{"zero": 0, "five": 5, "eight": 8}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Model string `json:"model"`
+			Input string `json:"input"`
+		}
+
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Model != "stub-model" {
+			http.Error(w, `{"error":{"message":"bad request"}}`, http.StatusBadRequest)
+			return
+		}
+
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"data": []map[string]any{{"embedding": []float32{words[req.Input], 0, 0}}},
+		})
+	}))
+	defer server.Close()
+
+	script := filepath.Join(suite.T().TempDir(), "embed.sh")
+	err = os.WriteFile(script, []byte(`#!/bin/sh
+cat > /dev/null
+[ "$ASVEC_EMBEDDING_MODEL" = stub-model ] || exit 1
+echo "[9, 0, 0]"
+`), 0o700)
+	suite.Require().NoError(err)
+
+	badScript := filepath.Join(suite.T().TempDir(), "embed.sh")
+	err = os.WriteFile(badScript, []byte("#!/bin/sh\necho [1,2]\n"), 0o700)
+	suite.Require().NoError(err)
+
+	query := func(args string) (*writers.QueryResult, string, error) {
+		stdout, stderr, err := suite.RunSuiteCmd(strings.Split(
+			fmt.Sprintf("query -n %s -i %s -r 1 -o json %s", ns, index, args),
+			" ",
+		)...)
+		if err != nil {
+			return nil, stderr, err
+		}
+
+		result := &writers.QueryResult{}
+		suite.Require().NoError(json.Unmarshal([]byte(stdout), result))
+
+		return result, stderr, nil
+	}
+
+	suite.Run("embedding url", func() {
+		result, stderr, err := query("--text five --embedding-url " + server.URL + "/v1")
+		suite.Require().NoError(err, stderr)
+		suite.Require().Len(result.Neighbors, 1)
+		suite.Assert().Equal(float64(5), result.Neighbors[0].Key)
+	})
+
+	suite.Run("embedding command", func() {
+		result, stderr, err := query("--text anything --embedding-command " + script)
+		suite.Require().NoError(err, stderr)
+		suite.Require().Len(result.Neighbors, 1)
+		suite.Assert().Equal(float64(9), result.Neighbors[0].Key)
+	})
+
+	suite.Run("wrong dimensions", func() {
+		_, stderr, err := query("--text anything --embedding-command " + badScript)
+		suite.Assert().Error(err)
+		suite.Assert().Contains(stderr, "model stub-model returned a vector with 2 dimensions but the index has 3")
+	})
+
+	suite.Run("model override", func() {
+		_, stderr, err := query("--text five --embedding-model other --embedding-url " + server.URL + "/v1")
+		suite.Assert().Error(err)
+		suite.Assert().Contains(stderr, "400 Bad Request")
+	})
+
+	suite.Run("no provider", func() {
+		_, stderr, err := query("--text five")
+		suite.Assert().Error(err)
+		suite.Assert().Contains(stderr, "no embedding provider is configured for model stub-model")
+	})
+}
+
+func (suite *CmdTestSuite) TestQueryVectorInputCmd() {
+	ns := "test"
+	set := "vector-input"
+	index := "vector-input"
+
+	err := suite.AvsClient.IndexCreate(
+		context.Background(), ns, index, "vec", uint32(3), protos.VectorDistanceMetric_SQUARED_EUCLIDEAN,
+		&avs.IndexCreateOpts{Sets: []string{set}},
+	)
+	suite.Require().NoError(err)
+
+	defer suite.AvsClient.IndexDrop(context.Background(), ns, index)
+
+	for i := 0; i < 10; i++ {
+		err = suite.AvsClient.Upsert(
+			context.Background(), ns, &set, int64(i), map[string]any{"vec": []float32{float32(i), 0, 0}}, false,
+		)
+		suite.Require().NoError(err)
+	}
+
+	err = suite.AvsClient.WaitForIndexCompletion(context.Background(), ns, index, time.Second*12)
+	suite.Require().NoError(err)
+
+	vectorBytes := func(v []float32) []byte {
+		buf := &bytes.Buffer{}
+		suite.Require().NoError(binary.Write(buf, binary.LittleEndian, v))
+
+		return buf.Bytes()
+	}
+
+	dir := suite.T().TempDir()
+
+	jsonFile := filepath.Join(dir, "query.json")
+	suite.Require().NoError(os.WriteFile(jsonFile, []byte("[6.0,\n 0.0,\n 0.0]\n"), 0o600))
+
+	fvecsFile := filepath.Join(dir, "query.fvecs")
+	suite.Require().NoError(os.WriteFile(
+		fvecsFile, append(binary.LittleEndian.AppendUint32(nil, 3), vectorBytes([]float32{7, 0, 0})...), 0o600,
+	))
+
+	query := func(vector, stdin string) (*writers.QueryResult, string, error) {
+		cmd := suite.GetCmd(suite.AddSuiteArgs(strings.Split(
+			fmt.Sprintf("query -n %s -i %s -r 1 -o json -v %s", ns, index, vector),
+			" ",
+		)...)...)
+		cmd.Stdin = strings.NewReader(stdin)
+
+		stdout, stderr, err := suite.GetCmdOutput(cmd)
+		if err != nil {
+			return nil, stderr, err
+		}
+
+		result := &writers.QueryResult{}
+		suite.Require().NoError(json.Unmarshal([]byte(stdout), result))
+
+		return result, stderr, nil
+	}
+
+	testCases := []struct {
+		name        string
+		vector      string
+		stdin       string
+		expectedKey float64
+	}{
+		{"json file", "@" + jsonFile, "", 6},
+		{"fvecs file", "@" + fvecsFile, "", 7},
+		{"stdin", "-", "[3.0 0.0 0.0]\n", 3},
+		{"base64", "b64:" + base64.StdEncoding.EncodeToString(vectorBytes([]float32{8, 0, 0})), "", 8},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.name, func() {
+			result, stderr, err := query(tc.vector, tc.stdin)
+			suite.Require().NoError(err, stderr)
+			suite.Require().Len(result.Neighbors, 1)
+			suite.Assert().Equal(tc.expectedKey, result.Neighbors[0].Key)
+		})
+	}
+
+	suite.Run("wrong dimensions", func() {
+		_, stderr, err := query("[1.0,2.0]", "")
+		suite.Assert().Error(err)
+		suite.Assert().Contains(stderr, "the vector has 2 dimensions but index test.vector-input has 3")
+	})
+}
+
+func (suite *CmdTestSuite) TestIndexRecallCmd() {
+	ns := "test"
+	set := "recall"
+	index := "recall"
+
+	err := suite.AvsClient.IndexCreate(
+		context.Background(), ns, index, "vec", uint32(3), protos.VectorDistanceMetric_SQUARED_EUCLIDEAN,
+		&avs.IndexCreateOpts{Sets: []string{set}},
+	)
+	suite.Require().NoError(err)
+
+	defer suite.AvsClient.IndexDrop(context.Background(), ns, index)
+
+	base := ""
+
+	for i := 0; i < 10; i++ {
+		err = suite.AvsClient.Upsert(
+			context.Background(), ns, &set, int64(i), map[string]any{"vec": []float32{float32(i), 0, 0}}, false,
+		)
+		suite.Require().NoError(err)
+
+		base += fmt.Sprintf("{\"key\": %d, \"vec\": [%d, 0, 0]}\n", i, i)
+	}
+
+	err = suite.AvsClient.WaitForIndexCompletion(context.Background(), ns, index, time.Second*12)
+	suite.Require().NoError(err)
+
+	dir := suite.T().TempDir()
+	queryFile := dir + "/queries.jsonl"
+	baseFile := dir + "/base.jsonl"
+	truthFile := dir + "/truth.jsonl"
+
+	err = os.WriteFile(queryFile, []byte(`{"id": "q1", "vector": [0, 0, 0]}
+{"id": "q2", "vector": [9, 0, 0]}
+`), 0o600)
+	suite.Require().NoError(err)
+
+	err = os.WriteFile(baseFile, []byte(base), 0o600)
+	suite.Require().NoError(err)
+
+	err = os.WriteFile(truthFile, []byte(`{"id": "q1", "neighbors": [0, 1, 2]}
+{"id": "q2", "neighbors": [9, 8, 7]}
+`), 0o600)
+	suite.Require().NoError(err)
+
+	testCases := []struct {
+		name string
+		cmd  string
+	}{
+		{
+			name: "ground truth file",
+			cmd:  fmt.Sprintf("index recall -n %s -i %s --query-file %s --ground-truth %s -r 3 --hnsw-ef 10,20 -o jsonl", ns, index, queryFile, truthFile),
+		},
+		{
+			name: "computed ground truth",
+			cmd:  fmt.Sprintf("index recall -n %s -i %s --query-file %s --base-file %s -r 3 -o jsonl", ns, index, queryFile, baseFile),
+		},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.name, func() {
+			lines, stderr, err := suite.RunSuiteCmd(strings.Split(tc.cmd, " ")...)
+			suite.Require().NoError(err, "stdout: %s stderr: %s", lines, stderr)
+
+			for _, line := range strings.Split(strings.TrimSpace(lines), "\n") {
+				suite.Assert().Contains(line, `"recall":1`)
+				suite.Assert().Contains(line, `"mrr":1`)
+				suite.Assert().Contains(line, `"queries":2`)
+				suite.Assert().Contains(line, `"failed":0`)
+			}
+		})
+	}
+}
+
+func (suite *CmdTestSuite) TestBenchCmd() {
+	ns := "test"
+	set := "bench"
+	index := "bench"
+
+	err := suite.AvsClient.IndexCreate(
+		context.Background(), ns, index, "vec", uint32(8), protos.VectorDistanceMetric_COSINE,
+		&avs.IndexCreateOpts{Sets: []string{set}},
+	)
+	suite.Require().NoError(err)
+
+	defer suite.AvsClient.IndexDrop(context.Background(), ns, index)
+
+	timeSeriesFile := suite.T().TempDir() + "/series.csv"
+
+	testCases := []struct {
+		name      string
+		cmd       string
+		operation string
+	}{
+		{
+			name:      "write",
+			cmd:       fmt.Sprintf("bench write -n %s -i %s --duration 2s --concurrency 2 -o jsonl", ns, index),
+			operation: "write",
+		},
+		{
+			name:      "search with qps and time series",
+			cmd:       fmt.Sprintf("bench search -n %s -i %s --duration 2s --qps 20 --time-series-file %s -o jsonl", ns, index, timeSeriesFile),
+			operation: "search",
+		},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.name, func() {
+			lines, stderr, err := suite.RunSuiteCmd(strings.Split(tc.cmd, " ")...)
+			suite.Require().NoError(err, "stdout: %s stderr: %s", lines, stderr)
+
+			result := map[string]any{}
+			suite.Require().NoError(json.Unmarshal([]byte(lines), &result))
+			suite.Assert().Equal(tc.operation, result["operation"])
+			suite.Assert().Greater(result["requests"], float64(0))
+			suite.Assert().Equal(float64(0), result["errors"])
+		})
+	}
+
+	series, err := os.ReadFile(timeSeriesFile)
+	suite.Require().NoError(err)
+	suite.Assert().True(strings.HasPrefix(string(series), "second,requests,errors"))
+}
+
 func (suite *CmdTestSuite) TestFailedQueryCmd() {
 	namespace := "test"
 	indexName := "index"
@@ -1624,7 +2504,7 @@ func (suite *CmdTestSuite) TestFailedQueryCmd() {
 		{
 			name:           "use set without the key flag",
 			cmd:            "query --namespace test -i index --set testset",
-			expectedErrStr: "Warning: The --set flag is only used when the --key-str or --key-int flag is set.",
+			expectedErrStr: "Warning: The --set flag is only used when the --key-str, --key-int, or --query-file flag is set.",
 		},
 		{
 			name:           "try to query an index that does not exist",
@@ -1646,6 +2526,11 @@ func (suite *CmdTestSuite) TestFailedQueryCmd() {
 			cmd:            fmt.Sprintf("query --namespace %s -i %s -t 1234", namespace, indexName),
 			expectedErrStr: "Warning: The requested record was not found. If the record is in a set, you may also need to provide the --set flag.",
 		},
+		{
+			name:           "query using an invalid filter",
+			cmd:            fmt.Sprintf("query --namespace %s -i %s --filter tenant==", namespace, indexName),
+			expectedErrStr: "Error: invalid argument \"tenant==\" for \"--filter\" flag: expected a value at position 8",
+		},
 		{
 			name:           "query using an invalid int key",
 			cmd:            fmt.Sprintf("query --namespace %s -i %s -t DNE", namespace, indexName),
@@ -1754,7 +2639,7 @@ func (suite *CmdTestSuite) TestSuccessfulListRolesCmd() {
 	}{
 		{
 			"roles list",
-			"role list --format 1",
+			"role list -o csv",
 			`,Roles
 1,admin
 2,read-write
@@ -1771,6 +2656,203 @@ func (suite *CmdTestSuite) TestSuccessfulListRolesCmd() {
 	}
 }
 
+func (suite *CmdTestSuite) TestSuccessfulRecordCmds() {
+	set := "record-cmds"
+	key := "record-cmds-key"
+
+	lines, stderr, err := suite.RunSuiteCmd(strings.Split(
+		fmt.Sprintf("record put -n test -s %s -k %s -f vec -v [1.0,2.0,3.0] --data {\"name\":\"foo\"}", set, key),
+		" ",
+	)...)
+	suite.Assert().NoError(err, "error: %s, stdout: %s stderr: %s", err, lines, stderr)
+	suite.Assert().Contains(lines, fmt.Sprintf("Successfully wrote record test.%s with key %s", set, key))
+
+	record, err := suite.AvsClient.Get(context.Background(), "test", &set, key, nil, nil)
+	suite.Assert().NoError(err)
+	suite.Assert().Equal("foo", record.Data["name"])
+	suite.Assert().Equal([]float32{1.0, 2.0, 3.0}, record.Data["vec"])
+
+	lines, stderr, err = suite.RunSuiteCmd(strings.Split(
+		fmt.Sprintf("record get -n test -s %s -k %s -o csv --no-color", set, key),
+		" ",
+	)...)
+	suite.Assert().NoError(err, "error: %s, stdout: %s stderr: %s", err, lines, stderr)
+	suite.Assert().Contains(lines, "Records")
+	suite.Assert().Contains(lines, "name\\,foo")
+
+	lines, stderr, err = suite.RunSuiteCmd(strings.Split(
+		fmt.Sprintf("record exists -n test -s %s -k %s", set, key),
+		" ",
+	)...)
+	suite.Assert().NoError(err, "error: %s, stdout: %s stderr: %s", err, lines, stderr)
+	suite.Assert().Contains(lines, "exists")
+
+	lines, stderr, err = suite.RunSuiteCmd(strings.Split(
+		fmt.Sprintf("record delete -y -n test -s %s -k %s", set, key),
+		" ",
+	)...)
+	suite.Assert().NoError(err, "error: %s, stdout: %s stderr: %s", err, lines, stderr)
+
+	exists, err := suite.AvsClient.Exists(context.Background(), "test", &set, key)
+	suite.Assert().NoError(err)
+	suite.Assert().False(exists)
+
+	_, stderr, err = suite.RunSuiteCmd(strings.Split(
+		fmt.Sprintf("record exists -n test -s %s -k %s", set, key),
+		" ",
+	)...)
+	suite.Assert().Error(err)
+	suite.Assert().Contains(stderr, "does not exist")
+}
+
+func (suite *CmdTestSuite) TestDataImportCmd() {
+	ns := "test"
+	set := "data-import"
+	index := "data-import"
+
+	err := suite.AvsClient.IndexCreate(
+		context.Background(), ns, index, "vec", uint32(3), protos.VectorDistanceMetric_SQUARED_EUCLIDEAN,
+		&avs.IndexCreateOpts{Sets: []string{set}},
+	)
+	suite.Require().NoError(err)
+
+	defer suite.AvsClient.IndexDrop(context.Background(), ns, index)
+
+	dir := suite.T().TempDir()
+	inputFile := dir + "/records.jsonl"
+	errorFile := dir + "/errors.jsonl"
+
+	err = os.WriteFile(inputFile, []byte(`{"key": "a", "vec": [1.0, 2.0, 3.0], "name": "foo"}
+{"key": 1, "vec": [4, 5, 6]}
+{"key": "bad-dims", "vec": [1.0, 2.0]}
+`), 0o600)
+	suite.Require().NoError(err)
+
+	lines, stderr, err := suite.RunSuiteCmd(strings.Split(
+		fmt.Sprintf("data import -n %s -i %s --file %s --error-file %s", ns, index, inputFile, errorFile),
+		" ",
+	)...)
+	suite.Assert().Error(err, "stdout: %s stderr: %s", lines, stderr)
+	suite.Assert().Contains(lines, fmt.Sprintf("Successfully imported 2 records into %s.%s", ns, set))
+	suite.Assert().Contains(stderr, "Failed to import 1 records")
+
+	record, err := suite.AvsClient.Get(context.Background(), ns, &set, "a", nil, nil)
+	suite.Assert().NoError(err)
+	suite.Assert().Equal("foo", record.Data["name"])
+	suite.Assert().Equal([]float32{1.0, 2.0, 3.0}, record.Data["vec"])
+
+	record, err = suite.AvsClient.Get(context.Background(), ns, &set, int64(1), nil, nil)
+	suite.Assert().NoError(err)
+	suite.Assert().Equal([]float32{4, 5, 6}, record.Data["vec"])
+
+	errors, err := os.ReadFile(errorFile)
+	suite.Assert().NoError(err)
+	suite.Assert().Contains(string(errors), `"key":"bad-dims"`)
+}
+
+func (suite *CmdTestSuite) TestDataExportCmd() {
+	ns := "test"
+	set := "data-export"
+	index := "data-export"
+
+	err := suite.AvsClient.IndexCreate(
+		context.Background(), ns, index, "vec", uint32(3), protos.VectorDistanceMetric_SQUARED_EUCLIDEAN,
+		&avs.IndexCreateOpts{Sets: []string{set}},
+	)
+	suite.Require().NoError(err)
+
+	defer suite.AvsClient.IndexDrop(context.Background(), ns, index)
+
+	err = suite.AvsClient.Upsert(
+		context.Background(), ns, &set, "float", map[string]any{"vec": []float32{1, 2, 3}, "name": "foo"}, false,
+	)
+	suite.Require().NoError(err)
+
+	err = suite.AvsClient.Upsert(
+		context.Background(), ns, &set, int64(1), map[string]any{"vec": []bool{true, false, true}}, false,
+	)
+	suite.Require().NoError(err)
+
+	err = suite.AvsClient.Upsert(context.Background(), ns, &set, "no-vector", map[string]any{"name": "bar"}, false)
+	suite.Require().NoError(err)
+
+	outputFile := suite.T().TempDir() + "/export.jsonl"
+
+	lines, stderr, err := suite.RunSuiteCmd(strings.Split(
+		fmt.Sprintf(
+			"data export -n %s -i %s --include-vector --asdb-host 127.0.0.1:3000 --file %s",
+			ns, index, outputFile,
+		),
+		" ",
+	)...)
+	suite.Assert().NoError(err, "stdout: %s stderr: %s", lines, stderr)
+	suite.Assert().Contains(lines, fmt.Sprintf("Successfully exported 2 records from index %s.%s", ns, index))
+
+	b, err := os.ReadFile(outputFile)
+	suite.Require().NoError(err)
+
+	exported := string(b)
+	suite.Assert().Contains(exported, `{"key":"float","name":"foo","vec":[1,2,3]}`)
+	suite.Assert().Contains(exported, `{"key":1,"vec":[true,false,true]}`)
+	suite.Assert().NotContains(exported, "no-vector")
+}
+
+func (suite *CmdTestSuite) TestVectorCmd() {
+	testCases := []struct {
+		name           string
+		cmd            string
+		expectedOutput string
+		expectedErrStr string
+	}{
+		{
+			name:           "cosine distance",
+			cmd:            "vector distance --a [1,0,2] --b [0,1,2] -m COSINE",
+			expectedOutput: "0.2\n",
+		},
+		{
+			name:           "dot product distance of float and bool vectors",
+			cmd:            "vector distance --a [0.5,2] --b [1,1] -m dot_product",
+			expectedOutput: "-2.5\n",
+		},
+		{
+			name:           "hamming distance of bool vectors",
+			cmd:            "vector distance --a [1,0,1,1] --b [1,1,0,1] -m HAMMING",
+			expectedOutput: "2\n",
+		},
+		{
+			name:           "normalize",
+			cmd:            "vector normalize -v [3,0,4]",
+			expectedOutput: "[0.6,0,0.8]\n",
+		},
+		{
+			name:           "different dimensions",
+			cmd:            "vector distance --a [1,2] --b [1] -m COSINE",
+			expectedErrStr: "Error: vectors have different dimensions 2 and 1",
+		},
+		{
+			name:           "normalize zero vector",
+			cmd:            "vector normalize -v [0,0,0]",
+			expectedErrStr: "Error: unable to normalize a vector with length 0",
+		},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.name, func() {
+			lines, stderr, err := suite.RunCmd(strings.Split(tc.cmd, " ")...)
+
+			if tc.expectedErrStr != "" {
+				suite.Assert().Error(err)
+				suite.Assert().Contains(stderr, tc.expectedErrStr)
+
+				return
+			}
+
+			suite.Assert().NoError(err, "stdout: %s stderr: %s", lines, stderr)
+			suite.Assert().Equal(tc.expectedOutput, lines)
+		})
+	}
+}
+
 func (suite *CmdTestSuite) TestFailInvalidArg() {
 	testCases := []struct {
 		name           string
@@ -1968,6 +3050,30 @@ func (suite *CmdTestSuite) TestConfigFile() {
 	suite.NoError(err, "err: %s, stdout: %s, stderr: %s", err, stdout, stderr)
 }
 
+func (suite *CmdTestSuite) TestMultiClusterCmd() {
+	configFile := "tests/asvec_.yml"
+
+	for _, subCmd := range []string{"index ls", "node ls", "user ls"} {
+		suite.Run(subCmd, func() {
+			cmd := fmt.Sprintf(
+				"%s --output jsonl --config-file %s --cluster-name %s,missing",
+				subCmd, configFile, suite.configFileClusterName,
+			)
+
+			stdout, stderr, err := suite.RunCmd(strings.Split(cmd, " ")...)
+			suite.Assert().Error(err, "the missing cluster sets the exit code")
+			suite.Assert().Contains(stderr, `Cluster missing: cluster "missing" not found`)
+
+			for _, line := range strings.Split(strings.TrimSpace(stdout), "\n") {
+				suite.Assert().True(
+					strings.HasPrefix(line, fmt.Sprintf(`{"cluster":%q`, suite.configFileClusterName)),
+					"line: %s stderr: %s", line, stderr,
+				)
+			}
+		})
+	}
+}
+
 func (suite *CmdTestSuite) TestEnvVars() {
 	convertArgsToEnvs := func(args []string) []string {
 		envs := make([]string, 0)
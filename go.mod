@@ -3,6 +3,7 @@ module asvec
 go 1.23.4
 
 require (
+	github.com/aerospike/aerospike-client-go/v7 v7.9.0
 	github.com/aerospike/avs-client-go v0.5.1-0.20250306234941-eeb92311a5ef
 	github.com/aerospike/tools-common-go v0.2.0
 	github.com/jedib0t/go-pretty/v6 v6.6.7
@@ -11,12 +12,13 @@ require (
 	github.com/spf13/viper v1.20.1
 	github.com/stretchr/testify v1.10.0
 	golang.org/x/term v0.30.0
+	google.golang.org/grpc v1.69.4
 	google.golang.org/protobuf v1.36.6
 	gopkg.in/yaml.v3 v3.0.1
+	k8s.io/client-go v0.32.3
 )
 
 require (
-	github.com/aerospike/aerospike-client-go/v7 v7.9.0 // indirect
 	github.com/davecgh/go-spew v1.1.2-0.20180830191138-d8f796af33cc // indirect
 	github.com/fsnotify/fsnotify v1.8.0 // indirect
 	github.com/go-viper/mapstructure/v2 v2.2.1 // indirect
@@ -40,5 +42,4 @@ require (
 	golang.org/x/text v0.23.0 // indirect
 	golang.org/x/tools v0.29.0 // indirect
 	google.golang.org/genproto/googleapis/rpc v0.0.0-20250115164207-1a7da9e5054f // indirect
-	google.golang.org/grpc v1.69.4 // indirect
 )
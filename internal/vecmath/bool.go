@@ -0,0 +1,71 @@
+package vecmath
+
+import (
+	"fmt"
+	"math"
+	"math/bits"
+
+	"github.com/aerospike/avs-client-go/protos"
+)
+
+// BoolDistance returns the distance between bool vectors a and b using
+// metric. The vectors are packed into 64 bit words so every metric is a
+// population count: for 0s and 1s the squared euclidean, manhattan, and
+// hamming distances are all the number of differing elements.
+func BoolDistance(metric protos.VectorDistanceMetric, a, b []bool) (float32, error) {
+	if len(a) != len(b) {
+		return 0, fmt.Errorf("vectors have different dimensions %d and %d", len(a), len(b))
+	}
+
+	pa := packBits(a)
+	pb := packBits(b)
+
+	switch metric {
+	case protos.VectorDistanceMetric_SQUARED_EUCLIDEAN,
+		protos.VectorDistanceMetric_MANHATTAN,
+		protos.VectorDistanceMetric_HAMMING:
+		return float32(popCount(pa, pb, func(x, y uint64) uint64 { return x ^ y })), nil
+	case protos.VectorDistanceMetric_COSINE:
+		normA := popCount(pa, pa, func(x, _ uint64) uint64 { return x })
+		normB := popCount(pb, pb, func(x, _ uint64) uint64 { return x })
+
+		if normA == 0 || normB == 0 {
+			return 1, nil
+		}
+
+		dot := popCount(pa, pb, func(x, y uint64) uint64 { return x & y })
+
+		return float32(1 - float64(dot)/math.Sqrt(float64(normA)*float64(normB))), nil
+	case protos.VectorDistanceMetric_DOT_PRODUCT:
+		return -float32(popCount(pa, pb, func(x, y uint64) uint64 { return x & y })), nil
+	default:
+		return 0, fmt.Errorf("unsupported distance metric %s", metric)
+	}
+}
+
+// packBits packs v into 64 bit words, the first element in the lowest bit.
+func packBits(v []bool) []uint64 {
+	packed := make([]uint64, (len(v)+63)/64)
+
+	for i, b := range v {
+		if b {
+			packed[i/64] |= 1 << (i % 64)
+		}
+	}
+
+	return packed
+}
+
+// popCount returns the number of bits set in op of each pair of words of a
+// and b.
+func popCount(a, b []uint64, op func(x, y uint64) uint64) int {
+	b = b[:len(a)]
+
+	var n int
+
+	for i := range a {
+		n += bits.OnesCount64(op(a[i], b[i]))
+	}
+
+	return n
+}
@@ -0,0 +1,210 @@
+// Package vecmath implements the vector distance metrics of AVS on the
+// client, so that distances returned by the server can be checked and exact
+// nearest neighbors computed.
+//
+// Smaller distances are always closer, so the dot product distance is the
+// negated dot product. Bool vectors are treated as vectors of 0s and 1s.
+//
+// The float32 loops are unrolled into four independent sums so that the
+// compiler can keep them in registers and CPUs can run them in parallel.
+package vecmath
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/aerospike/avs-client-go/protos"
+)
+
+// Distance returns the distance between vectors a and b, each a []float32 or
+// []bool, using metric. Two bool vectors are compared with BoolDistance,
+// anything else with Float32Distance.
+func Distance(metric protos.VectorDistanceMetric, a, b any) (float32, error) {
+	if ab, ok := a.([]bool); ok {
+		if bb, ok := b.([]bool); ok {
+			return BoolDistance(metric, ab, bb)
+		}
+	}
+
+	af, err := ToFloat32(a)
+	if err != nil {
+		return 0, err
+	}
+
+	bf, err := ToFloat32(b)
+	if err != nil {
+		return 0, err
+	}
+
+	return Float32Distance(metric, af, bf)
+}
+
+// Float32Distance returns the distance between a and b using metric.
+func Float32Distance(metric protos.VectorDistanceMetric, a, b []float32) (float32, error) {
+	if len(a) != len(b) {
+		return 0, fmt.Errorf("vectors have different dimensions %d and %d", len(a), len(b))
+	}
+
+	switch metric {
+	case protos.VectorDistanceMetric_SQUARED_EUCLIDEAN:
+		return SquaredEuclidean(a, b), nil
+	case protos.VectorDistanceMetric_COSINE:
+		return Cosine(a, b), nil
+	case protos.VectorDistanceMetric_DOT_PRODUCT:
+		return -DotProduct(a, b), nil
+	case protos.VectorDistanceMetric_MANHATTAN:
+		return Manhattan(a, b), nil
+	case protos.VectorDistanceMetric_HAMMING:
+		return Hamming(a, b), nil
+	default:
+		return 0, fmt.Errorf("unsupported distance metric %s", metric)
+	}
+}
+
+// SquaredEuclidean returns the squared euclidean distance between a and b,
+// which must have the same length.
+func SquaredEuclidean(a, b []float32) float32 {
+	b = b[:len(a)]
+
+	var s0, s1, s2, s3 float32
+
+	i := 0
+	for ; i+4 <= len(a); i += 4 {
+		d0 := a[i] - b[i]
+		d1 := a[i+1] - b[i+1]
+		d2 := a[i+2] - b[i+2]
+		d3 := a[i+3] - b[i+3]
+		s0 += d0 * d0
+		s1 += d1 * d1
+		s2 += d2 * d2
+		s3 += d3 * d3
+	}
+
+	for ; i < len(a); i++ {
+		d := a[i] - b[i]
+		s0 += d * d
+	}
+
+	return s0 + s1 + s2 + s3
+}
+
+// DotProduct returns the dot product of a and b, which must have the same
+// length.
+func DotProduct(a, b []float32) float32 {
+	b = b[:len(a)]
+
+	var s0, s1, s2, s3 float32
+
+	i := 0
+	for ; i+4 <= len(a); i += 4 {
+		s0 += a[i] * b[i]
+		s1 += a[i+1] * b[i+1]
+		s2 += a[i+2] * b[i+2]
+		s3 += a[i+3] * b[i+3]
+	}
+
+	for ; i < len(a); i++ {
+		s0 += a[i] * b[i]
+	}
+
+	return s0 + s1 + s2 + s3
+}
+
+// Cosine returns the cosine distance, 1 minus the cosine similarity, between
+// a and b, which must have the same length. The distance to a zero vector is
+// 1.
+func Cosine(a, b []float32) float32 {
+	normA := DotProduct(a, a)
+	normB := DotProduct(b, b)
+
+	if normA == 0 || normB == 0 {
+		return 1
+	}
+
+	return float32(1 - float64(DotProduct(a, b))/math.Sqrt(float64(normA)*float64(normB)))
+}
+
+// Manhattan returns the sum of the absolute differences of a and b, which
+// must have the same length.
+func Manhattan(a, b []float32) float32 {
+	b = b[:len(a)]
+
+	var s0, s1, s2, s3 float32
+
+	i := 0
+	for ; i+4 <= len(a); i += 4 {
+		s0 += abs(a[i] - b[i])
+		s1 += abs(a[i+1] - b[i+1])
+		s2 += abs(a[i+2] - b[i+2])
+		s3 += abs(a[i+3] - b[i+3])
+	}
+
+	for ; i < len(a); i++ {
+		s0 += abs(a[i] - b[i])
+	}
+
+	return s0 + s1 + s2 + s3
+}
+
+// Hamming returns the number of elements which differ between a and b, which
+// must have the same length.
+func Hamming(a, b []float32) float32 {
+	b = b[:len(a)]
+
+	var n int
+
+	for i := range a {
+		if a[i] != b[i] {
+			n++
+		}
+	}
+
+	return float32(n)
+}
+
+// Norm returns the euclidean length of v.
+func Norm(v []float32) float32 {
+	return float32(math.Sqrt(float64(DotProduct(v, v))))
+}
+
+// Normalize returns a copy of v scaled to a length of 1. A cosine index
+// gives the same results for a vector and its normalized copy, and dot
+// product indexes usually expect normalized vectors.
+func Normalize(v []float32) ([]float32, error) {
+	norm := Norm(v)
+	if norm == 0 || math.IsInf(float64(norm), 0) || math.IsNaN(float64(norm)) {
+		return nil, fmt.Errorf("unable to normalize a vector with length %v", norm)
+	}
+
+	normalized := make([]float32, len(v))
+	for i, f := range v {
+		normalized[i] = f / norm
+	}
+
+	return normalized, nil
+}
+
+// ToFloat32 converts a []float32 or []bool vector into a []float32. Bool
+// vectors become vectors of 0s and 1s.
+func ToFloat32(vector any) ([]float32, error) {
+	switch v := vector.(type) {
+	case []float32:
+		return v, nil
+	case []bool:
+		f := make([]float32, len(v))
+
+		for i, b := range v {
+			if b {
+				f[i] = 1
+			}
+		}
+
+		return f, nil
+	default:
+		return nil, fmt.Errorf("unsupported vector type %T", vector)
+	}
+}
+
+func abs(f float32) float32 {
+	return float32(math.Abs(float64(f)))
+}
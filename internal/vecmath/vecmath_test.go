@@ -0,0 +1,152 @@
+//go:build unit
+
+package vecmath
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/aerospike/avs-client-go/protos"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var metrics = []protos.VectorDistanceMetric{
+	protos.VectorDistanceMetric_SQUARED_EUCLIDEAN,
+	protos.VectorDistanceMetric_COSINE,
+	protos.VectorDistanceMetric_DOT_PRODUCT,
+	protos.VectorDistanceMetric_MANHATTAN,
+	protos.VectorDistanceMetric_HAMMING,
+}
+
+func TestFloat32Distance(t *testing.T) {
+	a := []float32{1, 0, 2}
+	b := []float32{0, 1, 2}
+
+	testCases := []struct {
+		metric   protos.VectorDistanceMetric
+		expected float32
+	}{
+		{protos.VectorDistanceMetric_SQUARED_EUCLIDEAN, 2},
+		{protos.VectorDistanceMetric_COSINE, 0.2},
+		{protos.VectorDistanceMetric_DOT_PRODUCT, -4},
+		{protos.VectorDistanceMetric_MANHATTAN, 2},
+		{protos.VectorDistanceMetric_HAMMING, 2},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.metric.String(), func(t *testing.T) {
+			d, err := Float32Distance(tc.metric, a, b)
+			assert.NoError(t, err)
+			assert.InDelta(t, tc.expected, d, 1e-6)
+		})
+	}
+
+	_, err := Float32Distance(protos.VectorDistanceMetric_SQUARED_EUCLIDEAN, a, []float32{1})
+	assert.ErrorContains(t, err, "different dimensions 3 and 1")
+
+	_, err = Float32Distance(protos.VectorDistanceMetric(100), a, b)
+	assert.ErrorContains(t, err, "unsupported distance metric")
+
+	d, err := Float32Distance(protos.VectorDistanceMetric_COSINE, a, []float32{0, 0, 0})
+	assert.NoError(t, err)
+	assert.Equal(t, float32(1), d)
+}
+
+// TestUnrolledLoops checks the unrolled loops against simple ones for
+// lengths which do and don't fill the last group of four.
+func TestUnrolledLoops(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+
+	for n := 0; n <= 17; n++ {
+		a := make([]float32, n)
+		b := make([]float32, n)
+
+		var sq, dot, manhattan float64
+
+		for i := range a {
+			a[i] = r.Float32()*2 - 1
+			b[i] = r.Float32()*2 - 1
+			sq += float64(a[i]-b[i]) * float64(a[i]-b[i])
+			dot += float64(a[i]) * float64(b[i])
+			manhattan += math.Abs(float64(a[i] - b[i]))
+		}
+
+		assert.InDelta(t, sq, SquaredEuclidean(a, b), 1e-5, "length %d", n)
+		assert.InDelta(t, dot, DotProduct(a, b), 1e-5, "length %d", n)
+		assert.InDelta(t, manhattan, Manhattan(a, b), 1e-5, "length %d", n)
+	}
+}
+
+func TestBoolDistance(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+
+	// Lengths either side of a 64 bit word boundary.
+	for _, n := range []int{0, 1, 5, 63, 64, 65, 130} {
+		a := make([]bool, n)
+		b := make([]bool, n)
+
+		for i := range a {
+			a[i] = r.Intn(2) == 1
+			b[i] = r.Intn(2) == 1
+		}
+
+		af, err := ToFloat32(a)
+		require.NoError(t, err)
+
+		bf, err := ToFloat32(b)
+		require.NoError(t, err)
+
+		for _, metric := range metrics {
+			expected, err := Float32Distance(metric, af, bf)
+			require.NoError(t, err)
+
+			actual, err := BoolDistance(metric, a, b)
+			require.NoError(t, err)
+			assert.InDelta(t, expected, actual, 1e-6, "%s length %d", metric, n)
+		}
+	}
+
+	_, err := BoolDistance(protos.VectorDistanceMetric_HAMMING, []bool{true}, []bool{true, false})
+	assert.ErrorContains(t, err, "different dimensions 1 and 2")
+}
+
+func TestDistance(t *testing.T) {
+	d, err := Distance(protos.VectorDistanceMetric_HAMMING, []bool{true, false, true}, []bool{true, true, false})
+	assert.NoError(t, err)
+	assert.Equal(t, float32(2), d)
+
+	d, err = Distance(protos.VectorDistanceMetric_DOT_PRODUCT, []bool{true, false}, []float32{0.5, 2})
+	assert.NoError(t, err)
+	assert.Equal(t, float32(-0.5), d)
+
+	_, err = Distance(protos.VectorDistanceMetric_DOT_PRODUCT, []float32{1}, "foo")
+	assert.ErrorContains(t, err, "unsupported vector type string")
+}
+
+func TestNormalize(t *testing.T) {
+	v, err := Normalize([]float32{3, 0, -4})
+	assert.NoError(t, err)
+	assert.InDeltaSlice(t, []float32{0.6, 0, -0.8}, v, 1e-6)
+	assert.InDelta(t, 1, Norm(v), 1e-6)
+
+	_, err = Normalize([]float32{0, 0})
+	assert.ErrorContains(t, err, "unable to normalize a vector with length 0")
+
+	_, err = Normalize(nil)
+	assert.Error(t, err)
+}
+
+func TestToFloat32(t *testing.T) {
+	v, err := ToFloat32([]bool{true, false})
+	assert.NoError(t, err)
+	assert.Equal(t, []float32{1, 0}, v)
+
+	v, err = ToFloat32([]float32{0.5})
+	assert.NoError(t, err)
+	assert.Equal(t, []float32{0.5}, v)
+
+	_, err = ToFloat32("foo")
+	assert.Error(t, err)
+}